@@ -177,29 +177,29 @@ func buildWeekSample(recency float64, offset int) weekSample {
 
 	return weekSample{
 		health: models.HealthMetrics{
-			SleepScore:     clampInt(72+int(math.Round(recency*10))+int(math.Round(waveA*3)), 66, 90),
-			WaistCm:        clampFloat(87.2-recency*2.4+(waveB*0.4), 82.5, 90.0),
-			BodyWeightKg:   clampFloat(78.0-recency*3.0+(waveB*0.5), 72.0, 82.0),
-			RHR:            clampInt(66-int(math.Round(recency*6))+int(math.Round(waveA)), 56, 69),
-			SystolicBP:     clampInt(124-int(math.Round(recency*5))+int(math.Round(waveB*2)), 114, 130),
-			DiastolicBP:    clampInt(82-int(math.Round(recency*4))+int(math.Round(waveA)), 72, 88),
-			NutritionScore: clampFloat(6.6+recency*1.6+(waveB*0.25), 5.8, 8.8),
+			SleepScore:     models.Int(clampInt(72+int(math.Round(recency*10))+int(math.Round(waveA*3)), 66, 90)),
+			WaistCm:        models.Float(clampFloat(87.2-recency*2.4+(waveB*0.4), 82.5, 90.0)),
+			BodyWeightKg:   models.Float(clampFloat(78.0-recency*3.0+(waveB*0.5), 72.0, 82.0)),
+			RHR:            models.Int(clampInt(66-int(math.Round(recency*6))+int(math.Round(waveA)), 56, 69)),
+			SystolicBP:     models.Int(clampInt(124-int(math.Round(recency*5))+int(math.Round(waveB*2)), 114, 130)),
+			DiastolicBP:    models.Int(clampInt(82-int(math.Round(recency*4))+int(math.Round(waveA)), 72, 88)),
+			NutritionScore: models.Float(clampFloat(6.6+recency*1.6+(waveB*0.25), 5.8, 8.8)),
 		},
 		fitness: models.FitnessMetrics{
-			VO2Max:          clampFloat(38.5+recency*6.0+(waveA*0.8), 36.5, 47.0),
-			Workouts:        clampInt(2+int(math.Round(recency*2))+positiveSwing(offset, 3), 1, 5),
-			DailySteps:      clampInt(6900+int(math.Round(recency*2600))+int(math.Round(waveB*700)), 5500, 12000),
-			Mobility:        clampInt(1+int(math.Round(recency*2))+positiveSwing(offset+1, 4), 1, 4),
-			CardioRecovery:  clampInt(21+int(math.Round(recency*6))+int(math.Round(waveA*2)), 17, 32),
-			LowerBodyWeight: clampFloat(165+recency*38+(waveB*6), 150, 230),
-			LowerBodyReps:   clampInt(8+positiveSwing(offset+2, 5)+int(math.Round(recency*2)), 8, 13),
-			DeadHangSeconds: clampInt(35+int(math.Round(recency*50))+int(math.Round(waveA*8)), 25, 95),
+			VO2Max:          models.Float(clampFloat(38.5+recency*6.0+(waveA*0.8), 36.5, 47.0)),
+			Workouts:        models.Int(clampInt(2+int(math.Round(recency*2))+positiveSwing(offset, 3), 1, 5)),
+			DailySteps:      models.Int(clampInt(6900+int(math.Round(recency*2600))+int(math.Round(waveB*700)), 5500, 12000)),
+			Mobility:        models.Int(clampInt(1+int(math.Round(recency*2))+positiveSwing(offset+1, 4), 1, 4)),
+			CardioRecovery:  models.Int(clampInt(21+int(math.Round(recency*6))+int(math.Round(waveA*2)), 17, 32)),
+			LowerBodyWeight: models.Float(clampFloat(165+recency*38+(waveB*6), 150, 230)),
+			LowerBodyReps:   models.Int(clampInt(8+positiveSwing(offset+2, 5)+int(math.Round(recency*2)), 8, 13)),
+			DeadHangSeconds: models.Int(clampInt(35+int(math.Round(recency*50))+int(math.Round(waveA*8)), 25, 95)),
 		},
 		cognition: models.CognitionMetrics{
-			Mindfulness:  clampInt(1+int(math.Round(recency*2))+positiveSwing(offset+3, 4), 1, 5),
-			DeepLearning: clampInt(50+int(math.Round(recency*55))+int(math.Round(waveA*12)), 30, 130),
-			StressScore:  clampInt(4-int(math.Round(recency*2))+positiveSwing(offset+4, 6)-1, 1, 4),
-			SocialDays:   clampInt(2+int(math.Round(recency*2))+positiveSwing(offset+5, 3), 1, 6),
+			Mindfulness:  models.Int(clampInt(1+int(math.Round(recency*2))+positiveSwing(offset+3, 4), 1, 5)),
+			DeepLearning: models.Int(clampInt(50+int(math.Round(recency*55))+int(math.Round(waveA*12)), 30, 130)),
+			StressScore:  models.Int(clampInt(4-int(math.Round(recency*2))+positiveSwing(offset+4, 6)-1, 1, 4)),
+			SocialDays:   models.Int(clampInt(2+int(math.Round(recency*2))+positiveSwing(offset+5, 3), 1, 6)),
 		},
 	}
 }
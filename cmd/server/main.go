@@ -49,10 +49,35 @@ func main() {
 
 	mux.HandleFunc("/", h.HandleHome)
 	mux.HandleFunc("/settings", h.HandleSettings)
+	mux.HandleFunc("POST /ai-privacy", h.HandleSaveAIPrivacy)
+	mux.HandleFunc("POST /ai-privacy/preview", h.HandleAIPrivacyPreview)
+	mux.HandleFunc("POST /ai-prompt", h.HandleSaveAIPrompt)
+	mux.HandleFunc("DELETE /ai-prompt", h.HandleResetAIPrompt)
+	mux.HandleFunc("/ai-prompt/preview", h.HandleAIPromptPreview)
+	mux.HandleFunc("POST /auto-summary", h.HandleSaveAutoSummary)
 	mux.HandleFunc("/rationale", h.HandleRationale)
 	mux.HandleFunc("/update-profile", h.HandleUpdateProfile)
 	mux.HandleFunc("/current-score", h.HandleCurrentScore)
 	mux.HandleFunc("/scores", h.HandleScores)
+	mux.HandleFunc("/api/scores", h.HandleScoresAPI)
+	mux.HandleFunc("/biological-age", h.HandleBiologicalAge)
+	mux.HandleFunc("/chart", h.HandleChart)
+	mux.HandleFunc("/api/chart", h.HandleChartAPI)
+	mux.HandleFunc("/history", h.HandleHistory)
+	mux.HandleFunc("/history-rows", h.HandleHistoryRows)
+	mux.HandleFunc("/report", h.HandleReport)
+	mux.HandleFunc("/report.pdf", h.HandleReportPDF)
+	mux.HandleFunc("GET /review", h.HandleReviewIndex)
+	mux.HandleFunc("/correlations", h.HandleCorrelations)
+	mux.HandleFunc("GET /goals", h.HandleGoals)
+	mux.HandleFunc("POST /goals", h.HandleCreateGoal)
+	mux.HandleFunc("DELETE /goals/{id}", h.HandleDeleteGoal)
+	mux.HandleFunc("GET /interventions", h.HandleInterventions)
+	mux.HandleFunc("POST /interventions", h.HandleCreateIntervention)
+	mux.HandleFunc("POST /interventions/{id}/end", h.HandleEndIntervention)
+	mux.HandleFunc("DELETE /interventions/{id}", h.HandleDeleteIntervention)
+	mux.HandleFunc("/correlations/pair", h.HandleCorrelationPair)
+	mux.HandleFunc("GET /review/{year}", h.HandleYearReview)
 	mux.HandleFunc("/health-metrics", h.HandleHealthMetrics)
 	mux.HandleFunc("/health-week-state", h.HandleHealthWeekState)
 	mux.HandleFunc("/add-health-metrics", h.HandleAddHealthMetrics)
@@ -63,11 +88,20 @@ func main() {
 	mux.HandleFunc("/delete-fitness-metric", h.HandleDeleteFitnessMetric)
 	mux.HandleFunc("/cognition-metrics", h.HandleCognitionMetrics)
 	mux.HandleFunc("/cognition-week-state", h.HandleCognitionWeekState)
+	mux.HandleFunc("GET /week-note", h.HandleWeekNote)
+	mux.HandleFunc("POST /week-note", h.HandleSaveWeekNote)
 	mux.HandleFunc("/add-cognition-metrics", h.HandleAddCognitionMetrics)
 	mux.HandleFunc("/delete-cognition-metric", h.HandleDeleteCognitionMetric)
 	mux.HandleFunc("/subscribe", h.HandleSubscribe)
 	mux.HandleFunc("/unsubscribe", h.HandleUnsubscribe)
 	mux.HandleFunc("/ai-summary", h.HandleAiSummary)
+	mux.HandleFunc("GET /ai-summary/stream", h.HandleAiSummaryStream)
+	mux.HandleFunc("GET /ai-summaries", h.HandleAiSummaryHistory)
+	mux.HandleFunc("GET /ai-comparison", h.HandleAIComparisons)
+	mux.HandleFunc("POST /ai-comparison", h.HandleAIComparison)
+	mux.HandleFunc("GET /coach", h.HandleCoach)
+	mux.HandleFunc("POST /coach", h.HandleAskCoach)
+	mux.HandleFunc("DELETE /coach/threads/{id}", h.HandleDeleteCoachThread)
 	mux.HandleFunc("/health", h.HandleAppHealth)
 
 	mux.HandleFunc("/sw.js", func(w http.ResponseWriter, r *http.Request) {
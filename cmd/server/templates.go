@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"health-balance/internal/models"
 	"html/template"
 	"os"
 	"path/filepath"
@@ -16,7 +17,18 @@ func templateFuncMap(webRoot string) template.FuncMap {
 			}
 			return a / b
 		},
-		"lt": func(a, b float64) bool { return a < b },
+		"mulf":     func(a, b float64) float64 { return a * b },
+		"lt":       func(a, b float64) bool { return a < b },
+		"opt":      models.FormatMetric,
+		"legPress": models.FormatLegPress,
+		// limits renders the min, max and step constraints of a validated input
+		"limits": func(key string) template.HTMLAttr {
+			rule, ok := models.LookupFieldRule(key)
+			if !ok {
+				return ""
+			}
+			return template.HTMLAttr(rule.Attributes())
+		},
 		"asset": func(path string) string {
 			trimmed := strings.TrimPrefix(path, "/")
 			diskPath := trimmed
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"database/sql"
+	"health-balance/internal/models"
+	"log"
+)
+
+const aiComparisonColumns = "id, baseline_from, baseline_to, from_date, to_date, provider, model, prompt_hash, text, created_at"
+
+// GetAIComparisons returns every generated period comparison, newest first
+func (db *DB) GetAIComparisons() ([]models.AIComparison, error) {
+	rows, err := db.Query("SELECT " + aiComparisonColumns + " FROM ai_comparisons ORDER BY created_at DESC, id DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("error closing rows for GetAIComparisons: %v", err)
+		}
+	}()
+
+	var comparisons []models.AIComparison
+	for rows.Next() {
+		c, err := scanAIComparison(rows)
+		if err != nil {
+			return nil, err
+		}
+		comparisons = append(comparisons, *c)
+	}
+	return comparisons, rows.Err()
+}
+
+// GetAIComparisonByPrompt returns the latest comparison generated by model for the prompt
+// with the given hash, or nil when there is none
+func (db *DB) GetAIComparisonByPrompt(promptHash, provider, model string) (*models.AIComparison, error) {
+	c, err := scanAIComparison(db.QueryRow(`
+		SELECT `+aiComparisonColumns+`
+		FROM ai_comparisons
+		WHERE prompt_hash = ? AND provider = ? AND model = ?
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`, promptHash, provider, model))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return c, err
+}
+
+// SaveAIComparison stores a generated comparison and returns its id
+func (db *DB) SaveAIComparison(c models.AIComparison) (int, error) {
+	res, err := db.Exec(`
+		INSERT INTO ai_comparisons (baseline_from, baseline_to, from_date, to_date, provider, model, prompt_hash, text, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, c.Baseline.From, c.Baseline.To, c.Current.From, c.Current.To, c.Provider, c.Model, c.PromptHash, c.Text, formatTimestamp(c.CreatedAt))
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	return int(id), err
+}
+
+func scanAIComparison(row interface{ Scan(...any) error }) (*models.AIComparison, error) {
+	var (
+		c         models.AIComparison
+		createdAt string
+	)
+	if err := row.Scan(&c.ID, &c.Baseline.From, &c.Baseline.To, &c.Current.From, &c.Current.To,
+		&c.Provider, &c.Model, &c.PromptHash, &c.Text, &createdAt); err != nil {
+		return nil, err
+	}
+	var err error
+	if c.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"database/sql"
+	"health-balance/internal/models"
+	"strings"
+)
+
+// GetAIPrivacy returns the privacy settings of the AI features, sharing everything until
+// they are saved
+func (db *DB) GetAIPrivacy() (models.AIPrivacy, error) {
+	var (
+		p      models.AIPrivacy
+		hidden string
+	)
+	err := db.QueryRow(`
+		SELECT hide_age, hide_sex, hide_height, hide_notes, coarsen, hidden_metrics
+		FROM ai_privacy WHERE id = 1
+	`).Scan(&p.HideAge, &p.HideSex, &p.HideHeight, &p.HideNotes, &p.Coarsen, &hidden)
+	if err == sql.ErrNoRows {
+		return models.AIPrivacy{}, nil
+	}
+	if err != nil {
+		return models.AIPrivacy{}, err
+	}
+	if hidden != "" {
+		p.HiddenMetrics = strings.Split(hidden, ",")
+	}
+	return p, nil
+}
+
+// SaveAIPrivacy replaces the privacy settings of the AI features
+func (db *DB) SaveAIPrivacy(p models.AIPrivacy) error {
+	_, err := db.Exec(`
+		INSERT INTO ai_privacy (id, hide_age, hide_sex, hide_height, hide_notes, coarsen, hidden_metrics)
+		VALUES (1, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			hide_age = excluded.hide_age,
+			hide_sex = excluded.hide_sex,
+			hide_height = excluded.hide_height,
+			hide_notes = excluded.hide_notes,
+			coarsen = excluded.coarsen,
+			hidden_metrics = excluded.hidden_metrics
+	`, p.HideAge, p.HideSex, p.HideHeight, p.HideNotes, p.Coarsen, strings.Join(p.HiddenMetrics, ","))
+	return err
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"database/sql"
+	"health-balance/internal/models"
+	"log"
+	"time"
+)
+
+const aiSummaryColumns = "id, week, provider, model, prompt_hash, text, created_at"
+
+// GetAISummaries returns every generated summary, newest first
+func (db *DB) GetAISummaries() ([]models.AISummary, error) {
+	rows, err := db.Query("SELECT " + aiSummaryColumns + " FROM ai_summaries ORDER BY created_at DESC, id DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("error closing rows for GetAISummaries: %v", err)
+		}
+	}()
+
+	var summaries []models.AISummary
+	for rows.Next() {
+		s, err := scanAISummary(rows)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, *s)
+	}
+	return summaries, rows.Err()
+}
+
+// GetLatestAISummary returns the most recently generated summary, or nil when there is none
+func (db *DB) GetLatestAISummary() (*models.AISummary, error) {
+	s, err := scanAISummary(db.QueryRow("SELECT " + aiSummaryColumns + " FROM ai_summaries ORDER BY created_at DESC, id DESC LIMIT 1"))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return s, err
+}
+
+// GetAISummaryByPrompt returns the latest summary generated by model for the prompt with
+// the given hash, or nil when there is none
+func (db *DB) GetAISummaryByPrompt(promptHash, provider, model string) (*models.AISummary, error) {
+	s, err := scanAISummary(db.QueryRow(`
+		SELECT `+aiSummaryColumns+`
+		FROM ai_summaries
+		WHERE prompt_hash = ? AND provider = ? AND model = ?
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`, promptHash, provider, model))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return s, err
+}
+
+// SaveAISummary stores a generated summary and returns its id
+func (db *DB) SaveAISummary(s models.AISummary) (int, error) {
+	res, err := db.Exec(`
+		INSERT INTO ai_summaries (week, provider, model, prompt_hash, text, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, s.Week, s.Provider, s.Model, s.PromptHash, s.Text, formatTimestamp(s.CreatedAt))
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	return int(id), err
+}
+
+func scanAISummary(row interface{ Scan(...any) error }) (*models.AISummary, error) {
+	var (
+		s         models.AISummary
+		createdAt string
+	)
+	if err := row.Scan(&s.ID, &s.Week, &s.Provider, &s.Model, &s.PromptHash, &s.Text, &createdAt); err != nil {
+		return nil, err
+	}
+	var err error
+	if s.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// formatTimestamp stores times as UTC RFC 3339 so they sort as text
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func parseTimestamp(value string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	return t.Local(), err
+}
@@ -0,0 +1,51 @@
+package database
+
+import (
+	"health-balance/internal/models"
+	"log"
+	"time"
+)
+
+// AddAIUsage records a call to the language model provider
+func (db *DB) AddAIUsage(u models.AIUsage) error {
+	_, err := db.Exec(`
+		INSERT INTO ai_usage (created_at, feature, provider, model, status, input_tokens, output_tokens, latency_ms, cost)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, formatTimestamp(u.CreatedAt), u.Feature, u.Provider, u.Model, u.Status, u.InputTokens, u.OutputTokens, u.Latency.Milliseconds(), u.Cost)
+	return err
+}
+
+// GetAIUsage returns the calls to the language model provider made since the given time,
+// oldest first
+func (db *DB) GetAIUsage(since time.Time) ([]models.AIUsage, error) {
+	rows, err := db.Query(`
+		SELECT id, created_at, feature, provider, model, status, input_tokens, output_tokens, latency_ms, cost
+		FROM ai_usage WHERE created_at >= ? ORDER BY created_at, id
+	`, formatTimestamp(since))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("error closing rows for GetAIUsage: %v", err)
+		}
+	}()
+
+	var usage []models.AIUsage
+	for rows.Next() {
+		var (
+			u                   models.AIUsage
+			createdAt           string
+			latencyMilliseconds int64
+		)
+		if err := rows.Scan(&u.ID, &createdAt, &u.Feature, &u.Provider, &u.Model, &u.Status, &u.InputTokens, &u.OutputTokens, &latencyMilliseconds, &u.Cost); err != nil {
+			return nil, err
+		}
+		if u.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+			return nil, err
+		}
+		u.Latency = time.Duration(latencyMilliseconds) * time.Millisecond
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"database/sql"
+	"health-balance/internal/models"
+)
+
+// GetAutoSummarySettings returns the settings of the automatic weekly AI summary, or the
+// defaults with the summary off until they are saved
+func (db *DB) GetAutoSummarySettings() (models.AutoSummarySettings, error) {
+	var s models.AutoSummarySettings
+	err := db.QueryRow(`
+		SELECT enabled, trigger, day, time, timezone, last_week
+		FROM auto_summary_settings WHERE id = 1
+	`).Scan(&s.Enabled, &s.Trigger, &s.Day, &s.Time, &s.Timezone, &s.LastWeek)
+	if err == sql.ErrNoRows {
+		return models.DefaultAutoSummarySettings(), nil
+	}
+	return s, err
+}
+
+// SaveAutoSummarySettings replaces the settings of the automatic weekly AI summary,
+// keeping the week it last ran for
+func (db *DB) SaveAutoSummarySettings(s models.AutoSummarySettings) error {
+	_, err := db.Exec(`
+		INSERT INTO auto_summary_settings (id, enabled, trigger, day, time, timezone)
+		VALUES (1, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			enabled = excluded.enabled,
+			trigger = excluded.trigger,
+			day = excluded.day,
+			time = excluded.time,
+			timezone = excluded.timezone
+	`, s.Enabled, s.Trigger, s.Day, s.Time, s.Timezone)
+	return err
+}
+
+// SetAutoSummaryWeek records the week an automatic summary was generated for
+func (db *DB) SetAutoSummaryWeek(week string) error {
+	_, err := db.Exec(`UPDATE auto_summary_settings SET last_week = ? WHERE id = 1`, week)
+	return err
+}
@@ -0,0 +1,157 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"health-balance/internal/models"
+	"log"
+)
+
+// GetCoachThreads returns every coach thread, most recently active first
+func (db *DB) GetCoachThreads() ([]models.CoachThread, error) {
+	rows, err := db.Query("SELECT id, title, created_at, updated_at FROM coach_threads ORDER BY updated_at DESC, id DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("error closing rows for GetCoachThreads: %v", err)
+		}
+	}()
+
+	var threads []models.CoachThread
+	for rows.Next() {
+		t, err := scanCoachThread(rows)
+		if err != nil {
+			return nil, err
+		}
+		threads = append(threads, *t)
+	}
+	return threads, rows.Err()
+}
+
+// GetCoachThread returns one thread, or nil when it does not exist
+func (db *DB) GetCoachThread(id int) (*models.CoachThread, error) {
+	t, err := scanCoachThread(db.QueryRow("SELECT id, title, created_at, updated_at FROM coach_threads WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return t, err
+}
+
+// CreateCoachThread stores a new thread and returns its id
+func (db *DB) CreateCoachThread(t models.CoachThread) (int, error) {
+	createdAt := formatTimestamp(t.CreatedAt)
+	res, err := db.Exec("INSERT INTO coach_threads (title, created_at, updated_at) VALUES (?, ?, ?)", t.Title, createdAt, createdAt)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	return int(id), err
+}
+
+// DeleteCoachThread deletes a thread together with its messages
+func (db *DB) DeleteCoachThread(id int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM coach_messages WHERE thread_id = ?", id); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM coach_threads WHERE id = ?", id); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetCoachMessages returns the messages of a thread in the order they were written
+func (db *DB) GetCoachMessages(threadID int) ([]models.CoachMessage, error) {
+	rows, err := db.Query(`
+		SELECT id, thread_id, role, content, created_at
+		FROM coach_messages
+		WHERE thread_id = ?
+		ORDER BY id
+	`, threadID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("error closing rows for GetCoachMessages: %v", err)
+		}
+	}()
+
+	var messages []models.CoachMessage
+	for rows.Next() {
+		var (
+			m         models.CoachMessage
+			createdAt string
+		)
+		if err := rows.Scan(&m.ID, &m.ThreadID, &m.Role, &m.Content, &createdAt); err != nil {
+			return nil, err
+		}
+		if m.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+	return messages, rows.Err()
+}
+
+// ErrCoachThreadNotFound is returned when messages are added to a thread that doesn't exist
+var ErrCoachThreadNotFound = errors.New("coach thread not found")
+
+// AddCoachMessages appends messages to their threads in one transaction, so a question is
+// only stored together with its answer, and marks the threads as updated. Nothing is stored
+// when a thread doesn't exist, e.g. because it was deleted meanwhile.
+func (db *DB) AddCoachMessages(messages []models.CoachMessage) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, m := range messages {
+		createdAt := formatTimestamp(m.CreatedAt)
+		if _, err := tx.Exec(`
+			INSERT INTO coach_messages (thread_id, role, content, created_at)
+			VALUES (?, ?, ?, ?)
+		`, m.ThreadID, m.Role, m.Content, createdAt); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		res, err := tx.Exec("UPDATE coach_threads SET updated_at = ? WHERE id = ?", createdAt, m.ThreadID)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		if updated, err := res.RowsAffected(); err != nil || updated == 0 {
+			_ = tx.Rollback()
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("%w: %d", ErrCoachThreadNotFound, m.ThreadID)
+		}
+	}
+	return tx.Commit()
+}
+
+func scanCoachThread(row interface{ Scan(...any) error }) (*models.CoachThread, error) {
+	var (
+		t                    models.CoachThread
+		createdAt, updatedAt string
+	)
+	if err := row.Scan(&t.ID, &t.Title, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	var err error
+	if t.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+		return nil, err
+	}
+	if t.UpdatedAt, err = parseTimestamp(updatedAt); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"database/sql"
+	"fmt"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -23,6 +24,10 @@ func Init(dbPath string) (*DB, error) {
 		return nil, err
 	}
 
+	if err := migrate(db); err != nil {
+		return nil, err
+	}
+
 	return &DB{db}, nil
 }
 
@@ -74,6 +79,104 @@ func createTables(db *sql.DB) error {
 			reminder_time TEXT NOT NULL DEFAULT '15:00',
 			timezone TEXT NOT NULL DEFAULT 'UTC'
 		);`,
+		`CREATE TABLE IF NOT EXISTS goals (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			metric_key TEXT NOT NULL,
+			comparison TEXT NOT NULL,
+			target REAL NOT NULL,
+			window_weeks INTEGER NOT NULL DEFAULT 1,
+			deadline TEXT NOT NULL DEFAULT '',
+			start_value REAL,
+			created_at TEXT NOT NULL,
+			notify INTEGER NOT NULL DEFAULT 0,
+			last_status TEXT NOT NULL DEFAULT '',
+			achieved_at TEXT NOT NULL DEFAULT ''
+		);`,
+		`CREATE TABLE IF NOT EXISTS interventions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			start_date TEXT NOT NULL,
+			end_date TEXT NOT NULL DEFAULT '',
+			metrics TEXT NOT NULL DEFAULT ''
+		);`,
+		`CREATE TABLE IF NOT EXISTS week_notes (
+			date TEXT PRIMARY KEY,
+			text TEXT NOT NULL DEFAULT '',
+			tags TEXT NOT NULL DEFAULT ''
+		);`,
+		`CREATE TABLE IF NOT EXISTS ai_summaries (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			week TEXT NOT NULL,
+			provider TEXT NOT NULL,
+			model TEXT NOT NULL,
+			prompt_hash TEXT NOT NULL,
+			text TEXT NOT NULL,
+			created_at TEXT NOT NULL
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_ai_summaries_prompt ON ai_summaries (prompt_hash, provider, model);`,
+		`CREATE TABLE IF NOT EXISTS ai_comparisons (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			baseline_from TEXT NOT NULL,
+			baseline_to TEXT NOT NULL,
+			from_date TEXT NOT NULL,
+			to_date TEXT NOT NULL,
+			provider TEXT NOT NULL,
+			model TEXT NOT NULL,
+			prompt_hash TEXT NOT NULL,
+			text TEXT NOT NULL,
+			created_at TEXT NOT NULL
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_ai_comparisons_prompt ON ai_comparisons (prompt_hash, provider, model);`,
+		`CREATE TABLE IF NOT EXISTS coach_threads (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			title TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			updated_at TEXT NOT NULL
+		);`,
+		`CREATE TABLE IF NOT EXISTS ai_usage (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			created_at TEXT NOT NULL,
+			feature TEXT NOT NULL,
+			provider TEXT NOT NULL,
+			model TEXT NOT NULL,
+			status TEXT NOT NULL,
+			input_tokens INTEGER NOT NULL,
+			output_tokens INTEGER NOT NULL,
+			latency_ms INTEGER NOT NULL,
+			cost REAL NOT NULL
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_ai_usage_created_at ON ai_usage (created_at);`,
+		`CREATE TABLE IF NOT EXISTS auto_summary_settings (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			enabled INTEGER NOT NULL DEFAULT 0,
+			trigger TEXT NOT NULL DEFAULT 'complete',
+			day INTEGER NOT NULL DEFAULT 0,
+			time TEXT NOT NULL DEFAULT '18:00',
+			timezone TEXT NOT NULL DEFAULT 'UTC',
+			last_week TEXT NOT NULL DEFAULT ''
+		);`,
+		`CREATE TABLE IF NOT EXISTS coach_messages (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			thread_id INTEGER NOT NULL,
+			role TEXT NOT NULL,
+			content TEXT NOT NULL,
+			created_at TEXT NOT NULL
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_coach_messages_thread ON coach_messages (thread_id, id);`,
+		`CREATE TABLE IF NOT EXISTS ai_privacy (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			hide_age INTEGER NOT NULL DEFAULT 0,
+			hide_sex INTEGER NOT NULL DEFAULT 0,
+			hide_height INTEGER NOT NULL DEFAULT 0,
+			hide_notes INTEGER NOT NULL DEFAULT 0,
+			coarsen INTEGER NOT NULL DEFAULT 0,
+			hidden_metrics TEXT NOT NULL DEFAULT ''
+		);`,
+		`CREATE TABLE IF NOT EXISTS prompt_templates (
+			name TEXT PRIMARY KEY,
+			body TEXT NOT NULL,
+			updated_at TEXT NOT NULL
+		);`,
 	}
 
 	for _, query := range queries {
@@ -84,3 +187,41 @@ func createTables(db *sql.DB) error {
 
 	return nil
 }
+
+// migrations are applied in order and tracked with PRAGMA user_version,
+// so each entry runs exactly once per database. Only ever append to this list.
+var migrations = []string{
+	// Optional metrics used to be stored as 0 when skipped; NULL now means "not recorded".
+	`UPDATE health_metrics SET systolic_bp = NULL WHERE systolic_bp = 0;
+	 UPDATE health_metrics SET diastolic_bp = NULL WHERE diastolic_bp = 0;
+	 UPDATE fitness_metrics SET lower_body_weight = NULL WHERE lower_body_weight = 0;
+	 UPDATE fitness_metrics SET lower_body_reps = NULL WHERE lower_body_reps = 0;
+	 UPDATE fitness_metrics SET dead_hang_seconds = NULL WHERE dead_hang_seconds = 0;`,
+}
+
+func migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d failed: %w", i+1, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record schema version %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -63,3 +63,48 @@ func TestInitError(t *testing.T) {
 		t.Error("Expected error when initializing with invalid path, but got none")
 	}
 }
+
+func TestMigrationsClearZeroPlaceholders(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "legacy.db")
+
+	db, err := Init(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// Simulate a database written before optional metrics were supported
+	if _, err := db.Exec("PRAGMA user_version = 0"); err != nil {
+		t.Fatalf("Failed to reset schema version: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO health_metrics (date, sleep_score, systolic_bp, diastolic_bp) VALUES ('2025-01-05', 80, 0, 0)`); err != nil {
+		t.Fatalf("Failed to insert legacy row: %v", err)
+	}
+	_ = db.Close()
+
+	db, err = Init(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("Error closing database: %v", err)
+		}
+	}()
+
+	var systolic, diastolic *int
+	if err := db.QueryRow("SELECT systolic_bp, diastolic_bp FROM health_metrics WHERE date = '2025-01-05'").Scan(&systolic, &diastolic); err != nil {
+		t.Fatalf("Failed to query migrated row: %v", err)
+	}
+	if systolic != nil || diastolic != nil {
+		t.Errorf("Expected 0 placeholders to become NULL, got %v/%v", systolic, diastolic)
+	}
+
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		t.Fatalf("Failed to read schema version: %v", err)
+	}
+	if version != len(migrations) {
+		t.Errorf("Expected schema version %d, got %d", len(migrations), version)
+	}
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"health-balance/internal/models"
+	"log"
+)
+
+// GetGoals returns every goal, oldest first
+func (db *DB) GetGoals() ([]models.Goal, error) {
+	rows, err := db.Query(`
+		SELECT id, metric_key, comparison, target, window_weeks, deadline, start_value, created_at, notify, last_status, achieved_at
+		FROM goals
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("error closing rows for GetGoals: %v", err)
+		}
+	}()
+
+	var goals []models.Goal
+	for rows.Next() {
+		var g models.Goal
+		if err := rows.Scan(&g.ID, &g.MetricKey, &g.Comparison, &g.Target, &g.WindowWeeks, &g.Deadline, &g.StartValue,
+			&g.CreatedAt, &g.Notify, &g.LastStatus, &g.AchievedAt); err != nil {
+			return nil, err
+		}
+		goals = append(goals, g)
+	}
+	return goals, rows.Err()
+}
+
+// CreateGoal stores a new goal and returns its id
+func (db *DB) CreateGoal(g models.Goal) (int, error) {
+	res, err := db.Exec(`
+		INSERT INTO goals (metric_key, comparison, target, window_weeks, deadline, start_value, created_at, notify, last_status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, g.MetricKey, g.Comparison, g.Target, g.WindowWeeks, g.Deadline, g.StartValue, g.CreatedAt, g.Notify, g.LastStatus)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	return int(id), err
+}
+
+// UpdateGoalStatus records the status last seen for a goal and when it was first achieved
+func (db *DB) UpdateGoalStatus(id int, status, achievedAt string) error {
+	_, err := db.Exec("UPDATE goals SET last_status = ?, achieved_at = ? WHERE id = ?", status, achievedAt, id)
+	return err
+}
+
+func (db *DB) DeleteGoal(id int) error {
+	_, err := db.Exec("DELETE FROM goals WHERE id = ?", id)
+	return err
+}
@@ -0,0 +1,161 @@
+package database
+
+import (
+	"fmt"
+	"health-balance/internal/models"
+	"log"
+	"strings"
+)
+
+// historyPageClause builds the WHERE, ORDER BY and LIMIT clauses for one keyset page of a
+// pillar table. Rows after the cursor are those that sort strictly after it, with the date
+// as a tie-breaker and skipped values last, so pages never overlap or skip rows.
+func historyPageClause(pillar string, q models.HistoryQuery) (string, []any, error) {
+	if !models.HistorySortable(pillar, q.Sort) {
+		return "", nil, fmt.Errorf("cannot sort %s history by %q", pillar, q.Sort)
+	}
+
+	var (
+		conditions []string
+		args       []any
+	)
+	if q.From != "" {
+		conditions = append(conditions, "date >= ?")
+		args = append(args, q.From)
+	}
+	if q.To != "" {
+		conditions = append(conditions, "date <= ?")
+		args = append(args, q.To)
+	}
+
+	direction, comparison := "DESC", "<"
+	if q.Ascending {
+		direction, comparison = "ASC", ">"
+	}
+
+	// The sort column is checked against the metric catalog above, never taken from user input
+	order := fmt.Sprintf("date %s", direction)
+	if q.Sort != models.HistorySortDate {
+		order = fmt.Sprintf("%[1]s IS NULL, %[1]s %[2]s, date %[2]s", q.Sort, direction)
+	}
+
+	if q.After != nil {
+		switch {
+		case q.Sort == models.HistorySortDate:
+			conditions = append(conditions, "date "+comparison+" ?")
+			args = append(args, q.After.Date)
+		case q.After.Value == nil:
+			conditions = append(conditions, fmt.Sprintf("(%s IS NULL AND date %s ?)", q.Sort, comparison))
+			args = append(args, q.After.Date)
+		default:
+			conditions = append(conditions, fmt.Sprintf("(%[1]s IS NULL OR %[1]s %[2]s ? OR (%[1]s = ? AND date %[2]s ?))", q.Sort, comparison))
+			args = append(args, *q.After.Value, *q.After.Value, q.After.Date)
+		}
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = models.DefaultHistoryLimit
+	}
+	args = append(args, limit)
+
+	return fmt.Sprintf("%s ORDER BY %s LIMIT ?", where, order), args, nil
+}
+
+// GetHealthMetricsPage returns one keyset page of health metrics history
+func (db *DB) GetHealthMetricsPage(q models.HistoryQuery) ([]models.HealthMetrics, error) {
+	clause, args, err := historyPageClause(models.PillarHealth, q)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT date, sleep_score, waist_cm, body_weight_kg, rhr, systolic_bp, diastolic_bp, nutrition_score
+		FROM health_metrics
+		`+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("error closing rows for GetHealthMetricsPage: %v", err)
+		}
+	}()
+
+	var metrics []models.HealthMetrics
+	for rows.Next() {
+		var m models.HealthMetrics
+		if err := rows.Scan(&m.Date, &m.SleepScore, &m.WaistCm, &m.BodyWeightKg, &m.RHR, &m.SystolicBP, &m.DiastolicBP, &m.NutritionScore); err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, rows.Err()
+}
+
+// GetFitnessMetricsPage returns one keyset page of fitness metrics history
+func (db *DB) GetFitnessMetricsPage(q models.HistoryQuery) ([]models.FitnessMetrics, error) {
+	clause, args, err := historyPageClause(models.PillarFitness, q)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT date, vo2_max, workouts, daily_steps, mobility, cardio_recovery, lower_body_weight, lower_body_reps, dead_hang_seconds
+		FROM fitness_metrics
+		`+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("error closing rows for GetFitnessMetricsPage: %v", err)
+		}
+	}()
+
+	var metrics []models.FitnessMetrics
+	for rows.Next() {
+		var m models.FitnessMetrics
+		if err := rows.Scan(&m.Date, &m.VO2Max, &m.Workouts, &m.DailySteps, &m.Mobility, &m.CardioRecovery, &m.LowerBodyWeight, &m.LowerBodyReps, &m.DeadHangSeconds); err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, rows.Err()
+}
+
+// GetCognitionMetricsPage returns one keyset page of cognition metrics history
+func (db *DB) GetCognitionMetricsPage(q models.HistoryQuery) ([]models.CognitionMetrics, error) {
+	clause, args, err := historyPageClause(models.PillarCognition, q)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT date, mindfulness, deep_learning, stress_score, social_days
+		FROM cognition_metrics
+		`+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("error closing rows for GetCognitionMetricsPage: %v", err)
+		}
+	}()
+
+	var metrics []models.CognitionMetrics
+	for rows.Next() {
+		var m models.CognitionMetrics
+		if err := rows.Scan(&m.Date, &m.Mindfulness, &m.DeepLearning, &m.StressScore, &m.SocialDays); err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, rows.Err()
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"health-balance/internal/models"
+	"log"
+	"strings"
+)
+
+// GetInterventions returns every intervention, most recently started first
+func (db *DB) GetInterventions() ([]models.Intervention, error) {
+	rows, err := db.Query(`
+		SELECT id, name, start_date, end_date, metrics
+		FROM interventions
+		ORDER BY start_date DESC, id DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("error closing rows for GetInterventions: %v", err)
+		}
+	}()
+
+	var interventions []models.Intervention
+	for rows.Next() {
+		var (
+			i       models.Intervention
+			metrics string
+		)
+		if err := rows.Scan(&i.ID, &i.Name, &i.StartDate, &i.EndDate, &metrics); err != nil {
+			return nil, err
+		}
+		if metrics != "" {
+			i.Metrics = strings.Split(metrics, ",")
+		}
+		interventions = append(interventions, i)
+	}
+	return interventions, rows.Err()
+}
+
+// CreateIntervention stores a new intervention and returns its id
+func (db *DB) CreateIntervention(i models.Intervention) (int, error) {
+	res, err := db.Exec(`
+		INSERT INTO interventions (name, start_date, end_date, metrics)
+		VALUES (?, ?, ?, ?)
+	`, i.Name, i.StartDate, i.EndDate, strings.Join(i.Metrics, ","))
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	return int(id), err
+}
+
+// EndIntervention sets the end date of an ongoing intervention
+func (db *DB) EndIntervention(id int, endDate string) error {
+	_, err := db.Exec("UPDATE interventions SET end_date = ? WHERE id = ?", endDate, id)
+	return err
+}
+
+func (db *DB) DeleteIntervention(id int) error {
+	_, err := db.Exec("DELETE FROM interventions WHERE id = ?", id)
+	return err
+}
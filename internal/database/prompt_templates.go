@@ -0,0 +1,42 @@
+package database
+
+import (
+	"database/sql"
+	"health-balance/internal/models"
+)
+
+// GetPromptTemplate returns the prompt template saved under name, or nil when the built-in
+// one is used
+func (db *DB) GetPromptTemplate(name string) (*models.PromptTemplate, error) {
+	var (
+		t         models.PromptTemplate
+		updatedAt string
+	)
+	err := db.QueryRow(`SELECT name, body, updated_at FROM prompt_templates WHERE name = ?`, name).
+		Scan(&t.Name, &t.Body, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if t.UpdatedAt, err = parseTimestamp(updatedAt); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// SavePromptTemplate stores a prompt template, replacing the one saved under the same name
+func (db *DB) SavePromptTemplate(t models.PromptTemplate) error {
+	_, err := db.Exec(`
+		INSERT INTO prompt_templates (name, body, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET body = excluded.body, updated_at = excluded.updated_at
+	`, t.Name, t.Body, formatTimestamp(t.UpdatedAt))
+	return err
+}
+
+// DeletePromptTemplate removes the prompt template saved under name, restoring the built-in one
+func (db *DB) DeletePromptTemplate(name string) error {
+	_, err := db.Exec(`DELETE FROM prompt_templates WHERE name = ?`, name)
+	return err
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"database/sql"
+	"fmt"
 	"health-balance/internal/models"
 	"health-balance/internal/utils"
 	"log"
@@ -13,6 +14,9 @@ type Querier interface {
 	GetRecentHealthMetrics(limit int) ([]models.HealthMetrics, error)
 	GetRecentFitnessMetrics(limit int) ([]models.FitnessMetrics, error)
 	GetRecentCognitionMetrics(limit int) ([]models.CognitionMetrics, error)
+	GetHealthMetricsPage(q models.HistoryQuery) ([]models.HealthMetrics, error)
+	GetFitnessMetricsPage(q models.HistoryQuery) ([]models.FitnessMetrics, error)
+	GetCognitionMetricsPage(q models.HistoryQuery) ([]models.CognitionMetrics, error)
 	SaveHealthMetrics(m models.HealthMetrics) error
 	SaveFitnessMetrics(m models.FitnessMetrics) error
 	SaveCognitionMetrics(m models.CognitionMetrics) error
@@ -22,13 +26,48 @@ type Querier interface {
 	DeleteHealthMetrics(date string) error
 	DeleteFitnessMetrics(date string) error
 	DeleteCognitionMetrics(date string) error
-	GetRHRBaselineForDate(date string) (int, error)
+	GetMetricSeries(key, from, to string) ([]models.MetricPoint, error)
 	GetUserProfile() (*models.UserProfile, error)
 	SaveUserProfile(profile models.UserProfile) error
 	SavePushSubscription(sub models.PushSubscription) error
 	GetAllSubscriptions() ([]models.PushSubscription, error)
 	GetAnyPushSubscription() (*models.PushSubscription, error)
 	DeletePushSubscription(endpoint string) error
+	GetGoals() ([]models.Goal, error)
+	CreateGoal(g models.Goal) (int, error)
+	UpdateGoalStatus(id int, status, achievedAt string) error
+	DeleteGoal(id int) error
+	GetInterventions() ([]models.Intervention, error)
+	CreateIntervention(i models.Intervention) (int, error)
+	EndIntervention(id int, endDate string) error
+	DeleteIntervention(id int) error
+	GetWeekNote(date string) (*models.WeekNote, error)
+	GetWeekNotes(from, to string) ([]models.WeekNote, error)
+	SaveWeekNote(n models.WeekNote) error
+	DeleteWeekNote(date string) error
+	GetAISummaries() ([]models.AISummary, error)
+	GetLatestAISummary() (*models.AISummary, error)
+	GetAISummaryByPrompt(promptHash, provider, model string) (*models.AISummary, error)
+	SaveAISummary(s models.AISummary) (int, error)
+	GetAIComparisons() ([]models.AIComparison, error)
+	GetAIComparisonByPrompt(promptHash, provider, model string) (*models.AIComparison, error)
+	SaveAIComparison(c models.AIComparison) (int, error)
+	GetCoachThreads() ([]models.CoachThread, error)
+	GetCoachThread(id int) (*models.CoachThread, error)
+	CreateCoachThread(t models.CoachThread) (int, error)
+	DeleteCoachThread(id int) error
+	GetCoachMessages(threadID int) ([]models.CoachMessage, error)
+	AddCoachMessages(messages []models.CoachMessage) error
+	GetAIPrivacy() (models.AIPrivacy, error)
+	SaveAIPrivacy(p models.AIPrivacy) error
+	GetPromptTemplate(name string) (*models.PromptTemplate, error)
+	SavePromptTemplate(t models.PromptTemplate) error
+	DeletePromptTemplate(name string) error
+	AddAIUsage(u models.AIUsage) error
+	GetAIUsage(since time.Time) ([]models.AIUsage, error)
+	GetAutoSummarySettings() (models.AutoSummarySettings, error)
+	SaveAutoSummarySettings(s models.AutoSummarySettings) error
+	SetAutoSummaryWeek(week string) error
 	Close() error
 }
 
@@ -285,27 +324,41 @@ func (db *DB) DeleteCognitionMetrics(date string) error {
 	return err
 }
 
-// GetRHRBaselineForDate calculates the 3-month average RHR up to the given date.
-func (db *DB) GetRHRBaselineForDate(date string) (int, error) {
-	asOfDate, err := time.Parse("2006-01-02", date)
-	if err != nil {
-		return 0, err
+// GetMetricSeries returns the recorded values of a catalog metric between from and to
+// (inclusive), ordered by date. Weeks where the metric was skipped are left out.
+func (db *DB) GetMetricSeries(key, from, to string) ([]models.MetricPoint, error) {
+	metric, ok := models.LookupMetric(key)
+	if !ok {
+		return nil, fmt.Errorf("unknown metric %q", key)
 	}
 
-	threeMonthsAgo := asOfDate.AddDate(0, -3, 0).Format("2006-01-02")
-
-	var baseline int
-	err = db.QueryRow(`
-		SELECT AVG(rhr)
-		FROM health_metrics
-		WHERE date >= ? AND date <= ?
-	`, threeMonthsAgo, date).Scan(&baseline)
+	// Table and column come from the metric catalog, never from user input
+	query := fmt.Sprintf(`
+		SELECT date, %[1]s
+		FROM %[2]s
+		WHERE %[1]s IS NOT NULL AND date >= ? AND date <= ?
+		ORDER BY date ASC
+	`, metric.Key, metric.Table())
 
+	rows, err := db.Query(query, from, to)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("error closing rows for GetMetricSeries: %v", err)
+		}
+	}()
 
-	return baseline, nil
+	var points []models.MetricPoint
+	for rows.Next() {
+		var p models.MetricPoint
+		if err := rows.Scan(&p.Date, &p.Value); err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
 }
 
 func (db *DB) GetUserProfile() (*models.UserProfile, error) {
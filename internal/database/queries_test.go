@@ -1,9 +1,11 @@
 package database
 
 import (
+	"errors"
 	"path/filepath"
 	"slices"
 	"testing"
+	"time"
 
 	"health-balance/internal/models"
 	"health-balance/internal/utils"
@@ -25,12 +27,12 @@ func TestGetAllDatesWithData(t *testing.T) {
 
 	testDate := utils.GetCurrentWeekSundayDate()
 	healthMetrics := models.HealthMetrics{
-		SleepScore:     80,
-		WaistCm:        85.0,
-		RHR:            60,
-		SystolicBP:     118,
-		DiastolicBP:    76,
-		NutritionScore: 7.5,
+		SleepScore:     models.Int(80),
+		WaistCm:        models.Float(85.0),
+		RHR:            models.Int(60),
+		SystolicBP:     models.Int(118),
+		DiastolicBP:    models.Int(76),
+		NutritionScore: models.Float(7.5),
 	}
 	if err := db.SaveHealthMetrics(healthMetrics); err != nil {
 		t.Fatalf("Failed to save health metrics: %v", err)
@@ -67,12 +69,12 @@ func TestGetRecentHealthMetrics(t *testing.T) {
 
 	// Insert test data
 	healthMetrics := models.HealthMetrics{
-		SleepScore:     80,
-		WaistCm:        85.0,
-		RHR:            60,
-		SystolicBP:     118,
-		DiastolicBP:    76,
-		NutritionScore: 7.5,
+		SleepScore:     models.Int(80),
+		WaistCm:        models.Float(85.0),
+		RHR:            models.Int(60),
+		SystolicBP:     models.Int(118),
+		DiastolicBP:    models.Int(76),
+		NutritionScore: models.Float(7.5),
 	}
 	if err := db.SaveHealthMetrics(healthMetrics); err != nil {
 		t.Fatalf("Failed to save health metrics: %v", err)
@@ -106,13 +108,13 @@ func TestGetRecentFitnessMetrics(t *testing.T) {
 
 	// Insert test data
 	fitnessMetrics := models.FitnessMetrics{
-		VO2Max:          45.0,
-		Workouts:        4,
-		DailySteps:      10000,
-		Mobility:        3,
-		CardioRecovery:  25,
-		LowerBodyWeight: 180.0,
-		LowerBodyReps:   12,
+		VO2Max:          models.Float(45.0),
+		Workouts:        models.Int(4),
+		DailySteps:      models.Int(10000),
+		Mobility:        models.Int(3),
+		CardioRecovery:  models.Int(25),
+		LowerBodyWeight: models.Float(180.0),
+		LowerBodyReps:   models.Int(12),
 	}
 	if err := db.SaveFitnessMetrics(fitnessMetrics); err != nil {
 		t.Fatalf("Failed to save fitness metrics: %v", err)
@@ -146,10 +148,10 @@ func TestGetRecentCognitionMetrics(t *testing.T) {
 
 	// Insert test data
 	cognitionMetrics := models.CognitionMetrics{
-		Mindfulness:  4,
-		DeepLearning: 60,
-		StressScore:  2,
-		SocialDays:   5,
+		Mindfulness:  models.Int(4),
+		DeepLearning: models.Int(60),
+		StressScore:  models.Int(2),
+		SocialDays:   models.Int(5),
 	}
 	if err := db.SaveCognitionMetrics(cognitionMetrics); err != nil {
 		t.Fatalf("Failed to save cognition metrics: %v", err)
@@ -185,12 +187,12 @@ func TestSaveAndRetrieveHealthMetrics(t *testing.T) {
 	testDate := utils.GetCurrentWeekSundayDate()
 
 	healthMetrics := models.HealthMetrics{
-		SleepScore:     85,
-		WaistCm:        82.5,
-		RHR:            58,
-		SystolicBP:     116,
-		DiastolicBP:    74,
-		NutritionScore: 8.0,
+		SleepScore:     models.Int(85),
+		WaistCm:        models.Float(82.5),
+		RHR:            models.Int(58),
+		SystolicBP:     models.Int(116),
+		DiastolicBP:    models.Int(74),
+		NutritionScore: models.Float(8.0),
 	}
 
 	if err := db.SaveHealthMetrics(healthMetrics); err != nil {
@@ -202,20 +204,20 @@ func TestSaveAndRetrieveHealthMetrics(t *testing.T) {
 		t.Fatalf("Failed to retrieve health metrics: %v", err)
 	}
 
-	if retrieved.SleepScore != 85 {
-		t.Errorf("Expected SleepScore 85, got %d", retrieved.SleepScore)
+	if *retrieved.SleepScore != 85 {
+		t.Errorf("Expected SleepScore 85, got %d", *retrieved.SleepScore)
 	}
-	if retrieved.WaistCm != 82.5 {
-		t.Errorf("Expected WaistCm 82.5, got %f", retrieved.WaistCm)
+	if *retrieved.WaistCm != 82.5 {
+		t.Errorf("Expected WaistCm 82.5, got %f", *retrieved.WaistCm)
 	}
-	if retrieved.RHR != 58 {
-		t.Errorf("Expected RHR 58, got %d", retrieved.RHR)
+	if *retrieved.RHR != 58 {
+		t.Errorf("Expected RHR 58, got %d", *retrieved.RHR)
 	}
-	if retrieved.SystolicBP != 116 || retrieved.DiastolicBP != 74 {
-		t.Errorf("Expected BP 116/74, got %d/%d", retrieved.SystolicBP, retrieved.DiastolicBP)
+	if *retrieved.SystolicBP != 116 || *retrieved.DiastolicBP != 74 {
+		t.Errorf("Expected BP 116/74, got %d/%d", *retrieved.SystolicBP, *retrieved.DiastolicBP)
 	}
-	if retrieved.NutritionScore != 8.0 {
-		t.Errorf("Expected NutritionScore 8.0, got %f", retrieved.NutritionScore)
+	if *retrieved.NutritionScore != 8.0 {
+		t.Errorf("Expected NutritionScore 8.0, got %f", *retrieved.NutritionScore)
 	}
 }
 
@@ -237,13 +239,13 @@ func TestSaveAndRetrieveFitnessMetrics(t *testing.T) {
 	testDate := utils.GetCurrentWeekSundayDate()
 
 	fitnessMetrics := models.FitnessMetrics{
-		VO2Max:          48.0,
-		Workouts:        5,
-		DailySteps:      12000,
-		Mobility:        4,
-		CardioRecovery:  30,
-		LowerBodyWeight: 180.0,
-		LowerBodyReps:   10,
+		VO2Max:          models.Float(48.0),
+		Workouts:        models.Int(5),
+		DailySteps:      models.Int(12000),
+		Mobility:        models.Int(4),
+		CardioRecovery:  models.Int(30),
+		LowerBodyWeight: models.Float(180.0),
+		LowerBodyReps:   models.Int(10),
 	}
 
 	if err := db.SaveFitnessMetrics(fitnessMetrics); err != nil {
@@ -255,23 +257,23 @@ func TestSaveAndRetrieveFitnessMetrics(t *testing.T) {
 		t.Fatalf("Failed to retrieve fitness metrics: %v", err)
 	}
 
-	if retrieved.VO2Max != 48.0 {
-		t.Errorf("Expected VO2Max 48.0, got %f", retrieved.VO2Max)
+	if *retrieved.VO2Max != 48.0 {
+		t.Errorf("Expected VO2Max 48.0, got %f", *retrieved.VO2Max)
 	}
-	if retrieved.Workouts != 5 {
-		t.Errorf("Expected Workouts 5, got %d", retrieved.Workouts)
+	if *retrieved.Workouts != 5 {
+		t.Errorf("Expected Workouts 5, got %d", *retrieved.Workouts)
 	}
-	if retrieved.DailySteps != 12000 {
-		t.Errorf("Expected DailySteps 12000, got %d", retrieved.DailySteps)
+	if *retrieved.DailySteps != 12000 {
+		t.Errorf("Expected DailySteps 12000, got %d", *retrieved.DailySteps)
 	}
-	if retrieved.Mobility != 4 {
-		t.Errorf("Expected Mobility 4, got %d", retrieved.Mobility)
+	if *retrieved.Mobility != 4 {
+		t.Errorf("Expected Mobility 4, got %d", *retrieved.Mobility)
 	}
-	if retrieved.CardioRecovery != 30 {
-		t.Errorf("Expected CardioRecovery 30, got %d", retrieved.CardioRecovery)
+	if *retrieved.CardioRecovery != 30 {
+		t.Errorf("Expected CardioRecovery 30, got %d", *retrieved.CardioRecovery)
 	}
-	if retrieved.LowerBodyWeight != 180.0 || retrieved.LowerBodyReps != 10 {
-		t.Errorf("Expected leg press 180.0 x 10, got %.1f x %d", retrieved.LowerBodyWeight, retrieved.LowerBodyReps)
+	if *retrieved.LowerBodyWeight != 180.0 || *retrieved.LowerBodyReps != 10 {
+		t.Errorf("Expected leg press 180.0 x 10, got %.1f x %d", *retrieved.LowerBodyWeight, *retrieved.LowerBodyReps)
 	}
 }
 
@@ -293,10 +295,10 @@ func TestSaveAndRetrieveCognitionMetrics(t *testing.T) {
 	testDate := utils.GetCurrentWeekSundayDate()
 
 	cognitionMetrics := models.CognitionMetrics{
-		Mindfulness:  5,
-		DeepLearning: 90,
-		StressScore:  2,
-		SocialDays:   6,
+		Mindfulness:  models.Int(5),
+		DeepLearning: models.Int(90),
+		StressScore:  models.Int(2),
+		SocialDays:   models.Int(6),
 	}
 
 	if err := db.SaveCognitionMetrics(cognitionMetrics); err != nil {
@@ -308,14 +310,14 @@ func TestSaveAndRetrieveCognitionMetrics(t *testing.T) {
 		t.Fatalf("Failed to retrieve cognition metrics: %v", err)
 	}
 
-	if retrieved.Mindfulness != 5 {
-		t.Errorf("Expected Mindfulness 5, got %d", retrieved.Mindfulness)
+	if *retrieved.Mindfulness != 5 {
+		t.Errorf("Expected Mindfulness 5, got %d", *retrieved.Mindfulness)
 	}
-	if retrieved.DeepLearning != 90 {
-		t.Errorf("Expected DeepLearning 90, got %d", retrieved.DeepLearning)
+	if *retrieved.DeepLearning != 90 {
+		t.Errorf("Expected DeepLearning 90, got %d", *retrieved.DeepLearning)
 	}
-	if retrieved.StressScore != 2 || retrieved.SocialDays != 6 {
-		t.Errorf("Expected stress/social 2/6, got %d/%d", retrieved.StressScore, retrieved.SocialDays)
+	if *retrieved.StressScore != 2 || *retrieved.SocialDays != 6 {
+		t.Errorf("Expected stress/social 2/6, got %d/%d", *retrieved.StressScore, *retrieved.SocialDays)
 	}
 }
 
@@ -338,10 +340,10 @@ func TestDeleteMetrics(t *testing.T) {
 
 	// Insert health metrics
 	healthMetrics := models.HealthMetrics{
-		SleepScore:     80,
-		WaistCm:        85.0,
-		RHR:            60,
-		NutritionScore: 7.5,
+		SleepScore:     models.Int(80),
+		WaistCm:        models.Float(85.0),
+		RHR:            models.Int(60),
+		NutritionScore: models.Float(7.5),
 	}
 	if err := db.SaveHealthMetrics(healthMetrics); err != nil {
 		t.Fatalf("Failed to save health metrics: %v", err)
@@ -365,7 +367,7 @@ func TestDeleteMetrics(t *testing.T) {
 	}
 }
 
-func TestGetRHRBaselineForDate(t *testing.T) {
+func TestGetMetricSeries(t *testing.T) {
 	tempDir := t.TempDir()
 	dbPath := filepath.Join(tempDir, "test.db")
 
@@ -399,14 +401,18 @@ func TestGetRHRBaselineForDate(t *testing.T) {
 		}
 	}
 
-	baseline, err := db.GetRHRBaselineForDate("2025-04-06")
+	points, err := db.GetMetricSeries("rhr", "2025-01-06", "2025-04-06")
 	if err != nil {
-		t.Fatalf("Failed to get dated RHR baseline: %v", err)
+		t.Fatalf("Failed to get RHR series: %v", err)
 	}
 
-	// Previous 3 months up to 2025-04-06 include 2025-02-02 and 2025-04-06, but not 2025-05-04.
-	if baseline != 63 {
-		t.Errorf("Expected dated RHR baseline 63, got %d", baseline)
+	// The range includes 2025-02-02 and 2025-04-06, but not 2025-01-05 or 2025-05-04.
+	if len(points) != 2 || points[0].Date != "2025-02-02" || points[0].Value != 66 || points[1].Value != 60 {
+		t.Errorf("Expected the 2025-02-02 and 2025-04-06 readings in date order, got %+v", points)
+	}
+
+	if _, err := db.GetMetricSeries("rhr; DROP TABLE health_metrics", "2025-01-01", "2025-12-31"); err == nil {
+		t.Error("Expected an error for a metric outside the catalog")
 	}
 }
 
@@ -504,3 +510,573 @@ func TestPushSubscriptions(t *testing.T) {
 		t.Errorf("Expected Timezone 'UTC', got '%s'", retrieved.Timezone)
 	}
 }
+
+func TestSaveFitnessMetricsStoresSkippedAsNull(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "test.db")
+
+	db, err := Init(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("Error closing database: %v", err)
+		}
+	}()
+
+	if err := db.SaveFitnessMetrics(models.FitnessMetrics{VO2Max: models.Float(44.5)}); err != nil {
+		t.Fatalf("Failed to save fitness metrics: %v", err)
+	}
+
+	retrieved, err := db.GetFitnessMetricsByDate(utils.GetCurrentWeekSundayDate())
+	if err != nil {
+		t.Fatalf("Failed to get fitness metrics: %v", err)
+	}
+	if retrieved.VO2Max == nil || *retrieved.VO2Max != 44.5 {
+		t.Errorf("Expected VO2 Max 44.5, got %v", retrieved.VO2Max)
+	}
+	if retrieved.DailySteps != nil || retrieved.LowerBodyWeight != nil || retrieved.DeadHangSeconds != nil {
+		t.Errorf("Expected skipped metrics to stay empty, got %+v", retrieved)
+	}
+
+	var nullSteps int
+	if err := db.QueryRow("SELECT COUNT(*) FROM fitness_metrics WHERE daily_steps IS NULL").Scan(&nullSteps); err != nil {
+		t.Fatalf("Failed to query fitness_metrics: %v", err)
+	}
+	if nullSteps != 1 {
+		t.Errorf("Expected skipped steps to be stored as NULL, got %d NULL rows", nullSteps)
+	}
+}
+
+func TestGetMetricSeriesSkipsMissingValues(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "test.db")
+
+	db, err := Init(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("Error closing database: %v", err)
+		}
+	}()
+
+	if err := db.SaveHealthMetrics(models.HealthMetrics{SleepScore: models.Int(80)}); err != nil {
+		t.Fatalf("Failed to save health metrics: %v", err)
+	}
+
+	date := utils.GetCurrentWeekSundayDate()
+	points, err := db.GetMetricSeries("rhr", date, date)
+	if err != nil {
+		t.Fatalf("Expected no error without RHR readings, got %v", err)
+	}
+	if len(points) != 0 {
+		t.Errorf("Expected skipped RHR to be left out, got %+v", points)
+	}
+
+	points, err = db.GetMetricSeries("sleep_score", date, date)
+	if err != nil || len(points) != 1 || points[0].Value != 80 {
+		t.Errorf("Expected one sleep score of 80, got %+v (%v)", points, err)
+	}
+}
+
+func TestGetHealthMetricsPage(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "test.db")
+
+	db, err := Init(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("Error closing database: %v", err)
+		}
+	}()
+
+	rows := []struct {
+		date string
+		rhr  *int
+	}{
+		{date: "2025-01-05", rhr: models.Int(62)},
+		{date: "2025-01-12", rhr: nil},
+		{date: "2025-01-19", rhr: models.Int(58)},
+		{date: "2025-01-26", rhr: models.Int(62)},
+		{date: "2025-02-02", rhr: nil},
+		{date: "2025-02-09", rhr: models.Int(60)},
+	}
+	for _, row := range rows {
+		if _, err := db.Exec("INSERT INTO health_metrics (date, rhr) VALUES (?, ?)", row.date, row.rhr); err != nil {
+			t.Fatalf("Failed to insert dated health metric %s: %v", row.date, err)
+		}
+	}
+
+	// Walk every page of two, sorted by RHR ascending, and check nothing repeats or goes missing
+	var dates []string
+	q := models.HistoryQuery{Sort: "rhr", Ascending: true, Limit: 2}
+	for page := 0; page < 5; page++ {
+		metrics, err := db.GetHealthMetricsPage(q)
+		if err != nil {
+			t.Fatalf("Failed to get health metrics page: %v", err)
+		}
+		if len(metrics) == 0 {
+			break
+		}
+		for _, m := range metrics {
+			dates = append(dates, m.Date)
+		}
+		last := metrics[len(metrics)-1]
+		q.After = &models.HistoryCursor{Date: last.Date, Value: last.Value("rhr")}
+	}
+
+	expected := []string{"2025-01-19", "2025-02-09", "2025-01-05", "2025-01-26", "2025-01-12", "2025-02-02"}
+	if !slices.Equal(dates, expected) {
+		t.Errorf("Expected pages in RHR order with skipped weeks last %v, got %v", expected, dates)
+	}
+
+	filtered, err := db.GetHealthMetricsPage(models.HistoryQuery{From: "2025-01-12", To: "2025-01-26", Sort: models.HistorySortDate, Limit: 10})
+	if err != nil {
+		t.Fatalf("Failed to get filtered page: %v", err)
+	}
+	if len(filtered) != 3 || filtered[0].Date != "2025-01-26" || filtered[2].Date != "2025-01-12" {
+		t.Errorf("Expected the three weeks in range, newest first, got %+v", filtered)
+	}
+
+	if _, err := db.GetHealthMetricsPage(models.HistoryQuery{Sort: "vo2_max"}); err == nil {
+		t.Error("Expected an error when sorting by a column outside the pillar")
+	}
+}
+
+func TestGoals(t *testing.T) {
+	db, err := Init(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("Error closing database: %v", err)
+		}
+	}()
+
+	first, err := db.CreateGoal(models.Goal{MetricKey: "vo2_max", Comparison: models.GoalAtLeast, Target: 50, WindowWeeks: 1,
+		Deadline: "2026-06-30", StartValue: models.Float(44), CreatedAt: "2026-01-04", Notify: true, LastStatus: models.GoalOnTrack})
+	if err != nil {
+		t.Fatalf("Failed to create goal: %v", err)
+	}
+	second, err := db.CreateGoal(models.Goal{MetricKey: "sleep_score", Comparison: models.GoalAtLeast, Target: 80, WindowWeeks: 4, CreatedAt: "2026-01-04"})
+	if err != nil {
+		t.Fatalf("Failed to create goal: %v", err)
+	}
+
+	if err := db.UpdateGoalStatus(first, models.GoalAchieved, "2026-03-01"); err != nil {
+		t.Fatalf("Failed to update goal status: %v", err)
+	}
+
+	goals, err := db.GetGoals()
+	if err != nil {
+		t.Fatalf("Failed to get goals: %v", err)
+	}
+	if len(goals) != 2 {
+		t.Fatalf("Expected 2 goals, got %d", len(goals))
+	}
+	g := goals[0]
+	if g.ID != first || g.MetricKey != "vo2_max" || g.Deadline != "2026-06-30" || g.StartValue == nil || *g.StartValue != 44 ||
+		!g.Notify || g.LastStatus != models.GoalAchieved || g.AchievedAt != "2026-03-01" {
+		t.Errorf("Unexpected first goal: %+v", g)
+	}
+	if g := goals[1]; g.ID != second || g.WindowWeeks != 4 || g.Deadline != "" || g.StartValue != nil || g.Notify {
+		t.Errorf("Unexpected second goal: %+v", g)
+	}
+
+	if err := db.DeleteGoal(first); err != nil {
+		t.Fatalf("Failed to delete goal: %v", err)
+	}
+	goals, err = db.GetGoals()
+	if err != nil || len(goals) != 1 || goals[0].ID != second {
+		t.Errorf("Expected only the second goal to remain, got %+v (%v)", goals, err)
+	}
+}
+
+func TestInterventions(t *testing.T) {
+	db, err := Init(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("Error closing database: %v", err)
+		}
+	}()
+
+	first, err := db.CreateIntervention(models.Intervention{Name: "No alcohol", StartDate: "2026-01-04", Metrics: []string{"rhr", "sleep_score"}})
+	if err != nil {
+		t.Fatalf("Failed to create intervention: %v", err)
+	}
+	second, err := db.CreateIntervention(models.Intervention{Name: "Zone 2 runs", StartDate: "2026-03-01", EndDate: "2026-04-26"})
+	if err != nil {
+		t.Fatalf("Failed to create intervention: %v", err)
+	}
+
+	if err := db.EndIntervention(first, "2026-02-22"); err != nil {
+		t.Fatalf("Failed to end intervention: %v", err)
+	}
+
+	interventions, err := db.GetInterventions()
+	if err != nil {
+		t.Fatalf("Failed to get interventions: %v", err)
+	}
+	if len(interventions) != 2 {
+		t.Fatalf("Expected 2 interventions, got %d", len(interventions))
+	}
+	if i := interventions[0]; i.ID != second || i.EndDate != "2026-04-26" || i.Metrics != nil {
+		t.Errorf("Expected the most recent intervention first without metrics, got %+v", i)
+	}
+	if i := interventions[1]; i.ID != first || i.EndDate != "2026-02-22" || len(i.Metrics) != 2 || i.Metrics[1] != "sleep_score" {
+		t.Errorf("Unexpected first intervention: %+v", i)
+	}
+
+	if err := db.DeleteIntervention(second); err != nil {
+		t.Fatalf("Failed to delete intervention: %v", err)
+	}
+	interventions, err = db.GetInterventions()
+	if err != nil || len(interventions) != 1 || interventions[0].ID != first {
+		t.Errorf("Expected only the first intervention to remain, got %+v (%v)", interventions, err)
+	}
+}
+
+func TestWeekNotes(t *testing.T) {
+	db, err := Init(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("Error closing database: %v", err)
+		}
+	}()
+
+	if note, err := db.GetWeekNote("2026-01-04"); err != nil || note != nil {
+		t.Fatalf("Expected no note yet, got %+v (%v)", note, err)
+	}
+
+	for _, n := range []models.WeekNote{
+		{Date: "2026-01-11", Text: "Flu", Tags: []string{"sick"}},
+		{Date: "2026-01-04", Tags: []string{"travel", "holiday"}},
+		{Date: "2026-01-11", Text: "Flu, stayed home", Tags: []string{"sick", "poor sleep"}},
+	} {
+		if err := db.SaveWeekNote(n); err != nil {
+			t.Fatalf("Failed to save week note: %v", err)
+		}
+	}
+
+	note, err := db.GetWeekNote("2026-01-11")
+	if err != nil || note == nil {
+		t.Fatalf("Failed to get week note: %v", err)
+	}
+	if note.Text != "Flu, stayed home" || len(note.Tags) != 2 || note.Tags[1] != "poor sleep" {
+		t.Errorf("Expected the note to be replaced, got %+v", note)
+	}
+
+	notes, err := db.GetWeekNotes("", "")
+	if err != nil || len(notes) != 2 || notes[0].Date != "2026-01-04" || notes[0].Text != "" {
+		t.Errorf("Expected both notes oldest first, got %+v (%v)", notes, err)
+	}
+	notes, err = db.GetWeekNotes("2026-01-05", "2026-01-31")
+	if err != nil || len(notes) != 1 || notes[0].Date != "2026-01-11" {
+		t.Errorf("Expected only the note inside the range, got %+v (%v)", notes, err)
+	}
+
+	if err := db.DeleteWeekNote("2026-01-11"); err != nil {
+		t.Fatalf("Failed to delete week note: %v", err)
+	}
+	if note, err := db.GetWeekNote("2026-01-11"); err != nil || note != nil {
+		t.Errorf("Expected the note to be deleted, got %+v (%v)", note, err)
+	}
+}
+
+func TestAISummaries(t *testing.T) {
+	db, err := Init(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("Error closing database: %v", err)
+		}
+	}()
+
+	if s, err := db.GetLatestAISummary(); err != nil || s != nil {
+		t.Fatalf("Expected no summary yet, got %+v (%v)", s, err)
+	}
+
+	generated := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	for i, s := range []models.AISummary{
+		{Week: "2026-03-01", Provider: "gemini", Model: "gemini-flash", PromptHash: "abc", Text: "First", CreatedAt: generated},
+		{Week: "2026-03-01", Provider: "openai", Model: "llama3", PromptHash: "abc", Text: "Local", CreatedAt: generated.Add(time.Hour)},
+		{Week: "2026-03-08", Provider: "gemini", Model: "gemini-flash", PromptHash: "def", Text: "Second", CreatedAt: generated.AddDate(0, 0, 7)},
+	} {
+		if id, err := db.SaveAISummary(s); err != nil || id != i+1 {
+			t.Fatalf("Failed to save summary: id %d, %v", id, err)
+		}
+	}
+
+	latest, err := db.GetLatestAISummary()
+	if err != nil || latest == nil || latest.Text != "Second" || !latest.CreatedAt.Equal(generated.AddDate(0, 0, 7)) {
+		t.Fatalf("Expected the newest summary, got %+v (%v)", latest, err)
+	}
+
+	cached, err := db.GetAISummaryByPrompt("abc", "gemini", "gemini-flash")
+	if err != nil || cached == nil || cached.Text != "First" || cached.Week != "2026-03-01" {
+		t.Errorf("Expected the summary for the prompt and model, got %+v (%v)", cached, err)
+	}
+	if s, err := db.GetAISummaryByPrompt("abc", "gemini", "gemini-pro"); err != nil || s != nil {
+		t.Errorf("Expected no summary for another model, got %+v (%v)", s, err)
+	}
+
+	summaries, err := db.GetAISummaries()
+	if err != nil || len(summaries) != 3 || summaries[0].Text != "Second" || summaries[2].Text != "First" {
+		t.Errorf("Expected all summaries newest first, got %+v (%v)", summaries, err)
+	}
+}
+
+func TestCoachThreads(t *testing.T) {
+	db, err := Init(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("Error closing database: %v", err)
+		}
+	}()
+
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	first, err := db.CreateCoachThread(models.CoachThread{Title: "Fitness", CreatedAt: start})
+	if err != nil {
+		t.Fatalf("Failed to create thread: %v", err)
+	}
+	second, err := db.CreateCoachThread(models.CoachThread{Title: "Sleep", CreatedAt: start.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("Failed to create thread: %v", err)
+	}
+
+	// Replying in the first thread makes it the most recently active
+	if err := db.AddCoachMessages([]models.CoachMessage{
+		{ThreadID: first, Role: models.CoachRoleUser, Content: "Why?", CreatedAt: start.Add(2 * time.Hour)},
+		{ThreadID: first, Role: models.CoachRoleAssistant, Content: "Because.", CreatedAt: start.Add(2 * time.Hour)},
+	}); err != nil {
+		t.Fatalf("Failed to add messages: %v", err)
+	}
+
+	threads, err := db.GetCoachThreads()
+	if err != nil || len(threads) != 2 || threads[0].ID != first || !threads[0].UpdatedAt.Equal(start.Add(2*time.Hour)) {
+		t.Fatalf("Expected the active thread first, got %+v (%v)", threads, err)
+	}
+	if thread, err := db.GetCoachThread(second); err != nil || thread == nil || thread.Title != "Sleep" || !thread.CreatedAt.Equal(start.Add(time.Hour)) {
+		t.Errorf("Unexpected thread %+v (%v)", thread, err)
+	}
+
+	messages, err := db.GetCoachMessages(first)
+	if err != nil || len(messages) != 2 || messages[0].Content != "Why?" || messages[1].Role != models.CoachRoleAssistant {
+		t.Errorf("Expected both messages in order, got %+v (%v)", messages, err)
+	}
+
+	if err := db.DeleteCoachThread(first); err != nil {
+		t.Fatalf("Failed to delete thread: %v", err)
+	}
+	if thread, err := db.GetCoachThread(first); err != nil || thread != nil {
+		t.Errorf("Expected the thread to be deleted, got %+v (%v)", thread, err)
+	}
+	if messages, err := db.GetCoachMessages(first); err != nil || len(messages) != 0 {
+		t.Errorf("Expected the messages to be deleted with the thread, got %+v (%v)", messages, err)
+	}
+
+	err = db.AddCoachMessages([]models.CoachMessage{{ThreadID: first, Role: models.CoachRoleUser, Content: "Still there?", CreatedAt: start}})
+	if !errors.Is(err, ErrCoachThreadNotFound) {
+		t.Errorf("Expected adding to a deleted thread to fail, got %v", err)
+	}
+	if messages, err := db.GetCoachMessages(first); err != nil || len(messages) != 0 {
+		t.Errorf("Expected nothing stored for a deleted thread, got %+v (%v)", messages, err)
+	}
+}
+
+func TestAIPrivacy(t *testing.T) {
+	db, err := Init(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("Error closing database: %v", err)
+		}
+	}()
+
+	privacy, err := db.GetAIPrivacy()
+	if err != nil || privacy.HideAge || privacy.Coarsen || privacy.HiddenMetrics != nil {
+		t.Fatalf("Expected everything shared before saving, got %+v (%v)", privacy, err)
+	}
+
+	saved := models.AIPrivacy{HideAge: true, HideNotes: true, Coarsen: true, HiddenMetrics: []string{"body_weight_kg", "waist_cm"}}
+	if err := db.SaveAIPrivacy(saved); err != nil {
+		t.Fatalf("Failed to save privacy settings: %v", err)
+	}
+	saved.HideAge = false
+	saved.HiddenMetrics = []string{"rhr"}
+	if err := db.SaveAIPrivacy(saved); err != nil {
+		t.Fatalf("Failed to update privacy settings: %v", err)
+	}
+
+	privacy, err = db.GetAIPrivacy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if privacy.HideAge || privacy.HideSex || !privacy.HideNotes || !privacy.Coarsen || len(privacy.HiddenMetrics) != 1 || privacy.HiddenMetrics[0] != "rhr" {
+		t.Errorf("Expected the updated settings, got %+v", privacy)
+	}
+}
+
+func TestPromptTemplates(t *testing.T) {
+	db, err := Init(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("Error closing database: %v", err)
+		}
+	}()
+
+	if tmpl, err := db.GetPromptTemplate(models.PromptTemplateSummary); err != nil || tmpl != nil {
+		t.Fatalf("Expected no saved template, got %+v (%v)", tmpl, err)
+	}
+
+	saved := models.PromptTemplate{Name: models.PromptTemplateSummary, Body: "first", UpdatedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	if err := db.SavePromptTemplate(saved); err != nil {
+		t.Fatalf("Failed to save template: %v", err)
+	}
+	saved.Body = "second"
+	if err := db.SavePromptTemplate(saved); err != nil {
+		t.Fatalf("Failed to update template: %v", err)
+	}
+
+	tmpl, err := db.GetPromptTemplate(models.PromptTemplateSummary)
+	if err != nil || tmpl == nil || tmpl.Body != "second" || !tmpl.UpdatedAt.Equal(saved.UpdatedAt) {
+		t.Fatalf("Expected the updated template, got %+v (%v)", tmpl, err)
+	}
+
+	if err := db.DeletePromptTemplate(models.PromptTemplateSummary); err != nil {
+		t.Fatalf("Failed to delete template: %v", err)
+	}
+	if tmpl, err := db.GetPromptTemplate(models.PromptTemplateSummary); err != nil || tmpl != nil {
+		t.Errorf("Expected the template deleted, got %+v (%v)", tmpl, err)
+	}
+}
+
+func TestAIUsage(t *testing.T) {
+	db, err := Init(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("Error closing database: %v", err)
+		}
+	}()
+
+	march := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, u := range []models.AIUsage{
+		{CreatedAt: march.AddDate(0, 0, -1), Feature: models.AIFeatureSummary, Provider: "gemini", Model: "g", Status: models.AIUsageOK, Cost: 1},
+		{CreatedAt: march.Add(time.Hour), Feature: models.AIFeatureCoach, Provider: "anthropic", Model: "c", Status: models.AIUsageCancelled, InputTokens: 1200, OutputTokens: 300, Latency: 1500 * time.Millisecond, Cost: 0.0081},
+	} {
+		if err := db.AddAIUsage(u); err != nil {
+			t.Fatalf("Failed to record usage: %v", err)
+		}
+	}
+
+	usage, err := db.GetAIUsage(march)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usage) != 1 {
+		t.Fatalf("Expected only the usage since March, got %+v", usage)
+	}
+	u := usage[0]
+	if u.ID == 0 || !u.CreatedAt.Equal(march.Add(time.Hour)) || u.Feature != models.AIFeatureCoach || u.Provider != "anthropic" || u.Model != "c" || u.Status != models.AIUsageCancelled ||
+		u.InputTokens != 1200 || u.OutputTokens != 300 || u.Latency != 1500*time.Millisecond || u.Cost != 0.0081 {
+		t.Errorf("Unexpected usage %+v", u)
+	}
+}
+
+func TestAutoSummarySettings(t *testing.T) {
+	db, err := Init(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("Error closing database: %v", err)
+		}
+	}()
+
+	settings, err := db.GetAutoSummarySettings()
+	if err != nil || settings != models.DefaultAutoSummarySettings() {
+		t.Fatalf("Expected the defaults before saving, got %+v (%v)", settings, err)
+	}
+
+	saved := models.AutoSummarySettings{Enabled: true, Trigger: models.AutoSummaryScheduled, Day: 1, Time: "07:30", Timezone: "Europe/Stockholm"}
+	if err := db.SaveAutoSummarySettings(saved); err != nil {
+		t.Fatalf("Failed to save settings: %v", err)
+	}
+	if err := db.SetAutoSummaryWeek("2026-03-08"); err != nil {
+		t.Fatalf("Failed to record the week: %v", err)
+	}
+	saved.Time = "08:00"
+	if err := db.SaveAutoSummarySettings(saved); err != nil {
+		t.Fatalf("Failed to update settings: %v", err)
+	}
+
+	settings, err = db.GetAutoSummarySettings()
+	saved.LastWeek = "2026-03-08"
+	if err != nil || settings != saved {
+		t.Errorf("Expected %+v with the week kept, got %+v (%v)", saved, settings, err)
+	}
+}
+
+func TestAIComparisons(t *testing.T) {
+	db, err := Init(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("Error closing database: %v", err)
+		}
+	}()
+
+	baseline := models.Period{From: "2025-04-01", To: "2025-06-30"}
+	current := models.Period{From: "2026-04-01", To: "2026-06-30"}
+	now := time.Now().Truncate(time.Second)
+	for i, c := range []models.AIComparison{
+		{Baseline: baseline, Current: current, Provider: "gemini", Model: "gemini-flash", PromptHash: "abc", Text: "First", CreatedAt: now.Add(-time.Hour)},
+		{Baseline: baseline, Current: current, Provider: "gemini", Model: "gemini-flash", PromptHash: "abc", Text: "Second", CreatedAt: now},
+	} {
+		if id, err := db.SaveAIComparison(c); err != nil || id != i+1 {
+			t.Fatalf("Failed to save comparison %d: id %d, %v", i, id, err)
+		}
+	}
+
+	cached, err := db.GetAIComparisonByPrompt("abc", "gemini", "gemini-flash")
+	if err != nil || cached == nil || cached.Text != "Second" || cached.Baseline != baseline || cached.Current != current || !cached.CreatedAt.Equal(now) {
+		t.Errorf("Expected the latest comparison for the prompt, got %+v (%v)", cached, err)
+	}
+	if c, err := db.GetAIComparisonByPrompt("abc", "gemini", "gemini-pro"); err != nil || c != nil {
+		t.Errorf("Expected no comparison for another model, got %+v (%v)", c, err)
+	}
+
+	comparisons, err := db.GetAIComparisons()
+	if err != nil || len(comparisons) != 2 || comparisons[0].Text != "Second" {
+		t.Errorf("Expected every comparison newest first, got %+v (%v)", comparisons, err)
+	}
+}
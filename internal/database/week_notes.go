@@ -0,0 +1,82 @@
+package database
+
+import (
+	"database/sql"
+	"health-balance/internal/models"
+	"log"
+	"strings"
+)
+
+// GetWeekNote returns the note of the week ending on date, or nil when there is none
+func (db *DB) GetWeekNote(date string) (*models.WeekNote, error) {
+	var (
+		n    models.WeekNote
+		tags string
+	)
+	err := db.QueryRow("SELECT date, text, tags FROM week_notes WHERE date = ?", date).Scan(&n.Date, &n.Text, &tags)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	n.Tags = splitTags(tags)
+	return &n, nil
+}
+
+// GetWeekNotes returns the notes between from and to (inclusive, either may be empty), oldest first
+func (db *DB) GetWeekNotes(from, to string) ([]models.WeekNote, error) {
+	rows, err := db.Query(`
+		SELECT date, text, tags
+		FROM week_notes
+		WHERE date >= ? AND (? = '' OR date <= ?)
+		ORDER BY date ASC
+	`, from, to, to)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("error closing rows for GetWeekNotes: %v", err)
+		}
+	}()
+
+	var notes []models.WeekNote
+	for rows.Next() {
+		var (
+			n    models.WeekNote
+			tags string
+		)
+		if err := rows.Scan(&n.Date, &n.Text, &tags); err != nil {
+			return nil, err
+		}
+		n.Tags = splitTags(tags)
+		notes = append(notes, n)
+	}
+	return notes, rows.Err()
+}
+
+// SaveWeekNote creates or replaces the note of the note's week
+func (db *DB) SaveWeekNote(n models.WeekNote) error {
+	_, err := db.Exec(`
+		INSERT INTO week_notes (date, text, tags)
+		VALUES (?, ?, ?)
+		ON CONFLICT(date) DO UPDATE SET
+			text = excluded.text,
+			tags = excluded.tags
+	`, n.Date, n.Text, strings.Join(n.Tags, ","))
+	return err
+}
+
+func (db *DB) DeleteWeekNote(date string) error {
+	_, err := db.Exec("DELETE FROM week_notes WHERE date = ?", date)
+	return err
+}
+
+// splitTags reverses the comma-joined storage of tags
+func splitTags(tags string) []string {
+	if tags == "" {
+		return nil
+	}
+	return strings.Split(tags, ",")
+}
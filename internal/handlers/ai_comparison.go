@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"fmt"
+	"health-balance/internal/models"
+	"health-balance/internal/services"
+	"html/template"
+	"log"
+	"net/http"
+	"time"
+)
+
+// AIComparisonData is the comparison page: the form with its presets and every stored comparison
+type AIComparisonData struct {
+	Presets     []models.ComparisonPreset
+	Baseline    models.Period
+	Current     models.Period
+	Comparisons []AIComparisonView
+}
+
+// AIComparisonView is a comparison together with its structured insights, or its markdown
+// rendered as sanitized HTML when it has none
+type AIComparisonView struct {
+	models.AIComparison
+	Insights *models.AIInsights
+	HTML     template.HTML
+}
+
+// HandleAIComparisons renders the comparison page. The form is filled from ?baseline_from=,
+// ?baseline_to=, ?from= and ?to= when given, else from the first preset.
+func (h *Handler) HandleAIComparisons(w http.ResponseWriter, r *http.Request) {
+	presets, err := services.ComparisonPresets(h.db, time.Now())
+	if err != nil {
+		log.Printf("Comparison presets error: %v", err)
+		http.Error(w, "Failed to load comparisons", http.StatusInternalServerError)
+		return
+	}
+	comparisons, err := h.db.GetAIComparisons()
+	if err != nil {
+		log.Printf("Error loading AI comparisons: %v", err)
+		http.Error(w, "Failed to load comparisons", http.StatusInternalServerError)
+		return
+	}
+
+	data := AIComparisonData{Presets: presets, Baseline: presets[0].Baseline, Current: presets[0].Current}
+	if q := r.URL.Query(); q.Get("from") != "" {
+		data.Baseline = models.Period{From: q.Get("baseline_from"), To: q.Get("baseline_to")}
+		data.Current = models.Period{From: q.Get("from"), To: q.Get("to")}
+	}
+	for _, c := range comparisons {
+		view, err := newAIComparisonView(c)
+		if err != nil {
+			log.Printf("Markdown conversion error for AI comparison %d: %v", c.ID, err)
+			continue
+		}
+		data.Comparisons = append(data.Comparisons, view)
+	}
+	h.render(w, "ai_comparison.html", data)
+}
+
+// HandleAIComparison compares the periods from the comparison form. The stored comparison
+// is served while the data behind it is unchanged, unless regenerate is set.
+func (h *Handler) HandleAIComparison(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		log.Printf("Error parsing form: %v", err)
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	baseline := models.Period{From: r.FormValue("baseline_from"), To: r.FormValue("baseline_to")}
+	current := models.Period{From: r.FormValue("from"), To: r.FormValue("to")}
+	if errs := models.ValidateComparison(baseline, current); len(errs) > 0 {
+		writeFieldErrors(w, "ai-comparison-form", errs)
+		return
+	}
+
+	comparison, err := services.CompareHealthPeriods(r.Context(), h.db, baseline, current, r.FormValue("regenerate") == "true")
+	if err != nil {
+		log.Printf("AI comparison error: %v", err)
+		if _, err := fmt.Fprintf(w, `<div class="text-red-600 p-4 bg-red-50 rounded">%s</div>`, aiErrorMessage(err, "Failed to generate AI comparison. Please check the LLM provider configuration.")); err != nil {
+			log.Printf("Error writing error response: %v", err)
+		}
+		return
+	}
+
+	view, err := newAIComparisonView(*comparison)
+	if err != nil {
+		log.Printf("Markdown conversion error: %v", err)
+		if _, err := fmt.Fprintf(w, `<div class="text-red-600 p-4 bg-red-50 rounded">Failed to render comparison.</div>`); err != nil {
+			log.Printf("Error writing error response: %v", err)
+		}
+		return
+	}
+	h.render(w, "ai_comparison", view)
+}
+
+func newAIComparisonView(c models.AIComparison) (AIComparisonView, error) {
+	if insights, err := c.Insights(); err == nil {
+		return AIComparisonView{AIComparison: c, Insights: insights}, nil
+	}
+	html, err := renderMarkdown(c.Text)
+	return AIComparisonView{AIComparison: c, HTML: html}, err
+}
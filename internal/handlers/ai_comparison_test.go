@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"health-balance/internal/models"
+	"health-balance/internal/utils"
+)
+
+func TestHandleAIComparison(t *testing.T) {
+	handler, mockDB := summaryTestHandler(t)
+	week := utils.GetCurrentWeekSundayDate()
+	sunday, _ := time.Parse("2006-01-02", week)
+	earlier := sunday.AddDate(0, 0, -56).Format("2006-01-02")
+	mockDB.GetAllDatesWithDataFunc = func() ([]string, error) { return []string{week, earlier}, nil }
+	var saved []models.AIComparison
+	mockDB.SaveAIComparisonFunc = func(c models.AIComparison) (int, error) {
+		saved = append(saved, c)
+		return len(saved), nil
+	}
+
+	form := url.Values{
+		"baseline_from": {sunday.AddDate(0, 0, -62).Format("2006-01-02")},
+		"baseline_to":   {earlier},
+		"from":          {sunday.AddDate(0, 0, -6).Format("2006-01-02")},
+		"to":            {week},
+	}
+	rr := httptest.NewRecorder()
+	handler.HandleAIComparison(rr, privacyForm("/ai-comparison", form))
+	if body := rr.Body.String(); rr.Code != http.StatusOK || !strings.Contains(body, "canned response") || !strings.Contains(body, "cached=false") {
+		t.Errorf("Expected a generated comparison, got %d %q", rr.Code, body)
+	}
+	if len(saved) != 1 || saved[0].Baseline.To != earlier || saved[0].Current.To != week {
+		t.Errorf("Expected the comparison stored with its periods, got %+v", saved)
+	}
+
+	form.Set("baseline_to", week)
+	rr = httptest.NewRecorder()
+	handler.HandleAIComparison(rr, privacyForm("/ai-comparison", form))
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Header().Get("HX-Trigger"), "The periods cannot overlap") {
+		t.Errorf("Expected overlapping periods rejected, got %d %q", rr.Code, rr.Header().Get("HX-Trigger"))
+	}
+	if len(saved) != 1 {
+		t.Errorf("Expected nothing generated for invalid periods, got %d comparisons", len(saved))
+	}
+}
+
+func TestHandleAIComparisons(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+	mockDB.GetAIComparisonsFunc = func() ([]models.AIComparison, error) {
+		return []models.AIComparison{{ID: 1, Text: "**Better** sleep"}}, nil
+	}
+
+	rr := httptest.NewRecorder()
+	handler.HandleAIComparisons(rr, httptest.NewRequest("GET", "/ai-comparison?baseline_from=2026-01-08&baseline_to=2026-02-04&from=2026-02-05&to=2026-03-04", nil))
+	if body := rr.Body.String(); !strings.Contains(body, "3 presets, 2026-02-05 to 2026-03-04 vs 2026-01-08 to 2026-02-04, 1 comparisons") {
+		t.Errorf("Expected the periods from the query, got %q", body)
+	}
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"health-balance/internal/models"
+	"health-balance/internal/services"
+	"log"
+	"net/http"
+	"slices"
+)
+
+// HandleSaveAIPrivacy stores the privacy settings from the AI privacy form
+func (h *Handler) HandleSaveAIPrivacy(w http.ResponseWriter, r *http.Request) {
+	privacy, ok := parseAIPrivacyForm(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.db.SaveAIPrivacy(privacy); err != nil {
+		log.Printf("Error saving AI privacy settings: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Trigger", `{"showToast":"AI privacy settings saved"}`)
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleAIPrivacyPreview renders exactly what the AI summary would send with the settings
+// in the form, before they are saved
+func (h *Handler) HandleAIPrivacyPreview(w http.ResponseWriter, r *http.Request) {
+	privacy, ok := parseAIPrivacyForm(w, r)
+	if !ok {
+		return
+	}
+
+	tmpl, err := services.ActiveSummaryTemplate(h.db)
+	if err != nil {
+		log.Printf("Prompt template error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	h.renderAIPromptPreview(w, privacy, tmpl)
+}
+
+// parseAIPrivacyForm reads the privacy form, whose checkboxes say what is shared
+func parseAIPrivacyForm(w http.ResponseWriter, r *http.Request) (models.AIPrivacy, bool) {
+	if err := r.ParseForm(); err != nil {
+		log.Printf("Error parsing form: %v", err)
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return models.AIPrivacy{}, false
+	}
+
+	privacy := models.AIPrivacy{
+		HideAge:    r.FormValue("share_age") == "",
+		HideSex:    r.FormValue("share_sex") == "",
+		HideHeight: r.FormValue("share_height") == "",
+		HideNotes:  r.FormValue("share_notes") == "",
+		Coarsen:    r.FormValue("coarsen") != "",
+	}
+	shared := r.Form["share_metrics"]
+	for _, m := range models.MetricCatalog {
+		if !slices.Contains(shared, m.Key) {
+			privacy.HiddenMetrics = append(privacy.HiddenMetrics, m.Key)
+		}
+	}
+	return privacy, true
+}
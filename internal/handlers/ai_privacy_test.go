@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"health-balance/internal/models"
+)
+
+func privacyForm(target string, values url.Values) *http.Request {
+	req := httptest.NewRequest("POST", target, strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func TestHandleSaveAIPrivacy(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+	var saved *models.AIPrivacy
+	mockDB.SaveAIPrivacyFunc = func(p models.AIPrivacy) error {
+		saved = &p
+		return nil
+	}
+
+	shared := url.Values{"share_age": {"on"}, "coarsen": {"on"}}
+	for _, m := range models.MetricCatalog {
+		if m.Key != "body_weight_kg" && m.Key != "waist_cm" {
+			shared.Add("share_metrics", m.Key)
+		}
+	}
+	rr := httptest.NewRecorder()
+	handler.HandleSaveAIPrivacy(rr, privacyForm("/ai-privacy", shared))
+
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Header().Get("HX-Trigger"), "AI privacy settings saved") {
+		t.Errorf("Expected a saved toast, got %d %q", rr.Code, rr.Header().Get("HX-Trigger"))
+	}
+	if saved == nil || saved.HideAge || !saved.HideSex || !saved.HideHeight || !saved.HideNotes || !saved.Coarsen {
+		t.Fatalf("Expected unchecked fields hidden, got %+v", saved)
+	}
+	if strings.Join(saved.HiddenMetrics, ",") != "body_weight_kg,waist_cm" {
+		t.Errorf("Expected the unchecked metrics hidden, got %v", saved.HiddenMetrics)
+	}
+}
+
+func TestHandleAIPrivacyPreview(t *testing.T) {
+	handler, mockDB := summaryTestHandler(t)
+	mockDB.GetAIPrivacyFunc = func() (models.AIPrivacy, error) {
+		t.Error("Expected the preview to use the submitted settings, not the saved ones")
+		return models.AIPrivacy{}, nil
+	}
+
+	rr := httptest.NewRecorder()
+	handler.HandleAIPrivacyPreview(rr, privacyForm("/ai-privacy/preview", url.Values{"share_sex": {"on"}, "share_metrics": {"sleep_score"}}))
+	body := rr.Body.String()
+	if rr.Code != http.StatusOK || !strings.HasPrefix(body, "fake: ") {
+		t.Fatalf("Expected the preview for the fake provider, got %d %q", rr.Code, body)
+	}
+	if !strings.Contains(body, "for a female") || !strings.Contains(body, "Sleep Score: 80") {
+		t.Errorf("Expected the shared fields in the preview, got %q", body)
+	}
+	if strings.Contains(body, "year-old") || strings.Contains(body, "168") || strings.Contains(body, "Workouts:") {
+		t.Errorf("Expected the hidden fields left out of the preview, got %q", body)
+	}
+}
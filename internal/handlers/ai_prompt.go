@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"health-balance/internal/models"
+	"health-balance/internal/services"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AIPromptPreview is the summary prompt as it would be sent, or why it can't be rendered
+type AIPromptPreview struct {
+	Provider string
+	Model    string
+	Prompt   string
+	Error    string
+}
+
+// HandleSaveAIPrompt stores the summary prompt template from the settings, after checking
+// that it renders
+func (h *Handler) HandleSaveAIPrompt(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		log.Printf("Error parsing form: %v", err)
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	body := r.FormValue("template")
+	if strings.TrimSpace(body) == "" {
+		writeFieldErrors(w, "ai-prompt-form", models.FieldErrors{"template": "template is required"})
+		return
+	}
+	if err := services.ValidateSummaryTemplate(body); err != nil {
+		writeFieldErrors(w, "ai-prompt-form", models.FieldErrors{"template": err.Error()})
+		return
+	}
+
+	tmpl := models.PromptTemplate{Name: models.PromptTemplateSummary, Body: body, UpdatedAt: time.Now()}
+	if err := h.db.SavePromptTemplate(tmpl); err != nil {
+		log.Printf("Error saving prompt template: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Trigger", `{"showToast":"Prompt template saved"}`)
+	h.renderAIPromptForm(w)
+}
+
+// HandleResetAIPrompt deletes the saved summary prompt template, going back to the file or
+// built-in one
+func (h *Handler) HandleResetAIPrompt(w http.ResponseWriter, r *http.Request) {
+	if err := h.db.DeletePromptTemplate(models.PromptTemplateSummary); err != nil {
+		log.Printf("Error deleting prompt template: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Trigger", `{"showToast":"Prompt template reset"}`)
+	h.renderAIPromptForm(w)
+}
+
+// HandleAIPromptPreview renders the summary prompt for the current data and privacy settings
+// from the submitted template, or from the active one when none is submitted
+func (h *Handler) HandleAIPromptPreview(w http.ResponseWriter, r *http.Request) {
+	tmpl, err := services.ActiveSummaryTemplate(h.db)
+	if err != nil {
+		log.Printf("Prompt template error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if body := r.FormValue("template"); strings.TrimSpace(body) != "" {
+		tmpl = services.SummaryTemplate{Body: body}
+	}
+
+	privacy, err := h.db.GetAIPrivacy()
+	if err != nil {
+		log.Printf("Error loading AI privacy settings: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	h.renderAIPromptPreview(w, privacy, tmpl)
+}
+
+// renderAIPromptPreview renders what a summary would send with the privacy settings and
+// template. Template errors are shown in the preview, since it is how they are fixed.
+func (h *Handler) renderAIPromptPreview(w http.ResponseWriter, privacy models.AIPrivacy, tmpl services.SummaryTemplate) {
+	cfg := services.LoadLLMConfig()
+	preview := AIPromptPreview{Provider: cfg.Provider, Model: cfg.Model}
+
+	if err := services.ValidateSummaryTemplate(tmpl.Body); err != nil {
+		preview.Error = err.Error()
+		h.render(w, "ai_prompt_preview", preview)
+		return
+	}
+	prompt, err := services.SummaryPromptPreview(h.db, privacy, tmpl)
+	if err != nil {
+		log.Printf("AI prompt preview error: %v", err)
+		http.Error(w, "Failed to build the preview. Please ensure your profile is complete.", http.StatusInternalServerError)
+		return
+	}
+	preview.Prompt = prompt
+	h.render(w, "ai_prompt_preview", preview)
+}
+
+// renderAIPromptForm renders the prompt template settings with the active template
+func (h *Handler) renderAIPromptForm(w http.ResponseWriter) {
+	tmpl, err := services.ActiveSummaryTemplate(h.db)
+	if err != nil {
+		log.Printf("Prompt template error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	h.render(w, "ai_prompt_form", tmpl)
+}
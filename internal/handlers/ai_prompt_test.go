@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"health-balance/internal/models"
+)
+
+func TestHandleSaveAIPrompt(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+	var saved *models.PromptTemplate
+	mockDB.SavePromptTemplateFunc = func(tmpl models.PromptTemplate) error {
+		saved = &tmpl
+		return nil
+	}
+	mockDB.GetPromptTemplateFunc = func(name string) (*models.PromptTemplate, error) {
+		return saved, nil
+	}
+
+	rr := httptest.NewRecorder()
+	handler.HandleSaveAIPrompt(rr, privacyForm("/ai-prompt", url.Values{"template": {"{{range .Weeks}}{{.Sleep}}{{end}}"}}))
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Header().Get("HX-Trigger"), `"template":`) || saved != nil {
+		t.Fatalf("Expected a template error, got %d %q", rr.Code, rr.Header().Get("HX-Trigger"))
+	}
+
+	rr = httptest.NewRecorder()
+	handler.HandleSaveAIPrompt(rr, privacyForm("/ai-prompt", url.Values{"template": {"Coach {{.Person}}.\n{{.Format}}"}}))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Header().Get("HX-Trigger"), "Prompt template saved") {
+		t.Fatalf("Expected the template saved, got %d %q", rr.Code, rr.Header().Get("HX-Trigger"))
+	}
+	if saved == nil || saved.Name != models.PromptTemplateSummary || saved.Body != "Coach {{.Person}}.\n{{.Format}}" {
+		t.Errorf("Unexpected saved template %+v", saved)
+	}
+	if rr.Body.String() != "settings" {
+		t.Errorf("Expected the form rendered with the saved template, got %q", rr.Body.String())
+	}
+}
+
+func TestHandleResetAIPrompt(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+	deleted := ""
+	mockDB.DeletePromptTemplateFunc = func(name string) error {
+		deleted = name
+		return nil
+	}
+
+	rr := httptest.NewRecorder()
+	handler.HandleResetAIPrompt(rr, httptest.NewRequest("DELETE", "/ai-prompt", nil))
+	if rr.Code != http.StatusOK || deleted != models.PromptTemplateSummary || rr.Body.String() != "built-in" {
+		t.Errorf("Expected the built-in template restored, got %d %q (deleted %q)", rr.Code, rr.Body.String(), deleted)
+	}
+}
+
+func TestHandleAIPromptPreview(t *testing.T) {
+	handler, _ := summaryTestHandler(t)
+
+	rr := httptest.NewRecorder()
+	handler.HandleAIPromptPreview(rr, httptest.NewRequest("GET", "/ai-prompt/preview", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "fake: You are an expert longevity and health coach") {
+		t.Errorf("Expected the built-in prompt, got %d %q", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	handler.HandleAIPromptPreview(rr, privacyForm("/ai-prompt/preview", url.Values{"template": {"Coach {{.Profile.Sex}}{{range .Weeks}} {{.Date}}{{end}}"}}))
+	if body := rr.Body.String(); rr.Code != http.StatusOK || !strings.HasPrefix(body, "fake: Coach female 20") {
+		t.Errorf("Expected the submitted template rendered, got %d %q", rr.Code, body)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.HandleAIPromptPreview(rr, privacyForm("/ai-prompt/preview", url.Values{"template": {"{{.Nope}}"}}))
+	if body := rr.Body.String(); rr.Code != http.StatusOK || !strings.Contains(body, "evaluate field Nope") {
+		t.Errorf("Expected the template error in the preview, got %d %q", rr.Code, body)
+	}
+}
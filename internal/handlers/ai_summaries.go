@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"health-balance/internal/models"
+	"health-balance/internal/services"
+	"html/template"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// AISummaryView is a summary together with its structured insights, or its markdown
+// rendered as sanitized HTML when it has none
+type AISummaryView struct {
+	models.AISummary
+	Insights *models.AIInsights
+	HTML     template.HTML
+}
+
+// aiErrorMessage explains why an AI feature failed, with fallback for provider errors
+func aiErrorMessage(err error, fallback string) string {
+	if errors.Is(err, services.ErrLLMBudgetExceeded) {
+		return "This month's AI budget is spent. Raise LLM_MONTHLY_BUDGET or wait until next month."
+	}
+	return fallback
+}
+
+// HandleAiSummary shows the AI summary of the latest weeks. GET serves the stored summary
+// while the data behind it is unchanged, POST always asks the model for a new one.
+func (h *Handler) HandleAiSummary(w http.ResponseWriter, r *http.Request) {
+	summary, err := services.GetHealthSummary(r.Context(), h.db, r.Method == http.MethodPost, nil)
+	if err != nil {
+		log.Printf("AI summary error: %v", err)
+		if _, err := fmt.Fprintf(w, `<div class="text-red-600 p-4 bg-red-50 rounded">%s</div>`, aiErrorMessage(err, "Failed to generate AI summary. Please check the LLM provider configuration.")); err != nil {
+			log.Printf("Error writing error response: %v", err)
+		}
+		return
+	}
+
+	view, err := newAISummaryView(*summary)
+	if err != nil {
+		log.Printf("Markdown conversion error: %v", err)
+		if _, err := fmt.Fprintf(w, `<div class="text-red-600 p-4 bg-red-50 rounded">Failed to render summary.</div>`); err != nil {
+			log.Printf("Error writing error response: %v", err)
+		}
+		return
+	}
+	h.render(w, "ai_summary", view)
+}
+
+// HandleAiSummaryStream streams the AI summary as server-sent events: "chunk" events carry
+// the summary text as it is generated as JSON strings, then "done" carries the rendered
+// insights or "error" a message. Disconnecting cancels the model request. ?regenerate=true
+// skips the stored summary.
+func (h *Handler) HandleAiSummaryStream(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Keep reverse proxies from buffering the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	send := func(event, data string) error {
+		var msg strings.Builder
+		msg.WriteString("event: " + event + "\n")
+		for _, line := range strings.Split(data, "\n") {
+			msg.WriteString("data: " + line + "\n")
+		}
+		msg.WriteString("\n")
+		if _, err := io.WriteString(w, msg.String()); err != nil {
+			return err
+		}
+		if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		return nil
+	}
+	sendError := func(message string) {
+		if err := send("error", `<div class="text-red-600 p-4 bg-red-50 rounded">`+message+`</div>`); err != nil {
+			log.Printf("Error writing AI summary stream error: %v", err)
+		}
+	}
+
+	regenerate := r.URL.Query().Get("regenerate") == "true"
+	summary, err := services.GetHealthSummary(r.Context(), h.db, regenerate, func(text string) error {
+		data, err := json.Marshal(text)
+		if err != nil {
+			return err
+		}
+		return send("chunk", string(data))
+	})
+	if err != nil {
+		if r.Context().Err() != nil {
+			log.Printf("AI summary stream cancelled by the client: %v", err)
+			return
+		}
+		log.Printf("AI summary error: %v", err)
+		sendError(aiErrorMessage(err, "Failed to generate AI summary. Please check the LLM provider configuration."))
+		return
+	}
+
+	view, err := newAISummaryView(*summary)
+	if err != nil {
+		log.Printf("Markdown conversion error: %v", err)
+		sendError("Failed to render summary.")
+		return
+	}
+	var buf bytes.Buffer
+	if err := h.templates.ExecuteTemplate(&buf, "ai_summary", view); err != nil {
+		log.Printf("Render error [ai_summary]: %v", err)
+		sendError("Failed to render summary.")
+		return
+	}
+	if err := send("done", buf.String()); err != nil {
+		log.Printf("Error writing AI summary stream: %v", err)
+	}
+}
+
+// HandleAiSummaryHistory lists every stored summary, newest first
+func (h *Handler) HandleAiSummaryHistory(w http.ResponseWriter, r *http.Request) {
+	summaries, err := h.db.GetAISummaries()
+	if err != nil {
+		log.Printf("Error loading AI summaries: %v", err)
+		http.Error(w, "Failed to load AI summaries", http.StatusInternalServerError)
+		return
+	}
+
+	views := make([]AISummaryView, 0, len(summaries))
+	for _, s := range summaries {
+		view, err := newAISummaryView(s)
+		if err != nil {
+			log.Printf("Markdown conversion error for AI summary %d: %v", s.ID, err)
+			continue
+		}
+		views = append(views, view)
+	}
+	h.render(w, "ai_summaries.html", views)
+}
+
+func newAISummaryView(s models.AISummary) (AISummaryView, error) {
+	if insights, err := s.Insights(); err == nil {
+		return AISummaryView{AISummary: s, Insights: insights}, nil
+	}
+	html, err := renderMarkdown(s.Text)
+	return AISummaryView{AISummary: s, HTML: html}, err
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"health-balance/internal/models"
+	"health-balance/internal/testutil"
+	"health-balance/internal/utils"
+)
+
+func summaryTestHandler(t *testing.T) (*Handler, *testutil.MockDB) {
+	t.Setenv("LLM_PROVIDER", "fake")
+	handler, mockDB := setupTestHandler()
+	week := utils.GetCurrentWeekSundayDate()
+	mockDB.GetUserProfileFunc = func() (*models.UserProfile, error) {
+		return &models.UserProfile{BirthDate: "1980-01-01", Sex: "female", HeightCm: 168}, nil
+	}
+	mockDB.GetAllDatesWithDataFunc = func() ([]string, error) { return []string{week}, nil }
+	mockDB.GetHealthMetricsByDateFunc = func(date string) (*models.HealthMetrics, error) {
+		return &models.HealthMetrics{Date: date, SleepScore: models.Int(80)}, nil
+	}
+	mockDB.GetFitnessMetricsByDateFunc = func(date string) (*models.FitnessMetrics, error) {
+		return &models.FitnessMetrics{Date: date, Workouts: models.Int(3)}, nil
+	}
+	mockDB.GetCognitionMetricsByDateFunc = func(date string) (*models.CognitionMetrics, error) {
+		return &models.CognitionMetrics{Date: date, Mindfulness: models.Int(4)}, nil
+	}
+	return handler, mockDB
+}
+
+func TestHandleAiSummary(t *testing.T) {
+	handler, mockDB := summaryTestHandler(t)
+	var saved []models.AISummary
+	mockDB.SaveAISummaryFunc = func(s models.AISummary) (int, error) {
+		saved = append(saved, s)
+		return len(saved), nil
+	}
+	mockDB.GetAISummaryByPromptFunc = func(promptHash, provider, model string) (*models.AISummary, error) {
+		return &models.AISummary{ID: 7, Provider: provider, Model: model, PromptHash: promptHash, Text: "**Stored** advice", CreatedAt: time.Now()}, nil
+	}
+
+	rr := httptest.NewRecorder()
+	handler.HandleAiSummary(rr, httptest.NewRequest("GET", "/ai-summary", nil))
+	if body := rr.Body.String(); !strings.Contains(body, "<strong>Stored</strong> advice") || !strings.Contains(body, "cached=true") {
+		t.Errorf("Expected the stored summary, got %q", body)
+	}
+	if len(saved) != 0 {
+		t.Errorf("Expected nothing generated for a stored summary, got %+v", saved)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.HandleAiSummary(rr, httptest.NewRequest("POST", "/ai-summary", nil))
+	if body := rr.Body.String(); !strings.Contains(body, "canned response") || !strings.Contains(body, "cached=false") {
+		t.Errorf("Expected a regenerated summary, got %q", body)
+	}
+	if len(saved) != 1 || saved[0].Provider != "fake" || saved[0].PromptHash == "" || saved[0].Week != utils.GetCurrentWeekSundayDate() {
+		t.Errorf("Expected the new summary to be stored, got %+v", saved)
+	}
+}
+
+func TestHandleAiSummaryError(t *testing.T) {
+	t.Setenv("LLM_PROVIDER", "unknown")
+	handler, _ := setupTestHandler()
+
+	rr := httptest.NewRecorder()
+	handler.HandleAiSummary(rr, httptest.NewRequest("GET", "/ai-summary", nil))
+	if !strings.Contains(rr.Body.String(), "Failed to generate AI summary") {
+		t.Errorf("Expected an error message, got %q", rr.Body.String())
+	}
+}
+
+func TestHandleAiSummaryHistory(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+	mockDB.GetAISummariesFunc = func() ([]models.AISummary, error) {
+		return []models.AISummary{
+			{ID: 2, Week: "2026-03-08", Text: "*Newer*"},
+			{ID: 1, Week: "2026-03-01", Text: "Older"},
+		}, nil
+	}
+
+	rr := httptest.NewRecorder()
+	handler.HandleAiSummaryHistory(rr, httptest.NewRequest("GET", "/ai-summaries", nil))
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+	if body := rr.Body.String(); !strings.HasPrefix(body, "2 summaries 2026-03-08: <p><em>Newer</em></p>") {
+		t.Errorf("Expected both summaries rendered newest first, got %q", body)
+	}
+
+	// Structured summaries are shown as insights
+	mockDB.GetAISummariesFunc = func() ([]models.AISummary, error) {
+		return []models.AISummary{{ID: 3, Week: "2026-03-15", Text: "```json\n" + testInsightsJSON + "\n```"}}, nil
+	}
+	rr = httptest.NewRecorder()
+	handler.HandleAiSummaryHistory(rr, httptest.NewRequest("GET", "/ai-summaries", nil))
+	if body := rr.Body.String(); body != "1 summaries 2026-03-15: Fitness is slipping. [Fitness] Add intervals goal=VO2 Max ≥ 45.0 ml/kg/min" {
+		t.Errorf("Expected the structured summary rendered as insights, got %q", body)
+	}
+}
+
+const testInsightsJSON = `{"summary": "Fitness is slipping.", "bottlenecks": [], "recommendations": [
+	{"pillar": "fitness", "title": "Add intervals", "action": "Two interval sessions a week.", "target_metric": "vo2_max", "target_value": 45, "priority": "high"}
+]}`
+
+func TestHandleAiSummaryStream(t *testing.T) {
+	handler, mockDB := summaryTestHandler(t)
+	var saved []models.AISummary
+	mockDB.SaveAISummaryFunc = func(s models.AISummary) (int, error) {
+		saved = append(saved, s)
+		return len(saved), nil
+	}
+
+	rr := httptest.NewRecorder()
+	handler.HandleAiSummaryStream(rr, httptest.NewRequest("GET", "/ai-summary/stream?regenerate=true", nil))
+
+	if ct := rr.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected an event stream, got %q", ct)
+	}
+	if !rr.Flushed {
+		t.Error("Expected the stream to be flushed as it is written")
+	}
+	body := rr.Body.String()
+	if !strings.HasPrefix(body, "event: chunk\ndata: \"This \"\n") {
+		t.Errorf("Expected the summary text streamed as JSON chunks, got %q", body)
+	}
+	if chunks, _, _ := strings.Cut(body, "event: done"); strings.Contains(chunks, "bottlenecks") || strings.Contains(chunks, "{") {
+		t.Errorf("Expected only the summary text streamed, not the raw JSON, got %q", chunks)
+	}
+	if !strings.Contains(body, "event: done\ndata: This is a canned response") || !strings.Contains(body, "[Health] Protect your sleep goal=Sleep Score ≥ 80") {
+		t.Errorf("Expected the rendered insights in the done event, got %q", body)
+	}
+	if len(saved) != 1 {
+		t.Errorf("Expected the streamed summary to be stored, got %+v", saved)
+	}
+}
+
+func TestHandleAiSummaryStreamError(t *testing.T) {
+	t.Setenv("LLM_PROVIDER", "unknown")
+	handler, _ := setupTestHandler()
+
+	rr := httptest.NewRecorder()
+	handler.HandleAiSummaryStream(rr, httptest.NewRequest("GET", "/ai-summary/stream", nil))
+	if body := rr.Body.String(); !strings.HasPrefix(body, "event: error\ndata: <div") || !strings.Contains(body, "Failed to generate AI summary") {
+		t.Errorf("Expected an error event, got %q", body)
+	}
+}
+
+func TestHandleAiSummaryOverBudget(t *testing.T) {
+	handler, mockDB := summaryTestHandler(t)
+	t.Setenv("LLM_MONTHLY_BUDGET", "5")
+	mockDB.GetAIUsageFunc = func(since time.Time) ([]models.AIUsage, error) {
+		return []models.AIUsage{{CreatedAt: time.Now(), Cost: 5.5}}, nil
+	}
+
+	rr := httptest.NewRecorder()
+	handler.HandleAiSummary(rr, httptest.NewRequest("POST", "/ai-summary", nil))
+	if !strings.Contains(rr.Body.String(), "This month's AI budget is spent") {
+		t.Errorf("Expected the budget message, got %q", rr.Body.String())
+	}
+}
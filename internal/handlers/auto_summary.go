@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"health-balance/internal/models"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// HandleSaveAutoSummary stores the settings of the automatic weekly AI summary
+func (h *Handler) HandleSaveAutoSummary(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		log.Printf("Error parsing form: %v", err)
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	settings := models.AutoSummarySettings{
+		Enabled:  r.FormValue("enabled") != "",
+		Trigger:  r.FormValue("trigger"),
+		Time:     strings.TrimSpace(r.FormValue("time")),
+		Timezone: strings.TrimSpace(r.FormValue("timezone")),
+	}
+	day, err := strconv.Atoi(r.FormValue("day"))
+	if err != nil {
+		day = -1
+	}
+	settings.Day = day
+	if errs := settings.Validate(); len(errs) > 0 {
+		writeFieldErrors(w, "auto-summary-form", errs)
+		return
+	}
+
+	if err := h.db.SaveAutoSummarySettings(settings); err != nil {
+		log.Printf("Error saving automatic summary settings: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Trigger", `{"showToast":"Weekly AI summary settings saved"}`)
+	w.WriteHeader(http.StatusOK)
+}
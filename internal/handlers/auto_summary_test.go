@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"health-balance/internal/models"
+)
+
+func TestHandleSaveAutoSummary(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+	var saved *models.AutoSummarySettings
+	mockDB.SaveAutoSummarySettingsFunc = func(s models.AutoSummarySettings) error {
+		saved = &s
+		return nil
+	}
+
+	rr := httptest.NewRecorder()
+	handler.HandleSaveAutoSummary(rr, privacyForm("/auto-summary", url.Values{
+		"enabled": {"on"}, "trigger": {"scheduled"}, "day": {"1"}, "time": {"07:30"}, "timezone": {"Europe/Stockholm"},
+	}))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Header().Get("HX-Trigger"), "Weekly AI summary settings saved") {
+		t.Fatalf("Expected the settings saved, got %d %q", rr.Code, rr.Header().Get("HX-Trigger"))
+	}
+	want := models.AutoSummarySettings{Enabled: true, Trigger: models.AutoSummaryScheduled, Day: 1, Time: "07:30", Timezone: "Europe/Stockholm"}
+	if saved == nil || *saved != want {
+		t.Errorf("Expected %+v, got %+v", want, saved)
+	}
+
+	saved = nil
+	rr = httptest.NewRecorder()
+	handler.HandleSaveAutoSummary(rr, privacyForm("/auto-summary", url.Values{"trigger": {"complete"}, "day": {"x"}, "time": {"7am"}, "timezone": {"UTC"}}))
+	if rr.Code != http.StatusBadRequest || saved != nil {
+		t.Fatalf("Expected the invalid settings rejected, got %d", rr.Code)
+	}
+	if trigger := rr.Header().Get("HX-Trigger"); !strings.Contains(trigger, `"day":`) || !strings.Contains(trigger, `"time":`) {
+		t.Errorf("Expected errors for the day and time, got %q", trigger)
+	}
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"health-balance/internal/models"
+	"health-balance/internal/services"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// HandleChart renders the SVG trend chart for the requested series and range
+func (h *Handler) HandleChart(w http.ResponseWriter, r *http.Request) {
+	series, ok := h.chartSeries(w, r)
+	if !ok {
+		return
+	}
+	h.render(w, "chart.html", services.LayoutChart(*series))
+}
+
+// HandleChartAPI returns the chart series as JSON
+func (h *Handler) HandleChartAPI(w http.ResponseWriter, r *http.Request) {
+	series, ok := h.chartSeries(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(series); err != nil {
+		log.Printf("Error encoding chart series: %v", err)
+	}
+}
+
+// chartSeries reads the series, range and ma query parameters, writing an error response when they are invalid
+func (h *Handler) chartSeries(w http.ResponseWriter, r *http.Request) (*models.ChartSeries, bool) {
+	query := r.URL.Query()
+
+	key := query.Get("series")
+	if key == "" {
+		key = "score"
+	}
+	rangeKey := query.Get("range")
+	if rangeKey == "" {
+		rangeKey = services.DefaultChartRange
+	}
+	movingAverage := services.DefaultMovingAverageWeeks
+	if raw := query.Get("ma"); raw != "" {
+		weeks, err := strconv.Atoi(raw)
+		if err != nil {
+			http.Error(w, "ma must be a whole number of weeks", http.StatusBadRequest)
+			return nil, false
+		}
+		movingAverage = weeks
+	}
+
+	series, err := services.GetChartSeries(h.db, key, rangeKey, movingAverage)
+	if errors.Is(err, services.ErrInvalidChartRequest) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Chart error: %v", err)
+		http.Error(w, "Failed to load chart data", http.StatusInternalServerError)
+		return nil, false
+	}
+	return series, true
+}
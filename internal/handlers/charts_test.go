@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"health-balance/internal/models"
+)
+
+func TestHandleChartAPI(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+
+	mockDB.GetMetricSeriesFunc = func(key, from, to string) ([]models.MetricPoint, error) {
+		if key != "dead_hang_seconds" {
+			t.Errorf("Expected dead hang series, got %s", key)
+		}
+		return []models.MetricPoint{{Date: "2026-01-04", Value: 60}, {Date: "2026-01-11", Value: 70}}, nil
+	}
+
+	req := httptest.NewRequest("GET", "/api/chart?series=dead_hang_seconds&range=3m&ma=2", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleChartAPI(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+
+	var series models.ChartSeries
+	if err := json.Unmarshal(rr.Body.Bytes(), &series); err != nil {
+		t.Fatalf("Expected JSON series, got error: %v", err)
+	}
+	if series.Range != "3m" || len(series.Points) != 2 || len(series.MovingAverage) != 2 || series.MovingAverage[1].Value != 65 {
+		t.Errorf("Unexpected chart series %+v", series)
+	}
+}
+
+func TestHandleChartRejectsInvalidRequests(t *testing.T) {
+	handler, _ := setupTestHandler()
+
+	for _, query := range []string{"series=bogus", "range=10y", "ma=abc", "ma=50"} {
+		req := httptest.NewRequest("GET", "/chart?"+query, nil)
+		rr := httptest.NewRecorder()
+		handler.HandleChart(rr, req)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("Expected status code %d for %q, got %d", http.StatusBadRequest, query, status)
+		}
+	}
+}
+
+func TestHandleChartDefaultsToTotalScore(t *testing.T) {
+	handler, _ := setupTestHandler()
+
+	req := httptest.NewRequest("GET", "/chart", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleChart(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+	if body := rr.Body.String(); !strings.Contains(body, "Total Score 0 points") {
+		t.Errorf("Expected an empty total score chart, got %q", body)
+	}
+}
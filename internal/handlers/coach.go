@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"fmt"
+	"health-balance/internal/models"
+	"health-balance/internal/services"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// CoachData is the coach page: every thread and the messages of the open one, if any
+type CoachData struct {
+	Threads  []models.CoachThread
+	Thread   *models.CoachThread
+	Messages []CoachMessageView
+}
+
+// CoachMessageView is a message as shown in the chat. Answers are rendered from markdown
+// and lookups get a short label; lookup results are not shown.
+type CoachMessageView struct {
+	models.CoachMessage
+	HTML  template.HTML
+	Label string
+}
+
+// HandleCoach renders the coach page, opening the thread given by ?thread=
+func (h *Handler) HandleCoach(w http.ResponseWriter, r *http.Request) {
+	threads, err := h.db.GetCoachThreads()
+	if err != nil {
+		log.Printf("Error loading coach threads: %v", err)
+		http.Error(w, "Failed to load coach threads", http.StatusInternalServerError)
+		return
+	}
+	data := CoachData{Threads: threads}
+
+	if raw := r.URL.Query().Get("thread"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			http.Error(w, "Invalid thread id", http.StatusBadRequest)
+			return
+		}
+		if data.Thread, err = h.db.GetCoachThread(id); err != nil {
+			log.Printf("Error loading coach thread: %v", err)
+			http.Error(w, "Failed to load coach thread", http.StatusInternalServerError)
+			return
+		}
+		if data.Thread == nil {
+			http.Error(w, "Thread not found", http.StatusNotFound)
+			return
+		}
+
+		messages, err := h.db.GetCoachMessages(id)
+		if err != nil {
+			log.Printf("Error loading coach messages: %v", err)
+			http.Error(w, "Failed to load coach thread", http.StatusInternalServerError)
+			return
+		}
+		data.Messages = coachMessageViews(messages)
+	}
+
+	h.render(w, "coach.html", data)
+}
+
+// HandleAskCoach sends a question from the chat form to the coach. Answers in an open
+// thread are appended to the chat; a first question starts a thread and opens it.
+func (h *Handler) HandleAskCoach(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		log.Printf("Error parsing form: %v", err)
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	var threadID int
+	if raw := r.FormValue("thread_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			http.Error(w, "Invalid thread id", http.StatusBadRequest)
+			return
+		}
+		threadID = id
+	}
+	question := r.FormValue("question")
+	if errs := models.ValidateCoachQuestion(question); len(errs) > 0 {
+		writeFieldErrors(w, "coach-form", errs)
+		return
+	}
+
+	// A stale tab may still post to a deleted thread; don't pay for an answer nobody can see
+	if threadID != 0 {
+		thread, err := h.db.GetCoachThread(threadID)
+		if err != nil {
+			log.Printf("Error loading coach thread: %v", err)
+			http.Error(w, "Failed to load coach thread", http.StatusInternalServerError)
+			return
+		}
+		if thread == nil {
+			w.Header().Set("HX-Trigger", `{"showToast":{"message":"This conversation was deleted.","type":"error"}}`)
+			http.Error(w, "Thread not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	id, messages, err := services.AskCoach(r.Context(), h.db, threadID, question)
+	if err != nil {
+		log.Printf("Coach error: %v", err)
+		message := aiErrorMessage(err, "The coach couldn't answer. Please check the LLM provider configuration and try again.")
+		w.Header().Set("HX-Trigger", fmt.Sprintf(`{"showToast":{"message":%q,"type":"error"}}`, message))
+		http.Error(w, "Failed to get an answer from the coach", http.StatusBadGateway)
+		return
+	}
+
+	if threadID == 0 {
+		w.Header().Set("HX-Redirect", fmt.Sprintf("/coach?thread=%d", id))
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	h.render(w, "coach_messages", coachMessageViews(messages))
+}
+
+// HandleDeleteCoachThread deletes the thread with the {id} path value and returns to the coach page
+func (h *Handler) HandleDeleteCoachThread(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid thread id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.db.DeleteCoachThread(id); err != nil {
+		log.Printf("Error deleting coach thread: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Redirect", "/coach")
+	w.WriteHeader(http.StatusOK)
+}
+
+func coachMessageViews(messages []models.CoachMessage) []CoachMessageView {
+	views := make([]CoachMessageView, 0, len(messages))
+	for _, m := range messages {
+		view := CoachMessageView{CoachMessage: m}
+		switch m.Role {
+		case models.CoachRoleAssistant:
+			html, err := renderMarkdown(m.Content)
+			if err != nil {
+				log.Printf("Markdown conversion error for coach message %d: %v", m.ID, err)
+				html = template.HTML(template.HTMLEscapeString(m.Content))
+			}
+			view.HTML = html
+		case models.CoachRoleToolCall:
+			view.Label = services.CoachToolCallLabel(m.Content)
+		case models.CoachRoleToolResult:
+			continue
+		}
+		views = append(views, view)
+	}
+	return views
+}
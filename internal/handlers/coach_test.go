@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"health-balance/internal/models"
+)
+
+func coachForm(values url.Values) *http.Request {
+	req := httptest.NewRequest("POST", "/coach", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func TestHandleCoach(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+	mockDB.GetCoachThreadsFunc = func() ([]models.CoachThread, error) {
+		return []models.CoachThread{{ID: 1, Title: "Fitness drop"}, {ID: 2, Title: "Sleep"}}, nil
+	}
+	mockDB.GetCoachThreadFunc = func(id int) (*models.CoachThread, error) {
+		if id != 1 {
+			return nil, nil
+		}
+		return &models.CoachThread{ID: 1, Title: "Fitness drop"}, nil
+	}
+	mockDB.GetCoachMessagesFunc = func(threadID int) ([]models.CoachMessage, error) {
+		return []models.CoachMessage{
+			{ID: 1, ThreadID: threadID, Role: models.CoachRoleUser, Content: "Why is my fitness dropping?"},
+			{ID: 2, ThreadID: threadID, Role: models.CoachRoleToolCall, Content: `CALL metric_history {"metric": "vo2_max", "weeks": 26}`},
+			{ID: 3, ThreadID: threadID, Role: models.CoachRoleToolResult, Content: "VO2 Max, weeks ending:"},
+			{ID: 4, ThreadID: threadID, Role: models.CoachRoleAssistant, Content: "Fewer **workouts**."},
+		}, nil
+	}
+
+	rr := httptest.NewRecorder()
+	handler.HandleCoach(rr, httptest.NewRequest("GET", "/coach", nil))
+	if rr.Code != http.StatusOK || rr.Body.String() != "2 threads" {
+		t.Errorf("Expected the thread list, got %d %q", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	handler.HandleCoach(rr, httptest.NewRequest("GET", "/coach?thread=1", nil))
+	body := rr.Body.String()
+	for _, want := range []string{
+		"open=Fitness drop",
+		"user:Why is my fitness dropping?",
+		"tool_call:metric_history · VO2 Max · 26 weeks",
+		"assistant:<p>Fewer <strong>workouts</strong>.</p>",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected %q in %q", want, body)
+		}
+	}
+	if strings.Contains(body, "weeks ending") {
+		t.Errorf("Expected lookup results to be hidden, got %q", body)
+	}
+
+	for target, code := range map[string]int{"/coach?thread=abc": http.StatusBadRequest, "/coach?thread=9": http.StatusNotFound} {
+		rr = httptest.NewRecorder()
+		handler.HandleCoach(rr, httptest.NewRequest("GET", target, nil))
+		if rr.Code != code {
+			t.Errorf("%s: expected status %d, got %d", target, code, rr.Code)
+		}
+	}
+}
+
+func TestHandleAskCoach(t *testing.T) {
+	handler, mockDB := summaryTestHandler(t)
+	var created []models.CoachThread
+	var saved []models.CoachMessage
+	mockDB.CreateCoachThreadFunc = func(thread models.CoachThread) (int, error) {
+		created = append(created, thread)
+		return 5, nil
+	}
+	mockDB.AddCoachMessagesFunc = func(messages []models.CoachMessage) error {
+		saved = append(saved, messages...)
+		return nil
+	}
+
+	// A first question starts a thread and opens it
+	rr := httptest.NewRecorder()
+	handler.HandleAskCoach(rr, coachForm(url.Values{"question": {"Why is my fitness pillar dropping?"}}))
+	if rr.Code != http.StatusOK || rr.Header().Get("HX-Redirect") != "/coach?thread=5" {
+		t.Errorf("Expected a redirect to the new thread, got %d %q", rr.Code, rr.Header().Get("HX-Redirect"))
+	}
+	if len(created) != 1 || created[0].Title != "Why is my fitness pillar dropping?" {
+		t.Errorf("Expected a thread titled after the question, got %+v", created)
+	}
+	if len(saved) != 2 || saved[0].ThreadID != 5 || saved[1].Role != models.CoachRoleAssistant {
+		t.Errorf("Expected the question and answer saved to the thread, got %+v", saved)
+	}
+
+	// A follow-up is appended to the open thread
+	mockDB.GetCoachThreadFunc = func(id int) (*models.CoachThread, error) {
+		return &models.CoachThread{ID: id, Title: "Why is my fitness pillar dropping?"}, nil
+	}
+	mockDB.GetCoachMessagesFunc = func(threadID int) ([]models.CoachMessage, error) {
+		return saved, nil
+	}
+	rr = httptest.NewRecorder()
+	handler.HandleAskCoach(rr, coachForm(url.Values{"thread_id": {"5"}, "question": {"And my sleep?"}}))
+	body := rr.Body.String()
+	if rr.Code != http.StatusOK || rr.Header().Get("HX-Redirect") != "" {
+		t.Errorf("Expected the new messages, got %d redirect %q", rr.Code, rr.Header().Get("HX-Redirect"))
+	}
+	if !strings.Contains(body, "user:And my sleep?") || !strings.Contains(body, "assistant:") || strings.Contains(body, "fitness pillar") {
+		t.Errorf("Expected only the follow-up and its answer, got %q", body)
+	}
+	if len(created) != 1 || len(saved) != 4 || saved[2].ThreadID != 5 {
+		t.Errorf("Expected the follow-up saved to thread 5, got %+v", saved)
+	}
+}
+
+func TestHandleAskCoachErrors(t *testing.T) {
+	handler, mockDB := summaryTestHandler(t)
+
+	rr := httptest.NewRecorder()
+	handler.HandleAskCoach(rr, coachForm(url.Values{"question": {"  "}}))
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Header().Get("HX-Trigger"), "question") {
+		t.Errorf("Expected a field error for an empty question, got %d %q", rr.Code, rr.Header().Get("HX-Trigger"))
+	}
+
+	rr = httptest.NewRecorder()
+	handler.HandleAskCoach(rr, coachForm(url.Values{"thread_id": {"x"}, "question": {"Hi"}}))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid thread id, got %d", rr.Code)
+	}
+
+	// A stale tab asking in a deleted thread gets no answer and stores nothing
+	mockDB.AddCoachMessagesFunc = func(messages []models.CoachMessage) error {
+		t.Errorf("Expected nothing stored for a deleted thread, got %+v", messages)
+		return nil
+	}
+	rr = httptest.NewRecorder()
+	handler.HandleAskCoach(rr, coachForm(url.Values{"thread_id": {"9"}, "question": {"Hi"}}))
+	if rr.Code != http.StatusNotFound || !strings.Contains(rr.Header().Get("HX-Trigger"), "deleted") {
+		t.Errorf("Expected 404 for a deleted thread, got %d %q", rr.Code, rr.Header().Get("HX-Trigger"))
+	}
+	mockDB.AddCoachMessagesFunc = nil
+
+	t.Setenv("LLM_PROVIDER", "gemini")
+	t.Setenv("GEMINI_API_KEY", "")
+	rr = httptest.NewRecorder()
+	handler.HandleAskCoach(rr, coachForm(url.Values{"question": {"Hi"}}))
+	if rr.Code != http.StatusBadGateway || !strings.Contains(rr.Header().Get("HX-Trigger"), "showToast") {
+		t.Errorf("Expected 502 with a toast when the provider fails, got %d %q", rr.Code, rr.Header().Get("HX-Trigger"))
+	}
+}
+
+func TestHandleDeleteCoachThread(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+	var deleted int
+	mockDB.DeleteCoachThreadFunc = func(id int) error {
+		deleted = id
+		return nil
+	}
+
+	req := httptest.NewRequest("DELETE", "/coach/threads/3", nil)
+	req.SetPathValue("id", "3")
+	rr := httptest.NewRecorder()
+	handler.HandleDeleteCoachThread(rr, req)
+	if rr.Code != http.StatusOK || deleted != 3 || rr.Header().Get("HX-Redirect") != "/coach" {
+		t.Errorf("Expected thread 3 deleted and a redirect, got %d %d %q", rr.Code, deleted, rr.Header().Get("HX-Redirect"))
+	}
+
+	req = httptest.NewRequest("DELETE", "/coach/threads/x", nil)
+	req.SetPathValue("id", "x")
+	rr = httptest.NewRecorder()
+	handler.HandleDeleteCoachThread(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid id, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"health-balance/internal/models"
+	"health-balance/internal/services"
+	"log"
+	"net/http"
+)
+
+const strongestCorrelationsLimit = 10
+
+// CorrelationPairData is the lag profile between two metrics
+type CorrelationPairData struct {
+	X       models.MetricDefinition
+	Y       models.MetricDefinition
+	Profile []models.Correlation
+}
+
+// HandleCorrelations renders the correlation explorer with the strongest relationships in the data
+func (h *Handler) HandleCorrelations(w http.ResponseWriter, r *http.Request) {
+	strongest, err := services.GetStrongestCorrelations(h.db, strongestCorrelationsLimit)
+	if err != nil {
+		log.Printf("Correlation error: %v", err)
+		http.Error(w, "Failed to compute correlations", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Strongest []models.Correlation
+		Metrics   []models.MetricDefinition
+		X         string
+		Y         string
+		MaxLag    int
+	}{
+		Strongest: strongest,
+		Metrics:   models.MetricCatalog,
+		X:         services.CorrelationBehaviors[0],
+		Y:         services.CorrelationOutcomes[0],
+		MaxLag:    services.MaxCorrelationLagWeeks,
+	}
+	h.render(w, "correlations.html", data)
+}
+
+// HandleCorrelationPair renders the lag profile between the x and y metrics
+func (h *Handler) HandleCorrelationPair(w http.ResponseWriter, r *http.Request) {
+	x, y := r.URL.Query().Get("x"), r.URL.Query().Get("y")
+
+	profile, err := services.GetCorrelationProfile(h.db, x, y)
+	if errors.Is(err, services.ErrUnknownCorrelationMetric) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Correlation error: %v", err)
+		http.Error(w, "Failed to compute correlations", http.StatusInternalServerError)
+		return
+	}
+
+	data := CorrelationPairData{Profile: profile}
+	data.X, _ = models.LookupMetric(x)
+	data.Y, _ = models.LookupMetric(y)
+	h.render(w, "correlation_pair", data)
+}
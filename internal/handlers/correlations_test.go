@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleCorrelations(t *testing.T) {
+	handler, _ := setupTestHandler()
+
+	rr := httptest.NewRecorder()
+	handler.HandleCorrelations(rr, httptest.NewRequest("GET", "/correlations", nil))
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+	if !strings.Contains(rr.Body.String(), "0 correlations, sleep_score vs rhr") {
+		t.Errorf("Expected the default pair, got %q", rr.Body.String())
+	}
+}
+
+func TestHandleCorrelationPair(t *testing.T) {
+	handler, _ := setupTestHandler()
+
+	rr := httptest.NewRecorder()
+	handler.HandleCorrelationPair(rr, httptest.NewRequest("GET", "/correlations/pair?x=workouts&y=vo2_max", nil))
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+	if !strings.Contains(rr.Body.String(), "Workouts vs VO2 Max: 0 lags") {
+		t.Errorf("Expected the pair profile, got %q", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	handler.HandleCorrelationPair(rr, httptest.NewRequest("GET", "/correlations/pair?x=workouts&y=luck", nil))
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for an unknown metric, got %d", http.StatusBadRequest, status)
+	}
+}
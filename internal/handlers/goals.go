@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"health-balance/internal/models"
+	"health-balance/internal/services"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// GoalsData is the progress of every goal together with the choices for a new one
+type GoalsData struct {
+	Goals          []models.GoalProgress
+	Metrics        []models.MetricDefinition
+	MaxWindowWeeks int
+}
+
+// HandleGoals renders the goals with their progress, expected completion and status
+func (h *Handler) HandleGoals(w http.ResponseWriter, r *http.Request) {
+	progress, err := services.GetGoalProgress(h.db, time.Now())
+	if err != nil {
+		log.Printf("Goal progress error: %v", err)
+		http.Error(w, "Failed to load goals", http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, "goals.html", GoalsData{
+		Goals:          progress,
+		Metrics:        models.MetricCatalog,
+		MaxWindowWeeks: models.MaxGoalWindowWeeks,
+	})
+}
+
+// HandleCreateGoal validates and stores a goal from the goal form
+func (h *Handler) HandleCreateGoal(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		log.Printf("Error parsing form: %v", err)
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	errs := models.FieldErrors{}
+	goal := models.Goal{
+		MetricKey:   r.FormValue("metric"),
+		Comparison:  r.FormValue("comparison"),
+		WindowWeeks: 1,
+		Deadline:    r.FormValue("deadline"),
+		Notify:      r.FormValue("notify") != "",
+	}
+	target, err := parseOptionalFormFloat(r, "target")
+	if err != nil {
+		errs.Add("target", "Target must be a number")
+	} else if target == nil {
+		errs.Add("target", "Target is required")
+	} else {
+		goal.Target = *target
+	}
+	if window, err := parseOptionalFormInt(r, "window_weeks"); err != nil {
+		errs.Add("window_weeks", err.Error())
+	} else if window != nil {
+		goal.WindowWeeks = *window
+	}
+
+	now := time.Now()
+	if len(errs) == 0 {
+		errs.Merge(goal.Validate(now))
+	}
+	if len(errs) > 0 {
+		writeFieldErrors(w, "goal-form", errs)
+		return
+	}
+
+	if _, err := services.CreateGoal(h.db, goal, now); err != nil {
+		log.Printf("Error saving goal: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Trigger", `{"refreshGoals":true, "showToast":"Goal added"}`)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleDeleteGoal deletes the goal with the {id} path value
+func (h *Handler) HandleDeleteGoal(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid goal id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.db.DeleteGoal(id); err != nil {
+		log.Printf("Error deleting goal: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Trigger", `{"refreshGoals":true, "showToast":"Goal deleted"}`)
+	w.WriteHeader(http.StatusOK)
+}
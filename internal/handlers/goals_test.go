@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"health-balance/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleGoals(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+	mockDB.GetGoalsFunc = func() ([]models.Goal, error) {
+		return []models.Goal{{ID: 1, MetricKey: "vo2_max", Comparison: models.GoalAtLeast, Target: 50, WindowWeeks: 1}}, nil
+	}
+
+	rr := httptest.NewRecorder()
+	handler.HandleGoals(rr, httptest.NewRequest("GET", "/goals", nil))
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+	if !strings.Contains(rr.Body.String(), "1 goals VO2 Max ≥ 50.0 ml/kg/min: no_data") {
+		t.Errorf("Expected the goal without data, got %q", rr.Body.String())
+	}
+}
+
+func TestHandleCreateGoal(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+
+	var saved *models.Goal
+	mockDB.CreateGoalFunc = func(g models.Goal) (int, error) {
+		saved = &g
+		return 1, nil
+	}
+
+	post := func(form string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/goals", strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		handler.HandleCreateGoal(rr, req)
+		return rr
+	}
+
+	rr := post("metric=sleep_score&comparison=at_least&target=300&window_weeks=4")
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for an impossible target, got %d", http.StatusBadRequest, status)
+	}
+	if !strings.Contains(rr.Header().Get("HX-Trigger"), `"goal-form"`) {
+		t.Errorf("Expected field errors for the goal form, got %q", rr.Header().Get("HX-Trigger"))
+	}
+	if saved != nil {
+		t.Fatal("Expected an invalid goal not to be saved")
+	}
+
+	rr = post("metric=sleep_score&comparison=at_least&target=80&window_weeks=4&deadline=2099-06-30&notify=on")
+	if status := rr.Code; status != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusNoContent, status, rr.Body.String())
+	}
+	if saved == nil || saved.MetricKey != "sleep_score" || saved.Target != 80 || saved.WindowWeeks != 4 ||
+		saved.Deadline != "2099-06-30" || !saved.Notify {
+		t.Errorf("Unexpected saved goal: %+v", saved)
+	}
+}
+
+func TestHandleDeleteGoal(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+
+	deleted := 0
+	mockDB.DeleteGoalFunc = func(id int) error {
+		deleted = id
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("DELETE /goals/{id}", handler.HandleDeleteGoal)
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("DELETE", "/goals/3", nil))
+	if rr.Code != http.StatusOK || deleted != 3 {
+		t.Errorf("Expected goal 3 to be deleted, got status %d and id %d", rr.Code, deleted)
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("DELETE", "/goals/abc", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for an invalid id, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
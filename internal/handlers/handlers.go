@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"encoding/json"
 	"health-balance/internal/database"
@@ -47,7 +48,12 @@ type DashboardData struct {
 	LastHealth      *models.HealthMetrics
 	LastFitness     *models.FitnessMetrics
 	LastCognition   *models.CognitionMetrics
-	HasProfile      bool
+	VO2MaxStanding  *models.VO2MaxStanding
+	// Personal baselines as of last week, compared with this week's entry
+	HealthBaselines    []models.PersonalBaseline
+	FitnessBaselines   []models.PersonalBaseline
+	CognitionBaselines []models.PersonalBaseline
+	HasProfile         bool
 }
 
 func (h *Handler) HandleHome(w http.ResponseWriter, r *http.Request) {
@@ -72,14 +78,55 @@ func (h *Handler) HandleSettings(w http.ResponseWriter, r *http.Request) {
 
 	sub, _ := h.db.GetAnyPushSubscription()
 
+	privacy, err := h.db.GetAIPrivacy()
+	if err != nil {
+		log.Printf("Error loading AI privacy settings: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	promptTemplate, err := services.ActiveSummaryTemplate(h.db)
+	if err != nil {
+		log.Printf("Prompt template error: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	usage, err := services.GetAIUsageReport(h.db, services.LoadLLMConfig(), time.Now())
+	if err != nil {
+		log.Printf("AI usage error: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	autoSummary, err := h.db.GetAutoSummarySettings()
+	if err != nil {
+		log.Printf("Error loading automatic summary settings: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
 	data := struct {
 		Profile        *models.UserProfile
 		Subscription   *models.PushSubscription
 		VapidPublicKey string
+		Privacy        models.AIPrivacy
+		Metrics        []models.MetricDefinition
+		PromptTemplate services.SummaryTemplate
+		Usage          *services.AIUsageReport
+		AutoSummary    models.AutoSummarySettings
+		Weekdays       []time.Weekday
 	}{
 		Profile:        profile,
 		Subscription:   sub,
 		VapidPublicKey: os.Getenv("VAPID_PUBLIC_KEY"),
+		Privacy:        privacy,
+		Metrics:        models.MetricCatalog,
+		PromptTemplate: promptTemplate,
+		Usage:          usage,
+		AutoSummary:    autoSummary,
+		// Weeks run from Monday to Sunday
+		Weekdays: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday},
 	}
 	err_t := h.templates.ExecuteTemplate(w, "settings.html", data)
 	if err_t != nil {
@@ -110,7 +157,39 @@ func (h *Handler) HandleScores(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	h.render(w, "scores.html", limitMasterScores(scores, historyPreviewLimit))
+	scores = limitMasterScores(scores, historyPreviewLimit)
+	if err := services.AttachWeekNotes(h.db, scores); err != nil {
+		log.Printf("Error loading week notes: %v", err)
+	}
+	h.render(w, "scores.html", scores)
+}
+
+func (h *Handler) HandleScoresAPI(w http.ResponseWriter, r *http.Request) {
+	scores, err := services.GetAllWeeklyScores(h.db)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if scores == nil {
+		scores = []models.MasterScore{}
+	}
+	if err := services.AttachWeekNotes(h.db, scores); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(scores); err != nil {
+		log.Printf("Error encoding scores: %v", err)
+	}
+}
+
+func (h *Handler) HandleBiologicalAge(w http.ResponseWriter, r *http.Request) {
+	estimate, err := services.GetBiologicalAge(h.db)
+	if err != nil {
+		log.Printf("Biological age error: %v", err)
+	}
+	h.render(w, "biological_age.html", estimate)
 }
 
 func (h *Handler) HandleHealthMetrics(w http.ResponseWriter, r *http.Request) {
@@ -119,7 +198,7 @@ func (h *Handler) HandleHealthMetrics(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	h.render(w, "health_metrics.html", metrics)
+	h.render(w, "health_metrics.html", healthHistoryRows(metrics, h.outlierFlags(models.PillarHealth)))
 }
 
 func (h *Handler) HandleHealthWeekState(w http.ResponseWriter, r *http.Request) {
@@ -132,7 +211,7 @@ func (h *Handler) HandleFitnessMetrics(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	h.render(w, "fitness_metrics.html", metrics)
+	h.render(w, "fitness_metrics.html", fitnessHistoryRows(metrics, h.outlierFlags(models.PillarFitness)))
 }
 
 func (h *Handler) HandleFitnessWeekState(w http.ResponseWriter, r *http.Request) {
@@ -145,7 +224,7 @@ func (h *Handler) HandleCognitionMetrics(w http.ResponseWriter, r *http.Request)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	h.render(w, "cognition_metrics.html", metrics)
+	h.render(w, "cognition_metrics.html", cognitionHistoryRows(metrics, h.outlierFlags(models.PillarCognition)))
 }
 
 func limitMasterScores(scores []models.MasterScore, limit int) []models.MasterScore {
@@ -171,18 +250,18 @@ func (h *Handler) HandleAddHealthMetrics(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	var errs []string
-	getF := func(key string) float64 {
-		val, err := parseFormFloat(r, key)
+	errs := models.FieldErrors{}
+	getF := func(key string) *float64 {
+		val, err := parseOptionalFormFloat(r, key)
 		if err != nil {
-			errs = append(errs, err.Error())
+			errs.Add(key, err.Error())
 		}
 		return val
 	}
-	getI := func(key string) int {
-		val, err := parseFormInt(r, key)
+	getI := func(key string) *int {
+		val, err := parseOptionalFormInt(r, key)
 		if err != nil {
-			errs = append(errs, err.Error())
+			errs.Add(key, err.Error())
 		}
 		return val
 	}
@@ -197,8 +276,17 @@ func (h *Handler) HandleAddHealthMetrics(w http.ResponseWriter, r *http.Request)
 		NutritionScore: getF("nutrition_score"),
 	}
 
+	errs.Merge(health.Validate())
+	if len(errs) == 0 && !hasHealthValues(health) {
+		errs.Add("", "at least one health metric is required")
+	}
+
 	if len(errs) > 0 {
-		http.Error(w, strings.Join(errs, ", "), http.StatusBadRequest)
+		writeFieldErrors(w, "health-form", errs)
+		return
+	}
+
+	if h.needsAnomalyConfirmation(w, r, models.PillarHealth, health.Value) {
 		return
 	}
 
@@ -223,24 +311,24 @@ func (h *Handler) HandleAddFitnessMetrics(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	var errs []string
-	getI := func(key string) int {
-		val, err := parseFormInt(r, key)
+	errs := models.FieldErrors{}
+	getI := func(key string) *int {
+		val, err := parseOptionalFormInt(r, key)
 		if err != nil {
-			errs = append(errs, err.Error())
+			errs.Add(key, err.Error())
 		}
 		return val
 	}
-	getF := func(key string) float64 {
-		val, err := parseFormFloat(r, key)
+	getF := func(key string) *float64 {
+		val, err := parseOptionalFormFloat(r, key)
 		if err != nil {
-			errs = append(errs, err.Error())
+			errs.Add(key, err.Error())
 		}
 		return val
 	}
 	lowerBodyWeight, lowerBodyReps, err := parseWeightAndReps(r.FormValue("leg_press_set"))
 	if err != nil {
-		errs = append(errs, err.Error())
+		errs.Add("leg_press_set", err.Error())
 	}
 
 	fitness := models.FitnessMetrics{
@@ -254,8 +342,23 @@ func (h *Handler) HandleAddFitnessMetrics(w http.ResponseWriter, r *http.Request
 		DeadHangSeconds: getI("dead_hang_seconds"),
 	}
 
+	for key, message := range fitness.Validate() {
+		// Leg press weight and reps share the single leg_press_set input
+		if key == "lower_body_weight" || key == "lower_body_reps" {
+			key = "leg_press_set"
+		}
+		errs.Add(key, message)
+	}
+	if len(errs) == 0 && !hasFitnessValues(fitness) {
+		errs.Add("", "at least one fitness metric is required")
+	}
+
 	if len(errs) > 0 {
-		http.Error(w, strings.Join(errs, ", "), http.StatusBadRequest)
+		writeFieldErrors(w, "fitness-form", errs)
+		return
+	}
+
+	if h.needsAnomalyConfirmation(w, r, models.PillarFitness, fitness.Value) {
 		return
 	}
 
@@ -280,24 +383,33 @@ func (h *Handler) HandleAddCognitionMetrics(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	var errs []string
-	getI := func(key string, label string) int {
-		val, err := parseFormInt(r, key)
+	errs := models.FieldErrors{}
+	getI := func(key string) *int {
+		val, err := parseOptionalFormInt(r, key)
 		if err != nil {
-			errs = append(errs, fmt.Sprintf("%s is required", label))
+			errs.Add(key, err.Error())
 		}
 		return val
 	}
 
 	cognition := models.CognitionMetrics{
-		Mindfulness:  getI("mindfulness", "Mindfulness"),
-		DeepLearning: getI("deep_learning", "Deep Learning"),
-		StressScore:  getI("stress_score", "Stress Score"),
-		SocialDays:   getI("social_days", "Social Days"),
+		Mindfulness:  getI("mindfulness"),
+		DeepLearning: getI("deep_learning"),
+		StressScore:  getI("stress_score"),
+		SocialDays:   getI("social_days"),
+	}
+
+	errs.Merge(cognition.Validate())
+	if len(errs) == 0 && !hasCognitionValues(cognition) {
+		errs.Add("", "at least one cognition metric is required")
 	}
 
 	if len(errs) > 0 {
-		http.Error(w, strings.Join(errs, ", "), http.StatusBadRequest)
+		writeFieldErrors(w, "cognition-form", errs)
+		return
+	}
+
+	if h.needsAnomalyConfirmation(w, r, models.PillarCognition, cognition.Value) {
 		return
 	}
 
@@ -311,6 +423,44 @@ func (h *Handler) HandleAddCognitionMetrics(w http.ResponseWriter, r *http.Reque
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// needsAnomalyConfirmation checks the submitted values of a pillar for likely typos. Unless
+// the form was resubmitted with confirm_anomalies, it answers 409 with the warnings and a
+// confirmAnomalies trigger so the page can ask before saving, and returns true.
+func (h *Handler) needsAnomalyConfirmation(w http.ResponseWriter, r *http.Request, pillar string, value func(key string) *float64) bool {
+	if confirmed, _ := strconv.ParseBool(r.FormValue("confirm_anomalies")); confirmed {
+		return false
+	}
+
+	week, err := time.Parse("2006-01-02", utils.GetCurrentWeekSundayDate())
+	if err != nil {
+		week = time.Now()
+	}
+	anomalies, err := services.CheckAnomalies(h.db, pillar, week, value)
+	if err != nil {
+		// Failing to check should not stop the user from saving
+		log.Printf("Error checking %s anomalies: %v", pillar, err)
+		return false
+	}
+	if len(anomalies) == 0 {
+		return false
+	}
+
+	messages := make([]string, len(anomalies))
+	for i, a := range anomalies {
+		messages[i] = a.Message()
+	}
+	trigger, err := json.Marshal(map[string]any{
+		"confirmAnomalies": map[string]any{"form": pillar + "-form", "warnings": messages},
+	})
+	if err != nil {
+		log.Printf("Error encoding anomaly warnings: %v", err)
+		return false
+	}
+	w.Header().Set("HX-Trigger", string(trigger))
+	http.Error(w, strings.Join(messages, "\n"), http.StatusConflict)
+	return true
+}
+
 func (h *Handler) HandleDeleteHealthMetric(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -394,10 +544,23 @@ func (h *Handler) HandleUpdateProfile(w http.ResponseWriter, r *http.Request) {
 		profile = *existingProfile
 	}
 
-	profile.BirthDate = r.FormValue("birth_date")
+	profile.BirthDate = strings.TrimSpace(r.FormValue("birth_date"))
 	profile.Sex = r.FormValue("sex")
-	height, _ := parseFormFloat(r, "height_cm")
-	profile.HeightCm = height
+	errs := models.FieldErrors{}
+	height, err := parseOptionalFormFloat(r, "height_cm")
+	if err != nil {
+		errs.Add("height_cm", err.Error())
+	}
+	profile.HeightCm = 0
+	if height != nil {
+		profile.HeightCm = *height
+	}
+
+	errs.Merge(profile.Validate(time.Now()))
+	if len(errs) > 0 {
+		writeFieldErrors(w, "profile-form", errs)
+		return
+	}
 
 	if err := h.db.SaveUserProfile(profile); err != nil {
 		log.Printf("Error saving user profile: %v", err)
@@ -485,33 +648,13 @@ func (h *Handler) HandleAppHealth(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (h *Handler) HandleAiSummary(w http.ResponseWriter, r *http.Request) {
-	summary, err := services.GetHealthSummary(h.db)
-	if err != nil {
-		log.Printf("AI summary error: %v", err)
-		if _, err := fmt.Fprintf(w, `<div class="text-red-600 p-4 bg-red-50 rounded">Failed to generate AI summary. Please ensure API_KEY is set.</div>`); err != nil {
-			log.Printf("Error writing error response: %v", err)
-		}
-		return
-	}
-
-	// Convert markdown to HTML
+// renderMarkdown converts markdown to HTML and sanitizes it to only allow safe tags
+func renderMarkdown(markdown string) (template.HTML, error) {
 	var buf bytes.Buffer
-	if err := goldmark.Convert([]byte(summary), &buf); err != nil {
-		log.Printf("Markdown conversion error: %v", err)
-		if _, err := fmt.Fprintf(w, `<div class="text-red-600 p-4 bg-red-50 rounded">Failed to render summary.</div>`); err != nil {
-			log.Printf("Error writing error response: %v", err)
-		}
-		return
-	}
-
-	// Sanitize HTML to only allow safe tags
-	policy := bluemonday.UGCPolicy()
-	safeHTML := policy.SanitizeBytes(buf.Bytes())
-
-	if _, err := fmt.Fprintf(w, `<div>%s</div>`, safeHTML); err != nil {
-		log.Printf("Error writing AI summary response: %v", err)
+	if err := goldmark.Convert([]byte(markdown), &buf); err != nil {
+		return "", err
 	}
+	return template.HTML(bluemonday.UGCPolicy().SanitizeBytes(buf.Bytes())), nil
 }
 
 func parseFormInt(r *http.Request, key string) (int, error) {
@@ -530,38 +673,98 @@ func parseFormFloat(r *http.Request, key string) (float64, error) {
 	return strconv.ParseFloat(val, 64)
 }
 
-func parseWeightAndReps(value string) (float64, int, error) {
+// parseOptionalFormInt returns nil when the field was left empty
+func parseOptionalFormInt(r *http.Request, key string) (*int, error) {
+	if strings.TrimSpace(r.FormValue(key)) == "" {
+		return nil, nil
+	}
+	val, err := strconv.Atoi(strings.TrimSpace(r.FormValue(key)))
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a whole number", fieldLabel(key))
+	}
+	return &val, nil
+}
+
+// parseOptionalFormFloat returns nil when the field was left empty
+func parseOptionalFormFloat(r *http.Request, key string) (*float64, error) {
+	if strings.TrimSpace(r.FormValue(key)) == "" {
+		return nil, nil
+	}
+	val, err := strconv.ParseFloat(strings.TrimSpace(r.FormValue(key)), 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a number", fieldLabel(key))
+	}
+	return &val, nil
+}
+
+// fieldLabel returns the label of a validated field, falling back to its key
+func fieldLabel(key string) string {
+	if rule, ok := models.LookupFieldRule(key); ok {
+		return rule.Label
+	}
+	return key
+}
+
+// writeFieldErrors answers 400 with the validation errors, plus a fieldErrors trigger so the
+// page can show each message next to its input in the form with the given id
+func writeFieldErrors(w http.ResponseWriter, form string, errs models.FieldErrors) {
+	trigger, err := json.Marshal(map[string]any{
+		"fieldErrors": map[string]any{"form": form, "errors": errs},
+	})
+	if err != nil {
+		log.Printf("Error encoding field errors: %v", err)
+	} else {
+		w.Header().Set("HX-Trigger", string(trigger))
+	}
+	http.Error(w, errs.Error(), http.StatusBadRequest)
+}
+
+// parseWeightAndReps parses a "weightxreps" set. An empty value means the set was skipped.
+func parseWeightAndReps(value string) (*float64, *int, error) {
 	trimmed := strings.TrimSpace(strings.ToLower(value))
 	if trimmed == "" {
-		return 0, 0, fmt.Errorf("leg_press_set is required")
+		return nil, nil, nil
 	}
 
 	parts := strings.Split(trimmed, "x")
 	if len(parts) != 2 {
-		return 0, 0, fmt.Errorf("leg_press_set must use the format weightxreps, for example 180x12")
+		return nil, nil, fmt.Errorf("leg_press_set must use the format weightxreps, for example 180x12")
 	}
 
 	weight, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
 	if err != nil {
-		return 0, 0, fmt.Errorf("leg_press_set must include a valid weight")
+		return nil, nil, fmt.Errorf("leg_press_set must include a valid weight")
 	}
 
 	reps, err := strconv.Atoi(strings.TrimSpace(parts[1]))
 	if err != nil {
-		return 0, 0, fmt.Errorf("leg_press_set must include valid reps")
+		return nil, nil, fmt.Errorf("leg_press_set must include valid reps")
 	}
 
 	if weight <= 0 || reps <= 0 {
-		return 0, 0, fmt.Errorf("leg_press_set weight and reps must both be positive")
+		return nil, nil, fmt.Errorf("leg_press_set weight and reps must both be positive")
 	}
 
-	return weight, reps, nil
+	return &weight, &reps, nil
+}
+
+func hasHealthValues(m models.HealthMetrics) bool {
+	return m.SleepScore != nil || m.WaistCm != nil || m.BodyWeightKg != nil || m.RHR != nil ||
+		m.SystolicBP != nil || m.DiastolicBP != nil || m.NutritionScore != nil
+}
+
+func hasFitnessValues(m models.FitnessMetrics) bool {
+	return m.VO2Max != nil || m.Workouts != nil || m.DailySteps != nil || m.Mobility != nil ||
+		m.CardioRecovery != nil || m.LowerBodyWeight != nil || m.LowerBodyReps != nil || m.DeadHangSeconds != nil
+}
+
+func hasCognitionValues(m models.CognitionMetrics) bool {
+	return m.Mindfulness != nil || m.DeepLearning != nil || m.StressScore != nil || m.SocialDays != nil
 }
 
 func (h *Handler) buildDashboardData() DashboardData {
 	data := h.buildWeekStateData()
 	data.CurrentScore, _ = services.GetCurrentMasterScore(h.db)
-	data.Profile, _ = h.db.GetUserProfile()
 	data.HasProfile = data.Profile != nil &&
 		data.Profile.BirthDate != "" &&
 		data.Profile.Sex != "" &&
@@ -575,15 +778,61 @@ func (h *Handler) buildWeekStateData() DashboardData {
 	todayHealth, _ := h.db.GetHealthMetricsByDate(date)
 	todayFitness, _ := h.db.GetFitnessMetricsByDate(date)
 	todayCognition, _ := h.db.GetCognitionMetricsByDate(date)
+	profile, _ := h.db.GetUserProfile()
+	lastFitness := latestFitnessMetric(h.db)
+
+	standingFitness := todayFitness
+	if standingFitness == nil {
+		standingFitness = lastFitness
+	}
+
+	var healthValue, fitnessValue, cognitionValue func(key string) *float64
+	if todayHealth != nil {
+		healthValue = todayHealth.Value
+	}
+	if todayFitness != nil {
+		fitnessValue = todayFitness.Value
+	}
+	if todayCognition != nil {
+		cognitionValue = todayCognition.Value
+	}
+
+	baselineConfig := services.LoadBaselineConfig()
+	baselineDate := time.Now()
+	if weekDate, err := time.Parse("2006-01-02", date); err == nil {
+		baselineDate = weekDate.AddDate(0, 0, -7)
+	}
 
 	return DashboardData{
-		WeekDateRange:  utils.GetCurrentWeekDateRange(),
-		TodayHealth:    todayHealth,
-		TodayFitness:   todayFitness,
-		TodayCognition: todayCognition,
-		LastHealth:     latestHealthMetric(h.db),
-		LastFitness:    latestFitnessMetric(h.db),
-		LastCognition:  latestCognitionMetric(h.db),
+		WeekDateRange:      utils.GetCurrentWeekDateRange(),
+		Profile:            profile,
+		TodayHealth:        todayHealth,
+		TodayFitness:       todayFitness,
+		TodayCognition:     todayCognition,
+		LastHealth:         latestHealthMetric(h.db),
+		LastFitness:        lastFitness,
+		LastCognition:      latestCognitionMetric(h.db),
+		VO2MaxStanding:     vo2MaxStanding(profile, standingFitness),
+		HealthBaselines:    services.GetPillarBaselines(h.db, models.PillarHealth, baselineDate, baselineConfig, healthValue),
+		FitnessBaselines:   services.GetPillarBaselines(h.db, models.PillarFitness, baselineDate, baselineConfig, fitnessValue),
+		CognitionBaselines: services.GetPillarBaselines(h.db, models.PillarCognition, baselineDate, baselineConfig, cognitionValue),
+	}
+}
+
+func vo2MaxStanding(profile *models.UserProfile, fitness *models.FitnessMetrics) *models.VO2MaxStanding {
+	if profile == nil || fitness == nil || fitness.VO2Max == nil || *fitness.VO2Max <= 0 {
+		return nil
+	}
+
+	age, err := utils.GetAge(profile, time.Now())
+	if err != nil {
+		return nil
+	}
+
+	return &models.VO2MaxStanding{
+		VO2Max:     *fitness.VO2Max,
+		Percentile: models.GetVO2MaxPercentile(float64(age), profile.Sex, *fitness.VO2Max),
+		Norm:       models.GetVO2MaxNorm(float64(age), profile.Sex),
 	}
 }
 
@@ -10,9 +10,11 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"health-balance/internal/models"
 	"health-balance/internal/testutil"
+	"health-balance/internal/utils"
 )
 
 func setupTestHandler() (*Handler, *testutil.MockDB) {
@@ -20,12 +22,32 @@ func setupTestHandler() (*Handler, *testutil.MockDB) {
 	templates := template.Must(template.New("").Parse(`
 {{define "index.html"}}<html><body>{{if .CurrentScore}}{{.CurrentScore.Score}}{{end}}</body></html>{{end}}
 {{define "settings.html"}}<html><body>Settings</body></html>{{end}}
+{{define "ai_prompt_preview"}}{{.Provider}}: {{.Prompt}}{{.Error}}{{end}}
+{{define "ai_prompt_form"}}{{.Source}}{{end}}
 {{define "rationale.html"}}<html><body>Rationale</body></html>{{end}}
 {{define "scores.html"}}<html><body>Scores</body></html>{{end}}
 {{define "health_metrics.html"}}<html><body>Health Metrics</body></html>{{end}}
 {{define "fitness_metrics.html"}}<html><body>Fitness Metrics</body></html>{{end}}
 {{define "cognition_metrics.html"}}<html><body>Cognition Metrics</body></html>{{end}}
 {{define "score_display"}}<html><body>Score Display</body></html>{{end}}
+{{define "biological_age.html"}}{{if .}}{{printf "%.1f" .Estimate}}{{else}}No estimate{{end}}{{end}}
+{{define "chart.html"}}{{.Series.Label}} {{len .Markers}} points{{end}}
+{{define "history.html"}}{{.View}} {{len .Health}} rows next={{.NextURL}}{{end}}
+{{define "report.html"}}{{.From}} to {{.To}} summary={{.SummaryHTML}}{{end}}
+{{define "review.html"}}{{.Year}} review: {{.Weeks}} weeks{{end}}
+{{define "correlations.html"}}{{len .Strongest}} correlations, {{.X}} vs {{.Y}}{{end}}
+{{define "goals.html"}}{{len .Goals}} goals{{range .Goals}} {{.Goal.Description}}: {{.Status}}{{end}}{{end}}
+{{define "interventions.html"}}{{len .Results}} interventions{{range .Results}} {{.Intervention.Name}}: {{len .Scores}} scores, {{len .Metrics}} metrics{{end}}{{end}}
+{{define "week_note"}}{{.FormID}}: {{.Note.Text}} [{{.OtherTags}}]{{end}}
+{{define "correlation_pair"}}{{.X.Label}} vs {{.Y.Label}}: {{len .Profile}} lags{{end}}
+{{define "ai_summary"}}{{template "ai_insights" .}} cached={{.Cached}}{{end}}
+{{define "ai_summaries.html"}}{{len .}} summaries{{range .}} {{.Week}}: {{template "ai_insights" .}}{{end}}{{end}}
+{{define "ai_comparison.html"}}{{len .Presets}} presets, {{.Current}} vs {{.Baseline}}, {{len .Comparisons}} comparisons{{end}}
+{{define "ai_comparison"}}{{template "ai_insights" .}} {{.Current}} vs {{.Baseline}} cached={{.Cached}}{{end}}
+{{define "ai_insights"}}{{with .Insights}}{{.Summary}}{{range .ByPillar}} [{{.Label}}]{{range .Recommendations}} {{.Title}}{{with .Goal}} goal={{.Description}}{{end}}{{end}}{{end}}{{else}}{{.HTML}}{{end}}{{end}}
+{{define "coach.html"}}{{len .Threads}} threads{{with .Thread}} open={{.Title}}{{end}}{{template "coach_messages" .Messages}}{{end}}
+{{define "coach_messages"}}{{range .}} {{.Role}}:{{if .Label}}{{.Label}}{{else if .HTML}}{{.HTML}}{{else}}{{.Content}}{{end}}{{end}}{{end}}
+{{define "history_rows"}}{{len .Health}} rows next={{.NextURL}}{{end}}
 `))
 	mockDB := &testutil.MockDB{}
 	handler := New(mockDB, templates)
@@ -120,16 +142,122 @@ func TestHandleRationale(t *testing.T) {
 	}
 }
 
+func TestHandleScoresAPI(t *testing.T) {
+	handler, _ := setupTestHandler()
+
+	req, err := http.NewRequest("GET", "/api/scores", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.HandleScoresAPI(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected JSON content type, got %q", ct)
+	}
+
+	var scores []models.MasterScore
+	if err := json.Unmarshal(rr.Body.Bytes(), &scores); err != nil {
+		t.Fatalf("Expected JSON array, got error: %v", err)
+	}
+	if len(scores) != 0 {
+		t.Errorf("Expected no scores without data, got %d", len(scores))
+	}
+}
+
+func TestHandleBiologicalAge(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+
+	mockDB.GetUserProfileFunc = func() (*models.UserProfile, error) {
+		return &models.UserProfile{BirthDate: "1980-01-01", Sex: "male", HeightCm: 180}, nil
+	}
+	mockDB.GetAllDatesWithDataFunc = func() ([]string, error) {
+		return []string{"2026-01-04"}, nil
+	}
+	mockDB.GetMetricSeriesFunc = func(key, from, to string) ([]models.MetricPoint, error) {
+		if key == "vo2_max" {
+			return []models.MetricPoint{{Date: "2026-01-04", Value: 42}}, nil
+		}
+		return nil, nil
+	}
+
+	req, err := http.NewRequest("GET", "/biological-age", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.HandleBiologicalAge(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+	if strings.Contains(rr.Body.String(), "No estimate") {
+		t.Errorf("Expected an estimate to be rendered, got %q", rr.Body.String())
+	}
+}
+
+func TestVO2MaxStanding(t *testing.T) {
+	birthDate := time.Now().AddDate(-35, 0, -1).Format("2006-01-02")
+	profile := &models.UserProfile{BirthDate: birthDate, Sex: "female", HeightCm: 170}
+
+	standing := vo2MaxStanding(profile, &models.FitnessMetrics{VO2Max: models.Float(36)})
+	if standing == nil {
+		t.Fatal("Expected a VO2 Max standing, got nil")
+	}
+	if standing.Percentile != 50 {
+		t.Errorf("Expected the median female VO2 Max at 35 to be the 50th percentile, got %.1f", standing.Percentile)
+	}
+
+	if vo2MaxStanding(profile, nil) != nil {
+		t.Error("Expected no standing without fitness metrics")
+	}
+	if vo2MaxStanding(nil, &models.FitnessMetrics{VO2Max: models.Float(36)}) != nil {
+		t.Error("Expected no standing without a profile")
+	}
+}
+
+func TestBuildWeekStateDataIncludesBaselines(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+
+	mockDB.GetHealthMetricsByDateFunc = func(date string) (*models.HealthMetrics, error) {
+		return &models.HealthMetrics{Date: date, RHR: models.Int(58)}, nil
+	}
+	mockDB.GetMetricSeriesFunc = func(key, from, to string) ([]models.MetricPoint, error) {
+		if key != "rhr" {
+			return nil, nil
+		}
+		if to >= utils.GetCurrentWeekSundayDate() {
+			t.Errorf("Expected baselines to exclude the current week, got range ending %s", to)
+		}
+		return []models.MetricPoint{{Date: "a", Value: 60}, {Date: "b", Value: 62}, {Date: "c", Value: 61}}, nil
+	}
+
+	data := handler.buildWeekStateData()
+	if len(data.HealthBaselines) != 1 || len(data.FitnessBaselines) != 0 {
+		t.Fatalf("Expected one health baseline, got %+v / %+v", data.HealthBaselines, data.FitnessBaselines)
+	}
+
+	rhr := data.HealthBaselines[0]
+	if rhr.Value != 61 || rhr.Current == nil || *rhr.Current != 58 || rhr.Status() != "better" {
+		t.Errorf("Expected RHR baseline 61 compared with this week's 58, got %+v", rhr)
+	}
+}
+
 func TestHandleAddHealthMetrics(t *testing.T) {
 	handler, mockDB := setupTestHandler()
 
 	// Mock the save function
 	mockDB.SaveHealthMetricsFunc = func(m models.HealthMetrics) error {
-		if m.SleepScore != 80 {
-			t.Errorf("Expected SleepScore 80, got %d", m.SleepScore)
+		if *m.SleepScore != 80 {
+			t.Errorf("Expected SleepScore 80, got %d", *m.SleepScore)
 		}
-		if m.SystolicBP != 118 || m.DiastolicBP != 76 {
-			t.Errorf("Expected BP 118/76, got %d/%d", m.SystolicBP, m.DiastolicBP)
+		if *m.SystolicBP != 118 || *m.DiastolicBP != 76 {
+			t.Errorf("Expected BP 118/76, got %d/%d", *m.SystolicBP, *m.DiastolicBP)
 		}
 		return nil
 	}
@@ -155,6 +283,157 @@ func TestHandleAddHealthMetrics(t *testing.T) {
 	}
 }
 
+func TestHandleAddHealthMetricsSkippedFields(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+
+	saved := false
+	mockDB.SaveHealthMetricsFunc = func(m models.HealthMetrics) error {
+		saved = true
+		if m.SleepScore == nil || *m.SleepScore != 80 {
+			t.Errorf("Expected SleepScore 80, got %v", m.SleepScore)
+		}
+		if m.SystolicBP != nil || m.DiastolicBP != nil || m.WaistCm != nil {
+			t.Errorf("Expected skipped fields to be nil, got %+v", m)
+		}
+		return nil
+	}
+
+	req, err := http.NewRequest("POST", "/add-health-metrics", strings.NewReader("sleep_score=80&waist_cm=&systolic_bp=&diastolic_bp="))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	rr := httptest.NewRecorder()
+	handler.HandleAddHealthMetrics(rr, req)
+
+	if status := rr.Code; status != http.StatusNoContent {
+		t.Errorf("Expected status code %d, got %d", http.StatusNoContent, status)
+	}
+	if !saved {
+		t.Error("Expected a partial entry to be saved")
+	}
+
+	req, err = http.NewRequest("POST", "/add-health-metrics", strings.NewReader("sleep_score=&rhr="))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	rr = httptest.NewRecorder()
+	handler.HandleAddHealthMetrics(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for an empty entry, got %d", http.StatusBadRequest, status)
+	}
+}
+
+func TestHandleAddHealthMetricsAnomalies(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+
+	saved := false
+	mockDB.SaveHealthMetricsFunc = func(m models.HealthMetrics) error {
+		saved = true
+		return nil
+	}
+
+	post := func(form string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("POST", "/add-health-metrics", strings.NewReader(form))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		handler.HandleAddHealthMetrics(rr, req)
+		return rr
+	}
+
+	rr := post("rhr=5&sleep_score=80")
+	if status := rr.Code; status != http.StatusConflict {
+		t.Fatalf("Expected status code %d, got %d", http.StatusConflict, status)
+	}
+	if saved {
+		t.Error("Expected an implausible value not to be saved without confirmation")
+	}
+
+	var trigger struct {
+		ConfirmAnomalies struct {
+			Form     string   `json:"form"`
+			Warnings []string `json:"warnings"`
+		} `json:"confirmAnomalies"`
+	}
+	if err := json.Unmarshal([]byte(rr.Header().Get("HX-Trigger")), &trigger); err != nil {
+		t.Fatalf("Expected a JSON HX-Trigger header, got %v", err)
+	}
+	if trigger.ConfirmAnomalies.Form != "health-form" || len(trigger.ConfirmAnomalies.Warnings) != 1 ||
+		!strings.Contains(trigger.ConfirmAnomalies.Warnings[0], "Resting Heart Rate") {
+		t.Errorf("Expected one RHR warning for the health form, got %+v", trigger.ConfirmAnomalies)
+	}
+
+	rr = post("rhr=5&sleep_score=80&confirm_anomalies=true")
+	if status := rr.Code; status != http.StatusNoContent {
+		t.Errorf("Expected status code %d after confirmation, got %d", http.StatusNoContent, status)
+	}
+	if !saved {
+		t.Error("Expected the confirmed value to be saved")
+	}
+}
+
+func TestHandleAddMetricsFieldErrors(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+
+	mockDB.SaveHealthMetricsFunc = func(m models.HealthMetrics) error {
+		t.Error("Expected invalid health metrics not to be saved")
+		return nil
+	}
+	mockDB.SaveCognitionMetricsFunc = func(m models.CognitionMetrics) error {
+		t.Error("Expected invalid cognition metrics not to be saved")
+		return nil
+	}
+
+	tests := []struct {
+		name     string
+		handle   http.HandlerFunc
+		form     string
+		formID   string
+		expected []string
+	}{
+		{name: "health", handle: handler.HandleAddHealthMetrics, form: "sleep_score=300&rhr=abc&waist_cm=85", formID: "health-form", expected: []string{"sleep_score", "rhr"}},
+		{name: "cognition", handle: handler.HandleAddCognitionMetrics, form: "stress_score=42&social_days=9", formID: "cognition-form", expected: []string{"stress_score", "social_days"}},
+		{name: "profile", handle: handler.HandleUpdateProfile, form: "birth_date=1990-01-01&sex=male&height_cm=18", formID: "profile-form", expected: []string{"height_cm"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.form))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+			tt.handle(rr, req)
+
+			if status := rr.Code; status != http.StatusBadRequest {
+				t.Fatalf("Expected status code %d, got %d", http.StatusBadRequest, status)
+			}
+			var trigger struct {
+				FieldErrors struct {
+					Form   string            `json:"form"`
+					Errors map[string]string `json:"errors"`
+				} `json:"fieldErrors"`
+			}
+			if err := json.Unmarshal([]byte(rr.Header().Get("HX-Trigger")), &trigger); err != nil {
+				t.Fatalf("Expected a JSON HX-Trigger header, got %v", err)
+			}
+			if trigger.FieldErrors.Form != tt.formID || len(trigger.FieldErrors.Errors) != len(tt.expected) {
+				t.Errorf("Expected errors for %v on %s, got %+v", tt.expected, tt.formID, trigger.FieldErrors)
+			}
+			for _, key := range tt.expected {
+				if trigger.FieldErrors.Errors[key] == "" {
+					t.Errorf("Expected an error for %s, got %v", key, trigger.FieldErrors.Errors)
+				}
+			}
+		})
+	}
+}
+
 func TestHandleHealthMetricsUsesHistoryPreviewLimit(t *testing.T) {
 	handler, mockDB := setupTestHandler()
 
@@ -199,11 +478,11 @@ func TestHandleAddFitnessMetrics(t *testing.T) {
 
 	// Mock the save function
 	mockDB.SaveFitnessMetricsFunc = func(m models.FitnessMetrics) error {
-		if m.VO2Max != 45.0 {
-			t.Errorf("Expected VO2Max 45.0, got %f", m.VO2Max)
+		if *m.VO2Max != 45.0 {
+			t.Errorf("Expected VO2Max 45.0, got %f", *m.VO2Max)
 		}
-		if m.LowerBodyWeight != 180.0 || m.LowerBodyReps != 12 {
-			t.Errorf("Expected leg press 180.0 x 12, got %.1f x %d", m.LowerBodyWeight, m.LowerBodyReps)
+		if *m.LowerBodyWeight != 180.0 || *m.LowerBodyReps != 12 {
+			t.Errorf("Expected leg press 180.0 x 12, got %.1f x %d", *m.LowerBodyWeight, *m.LowerBodyReps)
 		}
 		return nil
 	}
@@ -257,13 +536,18 @@ func TestParseWeightAndReps(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Expected valid parse, got error: %v", err)
 	}
-	if weight != 180 || reps != 12 {
-		t.Fatalf("Expected 180 x 12, got %.1f x %d", weight, reps)
+	if weight == nil || reps == nil || *weight != 180 || *reps != 12 {
+		t.Fatalf("Expected 180 x 12, got %v x %v", weight, reps)
 	}
 
 	if _, _, err := parseWeightAndReps("bad-input"); err == nil {
 		t.Fatal("Expected invalid format to return an error")
 	}
+
+	weight, reps, err = parseWeightAndReps("  ")
+	if err != nil || weight != nil || reps != nil {
+		t.Fatalf("Expected an empty set to be skipped, got %v x %v (%v)", weight, reps, err)
+	}
 }
 
 func TestHandleAddCognitionMetrics(t *testing.T) {
@@ -271,11 +555,11 @@ func TestHandleAddCognitionMetrics(t *testing.T) {
 
 	// Mock the save function
 	mockDB.SaveCognitionMetricsFunc = func(m models.CognitionMetrics) error {
-		if m.Mindfulness != 4 {
-			t.Errorf("Expected Mindfulness 4, got %d", m.Mindfulness)
+		if *m.Mindfulness != 4 {
+			t.Errorf("Expected Mindfulness 4, got %d", *m.Mindfulness)
 		}
-		if m.StressScore != 2 || m.SocialDays != 5 {
-			t.Errorf("Expected stress/social 2/5, got %d/%d", m.StressScore, m.SocialDays)
+		if *m.StressScore != 2 || *m.SocialDays != 5 {
+			t.Errorf("Expected stress/social 2/5, got %d/%d", *m.StressScore, *m.SocialDays)
 		}
 		return nil
 	}
@@ -552,3 +836,20 @@ func TestParseFormFloat(t *testing.T) {
 		t.Errorf("Expected 12.34, got %f", result)
 	}
 }
+
+func TestParseOptionalFormInt(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", strings.NewReader("value=12&empty=&bad=abc"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	val, err := parseOptionalFormInt(req, "value")
+	if err != nil || val == nil || *val != 12 {
+		t.Errorf("Expected 12, got %v (%v)", val, err)
+	}
+	val, err = parseOptionalFormInt(req, "empty")
+	if err != nil || val != nil {
+		t.Errorf("Expected an empty field to be nil, got %v (%v)", val, err)
+	}
+	if _, err := parseOptionalFormInt(req, "bad"); err == nil {
+		t.Error("Expected error for invalid int")
+	}
+}
@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"fmt"
+	"health-balance/internal/models"
+	"health-balance/internal/services"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// HistoryData is one page of a history view together with the filters that produced it
+type HistoryData struct {
+	View      string
+	Views     []string
+	From      string
+	To        string
+	Sort      string
+	Ascending bool
+	Health    []HealthHistoryRow
+	Fitness   []FitnessHistoryRow
+	Cognition []CognitionHistoryRow
+	Scores    []models.MasterScore
+	// NextURL loads the page after this one and is empty on the last page
+	NextURL string
+}
+
+// HealthHistoryRow is a week of health metrics in a history table
+type HealthHistoryRow struct {
+	models.HealthMetrics
+	// Outliers marks the metric keys whose value looks anomalous
+	Outliers map[string]bool
+}
+
+// FitnessHistoryRow is a week of fitness metrics in a history table
+type FitnessHistoryRow struct {
+	models.FitnessMetrics
+	// Outliers marks the metric keys whose value looks anomalous
+	Outliers map[string]bool
+}
+
+// CognitionHistoryRow is a week of cognition metrics in a history table
+type CognitionHistoryRow struct {
+	models.CognitionMetrics
+	// Outliers marks the metric keys whose value looks anomalous
+	Outliers map[string]bool
+}
+
+func healthHistoryRows(metrics []models.HealthMetrics, flags models.OutlierFlags) []HealthHistoryRow {
+	rows := make([]HealthHistoryRow, len(metrics))
+	for i, m := range metrics {
+		rows[i] = HealthHistoryRow{HealthMetrics: m, Outliers: flags[m.Date]}
+	}
+	return rows
+}
+
+func fitnessHistoryRows(metrics []models.FitnessMetrics, flags models.OutlierFlags) []FitnessHistoryRow {
+	rows := make([]FitnessHistoryRow, len(metrics))
+	for i, m := range metrics {
+		rows[i] = FitnessHistoryRow{FitnessMetrics: m, Outliers: flags[m.Date]}
+	}
+	return rows
+}
+
+func cognitionHistoryRows(metrics []models.CognitionMetrics, flags models.OutlierFlags) []CognitionHistoryRow {
+	rows := make([]CognitionHistoryRow, len(metrics))
+	for i, m := range metrics {
+		rows[i] = CognitionHistoryRow{CognitionMetrics: m, Outliers: flags[m.Date]}
+	}
+	return rows
+}
+
+// Empty reports whether the page has no rows
+func (d HistoryData) Empty() bool {
+	return len(d.Health)+len(d.Fitness)+len(d.Cognition)+len(d.Scores) == 0
+}
+
+// SortURL links to the history sorted by key, flipping the direction when key is already the sort column
+func (d HistoryData) SortURL(key string) string {
+	ascending := false
+	if key == d.Sort {
+		ascending = !d.Ascending
+	}
+	return "/history?" + d.values(key, ascending, nil).Encode()
+}
+
+// SortIndicator returns the arrow shown next to the sorted column
+func (d HistoryData) SortIndicator(key string) string {
+	switch {
+	case key != d.Sort:
+		return ""
+	case d.Ascending:
+		return " ▲"
+	default:
+		return " ▼"
+	}
+}
+
+func (d HistoryData) values(sortKey string, ascending bool, after *models.HistoryCursor) url.Values {
+	values := url.Values{"view": {d.View}, "sort": {sortKey}}
+	if d.From != "" {
+		values.Set("from", d.From)
+	}
+	if d.To != "" {
+		values.Set("to", d.To)
+	}
+	if ascending {
+		values.Set("order", "asc")
+	}
+	if after != nil {
+		values.Set("after", after.Date)
+		if after.Value != nil {
+			values.Set("after_value", strconv.FormatFloat(*after.Value, 'g', -1, 64))
+		}
+	}
+	return values
+}
+
+// HandleHistory renders the full history page for scores or one pillar
+func (h *Handler) HandleHistory(w http.ResponseWriter, r *http.Request) {
+	data, ok := h.historyPage(w, r)
+	if !ok {
+		return
+	}
+	h.render(w, "history.html", data)
+}
+
+// HandleHistoryRows renders the next page of history rows for the "load more" button
+func (h *Handler) HandleHistoryRows(w http.ResponseWriter, r *http.Request) {
+	data, ok := h.historyPage(w, r)
+	if !ok {
+		return
+	}
+	h.render(w, "history_rows", data)
+}
+
+// historyPage loads the page described by the query string, writing an error response when it is invalid
+func (h *Handler) historyPage(w http.ResponseWriter, r *http.Request) (HistoryData, bool) {
+	data, q, err := parseHistoryQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return data, false
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	q.Limit = models.DefaultHistoryLimit + 1
+
+	var (
+		rows int
+		last func() models.HistoryCursor
+	)
+	switch data.View {
+	case models.PillarHealth:
+		var metrics []models.HealthMetrics
+		metrics, err = h.db.GetHealthMetricsPage(q)
+		rows = len(metrics)
+		if rows > models.DefaultHistoryLimit {
+			metrics = metrics[:models.DefaultHistoryLimit]
+		}
+		data.Health = healthHistoryRows(metrics, h.outlierFlags(models.PillarHealth))
+		last = func() models.HistoryCursor {
+			m := data.Health[len(data.Health)-1]
+			return models.HistoryCursor{Date: m.Date, Value: m.Value(q.Sort)}
+		}
+	case models.PillarFitness:
+		var metrics []models.FitnessMetrics
+		metrics, err = h.db.GetFitnessMetricsPage(q)
+		rows = len(metrics)
+		if rows > models.DefaultHistoryLimit {
+			metrics = metrics[:models.DefaultHistoryLimit]
+		}
+		data.Fitness = fitnessHistoryRows(metrics, h.outlierFlags(models.PillarFitness))
+		last = func() models.HistoryCursor {
+			m := data.Fitness[len(data.Fitness)-1]
+			return models.HistoryCursor{Date: m.Date, Value: m.Value(q.Sort)}
+		}
+	case models.PillarCognition:
+		var metrics []models.CognitionMetrics
+		metrics, err = h.db.GetCognitionMetricsPage(q)
+		rows = len(metrics)
+		if rows > models.DefaultHistoryLimit {
+			metrics = metrics[:models.DefaultHistoryLimit]
+		}
+		data.Cognition = cognitionHistoryRows(metrics, h.outlierFlags(models.PillarCognition))
+		last = func() models.HistoryCursor {
+			m := data.Cognition[len(data.Cognition)-1]
+			return models.HistoryCursor{Date: m.Date, Value: m.Value(q.Sort)}
+		}
+	default:
+		data.Scores, err = services.GetScoresPage(h.db, q)
+		rows = len(data.Scores)
+		if rows > models.DefaultHistoryLimit {
+			data.Scores = data.Scores[:models.DefaultHistoryLimit]
+		}
+		last = func() models.HistoryCursor {
+			s := data.Scores[len(data.Scores)-1]
+			cursor := models.HistoryCursor{Date: s.Date}
+			if q.Sort != models.HistorySortDate {
+				cursor.Value = models.Float(s.ScoreValue(q.Sort))
+			}
+			return cursor
+		}
+	}
+	if err != nil {
+		log.Printf("Error loading %s history: %v", data.View, err)
+		http.Error(w, "Failed to load history", http.StatusInternalServerError)
+		return data, false
+	}
+
+	if rows > models.DefaultHistoryLimit {
+		cursor := last()
+		data.NextURL = "/history-rows?" + data.values(data.Sort, data.Ascending, &cursor).Encode()
+	}
+	return data, true
+}
+
+// outlierFlags returns the anomalous stored values of a pillar, or none when they cannot be determined
+func (h *Handler) outlierFlags(pillar string) models.OutlierFlags {
+	flags, err := services.GetOutlierFlags(h.db, pillar)
+	if err != nil {
+		log.Printf("Error flagging %s outliers: %v", pillar, err)
+	}
+	return flags
+}
+
+// parseHistoryQuery reads the view, from, to, sort, order, after and after_value parameters
+func parseHistoryQuery(query url.Values) (HistoryData, models.HistoryQuery, error) {
+	data := HistoryData{
+		View:      query.Get("view"),
+		Views:     models.HistoryViews,
+		From:      query.Get("from"),
+		To:        query.Get("to"),
+		Sort:      query.Get("sort"),
+		Ascending: query.Get("order") == "asc",
+	}
+	if data.View == "" {
+		data.View = models.HistoryViewScores
+	}
+	if data.Sort == "" {
+		data.Sort = models.HistorySortDate
+	}
+
+	if !slices.Contains(models.HistoryViews, data.View) {
+		return data, models.HistoryQuery{}, fmt.Errorf("unknown history view %q", data.View)
+	}
+	if !models.HistorySortable(data.View, data.Sort) {
+		return data, models.HistoryQuery{}, fmt.Errorf("cannot sort %s history by %q", data.View, data.Sort)
+	}
+	for _, date := range []string{data.From, data.To, query.Get("after")} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return data, models.HistoryQuery{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+		}
+	}
+
+	q := models.HistoryQuery{From: data.From, To: data.To, Sort: data.Sort, Ascending: data.Ascending}
+	if after := query.Get("after"); after != "" {
+		q.After = &models.HistoryCursor{Date: after}
+		if raw := query.Get("after_value"); raw != "" {
+			value, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return data, models.HistoryQuery{}, fmt.Errorf("invalid after_value %q", raw)
+			}
+			q.After.Value = &value
+		}
+	}
+	return data, q, nil
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"health-balance/internal/models"
+)
+
+func TestHandleHistoryPaginates(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+
+	var received models.HistoryQuery
+	mockDB.GetHealthMetricsPageFunc = func(q models.HistoryQuery) ([]models.HealthMetrics, error) {
+		received = q
+		metrics := make([]models.HealthMetrics, q.Limit)
+		for i := range metrics {
+			metrics[i] = models.HealthMetrics{Date: fmt.Sprintf("2025-%02d-01", 12-i%12), RHR: models.Int(50 + i)}
+		}
+		return metrics, nil
+	}
+
+	req := httptest.NewRequest("GET", "/history?view=health&sort=rhr&order=asc&from=2025-01-01", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleHistory(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+	if received.Sort != "rhr" || !received.Ascending || received.From != "2025-01-01" || received.Limit != models.DefaultHistoryLimit+1 {
+		t.Errorf("Unexpected history query %+v", received)
+	}
+
+	// The cursor points at the last shown row, not the extra one used to detect more pages
+	body := rr.Body.String()
+	afterDate := fmt.Sprintf("after=2025-%02d-01", 12-(models.DefaultHistoryLimit-1)%12)
+	afterValue := fmt.Sprintf("after_value=%d", 50+models.DefaultHistoryLimit-1)
+	if !strings.Contains(body, fmt.Sprintf("health %d rows", models.DefaultHistoryLimit)) || !strings.Contains(body, afterDate) || !strings.Contains(body, afterValue) {
+		t.Errorf("Expected a full page with a cursor at the last row (%s, %s), got %q", afterDate, afterValue, body)
+	}
+
+	req = httptest.NewRequest("GET", "/history-rows?view=health&sort=rhr&after=2025-06-01&after_value=55", nil)
+	rr = httptest.NewRecorder()
+	handler.HandleHistoryRows(rr, req)
+
+	if received.After == nil || received.After.Date != "2025-06-01" || received.After.Value == nil || *received.After.Value != 55 {
+		t.Errorf("Expected the cursor to be passed through, got %+v", received.After)
+	}
+}
+
+func TestHandleHistoryLastPage(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+
+	mockDB.GetCognitionMetricsPageFunc = func(q models.HistoryQuery) ([]models.CognitionMetrics, error) {
+		return []models.CognitionMetrics{{Date: "2025-01-05"}}, nil
+	}
+
+	req := httptest.NewRequest("GET", "/history-rows?view=cognition", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleHistoryRows(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+	if !strings.HasSuffix(rr.Body.String(), "next=") {
+		t.Errorf("Expected no next page, got %q", rr.Body.String())
+	}
+}
+
+func TestHandleHistoryRejectsInvalidRequests(t *testing.T) {
+	handler, _ := setupTestHandler()
+
+	for _, query := range []string{"view=sleep", "view=health&sort=vo2_max", "view=scores&sort=rhr", "from=last-week", "view=fitness&after=2025-01-05&after_value=abc"} {
+		req := httptest.NewRequest("GET", "/history?"+query, nil)
+		rr := httptest.NewRecorder()
+		handler.HandleHistory(rr, req)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("Expected status code %d for %q, got %d", http.StatusBadRequest, query, status)
+		}
+	}
+}
+
+func TestHistorySortURL(t *testing.T) {
+	data := HistoryData{View: "fitness", Sort: "vo2_max", From: "2025-01-01"}
+
+	if got := data.SortURL("vo2_max"); got != "/history?from=2025-01-01&order=asc&sort=vo2_max&view=fitness" {
+		t.Errorf("Expected the sorted column to flip direction, got %s", got)
+	}
+	if got := data.SortURL("date"); got != "/history?from=2025-01-01&sort=date&view=fitness" {
+		t.Errorf("Expected a new column to sort descending, got %s", got)
+	}
+	if data.SortIndicator("vo2_max") != " ▼" || data.SortIndicator("date") != "" {
+		t.Error("Expected only the sorted column to show an indicator")
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"health-balance/internal/models"
+	"health-balance/internal/services"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InterventionsData is the before/after comparison of every intervention together with
+// the metrics that can be compared for a new one
+type InterventionsData struct {
+	Results []models.InterventionResult
+	Metrics []models.MetricDefinition
+	Today   string
+}
+
+// HandleInterventions renders the interventions with their before/after comparisons
+func (h *Handler) HandleInterventions(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	results, err := services.GetInterventionResults(h.db, now)
+	if err != nil {
+		log.Printf("Intervention error: %v", err)
+		http.Error(w, "Failed to load interventions", http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, "interventions.html", InterventionsData{
+		Results: results,
+		Metrics: models.MetricCatalog,
+		Today:   now.Format("2006-01-02"),
+	})
+}
+
+// HandleCreateIntervention validates and stores an intervention from the intervention form
+func (h *Handler) HandleCreateIntervention(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		log.Printf("Error parsing form: %v", err)
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	intervention := models.Intervention{
+		Name:      strings.TrimSpace(r.FormValue("name")),
+		StartDate: r.FormValue("start_date"),
+		EndDate:   r.FormValue("end_date"),
+		Metrics:   r.Form["metrics"],
+	}
+	if errs := intervention.Validate(time.Now()); len(errs) > 0 {
+		writeFieldErrors(w, "intervention-form", errs)
+		return
+	}
+
+	if _, err := h.db.CreateIntervention(intervention); err != nil {
+		log.Printf("Error saving intervention: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Trigger", `{"refreshInterventions":true, "showToast":"Intervention started"}`)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleEndIntervention ends the ongoing intervention with the {id} path value today
+func (h *Handler) HandleEndIntervention(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid intervention id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.db.EndIntervention(id, time.Now().Format("2006-01-02")); err != nil {
+		log.Printf("Error ending intervention: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Trigger", `{"refreshInterventions":true, "showToast":"Intervention ended"}`)
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleDeleteIntervention deletes the intervention with the {id} path value
+func (h *Handler) HandleDeleteIntervention(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid intervention id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.db.DeleteIntervention(id); err != nil {
+		log.Printf("Error deleting intervention: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Trigger", `{"refreshInterventions":true, "showToast":"Intervention deleted"}`)
+	w.WriteHeader(http.StatusOK)
+}
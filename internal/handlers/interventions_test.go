@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"health-balance/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandleInterventions(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+	mockDB.GetInterventionsFunc = func() ([]models.Intervention, error) {
+		return []models.Intervention{{ID: 1, Name: "No alcohol", StartDate: "2026-01-04", Metrics: []string{"rhr"}}}, nil
+	}
+
+	rr := httptest.NewRecorder()
+	handler.HandleInterventions(rr, httptest.NewRequest("GET", "/interventions", nil))
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+	if !strings.Contains(rr.Body.String(), "1 interventions No alcohol: 4 scores, 1 metrics") {
+		t.Errorf("Expected the intervention with its comparisons, got %q", rr.Body.String())
+	}
+}
+
+func TestHandleCreateIntervention(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+
+	var saved *models.Intervention
+	mockDB.CreateInterventionFunc = func(i models.Intervention) (int, error) {
+		saved = &i
+		return 1, nil
+	}
+
+	post := func(form string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/interventions", strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		handler.HandleCreateIntervention(rr, req)
+		return rr
+	}
+
+	rr := post("name=&start_date=2026-03-01&end_date=2026-02-01")
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d without a name, got %d", http.StatusBadRequest, status)
+	}
+	trigger := rr.Header().Get("HX-Trigger")
+	if !strings.Contains(trigger, `"intervention-form"`) || !strings.Contains(trigger, `"end_date"`) {
+		t.Errorf("Expected field errors for the intervention form, got %q", trigger)
+	}
+	if saved != nil {
+		t.Fatal("Expected an invalid intervention not to be saved")
+	}
+
+	rr = post("name=+No+alcohol+&start_date=2026-01-04&metrics=rhr&metrics=sleep_score")
+	if status := rr.Code; status != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusNoContent, status, rr.Body.String())
+	}
+	if saved == nil || saved.Name != "No alcohol" || saved.StartDate != "2026-01-04" || !saved.Ongoing() || len(saved.Metrics) != 2 {
+		t.Errorf("Unexpected saved intervention: %+v", saved)
+	}
+}
+
+func TestHandleEndAndDeleteIntervention(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+
+	var ended, deleted int
+	var endDate string
+	mockDB.EndInterventionFunc = func(id int, date string) error {
+		ended, endDate = id, date
+		return nil
+	}
+	mockDB.DeleteInterventionFunc = func(id int) error {
+		deleted = id
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /interventions/{id}/end", handler.HandleEndIntervention)
+	mux.HandleFunc("DELETE /interventions/{id}", handler.HandleDeleteIntervention)
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("POST", "/interventions/2/end", nil))
+	if rr.Code != http.StatusOK || ended != 2 || endDate != time.Now().Format("2006-01-02") {
+		t.Errorf("Expected intervention 2 to end today, got status %d, id %d and date %q", rr.Code, ended, endDate)
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("DELETE", "/interventions/3", nil))
+	if rr.Code != http.StatusOK || deleted != 3 {
+		t.Errorf("Expected intervention 3 to be deleted, got status %d and id %d", rr.Code, deleted)
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("DELETE", "/interventions/abc", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for an invalid id, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"health-balance/internal/models"
+	"health-balance/internal/services"
+	"html/template"
+	"log"
+	"net/http"
+)
+
+// ReportData is the health report with its AI summary rendered for the page
+type ReportData struct {
+	*models.HealthReport
+	SummaryHTML template.HTML
+}
+
+// HandleReport renders the printable health report for the from/to period
+func (h *Handler) HandleReport(w http.ResponseWriter, r *http.Request) {
+	report, ok := h.healthReport(w, r)
+	if !ok {
+		return
+	}
+
+	data := ReportData{HealthReport: report}
+	if report.Summary != "" {
+		summaryHTML, err := renderMarkdown(report.Summary)
+		if err != nil {
+			log.Printf("Markdown conversion error: %v", err)
+		}
+		data.SummaryHTML = summaryHTML
+	}
+	h.render(w, "report.html", data)
+}
+
+// HandleReportPDF serves the health report for the from/to period as a PDF download
+func (h *Handler) HandleReportPDF(w http.ResponseWriter, r *http.Request) {
+	report, ok := h.healthReport(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="health-report-%s-to-%s.pdf"`, report.From, report.To))
+	if _, err := w.Write(services.RenderReportPDF(report)); err != nil {
+		log.Printf("Error writing report PDF: %v", err)
+	}
+}
+
+// healthReport builds the report for the requested period, defaulting to the last three months,
+// writing an error response when it cannot be built
+func (h *Handler) healthReport(w http.ResponseWriter, r *http.Request) (*models.HealthReport, bool) {
+	from, to := services.DefaultReportPeriod()
+	if v := r.URL.Query().Get("from"); v != "" {
+		from = v
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		to = v
+	}
+
+	report, err := services.BuildReport(h.db, from, to)
+	if errors.Is(err, services.ErrInvalidReportPeriod) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Report error: %v", err)
+		http.Error(w, "Failed to build report. Please ensure your profile is complete.", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	summary, err := h.db.GetLatestAISummary()
+	if err != nil {
+		log.Printf("Error loading latest AI summary for report: %v", err)
+	} else if summary != nil {
+		report.Summary = summary.Markdown()
+		report.SummaryGeneratedAt = summary.CreatedAt
+	}
+
+	return report, true
+}
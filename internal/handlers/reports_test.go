@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"health-balance/internal/models"
+)
+
+func TestHandleReport(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+	mockDB.GetUserProfileFunc = func() (*models.UserProfile, error) {
+		return &models.UserProfile{BirthDate: "1980-01-01", Sex: "female", HeightCm: 168}, nil
+	}
+	mockDB.GetLatestAISummaryFunc = func() (*models.AISummary, error) {
+		return &models.AISummary{Text: "**Keep** going", CreatedAt: time.Now()}, nil
+	}
+
+	req := httptest.NewRequest("GET", "/report?from=2025-01-05&to=2025-03-30", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleReport(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+	body := rr.Body.String()
+	if !strings.Contains(body, "2025-01-05 to 2025-03-30") || !strings.Contains(body, "<strong>Keep</strong>") {
+		t.Errorf("Expected the requested period and the rendered summary, got %q", body)
+	}
+}
+
+func TestHandleReportPDF(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+	mockDB.GetUserProfileFunc = func() (*models.UserProfile, error) {
+		return &models.UserProfile{BirthDate: "1980-01-01", Sex: "female", HeightCm: 168}, nil
+	}
+
+	req := httptest.NewRequest("GET", "/report.pdf?from=2025-01-05&to=2025-03-30", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleReportPDF(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/pdf" {
+		t.Errorf("Expected a PDF content type, got %s", ct)
+	}
+	if cd := rr.Header().Get("Content-Disposition"); !strings.Contains(cd, "health-report-2025-01-05-to-2025-03-30.pdf") {
+		t.Errorf("Expected a dated attachment filename, got %s", cd)
+	}
+	if !bytes.HasPrefix(rr.Body.Bytes(), []byte("%PDF-")) {
+		t.Error("Expected a PDF body")
+	}
+}
+
+func TestHandleReportRejectsInvalidPeriod(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+	mockDB.GetUserProfileFunc = func() (*models.UserProfile, error) {
+		return &models.UserProfile{BirthDate: "1980-01-01", Sex: "female", HeightCm: 168}, nil
+	}
+
+	req := httptest.NewRequest("GET", "/report?from=2025-04-01&to=2025-01-01", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleReport(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, status)
+	}
+}
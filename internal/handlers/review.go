@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"fmt"
+	"health-balance/internal/services"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HandleReviewIndex redirects to the review of the current year
+func (h *Handler) HandleReviewIndex(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, fmt.Sprintf("/review/%d", time.Now().Year()), http.StatusFound)
+}
+
+// HandleYearReview renders the year-in-review page for the {year} path value
+func (h *Handler) HandleYearReview(w http.ResponseWriter, r *http.Request) {
+	year, err := strconv.Atoi(r.PathValue("year"))
+	if err != nil || year < 1900 || year > 9999 {
+		http.Error(w, "Year must be a four-digit year", http.StatusBadRequest)
+		return
+	}
+
+	review, err := services.GetYearReview(h.db, year)
+	if err != nil {
+		log.Printf("Year review error: %v", err)
+		http.Error(w, "Failed to build year review", http.StatusInternalServerError)
+		return
+	}
+	h.render(w, "review.html", review)
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandleYearReview(t *testing.T) {
+	handler, _ := setupTestHandler()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /review", handler.HandleReviewIndex)
+	mux.HandleFunc("GET /review/{year}", handler.HandleYearReview)
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("GET", "/review/2025", nil))
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+	if !strings.Contains(rr.Body.String(), "2025 review: 0 weeks") {
+		t.Errorf("Expected the 2025 review, got %q", rr.Body.String())
+	}
+
+	for _, path := range []string{"/review/last-year", "/review/25"} {
+		rr = httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("Expected status code %d for %s, got %d", http.StatusBadRequest, path, status)
+		}
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("GET", "/review", nil))
+	if location := rr.Header().Get("Location"); rr.Code != http.StatusFound || location != fmt.Sprintf("/review/%d", time.Now().Year()) {
+		t.Errorf("Expected a redirect to the current year, got %d to %q", rr.Code, location)
+	}
+}
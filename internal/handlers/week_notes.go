@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"health-balance/internal/models"
+	"health-balance/internal/utils"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// WeekNoteData is the current week's note as edited from one pillar's week state panel
+type WeekNoteData struct {
+	Pillar        string
+	Note          models.WeekNote
+	SuggestedTags []string
+}
+
+// FormID returns the id of the pillar's note form, unique on the dashboard
+func (d WeekNoteData) FormID() string {
+	return d.Pillar + "-week-note-form"
+}
+
+// OtherTags returns the note's tags that are not suggested, comma-separated for the free-text input
+func (d WeekNoteData) OtherTags() string {
+	var other []string
+	for _, tag := range d.Note.Tags {
+		if !slices.Contains(d.SuggestedTags, tag) {
+			other = append(other, tag)
+		}
+	}
+	return strings.Join(other, ", ")
+}
+
+// HandleWeekNote renders the current week's note form for the week state panel of the pillar query parameter
+func (h *Handler) HandleWeekNote(w http.ResponseWriter, r *http.Request) {
+	pillar, ok := weekNotePillar(w, r.URL.Query().Get("pillar"))
+	if !ok {
+		return
+	}
+
+	date := utils.GetCurrentWeekSundayDate()
+	note, err := h.db.GetWeekNote(date)
+	if err != nil {
+		log.Printf("Error loading week note: %v", err)
+		http.Error(w, "Failed to load week note", http.StatusInternalServerError)
+		return
+	}
+	if note == nil {
+		note = &models.WeekNote{Date: date}
+	}
+
+	h.render(w, "week_note", WeekNoteData{Pillar: pillar, Note: *note, SuggestedTags: models.SuggestedWeekTags})
+}
+
+// HandleSaveWeekNote stores the current week's note and tags, deleting the note when both are cleared
+func (h *Handler) HandleSaveWeekNote(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		log.Printf("Error parsing form: %v", err)
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+	pillar, ok := weekNotePillar(w, r.FormValue("pillar"))
+	if !ok {
+		return
+	}
+
+	note := models.WeekNote{
+		Date: utils.GetCurrentWeekSundayDate(),
+		Text: strings.TrimSpace(r.FormValue("note")),
+		Tags: models.ParseWeekTags(append(r.Form["tags"], r.FormValue("other_tags"))...),
+	}
+	if errs := note.Validate(); len(errs) > 0 {
+		// Tag errors are shown next to the free-text input rather than the first checkbox
+		if message, ok := errs["tags"]; ok {
+			delete(errs, "tags")
+			errs.Add("other_tags", message)
+		}
+		writeFieldErrors(w, WeekNoteData{Pillar: pillar}.FormID(), errs)
+		return
+	}
+
+	var err error
+	if note.Empty() {
+		err = h.db.DeleteWeekNote(note.Date)
+	} else {
+		err = h.db.SaveWeekNote(note)
+	}
+	if err != nil {
+		log.Printf("Error saving week note: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Trigger", `{"refreshWeekNote":true, "showToast":"Week note saved"}`)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// weekNotePillar checks the pillar whose panel a note form belongs to, writing an error response when it is unknown
+func weekNotePillar(w http.ResponseWriter, pillar string) (string, bool) {
+	switch pillar {
+	case models.PillarHealth, models.PillarFitness, models.PillarCognition:
+		return pillar, true
+	default:
+		http.Error(w, "Unknown pillar", http.StatusBadRequest)
+		return "", false
+	}
+}
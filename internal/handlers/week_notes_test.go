@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"health-balance/internal/models"
+	"health-balance/internal/utils"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleWeekNote(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+	mockDB.GetWeekNoteFunc = func(date string) (*models.WeekNote, error) {
+		return &models.WeekNote{Date: date, Text: "Conference week", Tags: []string{"travel", "conference"}}, nil
+	}
+
+	rr := httptest.NewRecorder()
+	handler.HandleWeekNote(rr, httptest.NewRequest("GET", "/week-note?pillar=fitness", nil))
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+	if body := rr.Body.String(); body != "fitness-week-note-form: Conference week [conference]" {
+		t.Errorf("Expected the fitness panel's form with only the unsuggested tag as free text, got %q", body)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.HandleWeekNote(rr, httptest.NewRequest("GET", "/week-note?pillar=sleep", nil))
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for an unknown pillar, got %d", http.StatusBadRequest, status)
+	}
+}
+
+func TestHandleSaveWeekNote(t *testing.T) {
+	handler, mockDB := setupTestHandler()
+
+	var (
+		saved   *models.WeekNote
+		deleted string
+	)
+	mockDB.SaveWeekNoteFunc = func(n models.WeekNote) error {
+		saved = &n
+		return nil
+	}
+	mockDB.DeleteWeekNoteFunc = func(date string) error {
+		deleted = date
+		return nil
+	}
+
+	post := func(form string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/week-note", strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		handler.HandleSaveWeekNote(rr, req)
+		return rr
+	}
+
+	rr := post("pillar=health&note=+Flu+&tags=sick&tags=travel&other_tags=Sick,+Conference")
+	if status := rr.Code; status != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusNoContent, status, rr.Body.String())
+	}
+	week := utils.GetCurrentWeekSundayDate()
+	if saved == nil || saved.Date != week || saved.Text != "Flu" || strings.Join(saved.Tags, "|") != "sick|travel|conference" {
+		t.Errorf("Unexpected saved note: %+v", saved)
+	}
+	if !strings.Contains(rr.Header().Get("HX-Trigger"), "refreshWeekNote") {
+		t.Errorf("Expected the note panels to refresh, got %q", rr.Header().Get("HX-Trigger"))
+	}
+
+	rr = post("pillar=cognition&other_tags=" + strings.Repeat("x", models.MaxWeekTagLength+1))
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for an oversized tag, got %d", http.StatusBadRequest, status)
+	}
+	if trigger := rr.Header().Get("HX-Trigger"); !strings.Contains(trigger, `"cognition-week-note-form"`) || !strings.Contains(trigger, `"other_tags"`) {
+		t.Errorf("Expected the tag error on the cognition form's free-text input, got %q", trigger)
+	}
+
+	rr = post("pillar=health&note=+")
+	if rr.Code != http.StatusNoContent || deleted != week {
+		t.Errorf("Expected clearing the note to delete it, got status %d and deleted %q", rr.Code, deleted)
+	}
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Period is an inclusive range of YYYY-MM-DD dates
+type Period struct {
+	From string
+	To   string
+}
+
+// Contains reports whether the YYYY-MM-DD date falls within the period
+func (p Period) Contains(date string) bool {
+	return date >= p.From && date <= p.To
+}
+
+// String returns the period for display, e.g. "2026-01-01 to 2026-03-31"
+func (p Period) String() string {
+	return p.From + " to " + p.To
+}
+
+// validate checks the dates of the period, reporting errors under prefix+"from" and prefix+"to"
+func (p Period) validate(prefix, name string) FieldErrors {
+	errs := FieldErrors{}
+	from, err := time.Parse("2006-01-02", p.From)
+	if err != nil {
+		errs.Add(prefix+"from", fmt.Sprintf("%s start must be a date like 2026-01-01", name))
+	}
+	to, err := time.Parse("2006-01-02", p.To)
+	if err != nil {
+		errs.Add(prefix+"to", fmt.Sprintf("%s end must be a date like 2026-03-31", name))
+	}
+	if len(errs) == 0 && to.Before(from) {
+		errs.Add(prefix+"to", fmt.Sprintf("%s end cannot be before its start", name))
+	}
+	return errs
+}
+
+// ValidateComparison checks the periods of a comparison from the comparison form, whose
+// baseline fields are prefixed with "baseline_". The periods may not overlap, so every
+// week counts towards one side only.
+func ValidateComparison(baseline, current Period) FieldErrors {
+	errs := baseline.validate("baseline_", "Baseline")
+	errs.Merge(current.validate("", "Period"))
+	if len(errs) == 0 && baseline.From <= current.To && current.From <= baseline.To {
+		errs.Add("from", "The periods cannot overlap")
+	}
+	return errs
+}
+
+// ComparisonPreset is a common pair of periods offered on the comparison form
+type ComparisonPreset struct {
+	Label    string
+	Baseline Period
+	Current  Period
+}
+
+// AIComparison is a generated analysis of one period against a baseline period, stored
+// like AISummary so it can be served again while the data behind its prompt is unchanged
+type AIComparison struct {
+	ID         int
+	Baseline   Period
+	Current    Period
+	Provider   string
+	Model      string
+	PromptHash string
+	Text       string
+	CreatedAt  time.Time
+	// Cached is set when the comparison was served from the database instead of generated
+	Cached bool
+}
+
+// Insights parses the structured analysis of the comparison, which uses the summary format
+func (c AIComparison) Insights() (*AIInsights, error) {
+	return ParseAIInsights(c.Text)
+}
@@ -0,0 +1,32 @@
+package models
+
+import "testing"
+
+func TestValidateComparison(t *testing.T) {
+	baseline := Period{From: "2025-04-01", To: "2025-06-30"}
+	current := Period{From: "2026-04-01", To: "2026-06-30"}
+	if errs := ValidateComparison(baseline, current); len(errs) > 0 {
+		t.Errorf("Expected valid periods, got %v", errs)
+	}
+	if errs := ValidateComparison(current, baseline); len(errs) > 0 {
+		t.Errorf("Expected a later baseline to be allowed, got %v", errs)
+	}
+
+	tests := []struct {
+		name     string
+		baseline Period
+		current  Period
+		field    string
+	}{
+		{"missing baseline start", Period{To: "2025-06-30"}, current, "baseline_from"},
+		{"reversed baseline", Period{From: "2025-06-30", To: "2025-04-01"}, current, "baseline_to"},
+		{"invalid end", baseline, Period{From: "2026-04-01", To: "June"}, "to"},
+		{"overlap", Period{From: "2026-01-01", To: "2026-04-01"}, current, "from"},
+	}
+	for _, tt := range tests {
+		errs := ValidateComparison(tt.baseline, tt.current)
+		if errs[tt.field] == "" {
+			t.Errorf("%s: expected an error for %s, got %v", tt.name, tt.field, errs)
+		}
+	}
+}
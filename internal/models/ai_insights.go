@@ -0,0 +1,209 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	PriorityHigh   = "high"
+	PriorityMedium = "medium"
+	PriorityLow    = "low"
+)
+
+// Priorities lists the recommendation priorities, most urgent first
+var Priorities = []string{PriorityHigh, PriorityMedium, PriorityLow}
+
+// Pillars lists the score pillars in the order they are shown
+var Pillars = []string{PillarHealth, PillarFitness, PillarCognition}
+
+// AIInsights is the structured analysis the summary prompt asks the model for
+type AIInsights struct {
+	Summary         string             `json:"summary"`
+	Bottlenecks     []AIBottleneck     `json:"bottlenecks"`
+	Recommendations []AIRecommendation `json:"recommendations"`
+}
+
+// AIBottleneck is a finding that holds back the score
+type AIBottleneck struct {
+	Pillar string `json:"pillar"`
+	// Metric is the key of the metric behind the finding, empty when there is no single one
+	Metric  string `json:"metric"`
+	Finding string `json:"finding"`
+}
+
+// AIRecommendation is an action to take, optionally measured by a target for one metric
+type AIRecommendation struct {
+	Pillar string `json:"pillar"`
+	Title  string `json:"title"`
+	Action string `json:"action"`
+	// TargetMetric is the key of the metric the recommendation should move, empty for none
+	TargetMetric string `json:"target_metric"`
+	// Comparison is GoalAtLeast or GoalAtMost, defaulting to the metric's better direction
+	Comparison     string   `json:"comparison"`
+	TargetValue    *float64 `json:"target_value"`
+	ExpectedImpact string   `json:"expected_impact"`
+	Priority       string   `json:"priority"`
+}
+
+// AIPillarInsights are the bottlenecks and recommendations of one pillar
+type AIPillarInsights struct {
+	Pillar          string
+	Bottlenecks     []AIBottleneck
+	Recommendations []AIRecommendation
+}
+
+// ParseAIInsights reads and validates the model's JSON response. Code fences and text
+// around the object are ignored since some models add them despite the instructions.
+func ParseAIInsights(text string) (*AIInsights, error) {
+	start, end := strings.Index(text, "{"), strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("response is not a JSON object")
+	}
+
+	var insights AIInsights
+	if err := json.Unmarshal([]byte(text[start:end+1]), &insights); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if errs := insights.Validate(); len(errs) > 0 {
+		return nil, errs
+	}
+	return &insights, nil
+}
+
+// Validate checks the insights against the format described in the summary prompt
+func (i AIInsights) Validate() FieldErrors {
+	errs := FieldErrors{}
+	if strings.TrimSpace(i.Summary) == "" {
+		errs.Add("summary", "summary is required")
+	}
+	if len(i.Recommendations) == 0 {
+		errs.Add("recommendations", "at least one recommendation is required")
+	}
+
+	for n, b := range i.Bottlenecks {
+		field := fmt.Sprintf("bottlenecks[%d]", n)
+		errs.Add(field+".pillar", checkPillar(field, b.Pillar))
+		if b.Metric != "" {
+			if _, ok := LookupMetric(b.Metric); !ok {
+				errs.Add(field+".metric", fmt.Sprintf("%s.metric %q is not a metric key", field, b.Metric))
+			}
+		}
+		if strings.TrimSpace(b.Finding) == "" {
+			errs.Add(field+".finding", field+".finding is required")
+		}
+	}
+
+	for n, r := range i.Recommendations {
+		field := fmt.Sprintf("recommendations[%d]", n)
+		errs.Add(field+".pillar", checkPillar(field, r.Pillar))
+		if strings.TrimSpace(r.Title) == "" {
+			errs.Add(field+".title", field+".title is required")
+		}
+		if strings.TrimSpace(r.Action) == "" {
+			errs.Add(field+".action", field+".action is required")
+		}
+		if !slices.Contains(Priorities, r.Priority) {
+			errs.Add(field+".priority", fmt.Sprintf("%s.priority must be one of %s", field, strings.Join(Priorities, ", ")))
+		}
+		if r.Comparison != "" && r.Comparison != GoalAtLeast && r.Comparison != GoalAtMost {
+			errs.Add(field+".comparison", fmt.Sprintf("%s.comparison must be %s or %s", field, GoalAtLeast, GoalAtMost))
+		}
+		if r.TargetMetric == "" {
+			continue
+		}
+		if _, ok := LookupMetric(r.TargetMetric); !ok {
+			errs.Add(field+".target_metric", fmt.Sprintf("%s.target_metric %q is not a metric key", field, r.TargetMetric))
+		} else if rule, ok := LookupFieldRule(r.TargetMetric); ok && r.TargetValue != nil {
+			if msg := rule.Check(r.TargetValue); msg != "" {
+				errs.Add(field+".target_value", field+".target_value: "+msg)
+			}
+		}
+	}
+	return errs
+}
+
+func checkPillar(field, pillar string) string {
+	if slices.Contains(Pillars, pillar) {
+		return ""
+	}
+	return fmt.Sprintf("%s.pillar must be one of %s", field, strings.Join(Pillars, ", "))
+}
+
+// ByPillar groups the bottlenecks and recommendations by pillar, leaving out pillars
+// without any and ordering recommendations by priority
+func (i AIInsights) ByPillar() []AIPillarInsights {
+	var groups []AIPillarInsights
+	for _, pillar := range Pillars {
+		group := AIPillarInsights{Pillar: pillar}
+		for _, b := range i.Bottlenecks {
+			if b.Pillar == pillar {
+				group.Bottlenecks = append(group.Bottlenecks, b)
+			}
+		}
+		for _, r := range i.Recommendations {
+			if r.Pillar == pillar {
+				group.Recommendations = append(group.Recommendations, r)
+			}
+		}
+		slices.SortStableFunc(group.Recommendations, func(a, b AIRecommendation) int {
+			return slices.Index(Priorities, a.Priority) - slices.Index(Priorities, b.Priority)
+		})
+		if len(group.Bottlenecks) > 0 || len(group.Recommendations) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// Label returns the display name of the group's pillar, e.g. "Fitness"
+func (g AIPillarInsights) Label() string {
+	return strings.ToUpper(g.Pillar[:1]) + g.Pillar[1:]
+}
+
+// Markdown renders the insights as markdown, for the report and anywhere cards don't fit
+func (i AIInsights) Markdown() string {
+	var md strings.Builder
+	md.WriteString(i.Summary + "\n")
+	for _, group := range i.ByPillar() {
+		fmt.Fprintf(&md, "\n### %s\n", group.Label())
+		for _, b := range group.Bottlenecks {
+			fmt.Fprintf(&md, "- **Bottleneck**: %s\n", b.Finding)
+		}
+		for _, r := range group.Recommendations {
+			fmt.Fprintf(&md, "- **%s** (%s priority): %s", r.Title, r.Priority, r.Action)
+			if goal := r.Goal(); goal != nil {
+				fmt.Fprintf(&md, " Target: %s.", goal.Description())
+			}
+			if r.ExpectedImpact != "" {
+				fmt.Fprintf(&md, " Expected impact: %s", r.ExpectedImpact)
+			}
+			md.WriteString("\n")
+		}
+	}
+	return md.String()
+}
+
+// Goal returns the goal the recommendation converts to, or nil when it has no target
+func (r AIRecommendation) Goal() *Goal {
+	metric, ok := LookupMetric(r.TargetMetric)
+	if !ok || r.TargetValue == nil {
+		return nil
+	}
+	comparison := r.Comparison
+	if comparison == "" {
+		comparison = GoalAtLeast
+		if metric.LowerIsBetter {
+			comparison = GoalAtMost
+		}
+	}
+	return &Goal{MetricKey: metric.Key, Comparison: comparison, Target: *r.TargetValue, WindowWeeks: 1}
+}
+
+// MetricLabel returns the label of the bottleneck's metric, or "" when there is none
+func (b AIBottleneck) MetricLabel() string {
+	metric, _ := LookupMetric(b.Metric)
+	return metric.Label
+}
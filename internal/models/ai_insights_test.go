@@ -0,0 +1,103 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+const testInsights = `{
+	"summary": "Fitness is the bottleneck.",
+	"bottlenecks": [{"pillar": "fitness", "metric": "vo2_max", "finding": "VO2 Max fell from 44 to 41."}],
+	"recommendations": [
+		{"pillar": "fitness", "title": "Walk more", "action": "10k steps a day.", "target_metric": "daily_steps", "target_value": 10000, "priority": "low"},
+		{"pillar": "fitness", "title": "Add intervals", "action": "Two sessions a week.", "target_metric": "vo2_max", "target_value": 45, "expected_impact": "+2 VO2 Max in 8 weeks.", "priority": "high"},
+		{"pillar": "health", "title": "Trim the waist", "action": "Cut late snacks.", "target_metric": "waist_cm", "target_value": 85, "priority": "medium"},
+		{"pillar": "cognition", "title": "Call a friend", "action": "Once a week.", "target_metric": "", "target_value": null, "priority": "medium"}
+	]
+}`
+
+func TestParseAIInsights(t *testing.T) {
+	insights, err := ParseAIInsights("Here you go:\n```json\n" + testInsights + "\n```")
+	if err != nil {
+		t.Fatalf("Expected the fenced JSON to parse, got %v", err)
+	}
+	if insights.Summary != "Fitness is the bottleneck." || len(insights.Bottlenecks) != 1 || len(insights.Recommendations) != 4 {
+		t.Errorf("Unexpected insights %+v", insights)
+	}
+
+	for name, text := range map[string]string{
+		"prose":          "**Sleep** more.",
+		"broken JSON":    `{"summary": "Fine.",`,
+		"no summary":     `{"recommendations": [{"pillar": "health", "title": "T", "action": "A", "priority": "high"}]}`,
+		"no advice":      `{"summary": "Fine.", "recommendations": []}`,
+		"bad pillar":     `{"summary": "Fine.", "recommendations": [{"pillar": "sleep", "title": "T", "action": "A", "priority": "high"}]}`,
+		"bad priority":   `{"summary": "Fine.", "recommendations": [{"pillar": "health", "title": "T", "action": "A", "priority": "urgent"}]}`,
+		"bad metric":     `{"summary": "Fine.", "recommendations": [{"pillar": "health", "title": "T", "action": "A", "target_metric": "hrv", "priority": "high"}]}`,
+		"bad target":     `{"summary": "Fine.", "recommendations": [{"pillar": "health", "title": "T", "action": "A", "target_metric": "sleep_score", "target_value": 150, "priority": "high"}]}`,
+		"bad bottleneck": `{"summary": "Fine.", "bottlenecks": [{"pillar": "health", "finding": ""}], "recommendations": [{"pillar": "health", "title": "T", "action": "A", "priority": "high"}]}`,
+	} {
+		if _, err := ParseAIInsights(text); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	_, err = ParseAIInsights(`{"summary": "Fine.", "recommendations": [{"pillar": "sleep", "title": "T", "action": "A", "priority": "urgent"}]}`)
+	if err == nil || !strings.Contains(err.Error(), "recommendations[0].pillar must be one of health, fitness, cognition") ||
+		!strings.Contains(err.Error(), "recommendations[0].priority must be one of high, medium, low") {
+		t.Errorf("Expected every problem named, got %v", err)
+	}
+}
+
+func TestAIInsightsByPillar(t *testing.T) {
+	insights, err := ParseAIInsights(testInsights)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	groups := insights.ByPillar()
+	if len(groups) != 3 || groups[0].Label() != "Health" || groups[1].Label() != "Fitness" || groups[2].Label() != "Cognition" {
+		t.Fatalf("Expected the pillars in order, got %+v", groups)
+	}
+	fitness := groups[1]
+	if len(fitness.Bottlenecks) != 1 || fitness.Bottlenecks[0].MetricLabel() != "VO2 Max" {
+		t.Errorf("Expected the fitness bottleneck, got %+v", fitness.Bottlenecks)
+	}
+	if len(fitness.Recommendations) != 2 || fitness.Recommendations[0].Title != "Add intervals" {
+		t.Errorf("Expected fitness recommendations by priority, got %+v", fitness.Recommendations)
+	}
+
+	md := insights.Markdown()
+	for _, want := range []string{
+		"Fitness is the bottleneck.\n",
+		"### Fitness\n- **Bottleneck**: VO2 Max fell from 44 to 41.\n",
+		"- **Add intervals** (high priority): Two sessions a week. Target: VO2 Max ≥ 45.0 ml/kg/min. Expected impact: +2 VO2 Max in 8 weeks.\n",
+		"- **Call a friend** (medium priority): Once a week.\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected %q in the markdown:\n%s", want, md)
+		}
+	}
+}
+
+func TestAIRecommendationGoal(t *testing.T) {
+	target := 85.0
+	goal := AIRecommendation{TargetMetric: "waist_cm", TargetValue: &target}.Goal()
+	if goal == nil || goal.Comparison != GoalAtMost || goal.Target != 85 || goal.WindowWeeks != 1 {
+		t.Errorf("Expected an at most goal for a lower is better metric, got %+v", goal)
+	}
+	goal = AIRecommendation{TargetMetric: "waist_cm", Comparison: GoalAtLeast, TargetValue: &target}.Goal()
+	if goal == nil || goal.Comparison != GoalAtLeast {
+		t.Errorf("Expected the model's comparison kept, got %+v", goal)
+	}
+	if (AIRecommendation{TargetMetric: "waist_cm"}).Goal() != nil || (AIRecommendation{TargetValue: &target}).Goal() != nil {
+		t.Error("Expected no goal without a target metric and value")
+	}
+
+	summary := AISummary{Text: testInsights}
+	if !strings.HasPrefix(summary.Markdown(), "Fitness is the bottleneck.\n\n### Health") {
+		t.Errorf("Expected structured summaries as markdown, got %q", summary.Markdown())
+	}
+	if (AISummary{Text: "**Sleep** more."}).Markdown() != "**Sleep** more." {
+		t.Error("Expected plain summaries unchanged")
+	}
+}
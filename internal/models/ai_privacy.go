@@ -0,0 +1,122 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// AIPrivacy controls what the AI features send to the language model provider. The zero
+// value shares everything exactly.
+type AIPrivacy struct {
+	HideAge    bool
+	HideSex    bool
+	HideHeight bool
+	// HideNotes leaves out the weekly notes and tags, which are free text
+	HideNotes bool
+	// Coarsen sends the age as a decade, the height to 5 cm and metrics rounded to
+	// coarseSteps instead of exact values
+	Coarsen bool
+	// HiddenMetrics are the keys of metrics that are never sent
+	HiddenMetrics []string
+}
+
+// coarseSteps are the steps metrics are rounded to when coarsening. Counts like workouts
+// say little about a person and are kept exact.
+var coarseSteps = map[string]float64{
+	"body_weight_kg":    5,
+	"waist_cm":          5,
+	"systolic_bp":       5,
+	"diastolic_bp":      5,
+	"rhr":               5,
+	"sleep_score":       5,
+	"daily_steps":       1000,
+	"vo2_max":           1,
+	"dead_hang_seconds": 10,
+	"lower_body_weight": 5,
+	"cardio_recovery":   5,
+	"deep_learning":     30,
+}
+
+// Shares reports whether the metric with key is sent
+func (p AIPrivacy) Shares(key string) bool {
+	return !slices.Contains(p.HiddenMetrics, key)
+}
+
+// ExactAge reports whether the exact age is sent. Values derived from it, like the
+// aging tax, would give it away otherwise.
+func (p AIPrivacy) ExactAge() bool {
+	return !p.HideAge && !p.Coarsen
+}
+
+// MetricValue returns the value of the metric as it is sent: nil when the metric is
+// hidden, rounded when coarsening. Coarsened is set when the value was rounded.
+func (p AIPrivacy) MetricValue(key string, value *float64) (sent *float64, coarsened bool) {
+	if value == nil || !p.Shares(key) {
+		return nil, false
+	}
+	step, ok := coarseSteps[key]
+	if !p.Coarsen || !ok {
+		return value, false
+	}
+	rounded := math.Round(*value/step) * step
+	return &rounded, true
+}
+
+// Person describes the user for a prompt, e.g. "a 46-year-old female (height: 168.0 cm)"
+// or "a person in their 40s"
+func (p AIPrivacy) Person(age int, profile *UserProfile) string {
+	noun := p.Sex(profile)
+	if noun == "" {
+		noun = "person"
+	}
+
+	var description string
+	switch {
+	case p.HideAge:
+		description = "a " + noun
+	case p.Coarsen:
+		description = fmt.Sprintf("a %s in their %s", noun, p.Age(age))
+	default:
+		description = fmt.Sprintf("a %s-year-old %s", p.Age(age), noun)
+	}
+
+	if height := p.Height(profile); height != "" {
+		description += fmt.Sprintf(" (height: %s)", height)
+	}
+	return description
+}
+
+// Age returns the age as it is sent, e.g. "46" or "40s" when coarsening, or "" when hidden
+func (p AIPrivacy) Age(age int) string {
+	switch {
+	case p.HideAge:
+		return ""
+	case p.Coarsen:
+		return fmt.Sprintf("%ds", age/10*10)
+	default:
+		return strconv.Itoa(age)
+	}
+}
+
+// Sex returns "male" or "female" as it is sent, or "" when hidden or unknown
+func (p AIPrivacy) Sex(profile *UserProfile) string {
+	if p.HideSex || (profile.Sex != "male" && profile.Sex != "female") {
+		return ""
+	}
+	return profile.Sex
+}
+
+// Height returns the height as it is sent, e.g. "168.4 cm" or "about 170 cm" when
+// coarsening, or "" when hidden or unknown
+func (p AIPrivacy) Height(profile *UserProfile) string {
+	switch {
+	case p.HideHeight || profile.HeightCm <= 0:
+		return ""
+	case p.Coarsen:
+		return fmt.Sprintf("about %.0f cm", math.Round(profile.HeightCm/5)*5)
+	default:
+		return fmt.Sprintf("%.1f cm", profile.HeightCm)
+	}
+}
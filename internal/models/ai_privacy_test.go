@@ -0,0 +1,50 @@
+package models
+
+import "testing"
+
+func TestAIPrivacyPerson(t *testing.T) {
+	profile := &UserProfile{Sex: "female", HeightCm: 168.4}
+	tests := []struct {
+		privacy AIPrivacy
+		want    string
+	}{
+		{AIPrivacy{}, "a 46-year-old female (height: 168.4 cm)"},
+		{AIPrivacy{Coarsen: true}, "a female in their 40s (height: about 170 cm)"},
+		{AIPrivacy{HideAge: true, HideHeight: true}, "a female"},
+		{AIPrivacy{HideSex: true, HideHeight: true}, "a 46-year-old person"},
+		{AIPrivacy{HideAge: true, HideSex: true, HideHeight: true}, "a person"},
+	}
+	for _, tt := range tests {
+		if got := tt.privacy.Person(46, profile); got != tt.want {
+			t.Errorf("%+v: expected %q, got %q", tt.privacy, tt.want, got)
+		}
+	}
+
+	if got := (AIPrivacy{}).Person(46, &UserProfile{Sex: "neutral"}); got != "a 46-year-old person" {
+		t.Errorf("Expected sex-neutral profiles described as a person, got %q", got)
+	}
+}
+
+func TestAIPrivacyMetricValue(t *testing.T) {
+	weight := 81.7
+	privacy := AIPrivacy{HiddenMetrics: []string{"waist_cm"}}
+
+	if sent, coarsened := privacy.MetricValue("body_weight_kg", &weight); sent == nil || *sent != 81.7 || coarsened {
+		t.Errorf("Expected the exact value, got %v %v", sent, coarsened)
+	}
+	if sent, _ := privacy.MetricValue("waist_cm", &weight); sent != nil || privacy.Shares("waist_cm") {
+		t.Errorf("Expected a hidden metric not to be sent, got %v", sent)
+	}
+
+	privacy.Coarsen = true
+	if sent, coarsened := privacy.MetricValue("body_weight_kg", &weight); sent == nil || *sent != 80 || !coarsened {
+		t.Errorf("Expected the weight rounded to 5 kg, got %v %v", sent, coarsened)
+	}
+	workouts := 3.0
+	if sent, coarsened := privacy.MetricValue("workouts", &workouts); sent == nil || *sent != 3 || coarsened {
+		t.Errorf("Expected counts kept exact, got %v %v", sent, coarsened)
+	}
+	if privacy.ExactAge() || !(AIPrivacy{}).ExactAge() {
+		t.Error("Expected the exact age only without coarsening")
+	}
+}
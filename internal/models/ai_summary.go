@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// AISummary is a generated health summary together with what produced it, so a summary
+// can be served again while the data behind its prompt is unchanged
+type AISummary struct {
+	ID int
+	// Week is the latest week of data included in the prompt
+	Week       string
+	Provider   string
+	Model      string
+	PromptHash string
+	Text       string
+	CreatedAt  time.Time
+	// Cached is set when the summary was served from the database instead of generated
+	Cached bool
+}
+
+// Insights parses the structured analysis of the summary. Summaries generated before the
+// model was asked for JSON, or by a model that ignored the format, are plain markdown.
+func (s AISummary) Insights() (*AIInsights, error) {
+	return ParseAIInsights(s.Text)
+}
+
+// Markdown returns the summary as markdown, rendering structured insights
+func (s AISummary) Markdown() string {
+	if insights, err := s.Insights(); err == nil {
+		return insights.Markdown()
+	}
+	return s.Text
+}
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// The AI features that call the language model provider
+const (
+	AIFeatureSummary    = "summary"
+	AIFeatureCoach      = "coach"
+	AIFeatureComparison = "comparison"
+)
+
+// The outcomes of a call to the language model provider
+const (
+	AIUsageOK        = "ok"
+	AIUsageFailed    = "error"
+	AIUsageCancelled = "cancelled"
+)
+
+// AIUsage records one call to the language model provider
+type AIUsage struct {
+	ID        int
+	CreatedAt time.Time
+	// Feature is the AI feature that made the call, e.g. AIFeatureSummary
+	Feature  string
+	Provider string
+	Model    string
+	// Status is the outcome of the call, e.g. AIUsageFailed. The usage of a call that failed
+	// while streaming covers the part received before it stopped.
+	Status       string
+	InputTokens  int
+	OutputTokens int
+	Latency      time.Duration
+	// Cost is the estimated cost in US dollars, 0 when the model's price is unknown
+	Cost float64
+}
+
+// AIUsageTotal sums up the calls to one model in one month
+type AIUsageTotal struct {
+	// Month is the first day of the month, in local time
+	Month    time.Time
+	Provider string
+	Model    string
+	Calls    int
+	// Failed counts the calls that failed or were cancelled
+	Failed       int
+	InputTokens  int
+	OutputTokens int
+	Latency      time.Duration
+	Cost         float64
+}
+
+// AverageLatency returns how long a call took on average
+func (t AIUsageTotal) AverageLatency() time.Duration {
+	if t.Calls == 0 {
+		return 0
+	}
+	return t.Latency / time.Duration(t.Calls)
+}
@@ -0,0 +1,46 @@
+package models
+
+import "fmt"
+
+const (
+	AnomalyOutOfRange = "out_of_range"
+	AnomalyUnusual    = "unusual"
+)
+
+// Bounds is the plausible range of a metric
+type Bounds struct {
+	Min float64
+	Max float64
+}
+
+// Contains reports whether value lies within the bounds
+func (b Bounds) Contains(value float64) bool {
+	return value >= b.Min && value <= b.Max
+}
+
+// Anomaly is a submitted value that looks like a data entry mistake
+type Anomaly struct {
+	Metric MetricDefinition
+	Value  float64
+	Kind   string
+	// Median and ZScore describe the personal history for unusual values
+	Median float64
+	ZScore float64
+}
+
+// Message explains why the value was flagged, e.g. "Resting Heart Rate 5 bpm is outside the plausible range 30–120"
+func (a Anomaly) Message() string {
+	value := a.Metric.FormatValue(a.Value)
+	if a.Metric.Unit != "" {
+		value += " " + a.Metric.Unit
+	}
+	if a.Kind == AnomalyOutOfRange {
+		bounds := a.Metric.Plausible
+		return fmt.Sprintf("%s %s is outside the plausible range %s–%s", a.Metric.Label, value,
+			a.Metric.FormatValue(bounds.Min), a.Metric.FormatValue(bounds.Max))
+	}
+	return fmt.Sprintf("%s %s is far from your usual %s", a.Metric.Label, value, a.Metric.FormatValue(a.Median))
+}
+
+// OutlierFlags marks, per week, the stored values that look anomalous
+type OutlierFlags map[string]map[string]bool
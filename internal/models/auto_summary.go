@@ -0,0 +1,74 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// When the automatic weekly AI summary is generated
+const (
+	// AutoSummaryOnComplete generates it once the week's three pillars are recorded
+	AutoSummaryOnComplete = "complete"
+	// AutoSummaryScheduled generates it at a set day and time of the week
+	AutoSummaryScheduled = "scheduled"
+)
+
+// AutoSummarySettings control the AI summary generated every week without asking. It is
+// off until enabled, since it sends data to the AI provider on its own.
+type AutoSummarySettings struct {
+	Enabled bool
+	// Trigger is AutoSummaryOnComplete or AutoSummaryScheduled
+	Trigger string
+	// Day and Time set when a scheduled summary is generated, in Timezone
+	Day      int    // 0-6 (Sunday-Saturday)
+	Time     string // "HH:MM"
+	Timezone string // e.g. "Europe/Stockholm"
+	// LastWeek is the week the last automatic summary was generated for
+	LastWeek string
+}
+
+// DefaultAutoSummarySettings are used until the settings are saved
+func DefaultAutoSummarySettings() AutoSummarySettings {
+	return AutoSummarySettings{Trigger: AutoSummaryOnComplete, Day: 0, Time: "18:00", Timezone: "UTC"}
+}
+
+// Weekday returns the day a scheduled summary is generated
+func (s AutoSummarySettings) Weekday() time.Weekday {
+	return time.Weekday(s.Day)
+}
+
+// Validate checks the trigger, day, time and time zone
+func (s AutoSummarySettings) Validate() FieldErrors {
+	errs := FieldErrors{}
+	if s.Trigger != AutoSummaryOnComplete && s.Trigger != AutoSummaryScheduled {
+		errs.Add("trigger", fmt.Sprintf("trigger must be %s or %s", AutoSummaryOnComplete, AutoSummaryScheduled))
+	}
+	if s.Day < 0 || s.Day > 6 {
+		errs.Add("day", "day must be between 0 (Sunday) and 6 (Saturday)")
+	}
+	if _, err := time.Parse("15:04", s.Time); err != nil {
+		errs.Add("time", "time must use the format HH:MM")
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil || s.Timezone == "" {
+		errs.Add("timezone", fmt.Sprintf("unknown time zone %q", s.Timezone))
+	}
+	return errs
+}
+
+// ScheduledAt returns when a scheduled summary is due in the week of now, which runs from
+// Monday to Sunday in the settings' time zone
+func (s AutoSummarySettings) ScheduledAt(now time.Time) time.Time {
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	local := now.In(location)
+	at, err := time.Parse("15:04", s.Time)
+	if err != nil {
+		at = time.Time{}
+	}
+
+	daysSinceMonday := (int(local.Weekday()) + 6) % 7
+	monday := time.Date(local.Year(), local.Month(), local.Day()-daysSinceMonday, 0, 0, 0, 0, location)
+	return time.Date(monday.Year(), monday.Month(), monday.Day()+(s.Day+6)%7, at.Hour(), at.Minute(), 0, 0, location)
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAutoSummarySettingsValidate(t *testing.T) {
+	if errs := DefaultAutoSummarySettings().Validate(); len(errs) > 0 {
+		t.Errorf("Expected the defaults to be valid, got %v", errs)
+	}
+
+	errs := AutoSummarySettings{Trigger: "daily", Day: 7, Time: "25:00", Timezone: "Mars/Olympus"}.Validate()
+	for _, field := range []string{"trigger", "day", "time", "timezone"} {
+		if errs[field] == "" {
+			t.Errorf("Expected an error for %s, got %v", field, errs)
+		}
+	}
+}
+
+func TestAutoSummarySettingsScheduledAt(t *testing.T) {
+	stockholm, err := time.LoadLocation("Europe/Stockholm")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+	// Wednesday evening in Stockholm
+	now := time.Date(2026, 3, 4, 18, 30, 0, 0, stockholm)
+
+	tests := []struct {
+		day  int
+		want time.Time
+	}{
+		{1, time.Date(2026, 3, 2, 9, 15, 0, 0, stockholm)},
+		{3, time.Date(2026, 3, 4, 9, 15, 0, 0, stockholm)},
+		{0, time.Date(2026, 3, 8, 9, 15, 0, 0, stockholm)},
+	}
+	for _, tt := range tests {
+		s := AutoSummarySettings{Day: tt.day, Time: "09:15", Timezone: "Europe/Stockholm"}
+		if got := s.ScheduledAt(now.UTC()); !got.Equal(tt.want) {
+			t.Errorf("day %d: expected %v, got %v", tt.day, tt.want, got)
+		}
+	}
+}
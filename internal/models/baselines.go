@@ -2,7 +2,9 @@ package models
 
 import "strings"
 
-// GetVO2MaxBaseline returns the age and sex-based VO2 Max baseline
+// GetVO2MaxBaseline returns the age and sex-based VO2 Max baseline the fitness pillar
+// scores against. Sex-neutral baselines sit midway between the male and female ones.
+// The continuous norms in GetVO2MaxNorm are for percentiles and age curves.
 func GetVO2MaxBaseline(age int, sex string) float64 {
 	var baseline float64
 
@@ -21,9 +23,42 @@ func GetVO2MaxBaseline(age int, sex string) float64 {
 		baseline = 24.0
 	}
 
-	if strings.ToLower(sex) == "male" {
+	switch strings.ToLower(sex) {
+	case SexMale:
 		baseline += 6.0
+	case SexNeutral:
+		baseline += 3.0
 	}
 
 	return baseline
 }
+
+// GetWHtRBaseline returns the typical Waist-to-Height Ratio for the given age.
+// Central adiposity creeps up with age, from roughly 0.45 at 25 to 0.53 at 65.
+func GetWHtRBaseline(age int) float64 {
+	return 0.45 + float64(age-25)*0.002
+}
+
+// GetRHRBaseline returns the typical resting heart rate for the given age.
+// RHR only drifts slightly with age, so it is a weak age signal.
+func GetRHRBaseline(age int) float64 {
+	return 62.0 + float64(age-25)*0.12
+}
+
+// GetSystolicBPBaseline returns the typical systolic blood pressure for the given age
+func GetSystolicBPBaseline(age int) float64 {
+	return 114.0 + float64(age-25)*0.45
+}
+
+// GetDeadHangBaseline returns the typical dead hang time in seconds for the given age.
+// 60s matches the healthy adult standard used by the fitness pillar at 35.
+func GetDeadHangBaseline(age int) float64 {
+	return 60.0 - float64(age-35)*1.0
+}
+
+// GetLowerBodyRSIBaseline returns the typical leg press Relative Strength Index
+// (leg press weight / body weight × reps) for the given age.
+// 24 matches the fitness pillar baseline at 35.
+func GetLowerBodyRSIBaseline(age int) float64 {
+	return 24.0 - float64(age-35)*0.25
+}
@@ -20,6 +20,7 @@ func TestGetVO2MaxBaseline(t *testing.T) {
 		{"Case insensitive male", 35, "MALE", 42.0}, // 36.0 + 6.0
 		{"Case insensitive female", 35, "FEMALE", 36.0},
 		{"Default to female if not specified", 35, "other", 36.0},
+		{"Sex-neutral baseline", 35, "neutral", 39.0}, // midway between 36.0 and 42.0
 	}
 
 	for _, tc := range testCases {
@@ -31,3 +32,77 @@ func TestGetVO2MaxBaseline(t *testing.T) {
 		})
 	}
 }
+
+func TestReserveBaselinesAgeDirection(t *testing.T) {
+	testCases := []struct {
+		name       string
+		baseline   func(age int) float64
+		increasing bool
+	}{
+		{"WHtR", GetWHtRBaseline, true},
+		{"RHR", GetRHRBaseline, true},
+		{"Systolic BP", GetSystolicBPBaseline, true},
+		{"Dead Hang", GetDeadHangBaseline, false},
+		{"Leg Press RSI", GetLowerBodyRSIBaseline, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			young, old := tc.baseline(30), tc.baseline(70)
+			if tc.increasing && old <= young {
+				t.Errorf("Expected %s baseline to rise with age, got %f at 30 and %f at 70", tc.name, young, old)
+			}
+			if !tc.increasing && old >= young {
+				t.Errorf("Expected %s baseline to fall with age, got %f at 30 and %f at 70", tc.name, young, old)
+			}
+		})
+	}
+}
+
+func TestGetVO2MaxPercentile(t *testing.T) {
+	testCases := []struct {
+		name     string
+		age      float64
+		sex      string
+		vo2Max   float64
+		expected float64
+	}{
+		{"Median female", 35, "female", 36.0, 50},
+		{"75th male", 45, "male", 43.5, 75},
+		{"Between 50th and 75th", 35, "female", 38.0, 62.5},
+		{"Top end is capped", 25, "male", 80.0, 99},
+		{"Bottom end is capped", 25, "female", 5.0, 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := GetVO2MaxPercentile(tc.age, tc.sex, tc.vo2Max)
+			if result != tc.expected {
+				t.Errorf("GetVO2MaxPercentile(%.0f, %s, %.1f) = %f; expected %f", tc.age, tc.sex, tc.vo2Max, result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestVO2MaxStandingOrdinalPercentile(t *testing.T) {
+	tests := map[float64]string{1: "1st", 2: "2nd", 3: "3rd", 11: "11th", 12.4: "12th", 13: "13th", 21: "21st", 50.6: "51st", 72: "72nd", 93: "93rd", 99: "99th"}
+	for percentile, expected := range tests {
+		if got := (VO2MaxStanding{Percentile: percentile}).OrdinalPercentile(); got != expected {
+			t.Errorf("Percentile %.1f: expected %q, got %q", percentile, expected, got)
+		}
+	}
+}
+
+func TestNormalizeSex(t *testing.T) {
+	for input, expected := range map[string]string{
+		"male":       SexMale,
+		" Female ":   SexFemale,
+		"neutral":    SexNeutral,
+		"non-binary": SexNeutral,
+		"":           SexNeutral,
+	} {
+		if got := NormalizeSex(input); got != expected {
+			t.Errorf("NormalizeSex(%q) = %q; expected %q", input, got, expected)
+		}
+	}
+}
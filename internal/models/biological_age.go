@@ -0,0 +1,28 @@
+package models
+
+// MarkerAge is the age whose population baseline matches one reserve marker
+type MarkerAge struct {
+	Name   string
+	Value  float64
+	Unit   string
+	Age    float64
+	Weight float64
+}
+
+// BiologicalAgePoint is a single functional age estimate in the trend
+type BiologicalAgePoint struct {
+	Date     string
+	Estimate float64
+}
+
+// BiologicalAge is the human-readable "functional age" derived from the reserve markers
+type BiologicalAge struct {
+	Date             string
+	ChronologicalAge int
+	Estimate         float64
+	Low              float64
+	High             float64
+	Markers          []MarkerAge
+	Trend            []BiologicalAgePoint
+	Change           float64
+}
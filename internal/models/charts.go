@@ -0,0 +1,87 @@
+package models
+
+// ChartPoint is a single dated value in a chart series
+type ChartPoint struct {
+	Date  string  `json:"date"`
+	Value float64 `json:"value"`
+}
+
+// ChartSeries is the time series behind one trend chart, with an optional
+// trailing moving average over MovingAverageWeeks points
+type ChartSeries struct {
+	Key                string            `json:"key"`
+	Label              string            `json:"label"`
+	Unit               string            `json:"unit"`
+	Format             string            `json:"-"`
+	Range              string            `json:"range"`
+	MovingAverageWeeks int               `json:"moving_average_weeks"`
+	Points             []ChartPoint      `json:"points"`
+	MovingAverage      []ChartPoint      `json:"moving_average"`
+	Annotations        []ChartAnnotation `json:"annotations"`
+	Notes              []WeekNote        `json:"notes"`
+}
+
+// ChartAnnotation marks the period of an intervention on a chart. End is empty
+// while the intervention is ongoing.
+type ChartAnnotation struct {
+	Label string `json:"label"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// ChartOption is one selectable series in the chart picker
+type ChartOption struct {
+	Key   string
+	Label string
+}
+
+// ChartOptionGroup groups the chart picker options by pillar
+type ChartOptionGroup struct {
+	Label   string
+	Options []ChartOption
+}
+
+// ChartMarker is a plotted data point with its tooltip
+type ChartMarker struct {
+	X     float64
+	Y     float64
+	Date  string
+	Label string
+	// Note summarizes the week's note, empty when the week has none
+	Note string
+}
+
+// ChartBand is an annotated period shaded across the plot
+type ChartBand struct {
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+	Label  string
+	Title  string
+}
+
+// ChartTick is an axis tick at the given position
+type ChartTick struct {
+	Pos   float64
+	Label string
+}
+
+// ChartView holds a series laid out in SVG coordinates, ready to render
+type ChartView struct {
+	Series      ChartSeries
+	Width       float64
+	Height      float64
+	PlotLeft    float64
+	PlotRight   float64
+	PlotTop     float64
+	PlotBottom  float64
+	Line        string
+	AverageLine string
+	Markers     []ChartMarker
+	Bands       []ChartBand
+	YTicks      []ChartTick
+	XTicks      []ChartTick
+	Options     []ChartOptionGroup
+	Ranges      []string
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	MaxCoachQuestionLength = 2000
+	MaxCoachTitleLength    = 60
+)
+
+// Roles of the messages in a coach thread. Tool calls and results are the coach looking
+// up data while answering; they are kept so follow-up questions see what it found.
+const (
+	CoachRoleUser       = "user"
+	CoachRoleAssistant  = "assistant"
+	CoachRoleToolCall   = "tool_call"
+	CoachRoleToolResult = "tool_result"
+)
+
+// CoachThread is one conversation with the AI coach
+type CoachThread struct {
+	ID        int
+	Title     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// CoachMessage is one message of a coach thread
+type CoachMessage struct {
+	ID        int
+	ThreadID  int
+	Role      string
+	Content   string
+	CreatedAt time.Time
+}
+
+// CoachThreadTitle derives a thread title from its first question
+func CoachThreadTitle(question string) string {
+	title := strings.Join(strings.Fields(question), " ")
+	if runes := []rune(title); len(runes) > MaxCoachTitleLength {
+		title = strings.TrimSpace(string(runes[:MaxCoachTitleLength-1])) + "…"
+	}
+	return title
+}
+
+// ValidateCoachQuestion checks a question before it is sent to the coach
+func ValidateCoachQuestion(question string) FieldErrors {
+	errs := FieldErrors{}
+	switch {
+	case strings.TrimSpace(question) == "":
+		errs.Add("question", "Question is required")
+	case len([]rune(question)) > MaxCoachQuestionLength:
+		errs.Add("question", fmt.Sprintf("Question must be at most %d characters", MaxCoachQuestionLength))
+	}
+	return errs
+}
@@ -0,0 +1,52 @@
+package models
+
+import "math"
+
+// SignificanceLevel is the p-value below which a correlation is reported as significant
+const SignificanceLevel = 0.05
+
+// Correlation is the Pearson correlation between one metric and another metric
+// LagWeeks later, over the N weeks where both were recorded
+type Correlation struct {
+	X        MetricDefinition
+	Y        MetricDefinition
+	LagWeeks int
+	R        float64
+	N        int
+	// PValue is the two-sided p-value of the correlation; AdjustedPValue corrects it
+	// for the number of lags tried when the lag was picked as the strongest one
+	PValue         float64
+	AdjustedPValue float64
+}
+
+// Significant reports whether the correlation holds at the significance level after adjustment
+func (c Correlation) Significant() bool {
+	return c.AdjustedPValue < SignificanceLevel
+}
+
+// AbsR returns the size of the correlation coefficient regardless of direction
+func (c Correlation) AbsR() float64 {
+	return math.Abs(c.R)
+}
+
+// Strength describes the size of the correlation coefficient
+func (c Correlation) Strength() string {
+	switch r := c.AbsR(); {
+	case r >= 0.7:
+		return "strong"
+	case r >= 0.4:
+		return "moderate"
+	case r >= 0.2:
+		return "weak"
+	default:
+		return "negligible"
+	}
+}
+
+// Direction returns "rises" or "falls", describing how Y moves as X goes up
+func (c Correlation) Direction() string {
+	if c.R < 0 {
+		return "falls"
+	}
+	return "rises"
+}
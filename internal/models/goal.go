@@ -0,0 +1,130 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	GoalAtLeast = "at_least"
+	GoalAtMost  = "at_most"
+)
+
+const (
+	GoalAchieved = "achieved"
+	GoalOnTrack  = "on_track"
+	GoalOffTrack = "off_track"
+	GoalNoData   = "no_data"
+)
+
+// MaxGoalWindowWeeks is the longest window a goal can average over
+const MaxGoalWindowWeeks = 13
+
+// Goal is a target for one metric, like "VO2 Max at least 50 by June" or
+// "sleep score averaging at least 80 over 4 weeks"
+type Goal struct {
+	ID         int
+	MetricKey  string
+	Comparison string
+	Target     float64
+	// WindowWeeks is 1 to compare the latest recorded value, or the number of
+	// recorded weeks to average
+	WindowWeeks int
+	// Deadline is an optional YYYY-MM-DD date the goal should be reached by
+	Deadline string
+	// StartValue is the metric's value when the goal was set, for measuring progress
+	StartValue *float64
+	CreatedAt  string
+	Notify     bool
+	// LastStatus is the status last seen by the notification check
+	LastStatus string
+	AchievedAt string
+}
+
+// Met reports whether value satisfies the goal
+func (g Goal) Met(value float64) bool {
+	if g.Comparison == GoalAtMost {
+		return value <= g.Target
+	}
+	return value >= g.Target
+}
+
+// Description returns a short summary, e.g. "Waist ≤ 85 cm" or "Sleep Score ≥ 80 (4-week average)"
+func (g Goal) Description() string {
+	metric, ok := LookupMetric(g.MetricKey)
+	if !ok {
+		metric = MetricDefinition{Key: g.MetricKey, Label: g.MetricKey, Format: "%.1f"}
+	}
+	symbol := "≥"
+	if g.Comparison == GoalAtMost {
+		symbol = "≤"
+	}
+	description := fmt.Sprintf("%s %s %s", metric.Label, symbol, metric.FormatValue(g.Target))
+	if metric.Unit != "" {
+		description += " " + metric.Unit
+	}
+	if g.WindowWeeks > 1 {
+		description += fmt.Sprintf(" (%d-week average)", g.WindowWeeks)
+	}
+	return description
+}
+
+// Validate checks the goal before it is created, as of now
+func (g Goal) Validate(now time.Time) FieldErrors {
+	errs := FieldErrors{}
+
+	if _, ok := LookupMetric(g.MetricKey); !ok {
+		errs.Add("metric", "Choose a metric")
+	} else if rule, ok := LookupFieldRule(g.MetricKey); ok {
+		errs.Add("target", rule.Check(&g.Target))
+	}
+	if g.Comparison != GoalAtLeast && g.Comparison != GoalAtMost {
+		errs.Add("comparison", "Choose at least or at most")
+	}
+	if g.WindowWeeks < 1 || g.WindowWeeks > MaxGoalWindowWeeks {
+		errs.Add("window_weeks", fmt.Sprintf("Window must be between 1 and %d weeks", MaxGoalWindowWeeks))
+	}
+	if g.Deadline != "" {
+		if _, err := time.Parse("2006-01-02", g.Deadline); err != nil {
+			errs.Add("deadline", "Deadline must be a date like 2026-06-30")
+		} else if g.Deadline < now.Format("2006-01-02") {
+			errs.Add("deadline", "Deadline cannot be in the past")
+		}
+	}
+
+	return errs
+}
+
+// GoalProgress is how far a goal has come and where its trend is heading
+type GoalProgress struct {
+	Goal   Goal
+	Metric MetricDefinition
+	// Current is the latest value, or the average over the goal's window
+	Current *float64
+	// Progress is the share of the way from the start value to the target, from 0 to 1
+	Progress float64
+	// WeeklyTrend is the change per week of the metric over its recent history
+	WeeklyTrend float64
+	// ExpectedDate is when the trend reaches the target, empty when it never does
+	ExpectedDate string
+	Status       string
+}
+
+// Percent returns the progress as a percentage for progress bars
+func (p GoalProgress) Percent() float64 {
+	return p.Progress * 100
+}
+
+// StatusLabel returns the human-readable status, e.g. "on track"
+func (p GoalProgress) StatusLabel() string {
+	switch p.Status {
+	case GoalAchieved:
+		return "achieved"
+	case GoalOnTrack:
+		return "on track"
+	case GoalOffTrack:
+		return "off track"
+	default:
+		return "not enough data"
+	}
+}
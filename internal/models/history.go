@@ -0,0 +1,65 @@
+package models
+
+const (
+	HistoryViewScores   = "scores"
+	HistorySortDate     = "date"
+	DefaultHistoryLimit = 25
+)
+
+// HistoryViews lists the history pages in display order
+var HistoryViews = []string{HistoryViewScores, PillarHealth, PillarFitness, PillarCognition}
+
+// HistoryCursor marks the last row of a page. Value holds that row's value of
+// the sort column and is nil when it was skipped or when sorting by date.
+type HistoryCursor struct {
+	Date  string
+	Value *float64
+}
+
+// HistoryQuery selects one keyset page of history. From and To are inclusive
+// and may be empty. Rows with no value for the sort column always come last.
+type HistoryQuery struct {
+	From      string
+	To        string
+	Sort      string
+	Ascending bool
+	After     *HistoryCursor
+	Limit     int
+}
+
+// HistorySortable reports whether the given view can be sorted by key. Pillar
+// views sort by their catalog metrics, the scores view by its score columns.
+func HistorySortable(view, key string) bool {
+	if key == HistorySortDate {
+		return true
+	}
+	if view == HistoryViewScores {
+		for _, k := range ScoreHistoryKeys {
+			if k == key {
+				return true
+			}
+		}
+		return false
+	}
+	metric, ok := LookupMetric(key)
+	return ok && metric.Pillar == view
+}
+
+// ScoreHistoryKeys are the sortable columns of the scores history
+var ScoreHistoryKeys = []string{"score", "health_score", "fitness_score", "cognition_score", "aging_tax"}
+
+// ScoreValue returns the score column with the given key
+func (s MasterScore) ScoreValue(key string) float64 {
+	switch key {
+	case "health_score":
+		return s.HealthScore
+	case "fitness_score":
+		return s.FitnessScore
+	case "cognition_score":
+		return s.CognitionScore
+	case "aging_tax":
+		return s.AgingTax
+	default:
+		return s.Score
+	}
+}
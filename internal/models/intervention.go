@@ -0,0 +1,133 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// MaxInterventionNameLength limits intervention names so they fit on chart annotations
+const MaxInterventionNameLength = 60
+
+// Intervention is a change the user tries for a while, like cutting alcohol or adding
+// zone-2 runs, to compare their metrics before and during it
+type Intervention struct {
+	ID   int
+	Name string
+	// StartDate and EndDate are YYYY-MM-DD dates; EndDate is empty while the
+	// intervention is ongoing
+	StartDate string
+	EndDate   string
+	// Metrics are the catalog keys compared before and during the intervention,
+	// next to the pillar scores which are always compared
+	Metrics []string
+}
+
+// Ongoing reports whether the intervention has not been ended yet
+func (i Intervention) Ongoing() bool {
+	return i.EndDate == ""
+}
+
+// Validate checks the intervention before it is created, as of now
+func (i Intervention) Validate(now time.Time) FieldErrors {
+	errs := FieldErrors{}
+
+	if i.Name == "" {
+		errs.Add("name", "Name is required")
+	} else if len([]rune(i.Name)) > MaxInterventionNameLength {
+		errs.Add("name", fmt.Sprintf("Name must be at most %d characters", MaxInterventionNameLength))
+	}
+
+	today := now.Format("2006-01-02")
+	if i.StartDate == "" {
+		errs.Add("start_date", "Start date is required")
+	} else if _, err := time.Parse("2006-01-02", i.StartDate); err != nil {
+		errs.Add("start_date", "Start date must be a date like 2026-03-01")
+	} else if i.StartDate > today {
+		errs.Add("start_date", "Start date cannot be in the future")
+	}
+
+	if i.EndDate != "" {
+		if _, err := time.Parse("2006-01-02", i.EndDate); err != nil {
+			errs.Add("end_date", "End date must be a date like 2026-04-30")
+		} else if i.EndDate < i.StartDate {
+			errs.Add("end_date", "End date cannot be before the start date")
+		}
+	}
+
+	for _, key := range i.Metrics {
+		if _, ok := LookupMetric(key); !ok {
+			errs.Add("metrics", fmt.Sprintf("Unknown metric %q", key))
+		}
+	}
+
+	return errs
+}
+
+// InterventionEffect compares one metric or score over the weeks before an
+// intervention with the weeks during it
+type InterventionEffect struct {
+	Metric      MetricDefinition
+	BeforeWeeks int
+	AfterWeeks  int
+	// BeforeMean and AfterMean are nil when no week was recorded in the period
+	BeforeMean *float64
+	AfterMean  *float64
+	// EffectSize is Hedges' g of the change, nil when either period has too few
+	// weeks or the values never vary
+	EffectSize *float64
+}
+
+// Change returns the raw difference between the means during and before the intervention
+func (e InterventionEffect) Change() float64 {
+	if e.BeforeMean == nil || e.AfterMean == nil {
+		return 0
+	}
+	return *e.AfterMean - *e.BeforeMean
+}
+
+// FormatChange returns the signed change in the metric's format, e.g. "-3.5"
+func (e InterventionEffect) FormatChange() string {
+	change := e.Change()
+	if change < 0 {
+		return "-" + e.Metric.FormatValue(-change)
+	}
+	return "+" + e.Metric.FormatValue(change)
+}
+
+// Improved reports whether the metric moved in the better direction
+func (e InterventionEffect) Improved() bool {
+	if e.Metric.LowerIsBetter {
+		return e.Change() < 0
+	}
+	return e.Change() > 0
+}
+
+// Magnitude describes the effect size using Cohen's conventions
+func (e InterventionEffect) Magnitude() string {
+	if e.EffectSize == nil {
+		return ""
+	}
+	switch g := math.Abs(*e.EffectSize); {
+	case g >= 0.8:
+		return "large"
+	case g >= 0.5:
+		return "medium"
+	case g >= 0.2:
+		return "small"
+	default:
+		return "negligible"
+	}
+}
+
+// InterventionResult is the before/after comparison of one intervention. The before
+// period is as long as the intervention, within limits, and ends the day before it starts.
+type InterventionResult struct {
+	Intervention Intervention
+	BeforeFrom   string
+	BeforeTo     string
+	AfterFrom    string
+	AfterTo      string
+	Scores       []InterventionEffect
+	Metrics      []InterventionEffect
+}
@@ -0,0 +1,177 @@
+package models
+
+const (
+	PillarHealth    = "health"
+	PillarFitness   = "fitness"
+	PillarCognition = "cognition"
+)
+
+// MetricDefinition describes one tracked metric. Key matches both the form field
+// and the database column of the pillar table.
+type MetricDefinition struct {
+	Key           string
+	Label         string
+	Unit          string
+	Pillar        string
+	Format        string
+	LowerIsBetter bool
+	// Min and Max are the accepted values. They only reject values that cannot be right;
+	// plausible but unusual values are left to the anomaly checks.
+	Min      float64
+	Max      float64
+	Integer  bool
+	Required bool
+	// Plausible is the range a weekly value can realistically fall in. Anything outside
+	// is almost certainly a typo, like RHR 5 instead of 55, and asks for confirmation.
+	Plausible Bounds
+}
+
+// Rule returns the validation rule of the metric's form field
+func (d MetricDefinition) Rule() FieldRule {
+	return FieldRule{Key: d.Key, Label: d.Label, Unit: d.Unit, Min: d.Min, Max: d.Max, Integer: d.Integer, Required: d.Required}
+}
+
+// Table returns the metrics table the metric is stored in
+func (d MetricDefinition) Table() string {
+	return d.Pillar + "_metrics"
+}
+
+// FormatValue formats a value of the metric with its display precision
+func (d MetricDefinition) FormatValue(value float64) string {
+	return FormatMetric(d.Format, &value)
+}
+
+// MetricCatalog lists every metric in the order it appears in the forms
+var MetricCatalog = []MetricDefinition{
+	{Key: "body_weight_kg", Label: "Body Weight", Unit: "kg", Pillar: PillarHealth, Format: "%.1f",
+		Min: 1, Max: 500, Plausible: Bounds{Min: 30, Max: 300}},
+	{Key: "waist_cm", Label: "Waist", Unit: "cm", Pillar: PillarHealth, Format: "%.1f", LowerIsBetter: true,
+		Min: 1, Max: 300, Plausible: Bounds{Min: 40, Max: 200}},
+	{Key: "systolic_bp", Label: "Systolic BP", Unit: "mmHg", Pillar: PillarHealth, Format: "%.0f", LowerIsBetter: true,
+		Min: 1, Max: 300, Integer: true, Plausible: Bounds{Min: 70, Max: 250}},
+	{Key: "diastolic_bp", Label: "Diastolic BP", Unit: "mmHg", Pillar: PillarHealth, Format: "%.0f", LowerIsBetter: true,
+		Min: 1, Max: 200, Integer: true, Plausible: Bounds{Min: 40, Max: 150}},
+	{Key: "rhr", Label: "Resting Heart Rate", Unit: "bpm", Pillar: PillarHealth, Format: "%.0f", LowerIsBetter: true,
+		Min: 1, Max: 250, Integer: true, Plausible: Bounds{Min: 30, Max: 120}},
+	{Key: "sleep_score", Label: "Sleep Score", Pillar: PillarHealth, Format: "%.0f",
+		Min: 0, Max: 100, Integer: true, Plausible: Bounds{Min: 0, Max: 100}},
+	{Key: "nutrition_score", Label: "Nutrition", Pillar: PillarHealth, Format: "%.1f",
+		Min: 1, Max: 10, Plausible: Bounds{Min: 1, Max: 10}},
+	{Key: "daily_steps", Label: "Daily Steps", Pillar: PillarFitness, Format: "%.0f",
+		Min: 0, Max: 200000, Integer: true, Plausible: Bounds{Min: 0, Max: 60000}},
+	{Key: "vo2_max", Label: "VO2 Max", Unit: "ml/kg/min", Pillar: PillarFitness, Format: "%.1f",
+		Min: 1, Max: 100, Plausible: Bounds{Min: 10, Max: 90}},
+	{Key: "workouts", Label: "Workouts", Pillar: PillarFitness, Format: "%.1f",
+		Min: 0, Max: 50, Integer: true, Plausible: Bounds{Min: 0, Max: 21}},
+	{Key: "mobility", Label: "Mobility", Pillar: PillarFitness, Format: "%.1f",
+		Min: 0, Max: 50, Integer: true, Plausible: Bounds{Min: 0, Max: 21}},
+	{Key: "dead_hang_seconds", Label: "Dead Hang", Unit: "s", Pillar: PillarFitness, Format: "%.0f",
+		Min: 0, Max: 3600, Integer: true, Plausible: Bounds{Min: 0, Max: 600}},
+	{Key: "lower_body_weight", Label: "Leg Press Weight", Unit: "kg", Pillar: PillarFitness, Format: "%.1f",
+		Min: 0, Max: 2000, Plausible: Bounds{Min: 0, Max: 1000}},
+	{Key: "lower_body_reps", Label: "Leg Press Reps", Pillar: PillarFitness, Format: "%.0f",
+		Min: 1, Max: 500, Integer: true, Plausible: Bounds{Min: 1, Max: 100}},
+	{Key: "cardio_recovery", Label: "Cardio Recovery", Unit: "bpm", Pillar: PillarFitness, Format: "%.0f",
+		Min: 0, Max: 200, Integer: true, Plausible: Bounds{Min: 0, Max: 100}},
+	{Key: "mindfulness", Label: "Mindfulness", Pillar: PillarCognition, Format: "%.1f",
+		Min: 0, Max: 100, Integer: true, Plausible: Bounds{Min: 0, Max: 21}},
+	{Key: "deep_learning", Label: "Deep Learning", Unit: "min", Pillar: PillarCognition, Format: "%.0f",
+		Min: 0, Max: 10080, Integer: true, Plausible: Bounds{Min: 0, Max: 5000}},
+	{Key: "stress_score", Label: "Stress Score", Pillar: PillarCognition, Format: "%.1f", LowerIsBetter: true,
+		Min: 1, Max: 5, Integer: true, Plausible: Bounds{Min: 1, Max: 5}},
+	{Key: "social_days", Label: "Social Days", Pillar: PillarCognition, Format: "%.1f",
+		Min: 0, Max: 7, Integer: true, Plausible: Bounds{Min: 0, Max: 7}},
+}
+
+// LookupMetric returns the catalog entry for the given key
+func LookupMetric(key string) (MetricDefinition, bool) {
+	for _, d := range MetricCatalog {
+		if d.Key == key {
+			return d, true
+		}
+	}
+	return MetricDefinition{}, false
+}
+
+// MetricsForPillar returns the catalog entries of one pillar
+func MetricsForPillar(pillar string) []MetricDefinition {
+	var defs []MetricDefinition
+	for _, d := range MetricCatalog {
+		if d.Pillar == pillar {
+			defs = append(defs, d)
+		}
+	}
+	return defs
+}
+
+// MetricPoint is a single recorded value of a metric
+type MetricPoint struct {
+	Date  string
+	Value float64
+}
+
+// Value returns the recorded value of the metric with the given key, or nil when it was skipped
+func (m HealthMetrics) Value(key string) *float64 {
+	switch key {
+	case "sleep_score":
+		return intAsFloat(m.SleepScore)
+	case "waist_cm":
+		return m.WaistCm
+	case "body_weight_kg":
+		return m.BodyWeightKg
+	case "rhr":
+		return intAsFloat(m.RHR)
+	case "systolic_bp":
+		return intAsFloat(m.SystolicBP)
+	case "diastolic_bp":
+		return intAsFloat(m.DiastolicBP)
+	case "nutrition_score":
+		return m.NutritionScore
+	}
+	return nil
+}
+
+// Value returns the recorded value of the metric with the given key, or nil when it was skipped
+func (m FitnessMetrics) Value(key string) *float64 {
+	switch key {
+	case "vo2_max":
+		return m.VO2Max
+	case "workouts":
+		return intAsFloat(m.Workouts)
+	case "daily_steps":
+		return intAsFloat(m.DailySteps)
+	case "mobility":
+		return intAsFloat(m.Mobility)
+	case "cardio_recovery":
+		return intAsFloat(m.CardioRecovery)
+	case "lower_body_weight":
+		return m.LowerBodyWeight
+	case "lower_body_reps":
+		return intAsFloat(m.LowerBodyReps)
+	case "dead_hang_seconds":
+		return intAsFloat(m.DeadHangSeconds)
+	}
+	return nil
+}
+
+// Value returns the recorded value of the metric with the given key, or nil when it was skipped
+func (m CognitionMetrics) Value(key string) *float64 {
+	switch key {
+	case "mindfulness":
+		return intAsFloat(m.Mindfulness)
+	case "deep_learning":
+		return intAsFloat(m.DeepLearning)
+	case "stress_score":
+		return intAsFloat(m.StressScore)
+	case "social_days":
+		return intAsFloat(m.SocialDays)
+	}
+	return nil
+}
+
+func intAsFloat(value *int) *float64 {
+	if value == nil {
+		return nil
+	}
+	return Float(float64(*value))
+}
@@ -0,0 +1,54 @@
+package models
+
+import "testing"
+
+func TestMetricCatalogValues(t *testing.T) {
+	health := HealthMetrics{SleepScore: Int(80), WaistCm: Float(85), BodyWeightKg: Float(75), RHR: Int(60), SystolicBP: Int(118), DiastolicBP: Int(76), NutritionScore: Float(7.5)}
+	fitness := FitnessMetrics{VO2Max: Float(42), Workouts: Int(3), DailySteps: Int(9000), Mobility: Int(2), CardioRecovery: Int(25), LowerBodyWeight: Float(180), LowerBodyReps: Int(10), DeadHangSeconds: Int(60)}
+	cognition := CognitionMetrics{Mindfulness: Int(3), DeepLearning: Int(90), StressScore: Int(2), SocialDays: Int(4)}
+
+	for _, metric := range MetricCatalog {
+		var value *float64
+		switch metric.Pillar {
+		case PillarHealth:
+			value = health.Value(metric.Key)
+		case PillarFitness:
+			value = fitness.Value(metric.Key)
+		case PillarCognition:
+			value = cognition.Value(metric.Key)
+		}
+		if value == nil {
+			t.Errorf("Expected catalog metric %s to map to a %s field", metric.Key, metric.Pillar)
+		}
+	}
+
+	if (HealthMetrics{}).Value("sleep_score") != nil {
+		t.Error("Expected a skipped metric to have no value")
+	}
+	if _, ok := LookupMetric("not_a_metric"); ok {
+		t.Error("Expected unknown metrics to be rejected")
+	}
+}
+
+func TestPersonalBaselineStatus(t *testing.T) {
+	rhr, _ := LookupMetric("rhr")
+	sleep, _ := LookupMetric("sleep_score")
+
+	testCases := []struct {
+		name     string
+		baseline PersonalBaseline
+		status   string
+	}{
+		{"lower RHR is better", PersonalBaseline{Metric: rhr, Value: 60, Current: Float(55)}, "better"},
+		{"higher RHR is worse", PersonalBaseline{Metric: rhr, Value: 60, Current: Float(66)}, "worse"},
+		{"higher sleep is better", PersonalBaseline{Metric: sleep, Value: 75, Current: Float(82)}, "better"},
+		{"small change is steady", PersonalBaseline{Metric: sleep, Value: 75, Current: Float(76)}, "steady"},
+		{"no current value", PersonalBaseline{Metric: sleep, Value: 75}, ""},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.baseline.Status(); got != tc.status {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.status, got)
+		}
+	}
+}
@@ -1,47 +1,151 @@
 package models
 
+import "fmt"
+
 // MasterScore represents the calculated overall longevity score
 type MasterScore struct {
-	Date           string
-	Score          float64
-	HealthScore    float64
-	FitnessScore   float64
-	CognitionScore float64
-	AgingTax       float64
+	Date                string           `json:"date"`
+	Score               float64          `json:"score"`
+	HealthScore         float64          `json:"health_score"`
+	FitnessScore        float64          `json:"fitness_score"`
+	CognitionScore      float64          `json:"cognition_score"`
+	AgingTax            float64          `json:"aging_tax"`
+	HealthProvenance    PillarProvenance `json:"health_provenance"`
+	FitnessProvenance   PillarProvenance `json:"fitness_provenance"`
+	CognitionProvenance PillarProvenance `json:"cognition_provenance"`
+	Confidence          float64          `json:"confidence"` // 0-1, lower when pillars are imputed
+	// Note is the user's context for the week, attached by AttachWeekNotes
+	Note *WeekNote `json:"note,omitempty"`
+}
+
+// IsImputed reports whether any pillar of the week was not measured
+func (s MasterScore) IsImputed() bool {
+	return !s.HealthProvenance.IsMeasured() || !s.FitnessProvenance.IsMeasured() || !s.CognitionProvenance.IsMeasured()
+}
+
+const (
+	ProvenanceMeasured       = "measured"
+	ProvenanceCarriedForward = "carried_forward"
+	ProvenanceDrifted        = "drifted"
+)
+
+// PillarProvenance describes where a pillar's weekly values came from
+type PillarProvenance struct {
+	Status      string `json:"status"`
+	MissedWeeks int    `json:"missed_weeks"`
+}
+
+func (p PillarProvenance) IsMeasured() bool {
+	return p.Status == ProvenanceMeasured
+}
+
+// Label returns a short human-readable description, e.g. "drifted 3w"
+func (p PillarProvenance) Label() string {
+	switch p.Status {
+	case ProvenanceMeasured:
+		return "measured"
+	case ProvenanceCarriedForward:
+		return "carried forward"
+	case ProvenanceDrifted:
+		return fmt.Sprintf("drifted %dw", p.MissedWeeks)
+	default:
+		return p.Status
+	}
 }
 
-// HealthMetrics represents the Health Pillar
+// HealthMetrics represents the Health Pillar.
+// Metric fields are optional; nil means the metric was skipped that week.
 type HealthMetrics struct {
 	Date           string
-	SleepScore     int
-	WaistCm        float64
-	BodyWeightKg   float64
-	RHR            int
-	SystolicBP     int
-	DiastolicBP    int
-	NutritionScore float64
+	SleepScore     *int
+	WaistCm        *float64
+	BodyWeightKg   *float64
+	RHR            *int
+	SystolicBP     *int
+	DiastolicBP    *int
+	NutritionScore *float64
 }
 
-// FitnessMetrics represents the Fitness Pillar
+// FitnessMetrics represents the Fitness Pillar.
+// Metric fields are optional; nil means the metric was skipped that week.
 type FitnessMetrics struct {
 	Date            string
-	VO2Max          float64
-	Workouts        int
-	DailySteps      int
-	Mobility        int
-	CardioRecovery  int
-	LowerBodyWeight float64
-	LowerBodyReps   int
-	DeadHangSeconds int
+	VO2Max          *float64
+	Workouts        *int
+	DailySteps      *int
+	Mobility        *int
+	CardioRecovery  *int
+	LowerBodyWeight *float64
+	LowerBodyReps   *int
+	DeadHangSeconds *int
 }
 
-// CognitionMetrics represents the Cognition Pillar
+// CognitionMetrics represents the Cognition Pillar.
+// Metric fields are optional; nil means the metric was skipped that week.
 type CognitionMetrics struct {
 	Date         string
-	Mindfulness  int
-	DeepLearning int
-	StressScore  int
-	SocialDays   int
+	Mindfulness  *int
+	DeepLearning *int
+	StressScore  *int
+	SocialDays   *int
+}
+
+// Int returns a pointer to v, for setting optional metric fields
+func Int(v int) *int {
+	return &v
+}
+
+// Float returns a pointer to v, for setting optional metric fields
+func Float(v float64) *float64 {
+	return &v
+}
+
+// FormatMetric formats an optional metric value with the given verb,
+// returning an empty string when the metric was skipped.
+func FormatMetric(format string, value any) string {
+	switch v := value.(type) {
+	case *int:
+		if v == nil {
+			return ""
+		}
+		return fmt.Sprintf(format, *v)
+	case *float64:
+		if v == nil {
+			return ""
+		}
+		return fmt.Sprintf(format, *v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprintf(format, v)
+	}
+}
+
+// FormatLegPress formats a leg press set as "weightxreps",
+// returning an empty string when the set was skipped.
+func FormatLegPress(weight *float64, reps *int) string {
+	if weight == nil || reps == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.1fx%d", *weight, *reps)
+}
+
+// Ordinal formats n with its English ordinal suffix, e.g. "1st", "12th" or "23rd"
+func Ordinal(n int) string {
+	suffix := "th"
+	switch n % 100 {
+	case 11, 12, 13:
+	default:
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
 }
 
 // UserProfile stores user-specific data for calculations
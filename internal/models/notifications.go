@@ -16,3 +16,10 @@ type PushSubscriptionRequest struct {
 	ReminderTime string           `json:"reminder_time"`
 	Timezone     string           `json:"timezone"`
 }
+
+// PushMessage is the JSON payload the service worker shows as a notification
+type PushMessage struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	URL   string `json:"url"`
+}
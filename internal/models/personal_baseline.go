@@ -0,0 +1,59 @@
+package models
+
+const (
+	BaselineMean   = "mean"
+	BaselineMedian = "median"
+)
+
+// PersonalBaseline is the user's own rolling baseline for one metric
+type PersonalBaseline struct {
+	Metric   MetricDefinition
+	Value    float64
+	Samples  int
+	Excluded int
+	// Window describes the span averaged, e.g. "3 months"
+	Window string
+	Method string
+	// Current is this week's value, if recorded, to compare against the baseline
+	Current *float64
+}
+
+// Delta returns how far the current value sits from the baseline
+func (b PersonalBaseline) Delta() float64 {
+	if b.Current == nil {
+		return 0
+	}
+	return *b.Current - b.Value
+}
+
+// FormattedDelta returns the signed difference to the baseline, e.g. "+2.5" or "-3"
+func (b PersonalBaseline) FormattedDelta() string {
+	delta := b.Delta()
+	if delta < 0 {
+		return "-" + b.Metric.FormatValue(-delta)
+	}
+	return "+" + b.Metric.FormatValue(delta)
+}
+
+// Status classifies the current value against the baseline as "better", "worse" or "steady",
+// taking into account whether lower values are better for the metric.
+func (b PersonalBaseline) Status() string {
+	if b.Current == nil {
+		return ""
+	}
+
+	delta := b.Delta()
+	// Within 2% of the baseline is treated as normal week-to-week noise
+	tolerance := 0.02 * b.Value
+	if tolerance < 0 {
+		tolerance = -tolerance
+	}
+	switch {
+	case delta > tolerance && !b.Metric.LowerIsBetter, delta < -tolerance && b.Metric.LowerIsBetter:
+		return "better"
+	case delta > tolerance, delta < -tolerance:
+		return "worse"
+	default:
+		return "steady"
+	}
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// PromptTemplateSummary names the template of the AI summary prompt
+const PromptTemplateSummary = "summary"
+
+// PromptTemplate is a prompt template saved in the settings, overriding the built-in one
+type PromptTemplate struct {
+	Name      string
+	Body      string
+	UpdatedAt time.Time
+}
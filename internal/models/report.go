@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// ReportTrend summarises how one score moved over the report period
+type ReportTrend struct {
+	Label   string
+	Start   float64
+	End     float64
+	Average float64
+	Weeks   int
+}
+
+// Change returns the difference between the last and first week of the period
+func (t ReportTrend) Change() float64 {
+	return t.End - t.Start
+}
+
+// ReportMarker is a reserve marker's latest reading in the period, next to the
+// typical value for the user's age and their own rolling baseline
+type ReportMarker struct {
+	Metric      MetricDefinition
+	Latest      *float64
+	LatestDate  string
+	AgeBaseline *float64
+	Personal    *PersonalBaseline
+}
+
+// BloodPressureReading is one week's blood pressure; either side may be missing
+type BloodPressureReading struct {
+	Date      string
+	Systolic  *float64
+	Diastolic *float64
+}
+
+// HealthReport is the printable summary of a period for sharing with a doctor
+type HealthReport struct {
+	From         string
+	To           string
+	GeneratedAt  time.Time
+	Profile      UserProfile
+	Age          int
+	CurrentScore *MasterScore
+	Trends       []ReportTrend
+	Markers      []ReportMarker
+	// BloodPressure lists the readings of the period, oldest first
+	BloodPressure    []BloodPressureReading
+	AverageSystolic  *float64
+	AverageDiastolic *float64
+	// Notes are the user's week notes within the period, oldest first
+	Notes []WeekNote
+	// Summary is the latest AI summary in markdown, empty when none was generated
+	Summary            string
+	SummaryGeneratedAt time.Time
+}
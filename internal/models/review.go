@@ -0,0 +1,83 @@
+package models
+
+// ReviewWeek is a week singled out in a review, with the change in total score from the week before
+type ReviewWeek struct {
+	Date   string
+	Score  float64
+	Change float64
+}
+
+// MarkerImprovement compares the first and last reading of a reserve marker within a period
+type MarkerImprovement struct {
+	Metric    MetricDefinition
+	Start     float64
+	End       float64
+	StartDate string
+	EndDate   string
+}
+
+// Change returns the raw difference between the last and first reading
+func (m MarkerImprovement) Change() float64 {
+	return m.End - m.Start
+}
+
+// Improvement returns the relative change in percent, positive when the marker got
+// better, taking into account whether lower values are better for the metric
+func (m MarkerImprovement) Improvement() float64 {
+	if m.Start == 0 {
+		return 0
+	}
+	improvement := (m.End - m.Start) / m.Start * 100
+	if m.Metric.LowerIsBetter {
+		return -improvement
+	}
+	return improvement
+}
+
+// QuarterReview aggregates the weekly scores of one calendar quarter
+type QuarterReview struct {
+	Quarter          int
+	Weeks            int
+	StartScore       float64
+	EndScore         float64
+	AverageScore     float64
+	AverageHealth    float64
+	AverageFitness   float64
+	AverageCognition float64
+	AgingTax         float64
+	CompleteWeeks    int
+	// AverageScoreDelta compares the average score with the previous quarter of the
+	// same year and is nil for the first quarter with data
+	AverageScoreDelta *float64
+}
+
+// ScoreChange returns the change in total score over the quarter
+func (q QuarterReview) ScoreChange() float64 {
+	return q.EndScore - q.StartScore
+}
+
+// YearReview aggregates a calendar year of weekly scores and reserve markers
+type YearReview struct {
+	Year             int
+	Years            []int
+	Weeks            int
+	StartScore       float64
+	EndScore         float64
+	BestWeek         *ReviewWeek
+	WorstWeek        *ReviewWeek
+	AgingTaxPaid     float64
+	AverageHealth    float64
+	AverageFitness   float64
+	AverageCognition float64
+	// CompleteWeeks counts weeks with every pillar measured; LongestStreak is the
+	// longest run of such weeks in a row
+	CompleteWeeks int
+	LongestStreak int
+	Improvements  []MarkerImprovement
+	Quarters      []QuarterReview
+}
+
+// ScoreChange returns the change in total score over the year
+func (r YearReview) ScoreChange() float64 {
+	return r.EndScore - r.StartScore
+}
@@ -0,0 +1,170 @@
+package models
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldRule declares the accepted values of one input field. The same rules constrain
+// the HTML inputs and validate submitted values in the handlers.
+type FieldRule struct {
+	Key      string
+	Label    string
+	Unit     string
+	Min      float64
+	Max      float64
+	Integer  bool
+	Required bool
+}
+
+// Check returns why value breaks the rule, or "" when it is accepted. A nil value means
+// the field was left empty.
+func (r FieldRule) Check(value *float64) string {
+	if value == nil {
+		if r.Required {
+			return r.Label + " is required"
+		}
+		return ""
+	}
+	if r.Integer && *value != math.Trunc(*value) {
+		return r.Label + " must be a whole number"
+	}
+	if *value < r.Min || *value > r.Max {
+		unit := ""
+		if r.Unit != "" {
+			unit = " " + r.Unit
+		}
+		return fmt.Sprintf("%s must be between %s and %s%s", r.Label, formatLimit(r.Min), formatLimit(r.Max), unit)
+	}
+	return ""
+}
+
+// Attributes returns the HTML input constraints of the rule, e.g. `min="0" max="100" step="1"`
+func (r FieldRule) Attributes() string {
+	step := "0.1"
+	if r.Integer {
+		step = "1"
+	}
+	attrs := fmt.Sprintf(`min="%s" max="%s" step="%s"`, formatLimit(r.Min), formatLimit(r.Max), step)
+	if r.Required {
+		attrs += " required"
+	}
+	return attrs
+}
+
+func formatLimit(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// profileFieldRules are the rules of the numeric profile fields. The rules of the
+// metrics come from the MetricCatalog.
+var profileFieldRules = []FieldRule{
+	{Key: "height_cm", Label: "Height", Unit: "cm", Min: 100, Max: 250, Required: true},
+}
+
+// LookupFieldRule returns the rule for the given metric or profile field key
+func LookupFieldRule(key string) (FieldRule, bool) {
+	if metric, ok := LookupMetric(key); ok {
+		return metric.Rule(), true
+	}
+	for _, r := range profileFieldRules {
+		if r.Key == key {
+			return r, true
+		}
+	}
+	return FieldRule{}, false
+}
+
+// FieldErrors maps input names to what is wrong with them. Errors that belong to the
+// whole form rather than one field use the empty key.
+type FieldErrors map[string]string
+
+// Add records message for key, keeping the first error reported for a field
+func (e FieldErrors) Add(key, message string) {
+	if message == "" {
+		return
+	}
+	if _, ok := e[key]; !ok {
+		e[key] = message
+	}
+}
+
+// Merge adds every error of other that is not already reported
+func (e FieldErrors) Merge(other FieldErrors) {
+	for key, message := range other {
+		e.Add(key, message)
+	}
+}
+
+// Error joins the messages in field order so that the errors can be returned as plain text
+func (e FieldErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, key := range slices.Sorted(maps.Keys(e)) {
+		messages = append(messages, e[key])
+	}
+	return strings.Join(messages, ", ")
+}
+
+// validateMetrics checks every metric of a pillar against its rule
+func validateMetrics(pillar string, value func(key string) *float64) FieldErrors {
+	errs := FieldErrors{}
+	for _, metric := range MetricsForPillar(pillar) {
+		errs.Add(metric.Key, metric.Rule().Check(value(metric.Key)))
+	}
+	return errs
+}
+
+// Validate checks the recorded health metrics against their rules
+func (m HealthMetrics) Validate() FieldErrors {
+	errs := validateMetrics(PillarHealth, m.Value)
+	if m.SystolicBP != nil && m.DiastolicBP != nil && *m.DiastolicBP >= *m.SystolicBP {
+		errs.Add("diastolic_bp", "Diastolic BP must be lower than systolic BP")
+	}
+	return errs
+}
+
+// Validate checks the recorded fitness metrics against their rules
+func (m FitnessMetrics) Validate() FieldErrors {
+	return validateMetrics(PillarFitness, m.Value)
+}
+
+// Validate checks the recorded cognition metrics against their rules
+func (m CognitionMetrics) Validate() FieldErrors {
+	return validateMetrics(PillarCognition, m.Value)
+}
+
+// ProfileSexes are the accepted values of UserProfile.Sex
+var ProfileSexes = []string{"male", "female", "neutral"}
+
+// Validate checks that the profile is complete and plausible as of now
+func (p UserProfile) Validate(now time.Time) FieldErrors {
+	errs := FieldErrors{}
+
+	if p.BirthDate == "" {
+		errs.Add("birth_date", "Birth Date is required")
+	} else if birth, err := time.Parse("2006-01-02", p.BirthDate); err != nil {
+		errs.Add("birth_date", "Birth Date must be a date like 1990-01-31")
+	} else if birth.After(now) {
+		errs.Add("birth_date", "Birth Date cannot be in the future")
+	} else if birth.Before(now.AddDate(-120, 0, 0)) {
+		errs.Add("birth_date", "Birth Date must be within the last 120 years")
+	}
+
+	if !slices.Contains(ProfileSexes, p.Sex) {
+		errs.Add("sex", "Biological Sex must be male, female or neutral")
+	}
+
+	rule, _ := LookupFieldRule("height_cm")
+	var height *float64
+	if p.HeightCm != 0 {
+		height = &p.HeightCm
+	}
+	errs.Add("height_cm", rule.Check(height))
+
+	return errs
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFieldRuleCheck(t *testing.T) {
+	sleep, _ := LookupFieldRule("sleep_score")
+	height, _ := LookupFieldRule("height_cm")
+
+	tests := []struct {
+		name     string
+		rule     FieldRule
+		value    *float64
+		expected string
+	}{
+		{name: "empty optional", rule: sleep, value: nil, expected: ""},
+		{name: "in range", rule: sleep, value: Float(80), expected: ""},
+		{name: "above range", rule: sleep, value: Float(300), expected: "Sleep Score must be between 0 and 100"},
+		{name: "fractional integer", rule: sleep, value: Float(80.5), expected: "Sleep Score must be a whole number"},
+		{name: "empty required", rule: height, value: nil, expected: "Height is required"},
+		{name: "unit in message", rule: height, value: Float(18), expected: "Height must be between 100 and 250 cm"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Check(tt.value); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFieldRulesCoverCatalog(t *testing.T) {
+	for _, metric := range MetricCatalog {
+		rule, ok := LookupFieldRule(metric.Key)
+		if !ok || rule.Label != metric.Label {
+			t.Errorf("Expected the validation rule of %s from the catalog, got %+v", metric.Key, rule)
+			continue
+		}
+		if rule.Min > rule.Max {
+			t.Errorf("Expected min <= max for %s, got %v > %v", metric.Key, rule.Min, rule.Max)
+		}
+		// A value outside the accepted range is rejected before the plausible range is checked
+		if metric.Plausible.Max <= metric.Plausible.Min || metric.Plausible.Min < rule.Min || metric.Plausible.Max > rule.Max {
+			t.Errorf("Expected the plausible range of %s within %v–%v, got %+v", metric.Key, rule.Min, rule.Max, metric.Plausible)
+		}
+	}
+}
+
+func TestMetricsValidate(t *testing.T) {
+	health := HealthMetrics{SleepScore: Int(300), SystolicBP: Int(80), DiastolicBP: Int(120), RHR: Int(55)}
+	errs := health.Validate()
+	if len(errs) != 2 || errs["sleep_score"] == "" || errs["diastolic_bp"] == "" {
+		t.Errorf("Expected sleep score and diastolic BP errors, got %v", errs)
+	}
+
+	fitness := FitnessMetrics{DailySteps: Int(-5), Workouts: Int(3)}
+	if errs := fitness.Validate(); len(errs) != 1 || errs["daily_steps"] == "" {
+		t.Errorf("Expected a daily steps error, got %v", errs)
+	}
+
+	cognition := CognitionMetrics{StressScore: Int(42), SocialDays: Int(9), Mindfulness: Int(3)}
+	if errs := cognition.Validate(); len(errs) != 2 || errs["stress_score"] == "" || errs["social_days"] == "" {
+		t.Errorf("Expected stress score and social days errors, got %v", errs)
+	}
+
+	if errs := (CognitionMetrics{}).Validate(); len(errs) != 0 {
+		t.Errorf("Expected skipped metrics to be valid, got %v", errs)
+	}
+}
+
+func TestUserProfileValidate(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	valid := UserProfile{BirthDate: "1990-01-01", Sex: "neutral", HeightCm: 180}
+	if errs := valid.Validate(now); len(errs) != 0 {
+		t.Errorf("Expected a valid profile, got %v", errs)
+	}
+
+	errs := UserProfile{BirthDate: "2030-01-01", Sex: "other", HeightCm: 18}.Validate(now)
+	for _, key := range []string{"birth_date", "sex", "height_cm"} {
+		if errs[key] == "" {
+			t.Errorf("Expected an error for %s, got %v", key, errs)
+		}
+	}
+
+	if errs := (UserProfile{}).Validate(now); !strings.Contains(errs.Error(), "Birth Date is required") || !strings.Contains(errs.Error(), "Height is required") {
+		t.Errorf("Expected required errors, got %q", errs.Error())
+	}
+}
+
+func TestFieldErrorsAdd(t *testing.T) {
+	errs := FieldErrors{}
+	errs.Add("rhr", "first")
+	errs.Add("rhr", "second")
+	errs.Add("sleep_score", "")
+	if len(errs) != 1 || errs["rhr"] != "first" {
+		t.Errorf("Expected only the first rhr error, got %v", errs)
+	}
+}
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"math"
+	"strings"
+)
+
+const (
+	SexMale    = "male"
+	SexFemale  = "female"
+	SexNeutral = "neutral"
+)
+
+// VO2MaxNorm holds the VO2 Max percentile cut-offs (ml/kg/min) for one age
+type VO2MaxNorm struct {
+	Age float64
+	P25 float64
+	P50 float64
+	P75 float64
+	P90 float64
+}
+
+// vo2MaxNorms anchors each table at the midpoint of a decade. The 50th percentile
+// keeps the original bracket baselines, so scoring stays comparable across ages.
+var vo2MaxNorms = map[string][]VO2MaxNorm{
+	SexFemale: {
+		{Age: 25, P25: 34.0, P50: 38.0, P75: 42.0, P90: 46.0},
+		{Age: 35, P25: 32.0, P50: 36.0, P75: 40.0, P90: 44.0},
+		{Age: 45, P25: 29.5, P50: 33.0, P75: 36.5, P90: 40.5},
+		{Age: 55, P25: 26.5, P50: 30.0, P75: 33.5, P90: 37.0},
+		{Age: 65, P25: 24.0, P50: 27.0, P75: 30.0, P90: 33.5},
+		{Age: 75, P25: 21.0, P50: 24.0, P75: 27.0, P90: 30.0},
+	},
+	SexMale: {
+		{Age: 25, P25: 39.0, P50: 44.0, P75: 49.0, P90: 54.0},
+		{Age: 35, P25: 37.0, P50: 42.0, P75: 47.0, P90: 51.5},
+		{Age: 45, P25: 34.5, P50: 39.0, P75: 43.5, P90: 48.0},
+		{Age: 55, P25: 31.5, P50: 36.0, P75: 40.5, P90: 44.5},
+		{Age: 65, P25: 29.0, P50: 33.0, P75: 37.0, P90: 41.0},
+		{Age: 75, P25: 26.0, P50: 30.0, P75: 34.0, P90: 37.5},
+	},
+}
+
+// NormalizeSex maps a profile sex to the norm table it should use.
+// Anything other than "male" or "female" uses the sex-neutral tables.
+func NormalizeSex(sex string) string {
+	switch strings.ToLower(strings.TrimSpace(sex)) {
+	case SexMale:
+		return SexMale
+	case SexFemale:
+		return SexFemale
+	default:
+		return SexNeutral
+	}
+}
+
+// GetVO2MaxNorm returns the percentile cut-offs for the given age, linearly
+// interpolated between the anchor ages and clamped at both ends of the table.
+func GetVO2MaxNorm(age float64, sex string) VO2MaxNorm {
+	normalized := NormalizeSex(sex)
+	if normalized == SexNeutral {
+		male := GetVO2MaxNorm(age, SexMale)
+		female := GetVO2MaxNorm(age, SexFemale)
+		return VO2MaxNorm{
+			Age: age,
+			P25: (male.P25 + female.P25) / 2,
+			P50: (male.P50 + female.P50) / 2,
+			P75: (male.P75 + female.P75) / 2,
+			P90: (male.P90 + female.P90) / 2,
+		}
+	}
+
+	table := vo2MaxNorms[normalized]
+	if age <= table[0].Age {
+		norm := table[0]
+		norm.Age = age
+		return norm
+	}
+	if age >= table[len(table)-1].Age {
+		norm := table[len(table)-1]
+		norm.Age = age
+		return norm
+	}
+
+	for i := 1; i < len(table); i++ {
+		lower, upper := table[i-1], table[i]
+		if age > upper.Age {
+			continue
+		}
+		t := (age - lower.Age) / (upper.Age - lower.Age)
+		return VO2MaxNorm{
+			Age: age,
+			P25: lerp(lower.P25, upper.P25, t),
+			P50: lerp(lower.P50, upper.P50, t),
+			P75: lerp(lower.P75, upper.P75, t),
+			P90: lerp(lower.P90, upper.P90, t),
+		}
+	}
+
+	return table[len(table)-1]
+}
+
+// GetVO2MaxPercentile estimates the population percentile (1-99) of a VO2 Max value
+// for the given age and sex by interpolating between the percentile cut-offs.
+func GetVO2MaxPercentile(age float64, sex string, vo2Max float64) float64 {
+	norm := GetVO2MaxNorm(age, sex)
+	points := []struct{ percentile, value float64 }{
+		{25, norm.P25},
+		{50, norm.P50},
+		{75, norm.P75},
+		{90, norm.P90},
+	}
+
+	var percentile float64
+	switch {
+	case vo2Max <= points[0].value:
+		slope := (points[1].percentile - points[0].percentile) / (points[1].value - points[0].value)
+		percentile = points[0].percentile - (points[0].value-vo2Max)*slope
+	case vo2Max >= points[len(points)-1].value:
+		last, prev := points[len(points)-1], points[len(points)-2]
+		slope := (last.percentile - prev.percentile) / (last.value - prev.value)
+		percentile = last.percentile + (vo2Max-last.value)*slope
+	default:
+		for i := 1; i < len(points); i++ {
+			if vo2Max > points[i].value {
+				continue
+			}
+			t := (vo2Max - points[i-1].value) / (points[i].value - points[i-1].value)
+			percentile = lerp(points[i-1].percentile, points[i].percentile, t)
+			break
+		}
+	}
+
+	return math.Max(1, math.Min(99, percentile))
+}
+
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+// VO2MaxStanding places a VO2 Max value within the norms for the user's age and sex
+type VO2MaxStanding struct {
+	VO2Max     float64
+	Percentile float64
+	Norm       VO2MaxNorm
+}
+
+// OrdinalPercentile returns the rounded percentile as an ordinal, e.g. "51st"
+func (s VO2MaxStanding) OrdinalPercentile() string {
+	return Ordinal(int(math.Round(s.Percentile)))
+}
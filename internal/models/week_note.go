@@ -0,0 +1,78 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	MaxWeekNoteLength = 1000
+	MaxWeekNoteTags   = 8
+	MaxWeekTagLength  = 24
+)
+
+// SuggestedWeekTags are offered when tagging a week; any other tag can be typed as well
+var SuggestedWeekTags = []string{"sick", "travel", "injury", "holiday", "new job", "stressful", "poor sleep", "alcohol"}
+
+// WeekNote is free-text context and tags for one week, like "sick" or "travel", that
+// explains why its metrics look the way they do
+type WeekNote struct {
+	Date string   `json:"date"`
+	Text string   `json:"text"`
+	Tags []string `json:"tags"`
+}
+
+// Empty reports whether the note has neither text nor tags
+func (n WeekNote) Empty() bool {
+	return n.Text == "" && len(n.Tags) == 0
+}
+
+// Summary joins the tags and text on one line, e.g. "[sick, travel] Flu on the road"
+func (n WeekNote) Summary() string {
+	var parts []string
+	if len(n.Tags) > 0 {
+		parts = append(parts, "["+strings.Join(n.Tags, ", ")+"]")
+	}
+	if n.Text != "" {
+		parts = append(parts, strings.Join(strings.Fields(n.Text), " "))
+	}
+	return strings.Join(parts, " ")
+}
+
+// HasTag reports whether the note carries the given tag
+func (n WeekNote) HasTag(tag string) bool {
+	return slices.Contains(n.Tags, tag)
+}
+
+// ParseWeekTags splits comma-separated tags, lower-cased and trimmed, dropping
+// empty and repeated ones
+func ParseWeekTags(raw ...string) []string {
+	var tags []string
+	for _, r := range raw {
+		for _, tag := range strings.Split(r, ",") {
+			tag = strings.ToLower(strings.Join(strings.Fields(tag), " "))
+			if tag != "" && !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// Validate checks the note's length and tags
+func (n WeekNote) Validate() FieldErrors {
+	errs := FieldErrors{}
+	if len([]rune(n.Text)) > MaxWeekNoteLength {
+		errs.Add("note", fmt.Sprintf("Note must be at most %d characters", MaxWeekNoteLength))
+	}
+	if len(n.Tags) > MaxWeekNoteTags {
+		errs.Add("tags", fmt.Sprintf("Use at most %d tags", MaxWeekNoteTags))
+	}
+	for _, tag := range n.Tags {
+		if len([]rune(tag)) > MaxWeekTagLength {
+			errs.Add("tags", fmt.Sprintf("Tags must be at most %d characters", MaxWeekTagLength))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseWeekTags(t *testing.T) {
+	tags := ParseWeekTags("sick", "Travel,  new   job ,,sick", "")
+	expected := []string{"sick", "travel", "new job"}
+	if strings.Join(tags, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected %v, got %v", expected, tags)
+	}
+	if ParseWeekTags("", " , ") != nil {
+		t.Error("Expected no tags from blank input")
+	}
+}
+
+func TestWeekNoteSummaryAndValidate(t *testing.T) {
+	note := WeekNote{Text: "Flu\n\nstayed  home", Tags: []string{"sick", "poor sleep"}}
+	if got := note.Summary(); got != "[sick, poor sleep] Flu stayed home" {
+		t.Errorf("Unexpected summary %q", got)
+	}
+	if errs := note.Validate(); len(errs) != 0 {
+		t.Errorf("Expected a valid note, got %v", errs)
+	}
+
+	long := WeekNote{Text: strings.Repeat("a", MaxWeekNoteLength+1), Tags: []string{strings.Repeat("t", MaxWeekTagLength+1)}}
+	errs := long.Validate()
+	if errs["note"] == "" || errs["tags"] == "" {
+		t.Errorf("Expected errors for the note and the tag, got %v", errs)
+	}
+	if !(WeekNote{}).Empty() {
+		t.Error("Expected a note without text or tags to be empty")
+	}
+}
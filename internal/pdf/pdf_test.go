@@ -0,0 +1,65 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDocumentBytes(t *testing.T) {
+	doc := New()
+	doc.Text(50, 60, 12, true, "Report (draft) \\ 100%")
+	doc.Line(50, 70, 545, 70, 0.5)
+	doc.AddPage()
+	doc.Text(50, 60, 10, false, "Second page — done")
+
+	out := doc.Bytes()
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatalf("Expected a PDF header and trailer, got %q...", out[:20])
+	}
+	if !bytes.Contains(out, []byte("/Count 2")) {
+		t.Error("Expected two pages in the page tree")
+	}
+	if !bytes.Contains(out, []byte(`(Report \(draft\) \\ 100%) Tj`)) {
+		t.Error("Expected parentheses and backslashes to be escaped")
+	}
+	if !bytes.Contains(out, []byte("(Second page \x97 done) Tj")) {
+		t.Error("Expected the em dash to be encoded as WinAnsi")
+	}
+
+	// Every xref entry must point at the start of its object
+	xrefStart := regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(out)
+	if xrefStart == nil {
+		t.Fatal("Expected a startxref entry")
+	}
+	offset, _ := strconv.Atoi(string(xrefStart[1]))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[offset:], -1)
+	if len(entries) != 8 {
+		t.Fatalf("Expected 8 objects for two pages, got %d", len(entries))
+	}
+	for i, entry := range entries {
+		at, _ := strconv.Atoi(string(entry[1]))
+		if !bytes.HasPrefix(out[at:], []byte(fmt.Sprintf("%d 0 obj", i+1))) {
+			t.Errorf("Expected xref entry %d to point at its object", i+1)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	text := strings.Repeat("steady progress ", 20)
+	lines := Wrap(text, 10, 200, false)
+	if len(lines) < 2 {
+		t.Fatalf("Expected the text to wrap, got %v", lines)
+	}
+	for _, line := range lines {
+		if TextWidth(line, 10, false) > 200 {
+			t.Errorf("Expected lines to fit the width, got %q", line)
+		}
+	}
+	if strings.Join(lines, " ") != strings.TrimSpace(text) {
+		t.Error("Expected wrapping to keep every word")
+	}
+}
@@ -0,0 +1,354 @@
+package services
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"health-balance/internal/database"
+	"health-balance/internal/models"
+	"health-balance/internal/utils"
+	"log"
+	"math"
+	"strings"
+	"text/template"
+	"time"
+)
+
+//go:embed prompts/comparison.tmpl
+var builtinComparisonTemplate string
+
+// comparisonTemplate shares the "scoring" template of the built-in summary template
+var comparisonTemplate = template.Must(template.Must(summaryTemplate.Clone()).New("comparison").Parse(builtinComparisonTemplate))
+
+// comparisonPresetWeeks is how many of the latest weeks the first preset compares with the weeks before
+const comparisonPresetWeeks = 12
+
+const noComparisonDataMessage = "Both periods need at least one week of scores to compare them."
+
+// ComparisonPromptData is what the comparison prompt template is rendered with. Everything
+// in it already respects the AI privacy settings.
+type ComparisonPromptData struct {
+	Person   string
+	Scoring  PromptScoringModel
+	Baseline PromptPeriod
+	Current  PromptPeriod
+	Scores   []PromptChange
+	Pillars  []PromptPillarChanges
+	Format   string
+}
+
+// PromptPeriod describes the weeks of one period of a comparison
+type PromptPeriod struct {
+	From          string
+	To            string
+	Weeks         int
+	CompleteWeeks int
+	StartScore    float64
+	EndScore      float64
+	// Notes are the period's week notes, e.g. "2026-03-01: [travel] Conference", empty when hidden
+	Notes []string
+}
+
+// PromptPillarChanges are the changes of the shared metrics of one pillar
+type PromptPillarChanges struct {
+	Label   string
+	Metrics []PromptChange
+}
+
+// PromptChange compares the weekly average of a score or metric between the periods, e.g.
+// Baseline "62 bpm (8 weeks)", Current "58 bpm (10 weeks)", Change "-4 bpm" and
+// Effect "effect size -0.91 (large, better)"
+type PromptChange struct {
+	Label    string
+	Baseline string
+	Current  string
+	Change   string
+	Effect   string
+}
+
+// ComparisonPresets returns the comparisons offered on the comparison form as of now: the
+// latest weeks against the weeks before, this quarter and this year against the same
+// period a year earlier, and the weeks before every intervention against the weeks during it
+func ComparisonPresets(db database.Querier, now time.Time) ([]models.ComparisonPreset, error) {
+	date := func(t time.Time) string { return t.Format("2006-01-02") }
+	period := func(from, to time.Time) models.Period { return models.Period{From: date(from), To: date(to)} }
+
+	sunday := utils.WeekEnding(now)
+	quarterStart := time.Date(now.Year(), (now.Month()-1)/3*3+1, 1, 0, 0, 0, 0, now.Location())
+	yearStart := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+
+	presets := []models.ComparisonPreset{
+		{
+			Label:    fmt.Sprintf("Last %d weeks vs the %d weeks before", comparisonPresetWeeks, comparisonPresetWeeks),
+			Baseline: period(sunday.AddDate(0, 0, -14*comparisonPresetWeeks+1), sunday.AddDate(0, 0, -7*comparisonPresetWeeks)),
+			Current:  period(sunday.AddDate(0, 0, -7*comparisonPresetWeeks+1), sunday),
+		},
+		{
+			Label:    "This quarter vs the same quarter last year",
+			Baseline: period(quarterStart.AddDate(-1, 0, 0), quarterStart.AddDate(-1, 3, -1)),
+			Current:  period(quarterStart, quarterStart.AddDate(0, 3, -1)),
+		},
+		{
+			Label:    "This year vs last year",
+			Baseline: period(yearStart.AddDate(-1, 0, 0), yearStart.AddDate(0, 0, -1)),
+			Current:  period(yearStart, yearStart.AddDate(1, 0, -1)),
+		},
+	}
+
+	interventions, err := db.GetInterventions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch interventions: %w", err)
+	}
+	for _, intervention := range interventions {
+		before, during, err := interventionPeriods(intervention, now)
+		if err != nil {
+			return nil, err
+		}
+		presets = append(presets, models.ComparisonPreset{Label: "Before vs during " + intervention.Name, Baseline: before, Current: during})
+	}
+	return presets, nil
+}
+
+// CompareHealthPeriods asks the configured language model how the current period compares
+// with the baseline period, within the configured timeout and budget
+func CompareHealthPeriods(ctx context.Context, db database.Querier, baseline, current models.Period, regenerate bool) (*models.AIComparison, error) {
+	cfg := LoadLLMConfig()
+	provider, err := NewLLMProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	provider = MeterLLMProvider(provider, db, cfg, models.AIFeatureComparison)
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+	return ComparePeriods(ctx, db, provider, baseline, current, regenerate)
+}
+
+// ComparePeriods builds the comparison prompt from aggregates of both periods and sends it
+// to provider. Like summaries, the stored comparison for the same prompt and model is
+// returned instead unless regenerate is set, and new comparisons are stored.
+func ComparePeriods(ctx context.Context, db database.Querier, provider LLMProvider, baseline, current models.Period, regenerate bool) (*models.AIComparison, error) {
+	privacy, err := db.GetAIPrivacy()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch AI privacy settings: %w", err)
+	}
+	prompt, err := buildComparisonPrompt(db, privacy, baseline, current, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if prompt == "" {
+		return &models.AIComparison{Baseline: baseline, Current: current, Text: noComparisonDataMessage}, nil
+	}
+
+	hash := promptHash(prompt)
+	if !regenerate {
+		cached, err := db.GetAIComparisonByPrompt(hash, provider.Name(), provider.Model())
+		if err != nil {
+			log.Printf("Error loading cached AI comparison: %v", err)
+		} else if cached != nil {
+			cached.Cached = true
+			return cached, nil
+		}
+	}
+
+	req := UserPrompt(prompt)
+	req.JSON = true
+	resp, err := provider.Generate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	text, err := validateSummary(ctx, provider, req, resp.Text)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := models.AIComparison{
+		Baseline:   baseline,
+		Current:    current,
+		Provider:   provider.Name(),
+		Model:      provider.Model(),
+		PromptHash: hash,
+		Text:       text,
+		CreatedAt:  time.Now(),
+	}
+	if comparison.ID, err = db.SaveAIComparison(comparison); err != nil {
+		// The user still gets the comparison, it just won't be cached
+		log.Printf("Error saving AI comparison: %v", err)
+	}
+	return &comparison, nil
+}
+
+// buildComparisonPrompt renders the comparison prompt as of now, or returns an empty prompt
+// when either period has no scores
+func buildComparisonPrompt(db database.Querier, privacy models.AIPrivacy, baseline, current models.Period, now time.Time) (string, error) {
+	profile, err := db.GetUserProfile()
+	if err != nil || profile == nil {
+		return "", fmt.Errorf("user profile required for comparison")
+	}
+	scores, err := GetAllWeeklyScores(db)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch scores: %v", err)
+	}
+
+	var baselineScores, currentScores []models.MasterScore
+	for _, s := range scores {
+		switch {
+		case baseline.Contains(s.Date):
+			baselineScores = append(baselineScores, s)
+		case current.Contains(s.Date):
+			currentScores = append(currentScores, s)
+		}
+	}
+	if len(baselineScores) == 0 || len(currentScores) == 0 {
+		return "", nil
+	}
+
+	age, _ := utils.GetAge(profile, now)
+	data := ComparisonPromptData{
+		Person:  privacy.Person(age, profile),
+		Scoring: newPromptScoringModel(age, privacy),
+		Format:  summaryFormat,
+	}
+	if data.Baseline, err = newPromptPeriod(db, baseline, baselineScores, privacy); err != nil {
+		return "", err
+	}
+	if data.Current, err = newPromptPeriod(db, current, currentScores, privacy); err != nil {
+		return "", err
+	}
+
+	for _, key := range interventionScoreSeries {
+		definition, _ := lookupScoreSeries(key)
+		var before, after []float64
+		for _, s := range baselineScores {
+			before = append(before, s.ScoreValue(key))
+		}
+		for _, s := range currentScores {
+			after = append(after, s.ScoreValue(key))
+		}
+		data.Scores = append(data.Scores, newPromptChange(compareInterventionValues(definition, before, after), privacy))
+	}
+
+	for _, pillar := range models.Pillars {
+		changes := PromptPillarChanges{Label: strings.ToUpper(pillar[:1]) + pillar[1:]}
+		for _, m := range models.MetricsForPillar(pillar) {
+			if !privacy.Shares(m.Key) {
+				continue
+			}
+			before, err := periodValues(db, m.Key, baseline)
+			if err != nil {
+				return "", err
+			}
+			after, err := periodValues(db, m.Key, current)
+			if err != nil {
+				return "", err
+			}
+			if len(before) == 0 && len(after) == 0 {
+				continue
+			}
+			changes.Metrics = append(changes.Metrics, newPromptChange(compareInterventionValues(m, before, after), privacy))
+		}
+		if len(changes.Metrics) > 0 {
+			data.Pillars = append(data.Pillars, changes)
+		}
+	}
+
+	var prompt strings.Builder
+	if err := comparisonTemplate.Execute(&prompt, data); err != nil {
+		return "", fmt.Errorf("failed to render the comparison prompt: %w", err)
+	}
+	return prompt.String(), nil
+}
+
+// newPromptPeriod describes the scored weeks of a period, oldest first, and its notes
+func newPromptPeriod(db database.Querier, period models.Period, scores []models.MasterScore, privacy models.AIPrivacy) (PromptPeriod, error) {
+	p := PromptPeriod{
+		From:       period.From,
+		To:         period.To,
+		Weeks:      len(scores),
+		StartScore: scores[0].Score,
+		EndScore:   scores[len(scores)-1].Score,
+	}
+	for _, s := range scores {
+		if !s.IsImputed() {
+			p.CompleteWeeks++
+		}
+	}
+
+	if privacy.HideNotes {
+		return p, nil
+	}
+	notes, err := db.GetWeekNotes(period.From, period.To)
+	if err != nil {
+		return p, fmt.Errorf("failed to fetch week notes: %w", err)
+	}
+	for _, n := range notes {
+		if !n.Empty() {
+			p.Notes = append(p.Notes, n.Date+": "+n.Summary())
+		}
+	}
+	return p, nil
+}
+
+// periodValues returns the recorded weekly values of the metric within the period
+func periodValues(db database.Querier, key string, period models.Period) ([]float64, error) {
+	points, err := db.GetMetricSeries(key, period.From, period.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s series: %w", key, err)
+	}
+	values := make([]float64, 0, len(points))
+	for _, p := range points {
+		values = append(values, p.Value)
+	}
+	return values, nil
+}
+
+// newPromptChange formats the averages of both periods as they are sent. When coarsening,
+// the change is taken between the rounded averages so it gives nothing more away.
+func newPromptChange(effect models.InterventionEffect, privacy models.AIPrivacy) PromptChange {
+	m := effect.Metric
+	// Averages of metrics recorded as whole numbers, like workouts, keep a decimal
+	format := m.Format
+	if m.Integer {
+		format = "%.1f"
+	}
+	change := PromptChange{
+		Label:    m.Label,
+		Baseline: formatPromptMetric(m, format, effect.BeforeMean, privacy),
+		Current:  formatPromptMetric(m, format, effect.AfterMean, privacy),
+	}
+	if effect.BeforeMean != nil {
+		change.Baseline += fmt.Sprintf(" (%d weeks)", effect.BeforeWeeks)
+	}
+	if effect.AfterMean != nil {
+		change.Current += fmt.Sprintf(" (%d weeks)", effect.AfterWeeks)
+	}
+
+	before, coarsened := privacy.MetricValue(m.Key, effect.BeforeMean)
+	after, _ := privacy.MetricValue(m.Key, effect.AfterMean)
+	if before != nil && after != nil {
+		if coarsened {
+			format = "%.0f"
+		}
+		difference := *after - *before
+		sign := "+"
+		if difference < 0 {
+			sign = "-"
+		}
+		change.Change = sign + models.FormatMetric(format, models.Float(math.Abs(difference)))
+		if m.Unit != "" {
+			change.Change += " " + m.Unit
+		}
+	}
+
+	if effect.EffectSize == nil {
+		change.Effect = "no effect size"
+		return change
+	}
+	direction := "worse"
+	if effect.Improved() {
+		direction = "better"
+	}
+	change.Effect = fmt.Sprintf("effect size %+.2f (%s, %s)", *effect.EffectSize, effect.Magnitude(), direction)
+	return change
+}
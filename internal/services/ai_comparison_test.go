@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"health-balance/internal/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	comparisonBaseline = models.Period{From: "2026-01-01", To: "2026-01-31"}
+	comparisonCurrent  = models.Period{From: "2026-02-01", To: "2026-02-28"}
+)
+
+// comparisonTestDB has four weeks in January and four in February, with a lower resting
+// heart rate in February
+func comparisonTestDB() *MockDB {
+	db := &MockDB{
+		UserProfile:  &models.UserProfile{BirthDate: "1980-01-01", Sex: "female", HeightCm: 168},
+		HealthMap:    map[string]*models.HealthMetrics{},
+		FitnessMap:   map[string]*models.FitnessMetrics{},
+		CognitionMap: map[string]*models.CognitionMetrics{},
+		WeekNotes:    []models.WeekNote{{Date: "2026-02-15", Tags: []string{"travel"}, Text: "Conference"}},
+	}
+	for i, date := range []string{"2026-01-04", "2026-01-11", "2026-01-18", "2026-01-25", "2026-02-01", "2026-02-08", "2026-02-15", "2026-02-22"} {
+		rhr := 64 - i%2
+		if date >= comparisonCurrent.From {
+			rhr -= 6
+		}
+		// Newest first
+		db.AllDates = append([]string{date}, db.AllDates...)
+		db.HealthMap[date] = &models.HealthMetrics{Date: date, RHR: models.Int(rhr)}
+		db.FitnessMap[date] = &models.FitnessMetrics{Date: date, Workouts: models.Int(3 + i%2)}
+		db.CognitionMap[date] = &models.CognitionMetrics{Date: date, Mindfulness: models.Int(2)}
+	}
+	return db
+}
+
+func TestBuildComparisonPrompt(t *testing.T) {
+	db := comparisonTestDB()
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+
+	prompt, err := buildComparisonPrompt(db, models.AIPrivacy{}, comparisonBaseline, comparisonCurrent, now)
+	if err != nil {
+		t.Fatalf("Failed to build the prompt: %v", err)
+	}
+	for _, want := range []string{
+		"for a 46-year-old female (height: 168.0 cm)",
+		"   - WHtR (Waist-to-Height Ratio): 0.48 or lower\n",
+		"Baseline period: 2026-01-01 to 2026-01-31: 4 weeks with scores, 4 with all three pillars logged",
+		"Current period: 2026-02-01 to 2026-02-28: 4 weeks with scores",
+		"- **Resting Heart Rate**: 63.5 bpm (4 weeks) → 57.5 bpm (4 weeks) | Change: -6.0 bpm | effect size ",
+		"(large, better)",
+		"- **Workouts**: 3.5 (4 weeks) → 3.5 (4 weeks) | Change: +0.0 | effect size +0.00 (negligible, worse)",
+		"User notes in the current period:\n- 2026-02-15: [travel] Conference",
+		`"recommendations": [`,
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected %q in the prompt:\n%s", want, prompt)
+		}
+	}
+	if strings.Contains(prompt, "Week of") || strings.Contains(prompt, "baseline period:\n") {
+		t.Errorf("Expected aggregates instead of weekly rows:\n%s", prompt)
+	}
+
+	private := models.AIPrivacy{Coarsen: true, HideNotes: true, HiddenMetrics: []string{"workouts"}}
+	prompt, err = buildComparisonPrompt(db, private, comparisonBaseline, comparisonCurrent, now)
+	if err != nil {
+		t.Fatalf("Failed to build the private prompt: %v", err)
+	}
+	if !strings.Contains(prompt, "- **Resting Heart Rate**: ~65 bpm (4 weeks) → ~60 bpm (4 weeks) | Change: -5 bpm") {
+		t.Errorf("Expected coarsened averages and change:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "**Workouts**") || strings.Contains(prompt, "Conference") || strings.Contains(prompt, "weekly decay rate") {
+		t.Errorf("Expected hidden metrics and notes left out:\n%s", prompt)
+	}
+
+	prompt, err = buildComparisonPrompt(db, models.AIPrivacy{}, models.Period{From: "2025-01-01", To: "2025-12-31"}, comparisonCurrent, now)
+	if err != nil || prompt != "" {
+		t.Errorf("Expected no prompt for a period without scores, got %q (%v)", prompt, err)
+	}
+}
+
+func TestComparePeriods(t *testing.T) {
+	db := comparisonTestDB()
+	provider := &FakeLLMProvider{}
+
+	comparison, err := ComparePeriods(context.Background(), db, provider, comparisonBaseline, comparisonCurrent, false)
+	if err != nil {
+		t.Fatalf("Failed to compare periods: %v", err)
+	}
+	if comparison.ID != 1 || comparison.Cached || comparison.Current != comparisonCurrent || comparison.Baseline != comparisonBaseline {
+		t.Errorf("Expected a new stored comparison, got %+v", comparison)
+	}
+	if _, err := comparison.Insights(); err != nil {
+		t.Errorf("Expected structured insights, got %v", err)
+	}
+	if requests := provider.Requests(); len(requests) != 1 || !requests[0].JSON {
+		t.Fatalf("Expected one JSON request, got %+v", requests)
+	}
+
+	comparison, err = ComparePeriods(context.Background(), db, provider, comparisonBaseline, comparisonCurrent, false)
+	if err != nil || !comparison.Cached || len(provider.Requests()) != 1 {
+		t.Errorf("Expected the stored comparison served, got %+v (%v)", comparison, err)
+	}
+	comparison, err = ComparePeriods(context.Background(), db, provider, comparisonBaseline, comparisonCurrent, true)
+	if err != nil || comparison.Cached || comparison.ID != 2 {
+		t.Errorf("Expected a new comparison when regenerating, got %+v (%v)", comparison, err)
+	}
+
+	comparison, err = ComparePeriods(context.Background(), db, provider, models.Period{From: "2025-01-01", To: "2025-01-31"}, comparisonCurrent, false)
+	if err != nil || comparison.Text != noComparisonDataMessage || len(provider.Requests()) != 2 {
+		t.Errorf("Expected no request without scores, got %+v (%v)", comparison, err)
+	}
+}
+
+func TestComparisonPresets(t *testing.T) {
+	db := &MockDB{Interventions: []models.Intervention{{ID: 1, Name: "No alcohol", StartDate: "2026-04-06", EndDate: "2026-05-03"}}}
+	// Wednesday in the second quarter
+	presets, err := ComparisonPresets(db, time.Date(2026, 5, 13, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Failed to build presets: %v", err)
+	}
+
+	want := []models.ComparisonPreset{
+		{Baseline: models.Period{From: "2025-12-01", To: "2026-02-22"}, Current: models.Period{From: "2026-02-23", To: "2026-05-17"}},
+		{Baseline: models.Period{From: "2025-04-01", To: "2025-06-30"}, Current: models.Period{From: "2026-04-01", To: "2026-06-30"}},
+		{Baseline: models.Period{From: "2025-01-01", To: "2025-12-31"}, Current: models.Period{From: "2026-01-01", To: "2026-12-31"}},
+		{Baseline: models.Period{From: "2026-03-09", To: "2026-04-05"}, Current: models.Period{From: "2026-04-06", To: "2026-05-03"}},
+	}
+	if len(presets) != len(want) {
+		t.Fatalf("Expected %d presets, got %+v", len(want), presets)
+	}
+	for i, p := range presets {
+		if p.Baseline != want[i].Baseline || p.Current != want[i].Current {
+			t.Errorf("%s: expected %v vs %v, got %v vs %v", p.Label, want[i].Current, want[i].Baseline, p.Current, p.Baseline)
+		}
+	}
+	if presets[3].Label != "Before vs during No alcohol" {
+		t.Errorf("Unexpected intervention preset %q", presets[3].Label)
+	}
+}
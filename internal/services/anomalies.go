@@ -0,0 +1,102 @@
+package services
+
+import (
+	"fmt"
+	"health-balance/internal/database"
+	"health-balance/internal/models"
+	"health-balance/internal/utils"
+	"math"
+	"time"
+)
+
+const (
+	// Submitted values are compared against about a year of weekly history
+	anomalyHistoryWeeks = 52
+	// Robust z-scores beyond this are flagged, following Iglewicz and Hoaglin
+	anomalyZThreshold = 3.5
+)
+
+// CheckAnomalies compares the submitted values of a pillar for the week of asOf against
+// the physiological bounds and the user's own history, returning the values that look
+// like data entry mistakes. The week itself is left out of the history so that
+// re-submitting a week is judged against the weeks around it.
+func CheckAnomalies(db database.Querier, pillar string, asOf time.Time, value func(key string) *float64) ([]models.Anomaly, error) {
+	week := asOf.Format("2006-01-02")
+	from := asOf.AddDate(0, 0, -7*anomalyHistoryWeeks).Format("2006-01-02")
+
+	var anomalies []models.Anomaly
+	for _, metric := range models.MetricsForPillar(pillar) {
+		v := value(metric.Key)
+		if v == nil {
+			continue
+		}
+
+		if !metric.Plausible.Contains(*v) {
+			anomalies = append(anomalies, models.Anomaly{Metric: metric, Value: *v, Kind: models.AnomalyOutOfRange})
+			continue
+		}
+
+		points, err := db.GetMetricSeries(metric.Key, from, week)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s history: %w", metric.Key, err)
+		}
+		history := make([]float64, 0, len(points))
+		for _, p := range points {
+			if p.Date != week {
+				history = append(history, p.Value)
+			}
+		}
+
+		if center, z, ok := robustZScore(history, *v); ok && math.Abs(z) > anomalyZThreshold {
+			anomalies = append(anomalies, models.Anomaly{Metric: metric, Value: *v, Kind: models.AnomalyUnusual, Median: center, ZScore: z})
+		}
+	}
+	return anomalies, nil
+}
+
+// GetOutlierFlags marks the stored values of a pillar that are outside the physiological
+// bounds or far from the rest of the metric's history, keyed by week and metric key
+func GetOutlierFlags(db database.Querier, pillar string) (models.OutlierFlags, error) {
+	flags := models.OutlierFlags{}
+	for _, metric := range models.MetricsForPillar(pillar) {
+		points, err := db.GetMetricSeries(metric.Key, "", utils.GetCurrentWeekSundayDate())
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s history: %w", metric.Key, err)
+		}
+
+		values := make([]float64, len(points))
+		for i, p := range points {
+			values[i] = p.Value
+		}
+
+		for _, p := range points {
+			_, z, ok := robustZScore(values, p.Value)
+			if !metric.Plausible.Contains(p.Value) || (ok && math.Abs(z) > anomalyZThreshold) {
+				if flags[p.Date] == nil {
+					flags[p.Date] = map[string]bool{}
+				}
+				flags[p.Date][metric.Key] = true
+			}
+		}
+	}
+	return flags, nil
+}
+
+// robustZScore measures how far value is from the median of history in scaled median
+// absolute deviations. It reports false when the history is too short or has no spread.
+func robustZScore(history []float64, value float64) (float64, float64, bool) {
+	if len(history) < minOutlierSamples {
+		return 0, 0, false
+	}
+
+	center := median(history)
+	deviations := make([]float64, len(history))
+	for i, v := range history {
+		deviations[i] = math.Abs(v - center)
+	}
+	spread := median(deviations) * madToStdDev
+	if spread == 0 {
+		return center, 0, false
+	}
+	return center, (value - center) / spread, true
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"health-balance/internal/models"
+	"math"
+	"testing"
+	"time"
+)
+
+func anomalyTestDB(rhr []int) *MockDB {
+	db := &MockDB{HealthMap: map[string]*models.HealthMetrics{}}
+	start := time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC)
+	for i := range rhr {
+		week := start.AddDate(0, 0, 7*i).Format("2006-01-02")
+		db.HealthMap[week] = &models.HealthMetrics{Date: week, RHR: &rhr[i]}
+	}
+	return db
+}
+
+func TestCheckAnomalies(t *testing.T) {
+	db := anomalyTestDB([]int{55, 57, 54, 56, 58, 55, 53, 56})
+	asOf := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		values   map[string]float64
+		expected map[string]string
+	}{
+		{name: "usual values", values: map[string]float64{"rhr": 57, "sleep_score": 80}, expected: map[string]string{}},
+		{name: "outside physiological bounds", values: map[string]float64{"rhr": 5, "waist_cm": 8.5}, expected: map[string]string{"rhr": models.AnomalyOutOfRange, "waist_cm": models.AnomalyOutOfRange}},
+		{name: "far from personal history", values: map[string]float64{"rhr": 85}, expected: map[string]string{"rhr": models.AnomalyUnusual}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anomalies, err := CheckAnomalies(db, models.PillarHealth, asOf, func(key string) *float64 {
+				if v, ok := tt.values[key]; ok {
+					return &v
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(anomalies) != len(tt.expected) {
+				t.Fatalf("Expected %d anomalies, got %+v", len(tt.expected), anomalies)
+			}
+			for _, a := range anomalies {
+				if tt.expected[a.Metric.Key] != a.Kind {
+					t.Errorf("Expected %s to be %q, got %q", a.Metric.Key, tt.expected[a.Metric.Key], a.Kind)
+				}
+				if a.Message() == "" {
+					t.Errorf("Expected a message for %s", a.Metric.Key)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckAnomaliesNeedsHistory(t *testing.T) {
+	db := anomalyTestDB([]int{55, 56})
+	rhr := 85.0
+	anomalies, err := CheckAnomalies(db, models.PillarHealth, time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC), func(key string) *float64 {
+		if key == "rhr" {
+			return &rhr
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(anomalies) != 0 {
+		t.Errorf("Expected no personal comparison with two weeks of history, got %+v", anomalies)
+	}
+}
+
+func TestGetOutlierFlags(t *testing.T) {
+	db := anomalyTestDB([]int{55, 57, 54, 5, 56, 58, 55, 90, 53})
+
+	flags, err := GetOutlierFlags(db, models.PillarHealth)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !flags["2025-01-26"]["rhr"] || !flags["2025-02-23"]["rhr"] {
+		t.Errorf("Expected the 5 and 90 bpm weeks to be flagged, got %v", flags)
+	}
+	if len(flags) != 2 {
+		t.Errorf("Expected only the two outlier weeks to be flagged, got %v", flags)
+	}
+}
+
+func TestRobustZScore(t *testing.T) {
+	center, z, ok := robustZScore([]float64{10, 12, 11, 13, 9}, 21)
+	if !ok || center != 11 || math.Abs(z-10/1.4826) > 1e-9 {
+		t.Errorf("Expected median 11 and z %.3f, got %.1f, %.3f, %v", 10/1.4826, center, z, ok)
+	}
+
+	if _, _, ok := robustZScore([]float64{10, 10, 10, 10, 10}, 12); ok {
+		t.Error("Expected no z-score without spread")
+	}
+}
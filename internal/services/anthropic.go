@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const anthropicAPIVersion = "2023-06-01"
+
+// AnthropicProvider calls the Anthropic messages API
+type AnthropicProvider struct {
+	apiKey  string
+	model   string
+	baseURL string
+	client  *http.Client
+}
+
+type AnthropicRequest struct {
+	Model     string             `json:"model"`
+	System    string             `json:"system,omitempty"`
+	Messages  []AnthropicMessage `json:"messages"`
+	MaxTokens int                `json:"max_tokens"`
+	Stream    bool               `json:"stream,omitempty"`
+}
+
+type AnthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type AnthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Usage struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// AnthropicStreamEvent is one event of a streamed messages response
+type AnthropicStreamEvent struct {
+	Type    string `json:"type"`
+	Message struct {
+		Usage struct {
+			InputTokens int `json:"input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+	Usage struct {
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (p *AnthropicProvider) Name() string  { return LLMProviderAnthropic }
+func (p *AnthropicProvider) Model() string { return p.model }
+
+func (p *AnthropicProvider) Generate(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	var resp AnthropicResponse
+	if err := postLLMJSON(ctx, p.client, "Anthropic", p.baseURL+"/messages", p.headers(), p.request(req), &resp); err != nil {
+		return nil, err
+	}
+
+	var text strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return nil, fmt.Errorf("empty response from Anthropic API")
+	}
+	return &LLMResponse{
+		Text:  text.String(),
+		Usage: LLMUsage{InputTokens: resp.Usage.InputTokens, OutputTokens: resp.Usage.OutputTokens},
+	}, nil
+}
+
+func (p *AnthropicProvider) Stream(ctx context.Context, req LLMRequest, onText func(string) error) (*LLMResponse, error) {
+	body := p.request(req)
+	body.Stream = true
+
+	var (
+		text  strings.Builder
+		usage LLMUsage
+	)
+	err := streamLLM(ctx, p.client, "Anthropic", p.baseURL+"/messages", p.headers(), body, func(_, data string) error {
+		var event AnthropicStreamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return fmt.Errorf("failed to unmarshal Anthropic stream event: %w", err)
+		}
+		switch event.Type {
+		case "message_start":
+			usage.InputTokens = event.Message.Usage.InputTokens
+		case "content_block_delta":
+			if event.Delta.Type == "text_delta" && event.Delta.Text != "" {
+				text.WriteString(event.Delta.Text)
+				return onText(event.Delta.Text)
+			}
+		case "message_delta":
+			usage.OutputTokens = event.Usage.OutputTokens
+		case "error":
+			return fmt.Errorf("Anthropic API stream error (%s): %s", event.Error.Type, event.Error.Message)
+		}
+		return nil
+	})
+	resp := &LLMResponse{Text: text.String(), Usage: usage}
+	if err != nil {
+		return resp, err
+	}
+
+	if text.Len() == 0 {
+		return resp, fmt.Errorf("empty response from Anthropic API")
+	}
+	return resp, nil
+}
+
+func (p *AnthropicProvider) headers() map[string]string {
+	return map[string]string{"x-api-key": p.apiKey, "anthropic-version": anthropicAPIVersion}
+}
+
+func (p *AnthropicProvider) request(req LLMRequest) AnthropicRequest {
+	body := AnthropicRequest{Model: p.model, System: req.System, MaxTokens: req.MaxTokens}
+	if body.MaxTokens <= 0 {
+		body.MaxTokens = defaultAnthropicMaxTokens
+	}
+	for _, m := range req.Messages {
+		body.Messages = append(body.Messages, AnthropicMessage{Role: m.Role, Content: m.Content})
+	}
+	return body
+}
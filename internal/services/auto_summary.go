@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"health-balance/internal/database"
+	"health-balance/internal/models"
+	"health-balance/internal/utils"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// autoSummaryRetry is how long a failed automatic summary waits before it is tried again,
+// so a failing provider isn't called every minute
+const autoSummaryRetry = time.Hour
+
+// autoSummaryJob generates the weekly AI summary for the notification scheduler once it is
+// due, reusing the stored summary when the data hasn't changed since the last one
+type autoSummaryJob struct {
+	db database.Querier
+	// summarize generates the summary, GetHealthSummary outside of tests
+	summarize   func(ctx context.Context) (*models.AISummary, error)
+	lastAttempt time.Time
+	// running is set while a summary is being generated, so a slow call or a week that
+	// fails to be recorded doesn't start another one alongside it
+	running atomic.Bool
+}
+
+func newAutoSummaryJob(db database.Querier) *autoSummaryJob {
+	return &autoSummaryJob{
+		db: db,
+		summarize: func(ctx context.Context) (*models.AISummary, error) {
+			return GetHealthSummary(ctx, db, false, nil)
+		},
+	}
+}
+
+// check starts generating the summary in the background when it is due and none is being
+// generated. It is called every minute from the scheduler goroutine, which must not block
+// on the model.
+func (j *autoSummaryJob) check(now time.Time) {
+	if j.running.Load() {
+		return
+	}
+	week, due := j.due(now)
+	if !due {
+		return
+	}
+	j.lastAttempt = now
+	j.running.Store(true)
+	go func() {
+		defer j.running.Store(false)
+		j.generate(week)
+	}()
+}
+
+// due reports whether the summary for the returned week should be generated now
+func (j *autoSummaryJob) due(now time.Time) (string, bool) {
+	settings, err := j.db.GetAutoSummarySettings()
+	if err != nil {
+		log.Printf("Scheduler error: failed to get automatic summary settings: %v", err)
+		return "", false
+	}
+	if !settings.Enabled || now.Sub(j.lastAttempt) < autoSummaryRetry {
+		return "", false
+	}
+
+	switch settings.Trigger {
+	case models.AutoSummaryScheduled:
+		at := settings.ScheduledAt(now)
+		week := utils.WeekEnding(at).Format("2006-01-02")
+		return week, week != settings.LastWeek && !now.Before(at)
+	default:
+		week := utils.WeekEnding(now).Format("2006-01-02")
+		if week == settings.LastWeek {
+			return "", false
+		}
+		h, _ := j.db.GetHealthMetricsByDate(week)
+		f, _ := j.db.GetFitnessMetricsByDate(week)
+		c, _ := j.db.GetCognitionMetricsByDate(week)
+		return week, h != nil && f != nil && c != nil
+	}
+}
+
+// generate creates the summary, records the week and pushes a notification linking to it
+func (j *autoSummaryJob) generate(week string) {
+	summary, err := j.summarize(context.Background())
+	if err != nil {
+		log.Printf("Scheduler error: automatic AI summary for week %s failed, retrying in an hour: %v", week, err)
+		return
+	}
+	if err := j.db.SetAutoSummaryWeek(week); err != nil {
+		log.Printf("Scheduler error: failed to record automatic summary week: %v", err)
+		return
+	}
+	log.Printf("Scheduler: Generated automatic AI summary for week %s", week)
+	sendPushToAll(j.db, autoSummaryMessage(summary))
+}
+
+// autoSummaryMessage announces a summary with its overview, linking to it in the history
+func autoSummaryMessage(summary *models.AISummary) models.PushMessage {
+	message := models.PushMessage{Title: "Your weekly AI summary is ready", Body: "See this week's bottlenecks and recommendations.", URL: "/ai-summaries"}
+	if insights, err := summary.Insights(); err == nil {
+		message.Body = insights.Summary
+	}
+	if summary.ID != 0 {
+		message.URL = fmt.Sprintf("/ai-summaries#summary-%d", summary.ID)
+	}
+	return message
+}
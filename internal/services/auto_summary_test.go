@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"health-balance/internal/models"
+	"testing"
+	"time"
+)
+
+func autoSummaryTestJob(db *MockDB) (*autoSummaryJob, *int) {
+	calls := 0
+	job := &autoSummaryJob{db: db, summarize: func(ctx context.Context) (*models.AISummary, error) {
+		calls++
+		return &models.AISummary{ID: 3, Text: "**Sleep** more."}, nil
+	}}
+	return job, &calls
+}
+
+func TestAutoSummaryJobOnComplete(t *testing.T) {
+	// Wednesday of the week ending Sunday 2026-03-08
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.Local)
+	week := "2026-03-08"
+	db := &MockDB{
+		AutoSummary: &models.AutoSummarySettings{Enabled: true, Trigger: models.AutoSummaryOnComplete, Time: "18:00", Timezone: "UTC"},
+		HealthMap:   map[string]*models.HealthMetrics{week: {Date: week}},
+		FitnessMap:  map[string]*models.FitnessMetrics{week: {Date: week}},
+	}
+	job, calls := autoSummaryTestJob(db)
+
+	if _, due := job.due(now); due {
+		t.Error("Expected no summary before the cognition pillar is recorded")
+	}
+	db.CognitionMap = map[string]*models.CognitionMetrics{week: {Date: week}}
+	if got, due := job.due(now); !due || got != week {
+		t.Fatalf("Expected the summary due for %s, got %q %t", week, got, due)
+	}
+
+	job.generate(week)
+	if *calls != 1 || db.AutoSummary.LastWeek != week {
+		t.Errorf("Expected the summary generated and the week recorded, got %d calls, last week %q", *calls, db.AutoSummary.LastWeek)
+	}
+	if _, due := job.due(now.Add(2 * time.Hour)); due {
+		t.Error("Expected one summary per week")
+	}
+
+	db.AutoSummary.Enabled = false
+	db.AutoSummary.LastWeek = ""
+	if _, due := job.due(now); due {
+		t.Error("Expected nothing while disabled")
+	}
+}
+
+func TestAutoSummaryJobScheduled(t *testing.T) {
+	// Sunday 18:00 UTC ends the week of 2026-03-08
+	db := &MockDB{AutoSummary: &models.AutoSummarySettings{Enabled: true, Trigger: models.AutoSummaryScheduled, Day: 0, Time: "18:00", Timezone: "UTC"}}
+	job, _ := autoSummaryTestJob(db)
+
+	if _, due := job.due(time.Date(2026, 3, 8, 17, 59, 0, 0, time.UTC)); due {
+		t.Error("Expected nothing before the scheduled time")
+	}
+	if week, due := job.due(time.Date(2026, 3, 8, 18, 0, 0, 0, time.UTC)); !due || week != "2026-03-08" {
+		t.Errorf("Expected the summary due at the scheduled time, got %q %t", week, due)
+	}
+	if _, due := job.due(time.Date(2026, 3, 9, 8, 0, 0, 0, time.UTC)); due {
+		t.Error("Expected nothing early in the next week")
+	}
+}
+
+func TestAutoSummaryJobRetry(t *testing.T) {
+	db := &MockDB{AutoSummary: &models.AutoSummarySettings{Enabled: true, Trigger: models.AutoSummaryScheduled, Day: 1, Time: "00:00", Timezone: "UTC"}}
+	job := &autoSummaryJob{db: db, summarize: func(ctx context.Context) (*models.AISummary, error) {
+		return nil, errors.New("provider down")
+	}}
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+
+	job.lastAttempt = now
+	job.generate("2026-03-08")
+	if db.AutoSummary.LastWeek != "" {
+		t.Error("Expected a failed summary not recorded")
+	}
+	if _, due := job.due(now.Add(30 * time.Minute)); due {
+		t.Error("Expected no retry within the hour")
+	}
+	if _, due := job.due(now.Add(time.Hour)); !due {
+		t.Error("Expected a retry after an hour")
+	}
+}
+
+func TestAutoSummaryJobRunsOnce(t *testing.T) {
+	db := &MockDB{AutoSummary: &models.AutoSummarySettings{Enabled: true, Trigger: models.AutoSummaryScheduled, Day: 1, Time: "00:00", Timezone: "UTC"}}
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	job := &autoSummaryJob{db: db, summarize: func(ctx context.Context) (*models.AISummary, error) {
+		started <- struct{}{}
+		<-release
+		return &models.AISummary{Text: "**Sleep** more."}, nil
+	}}
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+
+	job.check(now)
+	<-started
+	// A call slower than the retry interval must not start a second summary
+	job.check(now.Add(2 * autoSummaryRetry))
+	if len(started) != 0 {
+		t.Error("Expected no second summary while one is being generated")
+	}
+
+	close(release)
+	for deadline := time.Now().Add(time.Second); job.running.Load(); {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the summary to finish")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if db.AutoSummary.LastWeek != "2026-03-08" {
+		t.Errorf("Expected the week recorded, got %q", db.AutoSummary.LastWeek)
+	}
+}
+
+func TestAutoSummaryMessage(t *testing.T) {
+	message := autoSummaryMessage(&models.AISummary{ID: 12, Text: `{"summary": "Fitness is the bottleneck.", "recommendations": [{"pillar": "fitness", "title": "T", "action": "A", "priority": "high"}]}`})
+	if message.Body != "Fitness is the bottleneck." || message.URL != "/ai-summaries#summary-12" {
+		t.Errorf("Unexpected message %+v", message)
+	}
+	if message := autoSummaryMessage(&models.AISummary{Text: "**Sleep** more."}); message.URL != "/ai-summaries" || message.Body == "" {
+		t.Errorf("Unexpected message for a plain, unsaved summary %+v", message)
+	}
+}
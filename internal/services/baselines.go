@@ -0,0 +1,199 @@
+package services
+
+import (
+	"fmt"
+	"health-balance/internal/database"
+	"health-balance/internal/models"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// The default window is the three calendar months the RHR baseline always averaged
+	defaultBaselineWindowMonths = 3
+	defaultBaselineMethod       = models.BaselineMean
+	// Outliers are only dropped once there are enough samples to judge the spread
+	minOutlierSamples = 5
+	// Values further than this many scaled MADs from the median are outliers
+	outlierMADThreshold = 3.0
+	// Scales the median absolute deviation to a standard deviation for normal data
+	madToStdDev = 1.4826
+	// Baselines with fewer samples are not shown to the user
+	minDisplayBaselineSamples = 3
+)
+
+// BaselineConfig controls how personal baselines are calculated
+type BaselineConfig struct {
+	// WindowWeeks is the number of weeks in the window up to and including the current
+	// one. When 0 the window spans WindowMonths calendar months instead.
+	WindowWeeks  int
+	WindowMonths int
+	Method       string
+	// ExcludeOutliers drops values far from the median before averaging. It is opt-in,
+	// as the scoring model uses the RHR baseline and must not change under the user.
+	ExcludeOutliers bool
+}
+
+// LoadBaselineConfig reads the baseline settings from BASELINE_WINDOW_WEEKS,
+// BASELINE_METHOD (mean or median) and BASELINE_EXCLUDE_OUTLIERS, falling back to
+// a plain 3 month mean, the RHR baseline the health pillar has always been scored with.
+func LoadBaselineConfig() BaselineConfig {
+	cfg := BaselineConfig{
+		WindowMonths: defaultBaselineWindowMonths,
+		Method:       defaultBaselineMethod,
+	}
+
+	if weeks, err := strconv.Atoi(os.Getenv("BASELINE_WINDOW_WEEKS")); err == nil && weeks > 0 {
+		cfg.WindowWeeks = weeks
+	}
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("BASELINE_METHOD"))) {
+	case models.BaselineMedian:
+		cfg.Method = models.BaselineMedian
+	case models.BaselineMean:
+		cfg.Method = models.BaselineMean
+	}
+	if exclude, err := strconv.ParseBool(os.Getenv("BASELINE_EXCLUDE_OUTLIERS")); err == nil {
+		cfg.ExcludeOutliers = exclude
+	}
+
+	return cfg
+}
+
+// windowStart returns the first date of the window ending at asOf
+func (cfg BaselineConfig) windowStart(asOf time.Time) time.Time {
+	if cfg.WindowWeeks > 0 {
+		return asOf.AddDate(0, 0, -7*(cfg.WindowWeeks-1))
+	}
+	return asOf.AddDate(0, -cfg.WindowMonths, 0)
+}
+
+// window describes the length of the window, e.g. "8 weeks" or "3 months"
+func (cfg BaselineConfig) window() string {
+	if cfg.WindowWeeks > 0 {
+		return fmt.Sprintf("%d weeks", cfg.WindowWeeks)
+	}
+	return fmt.Sprintf("%d months", cfg.WindowMonths)
+}
+
+// GetPersonalBaseline calculates the rolling baseline of one metric over the configured
+// window up to and including asOf. It returns nil when nothing was recorded.
+func GetPersonalBaseline(db database.Querier, key string, asOf time.Time, cfg BaselineConfig) (*models.PersonalBaseline, error) {
+	metric, ok := models.LookupMetric(key)
+	if !ok {
+		return nil, fmt.Errorf("unknown metric %q", key)
+	}
+
+	from := cfg.windowStart(asOf).Format("2006-01-02")
+	points, err := db.GetMetricSeries(key, from, asOf.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s history: %w", key, err)
+	}
+
+	values := make([]float64, len(points))
+	for i, p := range points {
+		values[i] = p.Value
+	}
+
+	value, used, ok := calculateBaseline(values, cfg)
+	if !ok {
+		return nil, nil
+	}
+
+	return &models.PersonalBaseline{
+		Metric:   metric,
+		Value:    value,
+		Samples:  used,
+		Excluded: len(values) - used,
+		Window:   cfg.window(),
+		Method:   cfg.Method,
+	}, nil
+}
+
+// GetPillarBaselines returns the personal baselines of every metric in a pillar that has
+// enough history, comparing them with the current week's values when given.
+func GetPillarBaselines(db database.Querier, pillar string, asOf time.Time, cfg BaselineConfig, current func(key string) *float64) []models.PersonalBaseline {
+	var baselines []models.PersonalBaseline
+	for _, metric := range models.MetricsForPillar(pillar) {
+		baseline, err := GetPersonalBaseline(db, metric.Key, asOf, cfg)
+		if err != nil || baseline == nil || baseline.Samples < minDisplayBaselineSamples {
+			continue
+		}
+		if current != nil {
+			baseline.Current = current(metric.Key)
+		}
+		baselines = append(baselines, *baseline)
+	}
+	return baselines
+}
+
+// calculateBaseline reduces the values to a single baseline using the configured method
+// and returns how many values were used after outlier exclusion.
+func calculateBaseline(values []float64, cfg BaselineConfig) (float64, int, bool) {
+	if len(values) == 0 {
+		return 0, 0, false
+	}
+
+	if cfg.ExcludeOutliers {
+		values = excludeOutliers(values)
+	}
+
+	if cfg.Method == models.BaselineMedian {
+		return median(values), len(values), true
+	}
+
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	return total / float64(len(values)), len(values), true
+}
+
+// excludeOutliers drops values that are far from the median, measured in scaled
+// median absolute deviations, which stays robust with a handful of weekly samples.
+func excludeOutliers(values []float64) []float64 {
+	if len(values) < minOutlierSamples {
+		return values
+	}
+
+	center := median(values)
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - center)
+	}
+	spread := median(deviations) * madToStdDev
+	if spread == 0 {
+		return values
+	}
+
+	kept := make([]float64, 0, len(values))
+	for _, v := range values {
+		if math.Abs(v-center)/spread <= outlierMADThreshold {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+func median(values []float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// personalRHRBaseline returns the rounded rolling RHR baseline used by the health pillar, or 0 without history
+func personalRHRBaseline(db database.Querier, asOf time.Time, cfg BaselineConfig) int {
+	baseline, err := GetPersonalBaseline(db, "rhr", asOf, cfg)
+	if err != nil || baseline == nil {
+		return 0
+	}
+	return int(math.Round(baseline.Value))
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"health-balance/internal/models"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCalculateBaseline(t *testing.T) {
+	values := []float64{60, 62, 61, 63, 59, 90}
+
+	mean, used, ok := calculateBaseline(values, BaselineConfig{Method: models.BaselineMean})
+	if !ok || used != 6 || math.Abs(mean-65.83) > 0.01 {
+		t.Errorf("Expected plain mean 65.83 over 6 values, got %.2f over %d", mean, used)
+	}
+
+	trimmed, used, _ := calculateBaseline(values, BaselineConfig{Method: models.BaselineMean, ExcludeOutliers: true})
+	if used != 5 || trimmed != 61 {
+		t.Errorf("Expected the 90 outlier to be excluded for a mean of 61, got %.2f over %d", trimmed, used)
+	}
+
+	med, _, _ := calculateBaseline(values, BaselineConfig{Method: models.BaselineMedian})
+	if med != 61.5 {
+		t.Errorf("Expected median 61.5, got %.2f", med)
+	}
+
+	if _, _, ok := calculateBaseline(nil, BaselineConfig{Method: models.BaselineMean}); ok {
+		t.Error("Expected no baseline without values")
+	}
+}
+
+func TestExcludeOutliersNeedsEnoughSamples(t *testing.T) {
+	values := []float64{60, 61, 95}
+	if kept := excludeOutliers(values); len(kept) != len(values) {
+		t.Errorf("Expected small samples to be kept as-is, got %v", kept)
+	}
+}
+
+func TestLoadBaselineConfig(t *testing.T) {
+	t.Setenv("BASELINE_WINDOW_WEEKS", "")
+	t.Setenv("BASELINE_METHOD", "")
+	t.Setenv("BASELINE_EXCLUDE_OUTLIERS", "")
+
+	cfg := LoadBaselineConfig()
+	if cfg.WindowWeeks != 0 || cfg.WindowMonths != 3 || cfg.Method != models.BaselineMean || cfg.ExcludeOutliers {
+		t.Errorf("Unexpected default config %+v", cfg)
+	}
+
+	t.Setenv("BASELINE_WINDOW_WEEKS", "8")
+	t.Setenv("BASELINE_METHOD", "Median")
+	t.Setenv("BASELINE_EXCLUDE_OUTLIERS", "true")
+
+	cfg = LoadBaselineConfig()
+	if cfg.WindowWeeks != 8 || cfg.Method != models.BaselineMedian || !cfg.ExcludeOutliers || cfg.window() != "8 weeks" {
+		t.Errorf("Expected env overrides to apply, got %+v", cfg)
+	}
+}
+
+// The default RHR baseline must stay the plain 3 month average the health pillar was
+// always scored with: every reading since the same day 3 months ago, outliers included.
+func TestDefaultRHRBaselineIsThreeMonthMean(t *testing.T) {
+	t.Setenv("BASELINE_WINDOW_WEEKS", "")
+	t.Setenv("BASELINE_METHOD", "")
+	t.Setenv("BASELINE_EXCLUDE_OUTLIERS", "")
+
+	mock := &MockDB{
+		HealthMap: map[string]*models.HealthMetrics{
+			"2025-02-23": {RHR: models.Int(100)},
+			"2025-03-02": {RHR: models.Int(60)},
+			"2025-03-09": {RHR: models.Int(61)},
+			"2025-03-16": {RHR: models.Int(62)},
+			"2025-03-23": {RHR: models.Int(60)},
+			"2025-03-30": {RHR: models.Int(61)},
+			"2025-04-06": {RHR: models.Int(90)},
+			"2025-06-01": {RHR: models.Int(62)},
+		},
+	}
+
+	// 2025-03-01 to 2025-06-01: (60+61+62+60+61+90+62) / 7 = 65.14
+	if baseline := personalRHRBaseline(mock, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), LoadBaselineConfig()); baseline != 65 {
+		t.Errorf("Expected the plain 3 month RHR mean of 65, got %d", baseline)
+	}
+}
+
+func TestGetPersonalBaselineUsesWindow(t *testing.T) {
+	asOf := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	mock := &MockDB{
+		HealthMap: map[string]*models.HealthMetrics{
+			"2025-04-20": {SleepScore: models.Int(50)},
+			"2025-05-18": {SleepScore: models.Int(70)},
+			"2025-05-25": {SleepScore: models.Int(80)},
+			"2025-06-01": {SleepScore: models.Int(90)},
+			"2025-06-08": {SleepScore: models.Int(10)},
+		},
+	}
+
+	cfg := BaselineConfig{WindowWeeks: 3, Method: models.BaselineMean}
+	baseline, err := GetPersonalBaseline(mock, "sleep_score", asOf, cfg)
+	if err != nil {
+		t.Fatalf("Failed to get baseline: %v", err)
+	}
+	if baseline == nil || baseline.Samples != 3 || baseline.Value != 80 {
+		t.Fatalf("Expected a 3 week mean of 80, got %+v", baseline)
+	}
+
+	if baseline, _ := GetPersonalBaseline(mock, "dead_hang_seconds", asOf, cfg); baseline != nil {
+		t.Errorf("Expected no baseline without history, got %+v", baseline)
+	}
+	if _, err := GetPersonalBaseline(mock, "unknown", asOf, cfg); err == nil {
+		t.Error("Expected an error for an unknown metric")
+	}
+}
+
+func TestGetPillarBaselinesComparesCurrentWeek(t *testing.T) {
+	asOf := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	mock := &MockDB{
+		FitnessMap: map[string]*models.FitnessMetrics{
+			"2025-05-18": {DeadHangSeconds: models.Int(60), VO2Max: models.Float(42)},
+			"2025-05-25": {DeadHangSeconds: models.Int(62)},
+			"2025-06-01": {DeadHangSeconds: models.Int(64)},
+		},
+	}
+	current := models.FitnessMetrics{DeadHangSeconds: models.Int(70)}
+
+	baselines := GetPillarBaselines(mock, models.PillarFitness, asOf, BaselineConfig{WindowWeeks: 13, Method: models.BaselineMedian}, current.Value)
+	if len(baselines) != 1 {
+		t.Fatalf("Expected only dead hang to have enough history, got %+v", baselines)
+	}
+
+	b := baselines[0]
+	if b.Metric.Key != "dead_hang_seconds" || b.Value != 62 || b.Status() != "better" || b.FormattedDelta() != "+8" {
+		t.Errorf("Expected dead hang baseline 62 with a better +8 week, got %+v (%s %s)", b, b.Status(), b.FormattedDelta())
+	}
+}
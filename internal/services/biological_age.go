@@ -0,0 +1,251 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"health-balance/internal/database"
+	"health-balance/internal/models"
+	"health-balance/internal/utils"
+	"maps"
+	"math"
+	"slices"
+	"time"
+)
+
+const (
+	minMarkerAge            = 18
+	maxMarkerAge            = 95
+	biologicalAgeTrendWeeks = 12
+	minAgeRangeHalfWidth    = 1.5
+	missingCoverageAgeRange = 10.0
+)
+
+// Marker weights mirror the negative caps of each reserve marker in the pillar
+// calculations, so each marker counts for as much as it can cost the score.
+const (
+	vo2MaxAgeWeight        = 22.0
+	whtrAgeWeight          = 15.0
+	rhrAgeWeight           = 10.0
+	bloodPressureAgeWeight = 14.0
+	gripAgeWeight          = 10.0
+	legStrengthAgeWeight   = 8.0
+)
+
+const totalMarkerAgeWeight = vo2MaxAgeWeight + whtrAgeWeight + rhrAgeWeight + bloodPressureAgeWeight + gripAgeWeight + legStrengthAgeWeight
+
+// biologicalAgeMetrics are the metrics the reserve markers are calculated from
+var biologicalAgeMetrics = []string{
+	"vo2_max", "waist_cm", "rhr", "systolic_bp", "dead_hang_seconds", "body_weight_kg", "lower_body_weight", "lower_body_reps",
+}
+
+// GetBiologicalAge estimates the functional age from the most recent reserve markers,
+// together with a confidence range and the trend over the last weeks of data. Each
+// marker carries its latest recorded value forward, like the pillar calculations do.
+//
+// The marker ages come from population curves by age, which are separate from the pillar
+// targets: apart from VO2 Max, the pillars score against fixed or personal targets that
+// do not change with age, so they cannot be mapped to an age.
+func GetBiologicalAge(db database.Querier) (*models.BiologicalAge, error) {
+	profile, err := db.GetUserProfile()
+	if err != nil || profile == nil {
+		return nil, errors.New("profile required for biological age estimate")
+	}
+
+	dates, err := db.GetAllDatesWithData()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch dates: %w", err)
+	}
+	if len(dates) == 0 {
+		return nil, nil
+	}
+	slices.Sort(dates)
+
+	// One query per marker metric instead of one per week
+	recorded := map[string]map[string]float64{}
+	for _, key := range biologicalAgeMetrics {
+		points, err := db.GetMetricSeries(key, dates[0], dates[len(dates)-1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s history: %w", key, err)
+		}
+		for _, p := range points {
+			if recorded[p.Date] == nil {
+				recorded[p.Date] = map[string]float64{}
+			}
+			recorded[p.Date][key] = p.Value
+		}
+	}
+
+	var (
+		result *models.BiologicalAge
+		latest = map[string]float64{}
+		trend  []models.BiologicalAgePoint
+	)
+
+	for _, date := range dates {
+		calculationDate, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metric date %s: %w", date, err)
+		}
+		maps.Copy(latest, recorded[date])
+
+		age, err := utils.GetAge(profile, calculationDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid profile for date %s: %w", date, err)
+		}
+
+		health, fitness := markerMetrics(latest)
+		estimate := EstimateBiologicalAge(*profile, age, &health, &fitness)
+		if estimate == nil {
+			continue
+		}
+		estimate.Date = date
+		result = estimate
+		trend = append(trend, models.BiologicalAgePoint{Date: date, Estimate: estimate.Estimate})
+	}
+
+	if result == nil {
+		return nil, nil
+	}
+
+	if len(trend) > biologicalAgeTrendWeeks {
+		trend = trend[len(trend)-biologicalAgeTrendWeeks:]
+	}
+	result.Trend = trend
+	result.Change = trend[len(trend)-1].Estimate - trend[0].Estimate
+
+	return result, nil
+}
+
+// markerMetrics fills the metrics behind the reserve markers from the latest values by key
+func markerMetrics(latest map[string]float64) (models.HealthMetrics, models.FitnessMetrics) {
+	floatValue := func(key string) *float64 {
+		if v, ok := latest[key]; ok {
+			return models.Float(v)
+		}
+		return nil
+	}
+	intValue := func(key string) *int {
+		if v, ok := latest[key]; ok {
+			return models.Int(int(v))
+		}
+		return nil
+	}
+
+	health := models.HealthMetrics{
+		WaistCm:      floatValue("waist_cm"),
+		BodyWeightKg: floatValue("body_weight_kg"),
+		RHR:          intValue("rhr"),
+		SystolicBP:   intValue("systolic_bp"),
+	}
+	fitness := models.FitnessMetrics{
+		VO2Max:          floatValue("vo2_max"),
+		DeadHangSeconds: intValue("dead_hang_seconds"),
+		LowerBodyWeight: floatValue("lower_body_weight"),
+		LowerBodyReps:   intValue("lower_body_reps"),
+	}
+	return health, fitness
+}
+
+// EstimateBiologicalAge maps each available reserve marker to the age whose baseline it matches
+// and combines them into a weighted estimate. It returns nil when no marker is available.
+func EstimateBiologicalAge(profile models.UserProfile, age int, health *models.HealthMetrics, fitness *models.FitnessMetrics) *models.BiologicalAge {
+	var markers []models.MarkerAge
+	addMarker := func(name, unit string, value float64, baseline func(age int) float64, weight float64) {
+		markers = append(markers, models.MarkerAge{
+			Name:   name,
+			Value:  value,
+			Unit:   unit,
+			Age:    ageMatchingBaseline(value, baseline),
+			Weight: weight,
+		})
+	}
+
+	if fitness != nil && fitness.VO2Max != nil && *fitness.VO2Max > 0 {
+		addMarker("VO2 Max", "ml/kg/min", *fitness.VO2Max, func(a int) float64 { return models.GetVO2MaxNorm(float64(a), profile.Sex).P50 }, vo2MaxAgeWeight)
+	}
+
+	if health != nil {
+		if health.WaistCm != nil && *health.WaistCm > 0 && profile.HeightCm > 0 {
+			addMarker("WHtR", "", *health.WaistCm/profile.HeightCm, models.GetWHtRBaseline, whtrAgeWeight)
+		}
+		if health.RHR != nil && *health.RHR > 0 {
+			addMarker("Resting Heart Rate", "bpm", float64(*health.RHR), models.GetRHRBaseline, rhrAgeWeight)
+		}
+		if health.SystolicBP != nil && *health.SystolicBP > 0 {
+			addMarker("Systolic BP", "mmHg", float64(*health.SystolicBP), models.GetSystolicBPBaseline, bloodPressureAgeWeight)
+		}
+	}
+
+	if fitness != nil {
+		if fitness.DeadHangSeconds != nil && *fitness.DeadHangSeconds > 0 {
+			addMarker("Dead Hang", "s", float64(*fitness.DeadHangSeconds), models.GetDeadHangBaseline, gripAgeWeight)
+		}
+		bodyWeight := 0.0
+		if health != nil {
+			bodyWeight = floatOrZero(health.BodyWeightKg)
+		}
+		if bodyWeight > 0 && fitness.LowerBodyWeight != nil && fitness.LowerBodyReps != nil && *fitness.LowerBodyWeight > 0 && *fitness.LowerBodyReps > 0 {
+			rsi := (*fitness.LowerBodyWeight / bodyWeight) * float64(*fitness.LowerBodyReps)
+			addMarker("Leg Press RSI", "", rsi, models.GetLowerBodyRSIBaseline, legStrengthAgeWeight)
+		}
+	}
+
+	if len(markers) == 0 {
+		return nil
+	}
+
+	var weightSum, weightedAge float64
+	for _, m := range markers {
+		weightSum += m.Weight
+		weightedAge += m.Weight * m.Age
+	}
+	estimate := weightedAge / weightSum
+
+	var variance float64
+	for _, m := range markers {
+		variance += m.Weight * (m.Age - estimate) * (m.Age - estimate)
+	}
+	variance /= weightSum
+
+	// The range widens when markers disagree with each other and when
+	// some markers are missing entirely.
+	coverage := weightSum / totalMarkerAgeWeight
+	halfWidth := math.Max(minAgeRangeHalfWidth, math.Sqrt(variance)) + (1-coverage)*missingCoverageAgeRange
+
+	return &models.BiologicalAge{
+		ChronologicalAge: age,
+		Estimate:         estimate,
+		Low:              math.Max(minMarkerAge, estimate-halfWidth),
+		High:             math.Min(maxMarkerAge, estimate+halfWidth),
+		Markers:          markers,
+	}
+}
+
+// ageMatchingBaseline returns the (interpolated) age at which the monotonic baseline
+// function reaches the given value, clamped to the supported age range.
+func ageMatchingBaseline(value float64, baseline func(age int) float64) float64 {
+	increasing := baseline(maxMarkerAge) > baseline(minMarkerAge)
+	reached := func(age int) bool {
+		if increasing {
+			return baseline(age) >= value
+		}
+		return baseline(age) <= value
+	}
+
+	if reached(minMarkerAge) {
+		return minMarkerAge
+	}
+
+	for age := minMarkerAge + 1; age <= maxMarkerAge; age++ {
+		if !reached(age) {
+			continue
+		}
+		prev, next := baseline(age-1), baseline(age)
+		if prev == next {
+			return float64(age)
+		}
+		return float64(age-1) + (value-prev)/(next-prev)
+	}
+
+	return maxMarkerAge
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"health-balance/internal/models"
+)
+
+func TestAgeMatchingBaseline(t *testing.T) {
+	// Systolic baseline rises 0.45 mmHg per year from 114 at 25
+	age := ageMatchingBaseline(123, models.GetSystolicBPBaseline)
+	if math.Abs(age-45) > 0.01 {
+		t.Fatalf("Expected systolic 123 to match age 45, got %.2f", age)
+	}
+
+	if got := ageMatchingBaseline(200, models.GetDeadHangBaseline); got != minMarkerAge {
+		t.Fatalf("Expected exceptional dead hang to clamp to %d, got %.2f", minMarkerAge, got)
+	}
+	if got := ageMatchingBaseline(0.9, models.GetWHtRBaseline); got != maxMarkerAge {
+		t.Fatalf("Expected very high WHtR to clamp to %d, got %.2f", maxMarkerAge, got)
+	}
+}
+
+func TestEstimateBiologicalAge(t *testing.T) {
+	profile := models.UserProfile{BirthDate: "1980-01-01", Sex: "male", HeightCm: 180}
+
+	t.Run("Fit Markers Estimate Younger", func(t *testing.T) {
+		health := &models.HealthMetrics{WaistCm: models.Float(80), BodyWeightKg: models.Float(75), RHR: models.Int(55), SystolicBP: models.Int(115)}
+		fitness := &models.FitnessMetrics{VO2Max: models.Float(48), LowerBodyWeight: models.Float(200), LowerBodyReps: models.Int(12), DeadHangSeconds: models.Int(90)}
+
+		estimate := EstimateBiologicalAge(profile, 45, health, fitness)
+		if estimate == nil {
+			t.Fatal("Expected an estimate, got nil")
+		}
+		if estimate.Estimate >= 45 {
+			t.Fatalf("Expected fit markers to estimate below chronological age 45, got %.1f", estimate.Estimate)
+		}
+		if len(estimate.Markers) != 6 {
+			t.Fatalf("Expected all 6 reserve markers, got %d", len(estimate.Markers))
+		}
+		if estimate.Low > estimate.Estimate || estimate.High < estimate.Estimate {
+			t.Fatalf("Expected estimate %.1f inside range %.1f-%.1f", estimate.Estimate, estimate.Low, estimate.High)
+		}
+	})
+
+	t.Run("Missing Markers Widen The Range", func(t *testing.T) {
+		health := &models.HealthMetrics{WaistCm: models.Float(90), BodyWeightKg: models.Float(80), RHR: models.Int(62), SystolicBP: models.Int(123)}
+		fitness := &models.FitnessMetrics{VO2Max: models.Float(39), LowerBodyWeight: models.Float(150), LowerBodyReps: models.Int(10), DeadHangSeconds: models.Int(50)}
+
+		full := EstimateBiologicalAge(profile, 45, health, fitness)
+		partial := EstimateBiologicalAge(profile, 45, health, nil)
+		if full == nil || partial == nil {
+			t.Fatal("Expected both estimates to be available")
+		}
+		if partial.High-partial.Low <= full.High-full.Low {
+			t.Fatalf("Expected fewer markers to widen the range, got %.1f vs %.1f", partial.High-partial.Low, full.High-full.Low)
+		}
+	})
+
+	t.Run("No Markers", func(t *testing.T) {
+		if estimate := EstimateBiologicalAge(profile, 45, nil, nil); estimate != nil {
+			t.Fatalf("Expected nil estimate without markers, got %+v", estimate)
+		}
+	})
+}
+
+func TestGetBiologicalAge_Trend(t *testing.T) {
+	mock := &MockDB{
+		AllDates:    []string{"2026-01-18", "2026-01-11", "2026-01-04"},
+		UserProfile: &models.UserProfile{BirthDate: "1980-01-01", Sex: "male", HeightCm: 180},
+		HealthMap: map[string]*models.HealthMetrics{
+			"2026-01-04": {WaistCm: models.Float(95), BodyWeightKg: models.Float(85), RHR: models.Int(68), SystolicBP: models.Int(132)},
+			"2026-01-18": {WaistCm: models.Float(88), BodyWeightKg: models.Float(80), RHR: models.Int(60), SystolicBP: models.Int(120)},
+		},
+		FitnessMap: map[string]*models.FitnessMetrics{
+			"2026-01-04": {VO2Max: models.Float(36), DeadHangSeconds: models.Int(30)},
+			"2026-01-11": {VO2Max: models.Float(40), DeadHangSeconds: models.Int(45)},
+		},
+	}
+
+	result, err := GetBiologicalAge(mock)
+	if err != nil {
+		t.Fatalf("Failed to estimate: %v", err)
+	}
+	if result == nil {
+		t.Fatal("Expected an estimate, got nil")
+	}
+	if len(result.Trend) != 3 {
+		t.Fatalf("Expected 3 trend points, got %d", len(result.Trend))
+	}
+	if result.Date != "2026-01-18" {
+		t.Fatalf("Expected latest estimate for 2026-01-18, got %s", result.Date)
+	}
+	if result.Change >= 0 {
+		t.Fatalf("Expected improving markers to lower the functional age, got change %.1f", result.Change)
+	}
+}
+
+func TestGetBiologicalAge_CarriesMarkersForward(t *testing.T) {
+	mock := &MockDB{
+		AllDates:    []string{"2026-01-11", "2026-01-04"},
+		UserProfile: &models.UserProfile{BirthDate: "1980-01-01", Sex: "male", HeightCm: 180},
+		HealthMap: map[string]*models.HealthMetrics{
+			"2026-01-04": {WaistCm: models.Float(90), RHR: models.Int(64)},
+			"2026-01-11": {RHR: models.Int(60)},
+		},
+	}
+
+	result, err := GetBiologicalAge(mock)
+	if err != nil || result == nil {
+		t.Fatalf("Expected an estimate, got %+v (%v)", result, err)
+	}
+	markers := map[string]float64{}
+	for _, m := range result.Markers {
+		markers[m.Name] = m.Value
+	}
+	if markers["WHtR"] != 0.5 || markers["Resting Heart Rate"] != 60 {
+		t.Errorf("Expected the last waist carried forward with this week's RHR, got %+v", markers)
+	}
+}
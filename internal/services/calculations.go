@@ -28,6 +28,7 @@ const (
 	neutralCardioRecovery   = 25.0
 	neutralLegPressWeight   = 120.0
 	neutralLegPressReps     = 10.0
+	imputedConfidenceDecay  = 0.7
 )
 
 func GetCurrentMasterScore(db database.Querier) (*models.MasterScore, error) {
@@ -70,6 +71,7 @@ func GetAllWeeklyScores(db database.Querier) ([]models.MasterScore, error) {
 
 	var scores []models.MasterScore
 	currentScore := defaultMasterScore
+	baselineConfig := LoadBaselineConfig()
 	var healthHistory []models.HealthMetrics
 	var fitnessHistory []models.FitnessMetrics
 	var cognitionHistory []models.CognitionMetrics
@@ -111,13 +113,13 @@ func GetAllWeeklyScores(db database.Querier) ([]models.MasterScore, error) {
 		f, _ := db.GetFitnessMetricsByDate(date)
 		c, _ := db.GetCognitionMetricsByDate(date)
 
-		rhrBaseline, _ := db.GetRHRBaselineForDate(date)
-		if h != nil && rhrBaseline == 0 {
-			rhrBaseline = h.RHR
+		rhrBaseline := personalRHRBaseline(db, calculationDate, baselineConfig)
+		if h != nil && rhrBaseline == 0 && h.RHR != nil {
+			rhrBaseline = *h.RHR
 		}
 
 		if h != nil {
-			lastHealth = *h
+			lastHealth = carryForwardHealth(*h, lastHealth)
 			healthReady = true
 			healthMissed = 0
 		} else if healthReady {
@@ -126,7 +128,7 @@ func GetAllWeeklyScores(db database.Querier) ([]models.MasterScore, error) {
 		}
 
 		if f != nil {
-			lastFitness = *f
+			lastFitness = carryForwardFitness(*f, lastFitness)
 			fitnessReady = true
 			fitnessMissed = 0
 		} else if fitnessReady {
@@ -164,11 +166,14 @@ func GetAllWeeklyScores(db database.Querier) ([]models.MasterScore, error) {
 		effectiveFitness := smoothFitnessBehaviors(fitnessHistory, lastFitness)
 		effectiveCognition := smoothCognitionBehaviors(cognitionHistory, lastCognition)
 
-		if rhrBaseline == 0 {
-			rhrBaseline = lastHealth.RHR
+		if rhrBaseline == 0 && lastHealth.RHR != nil {
+			rhrBaseline = *lastHealth.RHR
 		}
 
-		whtr := lastHealth.WaistCm / profile.HeightCm
+		var whtr float64
+		if lastHealth.WaistCm != nil && profile.HeightCm > 0 {
+			whtr = *lastHealth.WaistCm / profile.HeightCm
+		}
 
 		newScore, hS, fS, cS, tax := CalculateMasterScore(
 			currentScore,
@@ -180,13 +185,21 @@ func GetAllWeeklyScores(db database.Querier) ([]models.MasterScore, error) {
 			calculationDate,
 		)
 
+		healthProvenance := pillarProvenance(healthMissed)
+		fitnessProvenance := pillarProvenance(fitnessMissed)
+		cognitionProvenance := pillarProvenance(cognitionMissed)
+
 		scores = append(scores, models.MasterScore{
-			Date:           date,
-			Score:          newScore,
-			HealthScore:    hS,
-			FitnessScore:   fS,
-			CognitionScore: cS,
-			AgingTax:       tax,
+			Date:                date,
+			Score:               newScore,
+			HealthScore:         hS,
+			FitnessScore:        fS,
+			CognitionScore:      cS,
+			AgingTax:            tax,
+			HealthProvenance:    healthProvenance,
+			FitnessProvenance:   fitnessProvenance,
+			CognitionProvenance: cognitionProvenance,
+			Confidence:          scoreConfidence(healthProvenance, fitnessProvenance, cognitionProvenance),
 		})
 
 		currentScore = newScore
@@ -195,33 +208,69 @@ func GetAllWeeklyScores(db database.Querier) ([]models.MasterScore, error) {
 	return scores, nil
 }
 
+// pillarProvenance classifies a pillar by how many weeks have passed since it was last measured.
+// Reserve markers are carried unchanged for stableCarryWeeks and drift toward baselines afterwards.
+func pillarProvenance(missedWeeks int) models.PillarProvenance {
+	switch {
+	case missedWeeks == 0:
+		return models.PillarProvenance{Status: models.ProvenanceMeasured}
+	case missedWeeks <= stableCarryWeeks:
+		return models.PillarProvenance{Status: models.ProvenanceCarriedForward, MissedWeeks: missedWeeks}
+	default:
+		return models.PillarProvenance{Status: models.ProvenanceDrifted, MissedWeeks: missedWeeks}
+	}
+}
+
+// scoreConfidence averages the per-pillar confidence, which decays with every imputed week
+func scoreConfidence(provenances ...models.PillarProvenance) float64 {
+	if len(provenances) == 0 {
+		return 0
+	}
+
+	var total float64
+	for _, p := range provenances {
+		total += math.Pow(imputedConfidenceDecay, float64(p.MissedWeeks))
+	}
+	return total / float64(len(provenances))
+}
+
+// CalculateHealthPillar scores the health metrics. Skipped metrics, and a whtr of 0
+// when the waist is unknown, contribute neutrally.
 func CalculateHealthPillar(m models.HealthMetrics, rhrBaseline int, whtr float64) float64 {
-	sleepPoints := cappedContribution(float64(m.SleepScore-75), 0.6, 0.9, 8.0, 12.0)
-	whtrPoints := cappedContribution(0.48-whtr, 180.0, 260.0, 10.0, 15.0)
-	rhrPoints := cappedContribution(float64(rhrBaseline-m.RHR), 1.0, 1.5, 7.0, 10.0)
+	sleepPoints := optionalContribution(m.SleepScore, 75, 1, 0.6, 0.9, 8.0, 12.0)
+	var whtrPoints float64
+	if whtr > 0 {
+		whtrPoints = cappedContribution(0.48-whtr, 180.0, 260.0, 10.0, 15.0)
+	}
+	var rhrPoints float64
+	if rhrBaseline > 0 {
+		rhrPoints = optionalContribution(m.RHR, float64(rhrBaseline), -1, 1.0, 1.5, 7.0, 10.0)
+	}
 	bloodPressurePoints := calculateBloodPressurePoints(m)
-	nutritionPoints := cappedContribution(m.NutritionScore-7.0, 1.5, 2.0, 4.5, 6.0)
+	nutritionPoints := optionalContribution(m.NutritionScore, 7.0, 1, 1.5, 2.0, 4.5, 6.0)
 
 	return sleepPoints + whtrPoints + rhrPoints + bloodPressurePoints + nutritionPoints
 }
 
+// CalculateFitnessPillar scores the fitness metrics. Skipped metrics contribute neutrally.
 func CalculateFitnessPillar(m models.FitnessMetrics, vo2MaxBaseline float64, bodyWeight float64) float64 {
-	vo2Points := cappedContribution(m.VO2Max-vo2MaxBaseline, 2.5, 3.5, 16.0, 22.0)
-	workoutPoints := cappedContribution(float64(m.Workouts-3), 1.5, 2.5, 6.0, 9.0)
-	stepPoints := cappedContribution(float64(m.DailySteps-8000)/2000.0, 1.0, 1.5, 3.0, 5.0)
-	mobilityPoints := cappedContribution(float64(m.Mobility-3), 1.0, 1.5, 3.0, 4.5)
-	recoveryPoints := cappedContribution(float64(m.CardioRecovery-25)/5.0, 1.0, 1.5, 4.0, 6.0)
+	vo2Points := optionalContribution(m.VO2Max, vo2MaxBaseline, 1, 2.5, 3.5, 16.0, 22.0)
+	workoutPoints := optionalContribution(m.Workouts, 3, 1, 1.5, 2.5, 6.0, 9.0)
+	stepPoints := optionalContribution(m.DailySteps, 8000, 2000, 1.0, 1.5, 3.0, 5.0)
+	mobilityPoints := optionalContribution(m.Mobility, 3, 1, 1.0, 1.5, 3.0, 4.5)
+	recoveryPoints := optionalContribution(m.CardioRecovery, 25, 5, 1.0, 1.5, 4.0, 6.0)
 	legStrengthPoints := calculateLowerBodyStrengthPoints(m, bodyWeight)
 	gripStrengthPoints := calculateGripStrengthPoints(m)
 
 	return vo2Points + workoutPoints + stepPoints + mobilityPoints + recoveryPoints + legStrengthPoints + gripStrengthPoints
 }
 
+// CalculateCognitionPillar scores the cognition metrics. Skipped metrics contribute neutrally.
 func CalculateCognitionPillar(m models.CognitionMetrics) float64 {
-	mindfulnessPoints := cappedContribution(float64(m.Mindfulness-3), 0.6, 1.0, 2.0, 3.0)
-	learningPoints := cappedContribution(float64(m.DeepLearning-90)/45.0, 0.6, 1.0, 2.0, 3.0)
-	stressPoints := cappedContribution(float64(3-m.StressScore), 1.2, 1.8, 4.0, 6.0)
-	socialPoints := cappedContribution(float64(m.SocialDays-4), 0.8, 1.2, 3.0, 4.0)
+	mindfulnessPoints := optionalContribution(m.Mindfulness, 3, 1, 0.6, 1.0, 2.0, 3.0)
+	learningPoints := optionalContribution(m.DeepLearning, 90, 45, 0.6, 1.0, 2.0, 3.0)
+	stressPoints := optionalContribution(m.StressScore, 3, -1, 1.2, 1.8, 4.0, 6.0)
+	socialPoints := optionalContribution(m.SocialDays, 4, 1, 0.8, 1.2, 3.0, 4.0)
 
 	return mindfulnessPoints + learningPoints + stressPoints + socialPoints
 }
@@ -242,7 +291,7 @@ func CalculateMasterScore(
 
 	tax := currentScore * weeklyDecayRate
 	hScore := CalculateHealthPillar(health, rhrBaseline, whtr)
-	fScore := CalculateFitnessPillar(fitness, vo2MaxBaseline, health.BodyWeightKg)
+	fScore := CalculateFitnessPillar(fitness, vo2MaxBaseline, floatOrZero(health.BodyWeightKg))
 	cScore := CalculateCognitionPillar(cognition)
 
 	postTaxScore := currentScore - tax
@@ -261,84 +310,108 @@ func cappedContribution(delta, positiveSlope, negativeSlope, positiveCap, negati
 	return math.Max(delta*negativeSlope, -negativeCap)
 }
 
+// optionalContribution scores (value-neutral)/scale, using a negative scale for
+// metrics where lower is better. A skipped metric contributes nothing.
+func optionalContribution[T int | float64](value *T, neutral, scale, positiveSlope, negativeSlope, positiveCap, negativeCap float64) float64 {
+	if value == nil {
+		return 0
+	}
+	return cappedContribution((float64(*value)-neutral)/scale, positiveSlope, negativeSlope, positiveCap, negativeCap)
+}
+
 func smoothHealthBehaviors(history []models.HealthMetrics, current models.HealthMetrics) models.HealthMetrics {
 	smoothed := current
-	smoothed.SleepScore = int(math.Round(averageLastN(history, behaviorConsistencySpan, func(m models.HealthMetrics) float64 {
-		return float64(m.SleepScore)
-	})))
-	smoothed.NutritionScore = averageLastN(history, behaviorConsistencySpan, func(m models.HealthMetrics) float64 {
-		return m.NutritionScore
-	})
+	smoothed.SleepScore = smoothedInt(history, func(m models.HealthMetrics) *int { return m.SleepScore })
+	smoothed.NutritionScore = smoothedFloat(history, func(m models.HealthMetrics) *float64 { return m.NutritionScore })
 	return smoothed
 }
 
 func smoothFitnessBehaviors(history []models.FitnessMetrics, current models.FitnessMetrics) models.FitnessMetrics {
 	smoothed := current
-	smoothed.Workouts = int(math.Round(averageLastN(history, behaviorConsistencySpan, func(m models.FitnessMetrics) float64 {
-		return float64(m.Workouts)
-	})))
-	smoothed.DailySteps = int(math.Round(averageLastN(history, behaviorConsistencySpan, func(m models.FitnessMetrics) float64 {
-		return float64(m.DailySteps)
-	})))
-	smoothed.Mobility = int(math.Round(averageLastN(history, behaviorConsistencySpan, func(m models.FitnessMetrics) float64 {
-		return float64(m.Mobility)
-	})))
+	smoothed.Workouts = smoothedInt(history, func(m models.FitnessMetrics) *int { return m.Workouts })
+	smoothed.DailySteps = smoothedInt(history, func(m models.FitnessMetrics) *int { return m.DailySteps })
+	smoothed.Mobility = smoothedInt(history, func(m models.FitnessMetrics) *int { return m.Mobility })
 	return smoothed
 }
 
 func smoothCognitionBehaviors(history []models.CognitionMetrics, current models.CognitionMetrics) models.CognitionMetrics {
 	smoothed := current
-	smoothed.Mindfulness = int(math.Round(averageLastN(history, behaviorConsistencySpan, func(m models.CognitionMetrics) float64 {
-		return float64(m.Mindfulness)
-	})))
-	smoothed.DeepLearning = int(math.Round(averageLastN(history, behaviorConsistencySpan, func(m models.CognitionMetrics) float64 {
-		return float64(m.DeepLearning)
-	})))
-	smoothed.StressScore = int(math.Round(averageLastN(history, behaviorConsistencySpan, func(m models.CognitionMetrics) float64 {
-		return float64(m.StressScore)
-	})))
-	smoothed.SocialDays = int(math.Round(averageLastN(history, behaviorConsistencySpan, func(m models.CognitionMetrics) float64 {
-		return float64(m.SocialDays)
-	})))
+	smoothed.Mindfulness = smoothedInt(history, func(m models.CognitionMetrics) *int { return m.Mindfulness })
+	smoothed.DeepLearning = smoothedInt(history, func(m models.CognitionMetrics) *int { return m.DeepLearning })
+	smoothed.StressScore = smoothedInt(history, func(m models.CognitionMetrics) *int { return m.StressScore })
+	smoothed.SocialDays = smoothedInt(history, func(m models.CognitionMetrics) *int { return m.SocialDays })
 	return smoothed
 }
 
-func averageLastN[T any](items []T, window int, value func(T) float64) float64 {
-	if len(items) == 0 {
-		return 0
+func smoothedInt[T any](history []T, value func(T) *int) *int {
+	avg, ok := averageLastN(history, behaviorConsistencySpan, func(item T) (float64, bool) {
+		v := value(item)
+		if v == nil {
+			return 0, false
+		}
+		return float64(*v), true
+	})
+	if !ok {
+		return nil
 	}
+	return models.Int(int(math.Round(avg)))
+}
+
+func smoothedFloat[T any](history []T, value func(T) *float64) *float64 {
+	avg, ok := averageLastN(history, behaviorConsistencySpan, func(item T) (float64, bool) {
+		v := value(item)
+		if v == nil {
+			return 0, false
+		}
+		return *v, true
+	})
+	if !ok {
+		return nil
+	}
+	return models.Float(avg)
+}
 
+// averageLastN averages the values recorded within the last window items, skipping
+// items where the value is missing. It reports false when no value was recorded.
+func averageLastN[T any](items []T, window int, value func(T) (float64, bool)) (float64, bool) {
 	start := len(items) - window
 	if start < 0 {
 		start = 0
 	}
 
 	var total float64
+	var count int
 	for _, item := range items[start:] {
-		total += value(item)
+		if v, ok := value(item); ok {
+			total += v
+			count++
+		}
 	}
 
-	return total / float64(len(items[start:]))
+	if count == 0 {
+		return 0, false
+	}
+	return total / float64(count), true
 }
 
 func calculateBloodPressurePoints(m models.HealthMetrics) float64 {
-	if m.SystolicBP <= 0 || m.DiastolicBP <= 0 {
+	if m.SystolicBP == nil || m.DiastolicBP == nil || *m.SystolicBP <= 0 || *m.DiastolicBP <= 0 {
 		return 0
 	}
 
-	systolicPoints := cappedContribution(float64(120-m.SystolicBP)/5.0, 1.0, 1.5, 5.0, 8.0)
-	diastolicPoints := cappedContribution(float64(80-m.DiastolicBP)/3.0, 1.0, 1.5, 4.0, 6.0)
+	systolicPoints := cappedContribution(float64(120-*m.SystolicBP)/5.0, 1.0, 1.5, 5.0, 8.0)
+	diastolicPoints := cappedContribution(float64(80-*m.DiastolicBP)/3.0, 1.0, 1.5, 4.0, 6.0)
 	return systolicPoints + diastolicPoints
 }
 
 // calculateLowerBodyStrengthPoints calculates strength score using Relative Strength Index (RSI)
 // RSI = (leg_press_weight / body_weight) × reps
 func calculateLowerBodyStrengthPoints(m models.FitnessMetrics, bodyWeight float64) float64 {
-	if m.LowerBodyWeight <= 0 || m.LowerBodyReps <= 0 || bodyWeight <= 0 {
+	if m.LowerBodyWeight == nil || m.LowerBodyReps == nil || *m.LowerBodyWeight <= 0 || *m.LowerBodyReps <= 0 || bodyWeight <= 0 {
 		return 0
 	}
 
-	rsi := (m.LowerBodyWeight / bodyWeight) * float64(m.LowerBodyReps)
+	rsi := (*m.LowerBodyWeight / bodyWeight) * float64(*m.LowerBodyReps)
 
 	// Baseline RSI of 24 (e.g., 2.0x bodyweight for 12 reps)
 	// Elite: 36+ (3.0x bodyweight for 12 reps)
@@ -349,7 +422,7 @@ func calculateLowerBodyStrengthPoints(m models.FitnessMetrics, bodyWeight float6
 }
 
 func calculateGripStrengthPoints(m models.FitnessMetrics) float64 {
-	if m.DeadHangSeconds <= 0 {
+	if m.DeadHangSeconds == nil || *m.DeadHangSeconds <= 0 {
 		return 0
 	}
 
@@ -358,7 +431,7 @@ func calculateGripStrengthPoints(m models.FitnessMetrics) float64 {
 	// High fitness: 90-120 seconds
 	// Solid: 60 seconds
 	// Below average: < 30 seconds (frailty risk)
-	return cappedContribution((float64(m.DeadHangSeconds)-60.0)/30.0, 1.5, 2.0, 7.0, 10.0)
+	return cappedContribution((float64(*m.DeadHangSeconds)-60.0)/30.0, 1.5, 2.0, 7.0, 10.0)
 }
 
 func expandWeeklyDates(start, end time.Time) []time.Time {
@@ -369,18 +442,58 @@ func expandWeeklyDates(start, end time.Time) []time.Time {
 	return dates
 }
 
+// carryForwardHealth fills reserve markers skipped in a recorded week with the most recent
+// known value. Skipped behaviors stay empty and are covered by the behavior smoothing window.
+func carryForwardHealth(current, previous models.HealthMetrics) models.HealthMetrics {
+	merged := current
+	merged.WaistCm = firstSet(current.WaistCm, previous.WaistCm)
+	merged.BodyWeightKg = firstSet(current.BodyWeightKg, previous.BodyWeightKg)
+	merged.RHR = firstSet(current.RHR, previous.RHR)
+	merged.SystolicBP = firstSet(current.SystolicBP, previous.SystolicBP)
+	merged.DiastolicBP = firstSet(current.DiastolicBP, previous.DiastolicBP)
+	return merged
+}
+
+// carryForwardFitness fills reserve markers skipped in a recorded week with the most recent known value
+func carryForwardFitness(current, previous models.FitnessMetrics) models.FitnessMetrics {
+	merged := current
+	merged.VO2Max = firstSet(current.VO2Max, previous.VO2Max)
+	merged.CardioRecovery = firstSet(current.CardioRecovery, previous.CardioRecovery)
+	merged.LowerBodyWeight = firstSet(current.LowerBodyWeight, previous.LowerBodyWeight)
+	merged.LowerBodyReps = firstSet(current.LowerBodyReps, previous.LowerBodyReps)
+	merged.DeadHangSeconds = firstSet(current.DeadHangSeconds, previous.DeadHangSeconds)
+	return merged
+}
+
+func firstSet[T any](values ...*T) *T {
+	for _, v := range values {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+func floatOrZero(value *float64) float64 {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
 func imputeHealthMetrics(previous models.HealthMetrics, missedWeeks int, profile models.UserProfile, rhrBaseline int) models.HealthMetrics {
 	imputed := previous
 	imputed.SleepScore = imputeSubjectiveInt(previous.SleepScore, neutralSleepScore, missedWeeks)
 	imputed.NutritionScore = imputeSubjectiveFloat(previous.NutritionScore, neutralNutritionScore, missedWeeks)
 	imputed.WaistCm = imputeStableFloat(previous.WaistCm, profile.HeightCm*0.48, missedWeeks)
-	imputed.BodyWeightKg = imputeStableFloat(previous.BodyWeightKg, previous.BodyWeightKg, missedWeeks)
 
-	targetRHR := float64(rhrBaseline)
-	if targetRHR == 0 {
-		targetRHR = float64(previous.RHR)
+	if previous.RHR != nil {
+		targetRHR := float64(rhrBaseline)
+		if targetRHR == 0 {
+			targetRHR = float64(*previous.RHR)
+		}
+		imputed.RHR = imputeStableInt(previous.RHR, targetRHR, missedWeeks)
 	}
-	imputed.RHR = imputeStableInt(previous.RHR, targetRHR, missedWeeks)
 	imputed.SystolicBP = imputeStableInt(previous.SystolicBP, neutralSystolicBP, missedWeeks)
 	imputed.DiastolicBP = imputeStableInt(previous.DiastolicBP, neutralDiastolicBP, missedWeeks)
 	return imputed
@@ -408,36 +521,39 @@ func imputeCognitionMetrics(previous models.CognitionMetrics, missedWeeks int) m
 	return imputed
 }
 
-func decayBehaviorInt(value int) int {
-	return decayInt(value, behaviorDecayRate)
+func decayBehaviorInt(value *int) *int {
+	if value == nil {
+		return nil
+	}
+	return models.Int(decayInt(*value, behaviorDecayRate))
 }
 
-func imputeStableFloat(value, baseline float64, missedWeeks int) float64 {
-	if missedWeeks <= stableCarryWeeks {
+func imputeStableFloat(value *float64, baseline float64, missedWeeks int) *float64 {
+	if value == nil || missedWeeks <= stableCarryWeeks {
 		return value
 	}
-	return driftFloat(value, baseline, stableDriftRate)
+	return models.Float(driftFloat(*value, baseline, stableDriftRate))
 }
 
-func imputeStableInt(value int, baseline float64, missedWeeks int) int {
-	if missedWeeks <= stableCarryWeeks {
+func imputeStableInt(value *int, baseline float64, missedWeeks int) *int {
+	if value == nil || missedWeeks <= stableCarryWeeks {
 		return value
 	}
-	return driftInt(value, baseline, stableDriftRate)
+	return models.Int(driftInt(*value, baseline, stableDriftRate))
 }
 
-func imputeSubjectiveFloat(value, neutral float64, missedWeeks int) float64 {
-	if missedWeeks <= subjectiveCarryWeeks {
+func imputeSubjectiveFloat(value *float64, neutral float64, missedWeeks int) *float64 {
+	if value == nil || missedWeeks <= subjectiveCarryWeeks {
 		return value
 	}
-	return driftFloat(value, neutral, subjectiveDriftRate)
+	return models.Float(driftFloat(*value, neutral, subjectiveDriftRate))
 }
 
-func imputeSubjectiveInt(value int, neutral float64, missedWeeks int) int {
-	if missedWeeks <= subjectiveCarryWeeks {
+func imputeSubjectiveInt(value *int, neutral float64, missedWeeks int) *int {
+	if value == nil || missedWeeks <= subjectiveCarryWeeks {
 		return value
 	}
-	return driftInt(value, neutral, subjectiveDriftRate)
+	return models.Int(driftInt(*value, neutral, subjectiveDriftRate))
 }
 
 func driftFloat(value, target, rate float64) float64 {
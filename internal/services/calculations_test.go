@@ -3,30 +3,50 @@ package services
 import (
 	"health-balance/internal/models"
 	"health-balance/internal/utils"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
 
 type MockDB struct {
-	AllDates          []string
-	UserProfile       *models.UserProfile
-	HealthMap         map[string]*models.HealthMetrics
-	FitnessMap        map[string]*models.FitnessMetrics
-	CognitionMap      map[string]*models.CognitionMetrics
-	RHRBaselineValue  int
-	RHRBaselineByDate map[string]int
-	Err               error
+	AllDates      []string
+	UserProfile   *models.UserProfile
+	HealthMap     map[string]*models.HealthMetrics
+	FitnessMap    map[string]*models.FitnessMetrics
+	CognitionMap  map[string]*models.CognitionMetrics
+	Interventions []models.Intervention
+	WeekNotes     []models.WeekNote
+	AISummaries   []models.AISummary
+	Comparisons   []models.AIComparison
+	CoachMessages []models.CoachMessage
+	Privacy       models.AIPrivacy
+	Templates     map[string]models.PromptTemplate
+	Usage         []models.AIUsage
+	AutoSummary   *models.AutoSummarySettings
+	Err           error
 }
 
 func (m *MockDB) GetAllDatesWithData() ([]string, error)       { return m.AllDates, m.Err }
 func (m *MockDB) GetUserProfile() (*models.UserProfile, error) { return m.UserProfile, m.Err }
-func (m *MockDB) GetRHRBaselineForDate(date string) (int, error) {
-	if m.RHRBaselineByDate != nil {
-		if baseline, ok := m.RHRBaselineByDate[date]; ok {
-			return baseline, m.Err
+func (m *MockDB) GetMetricSeries(key, from, to string) ([]models.MetricPoint, error) {
+	var points []models.MetricPoint
+	add := func(date string, value *float64) {
+		if value != nil && date >= from && date <= to {
+			points = append(points, models.MetricPoint{Date: date, Value: *value})
 		}
 	}
-	return m.RHRBaselineValue, m.Err
+	for date, h := range m.HealthMap {
+		add(date, h.Value(key))
+	}
+	for date, f := range m.FitnessMap {
+		add(date, f.Value(key))
+	}
+	for date, c := range m.CognitionMap {
+		add(date, c.Value(key))
+	}
+	slices.SortFunc(points, func(a, b models.MetricPoint) int { return strings.Compare(a.Date, b.Date) })
+	return points, m.Err
 }
 func (m *MockDB) GetHealthMetricsByDate(d string) (*models.HealthMetrics, error) {
 	return m.HealthMap[d], m.Err
@@ -40,11 +60,20 @@ func (m *MockDB) GetCognitionMetricsByDate(d string) (*models.CognitionMetrics,
 func (m *MockDB) GetRecentHealthMetrics(l int) ([]models.HealthMetrics, error)       { return nil, nil }
 func (m *MockDB) GetRecentFitnessMetrics(l int) ([]models.FitnessMetrics, error)     { return nil, nil }
 func (m *MockDB) GetRecentCognitionMetrics(l int) ([]models.CognitionMetrics, error) { return nil, nil }
-func (m *MockDB) SaveHealthMetrics(h models.HealthMetrics) error                     { return nil }
-func (m *MockDB) SaveFitnessMetrics(f models.FitnessMetrics) error                   { return nil }
-func (m *MockDB) SaveCognitionMetrics(c models.CognitionMetrics) error               { return nil }
-func (m *MockDB) SaveUserProfile(p models.UserProfile) error                         { return nil }
-func (m *MockDB) SavePushSubscription(sub models.PushSubscription) error             { return nil }
+func (m *MockDB) GetHealthMetricsPage(q models.HistoryQuery) ([]models.HealthMetrics, error) {
+	return nil, nil
+}
+func (m *MockDB) GetFitnessMetricsPage(q models.HistoryQuery) ([]models.FitnessMetrics, error) {
+	return nil, nil
+}
+func (m *MockDB) GetCognitionMetricsPage(q models.HistoryQuery) ([]models.CognitionMetrics, error) {
+	return nil, nil
+}
+func (m *MockDB) SaveHealthMetrics(h models.HealthMetrics) error         { return nil }
+func (m *MockDB) SaveFitnessMetrics(f models.FitnessMetrics) error       { return nil }
+func (m *MockDB) SaveCognitionMetrics(c models.CognitionMetrics) error   { return nil }
+func (m *MockDB) SaveUserProfile(p models.UserProfile) error             { return nil }
+func (m *MockDB) SavePushSubscription(sub models.PushSubscription) error { return nil }
 func (m *MockDB) GetAllSubscriptions() ([]models.PushSubscription, error) {
 	return nil, nil
 }
@@ -54,10 +83,144 @@ func (m *MockDB) DeleteHealthMetrics(date string) error                     { re
 func (m *MockDB) DeleteFitnessMetrics(date string) error                    { return nil }
 func (m *MockDB) DeleteCognitionMetrics(date string) error                  { return nil }
 func (m *MockDB) Close() error                                              { return nil }
+func (m *MockDB) GetGoals() ([]models.Goal, error)                          { return nil, nil }
+func (m *MockDB) CreateGoal(g models.Goal) (int, error)                     { return 0, nil }
+func (m *MockDB) UpdateGoalStatus(id int, status, achievedAt string) error  { return nil }
+func (m *MockDB) DeleteGoal(id int) error                                   { return nil }
+func (m *MockDB) GetInterventions() ([]models.Intervention, error) {
+	return m.Interventions, m.Err
+}
+func (m *MockDB) CreateIntervention(i models.Intervention) (int, error) { return 0, nil }
+func (m *MockDB) EndIntervention(id int, endDate string) error          { return nil }
+func (m *MockDB) DeleteIntervention(id int) error                       { return nil }
+func (m *MockDB) GetWeekNote(date string) (*models.WeekNote, error) {
+	for _, n := range m.WeekNotes {
+		if n.Date == date {
+			return &n, m.Err
+		}
+	}
+	return nil, m.Err
+}
+func (m *MockDB) GetWeekNotes(from, to string) ([]models.WeekNote, error) {
+	var notes []models.WeekNote
+	for _, n := range m.WeekNotes {
+		if (from == "" || n.Date >= from) && (to == "" || n.Date <= to) {
+			notes = append(notes, n)
+		}
+	}
+	return notes, m.Err
+}
+func (m *MockDB) SaveWeekNote(n models.WeekNote) error        { return nil }
+func (m *MockDB) DeleteWeekNote(date string) error            { return nil }
+func (m *MockDB) GetAISummaries() ([]models.AISummary, error) { return m.AISummaries, m.Err }
+func (m *MockDB) GetLatestAISummary() (*models.AISummary, error) {
+	if len(m.AISummaries) == 0 {
+		return nil, m.Err
+	}
+	return &m.AISummaries[len(m.AISummaries)-1], m.Err
+}
+func (m *MockDB) GetAISummaryByPrompt(promptHash, provider, model string) (*models.AISummary, error) {
+	for i := len(m.AISummaries) - 1; i >= 0; i-- {
+		if s := m.AISummaries[i]; s.PromptHash == promptHash && s.Provider == provider && s.Model == model {
+			return &s, m.Err
+		}
+	}
+	return nil, m.Err
+}
+func (m *MockDB) SaveAISummary(s models.AISummary) (int, error) {
+	s.ID = len(m.AISummaries) + 1
+	m.AISummaries = append(m.AISummaries, s)
+	return s.ID, m.Err
+}
+func (m *MockDB) GetAIComparisons() ([]models.AIComparison, error) { return m.Comparisons, m.Err }
+func (m *MockDB) GetAIComparisonByPrompt(promptHash, provider, model string) (*models.AIComparison, error) {
+	for i := len(m.Comparisons) - 1; i >= 0; i-- {
+		if c := m.Comparisons[i]; c.PromptHash == promptHash && c.Provider == provider && c.Model == model {
+			return &c, m.Err
+		}
+	}
+	return nil, m.Err
+}
+func (m *MockDB) SaveAIComparison(c models.AIComparison) (int, error) {
+	c.ID = len(m.Comparisons) + 1
+	m.Comparisons = append(m.Comparisons, c)
+	return c.ID, m.Err
+}
+func (m *MockDB) GetCoachThreads() ([]models.CoachThread, error)      { return nil, m.Err }
+func (m *MockDB) GetCoachThread(id int) (*models.CoachThread, error)  { return nil, m.Err }
+func (m *MockDB) CreateCoachThread(t models.CoachThread) (int, error) { return 1, m.Err }
+func (m *MockDB) DeleteCoachThread(id int) error                      { return m.Err }
+func (m *MockDB) GetCoachMessages(threadID int) ([]models.CoachMessage, error) {
+	var messages []models.CoachMessage
+	for _, msg := range m.CoachMessages {
+		if msg.ThreadID == threadID {
+			messages = append(messages, msg)
+		}
+	}
+	return messages, m.Err
+}
+func (m *MockDB) AddCoachMessages(messages []models.CoachMessage) error {
+	m.CoachMessages = append(m.CoachMessages, messages...)
+	return m.Err
+}
+func (m *MockDB) GetAIPrivacy() (models.AIPrivacy, error) { return m.Privacy, m.Err }
+func (m *MockDB) SaveAIPrivacy(p models.AIPrivacy) error {
+	m.Privacy = p
+	return m.Err
+}
+func (m *MockDB) GetPromptTemplate(name string) (*models.PromptTemplate, error) {
+	if t, ok := m.Templates[name]; ok {
+		return &t, m.Err
+	}
+	return nil, m.Err
+}
+func (m *MockDB) SavePromptTemplate(t models.PromptTemplate) error {
+	if m.Templates == nil {
+		m.Templates = map[string]models.PromptTemplate{}
+	}
+	m.Templates[t.Name] = t
+	return m.Err
+}
+func (m *MockDB) DeletePromptTemplate(name string) error {
+	delete(m.Templates, name)
+	return m.Err
+}
+func (m *MockDB) AddAIUsage(u models.AIUsage) error {
+	m.Usage = append(m.Usage, u)
+	return m.Err
+}
+func (m *MockDB) GetAutoSummarySettings() (models.AutoSummarySettings, error) {
+	if m.AutoSummary == nil {
+		return models.DefaultAutoSummarySettings(), m.Err
+	}
+	return *m.AutoSummary, m.Err
+}
+func (m *MockDB) SaveAutoSummarySettings(s models.AutoSummarySettings) error {
+	if m.AutoSummary != nil {
+		s.LastWeek = m.AutoSummary.LastWeek
+	}
+	m.AutoSummary = &s
+	return m.Err
+}
+func (m *MockDB) SetAutoSummaryWeek(week string) error {
+	if m.AutoSummary != nil {
+		m.AutoSummary.LastWeek = week
+	}
+	return m.Err
+}
+func (m *MockDB) GetAIUsage(since time.Time) ([]models.AIUsage, error) {
+	var usage []models.AIUsage
+	for _, u := range m.Usage {
+		if !u.CreatedAt.Before(since) {
+			usage = append(usage, u)
+		}
+	}
+	return usage, m.Err
+}
 
 func TestCalculatePillars(t *testing.T) {
 	t.Run("Health Pillar Math", func(t *testing.T) {
-		m := models.HealthMetrics{SleepScore: 80, WaistCm: 80, RHR: 60, SystolicBP: 118, DiastolicBP: 76, NutritionScore: 8}
+		m := models.HealthMetrics{SleepScore: models.Int(80), WaistCm: models.Float(80), RHR: models.Int(60), SystolicBP: models.Int(118), DiastolicBP: models.Int(76), NutritionScore: models.Float(8)}
 		score := CalculateHealthPillar(m, 60, 80.0/180.0)
 		if score <= 0 {
 			t.Errorf("Expected positive health score, got %f", score)
@@ -65,8 +228,8 @@ func TestCalculatePillars(t *testing.T) {
 	})
 
 	t.Run("Fitness Pillar Caps Excess Workout Volume", func(t *testing.T) {
-		high := models.FitnessMetrics{VO2Max: 42, Workouts: 7, DailySteps: 9000, Mobility: 3, CardioRecovery: 25}
-		extreme := models.FitnessMetrics{VO2Max: 42, Workouts: 12, DailySteps: 9000, Mobility: 3, CardioRecovery: 25}
+		high := models.FitnessMetrics{VO2Max: models.Float(42), Workouts: models.Int(7), DailySteps: models.Int(9000), Mobility: models.Int(3), CardioRecovery: models.Int(25)}
+		extreme := models.FitnessMetrics{VO2Max: models.Float(42), Workouts: models.Int(12), DailySteps: models.Int(9000), Mobility: models.Int(3), CardioRecovery: models.Int(25)}
 
 		highScore := CalculateFitnessPillar(high, 40, 75)
 		extremeScore := CalculateFitnessPillar(extreme, 40, 75)
@@ -77,8 +240,8 @@ func TestCalculatePillars(t *testing.T) {
 	})
 
 	t.Run("Reserve Markers Outweigh One Behavior Spike", func(t *testing.T) {
-		reserveHeavy := models.FitnessMetrics{VO2Max: 46, Workouts: 4, DailySteps: 9000, Mobility: 3, CardioRecovery: 25}
-		behaviorHeavy := models.FitnessMetrics{VO2Max: 42, Workouts: 10, DailySteps: 9000, Mobility: 3, CardioRecovery: 25}
+		reserveHeavy := models.FitnessMetrics{VO2Max: models.Float(46), Workouts: models.Int(4), DailySteps: models.Int(9000), Mobility: models.Int(3), CardioRecovery: models.Int(25)}
+		behaviorHeavy := models.FitnessMetrics{VO2Max: models.Float(42), Workouts: models.Int(10), DailySteps: models.Int(9000), Mobility: models.Int(3), CardioRecovery: models.Int(25)}
 
 		reserveScore := CalculateFitnessPillar(reserveHeavy, 40, 75)
 		behaviorScore := CalculateFitnessPillar(behaviorHeavy, 40, 75)
@@ -89,8 +252,8 @@ func TestCalculatePillars(t *testing.T) {
 	})
 
 	t.Run("Blood Pressure Rewards Healthier Range", func(t *testing.T) {
-		healthy := calculateBloodPressurePoints(models.HealthMetrics{SystolicBP: 118, DiastolicBP: 76})
-		elevated := calculateBloodPressurePoints(models.HealthMetrics{SystolicBP: 136, DiastolicBP: 88})
+		healthy := calculateBloodPressurePoints(models.HealthMetrics{SystolicBP: models.Int(118), DiastolicBP: models.Int(76)})
+		elevated := calculateBloodPressurePoints(models.HealthMetrics{SystolicBP: models.Int(136), DiastolicBP: models.Int(88)})
 
 		if healthy <= elevated {
 			t.Fatalf("Expected healthier blood pressure to score better, got %.2f vs %.2f", healthy, elevated)
@@ -99,9 +262,9 @@ func TestCalculatePillars(t *testing.T) {
 
 	t.Run("Strength Scores Leg Press Performance with RSI", func(t *testing.T) {
 		// 60kg person pressing 180kg for 10 reps: RSI = (180/60) * 10 = 30 (strong)
-		lightPerson := calculateLowerBodyStrengthPoints(models.FitnessMetrics{LowerBodyWeight: 180, LowerBodyReps: 10}, 60)
+		lightPerson := calculateLowerBodyStrengthPoints(models.FitnessMetrics{LowerBodyWeight: models.Float(180), LowerBodyReps: models.Int(10)}, 60)
 		// 90kg person pressing 180kg for 10 reps: RSI = (180/90) * 10 = 20 (moderate)
-		heavyPerson := calculateLowerBodyStrengthPoints(models.FitnessMetrics{LowerBodyWeight: 180, LowerBodyReps: 10}, 90)
+		heavyPerson := calculateLowerBodyStrengthPoints(models.FitnessMetrics{LowerBodyWeight: models.Float(180), LowerBodyReps: models.Int(10)}, 90)
 
 		if lightPerson <= 0 {
 			t.Fatalf("Expected leg press strength score to be positive, got %.2f", lightPerson)
@@ -113,15 +276,15 @@ func TestCalculatePillars(t *testing.T) {
 
 	t.Run("Behavior Smoothing Uses Recent Consistency", func(t *testing.T) {
 		history := []models.FitnessMetrics{
-			{Workouts: 0, DailySteps: 8000, Mobility: 3},
-			{Workouts: 0, DailySteps: 8000, Mobility: 3},
-			{Workouts: 0, DailySteps: 8000, Mobility: 3},
-			{Workouts: 8, DailySteps: 8000, Mobility: 3},
+			{Workouts: models.Int(0), DailySteps: models.Int(8000), Mobility: models.Int(3)},
+			{Workouts: models.Int(0), DailySteps: models.Int(8000), Mobility: models.Int(3)},
+			{Workouts: models.Int(0), DailySteps: models.Int(8000), Mobility: models.Int(3)},
+			{Workouts: models.Int(8), DailySteps: models.Int(8000), Mobility: models.Int(3)},
 		}
 
 		smoothed := smoothFitnessBehaviors(history, history[len(history)-1])
-		if smoothed.Workouts != 2 {
-			t.Fatalf("Expected workout smoothing to average recent weeks, got %d", smoothed.Workouts)
+		if *smoothed.Workouts != 2 {
+			t.Fatalf("Expected workout smoothing to average recent weeks, got %d", *smoothed.Workouts)
 		}
 	})
 }
@@ -139,18 +302,17 @@ func TestGetAllWeeklyScores_Compounding(t *testing.T) {
 		AllDates:    []string{date2, date1},
 		UserProfile: &models.UserProfile{BirthDate: "1990-12-26", HeightCm: 180, Sex: "male"},
 		HealthMap: map[string]*models.HealthMetrics{
-			date1: {RHR: 65, WaistCm: 85, BodyWeightKg: 75, SleepScore: 75, NutritionScore: 7},
-			date2: {RHR: 60, WaistCm: 85, BodyWeightKg: 75, SleepScore: 85, NutritionScore: 8},
+			date1: {RHR: models.Int(65), WaistCm: models.Float(85), BodyWeightKg: models.Float(75), SleepScore: models.Int(75), NutritionScore: models.Float(7)},
+			date2: {RHR: models.Int(60), WaistCm: models.Float(85), BodyWeightKg: models.Float(75), SleepScore: models.Int(85), NutritionScore: models.Float(8)},
 		},
 		FitnessMap: map[string]*models.FitnessMetrics{
-			date1: {VO2Max: 40, Workouts: 3, DailySteps: 8000, Mobility: 3, CardioRecovery: 20, DeadHangSeconds: 50},
-			date2: {VO2Max: 42, Workouts: 4, DailySteps: 10000, Mobility: 3, CardioRecovery: 25, DeadHangSeconds: 65},
+			date1: {VO2Max: models.Float(40), Workouts: models.Int(3), DailySteps: models.Int(8000), Mobility: models.Int(3), CardioRecovery: models.Int(20), DeadHangSeconds: models.Int(50)},
+			date2: {VO2Max: models.Float(42), Workouts: models.Int(4), DailySteps: models.Int(10000), Mobility: models.Int(3), CardioRecovery: models.Int(25), DeadHangSeconds: models.Int(65)},
 		},
 		CognitionMap: map[string]*models.CognitionMetrics{
-			date1: {Mindfulness: 3, DeepLearning: 50, StressScore: 3, SocialDays: 3},
-			date2: {Mindfulness: 4, DeepLearning: 80, StressScore: 2, SocialDays: 5},
+			date1: {Mindfulness: models.Int(3), DeepLearning: models.Int(50), StressScore: models.Int(3), SocialDays: models.Int(3)},
+			date2: {Mindfulness: models.Int(4), DeepLearning: models.Int(80), StressScore: models.Int(2), SocialDays: models.Int(5)},
 		},
-		RHRBaselineValue: 65,
 	}
 
 	scores, err := GetAllWeeklyScores(mock)
@@ -182,9 +344,9 @@ func TestGetAllWeeklyScores_Compounding(t *testing.T) {
 
 func TestCalculateMasterScore_ConvergesInsteadOfRunningAway(t *testing.T) {
 	profile := models.UserProfile{BirthDate: "1990-01-01", HeightCm: 180, Sex: "male"}
-	health := models.HealthMetrics{SleepScore: 84, WaistCm: 82, BodyWeightKg: 75, RHR: 58, NutritionScore: 8.5}
-	fitness := models.FitnessMetrics{VO2Max: 47, Workouts: 5, DailySteps: 10500, Mobility: 4, CardioRecovery: 28, DeadHangSeconds: 85}
-	cognition := models.CognitionMetrics{Mindfulness: 4, DeepLearning: 120, StressScore: 2, SocialDays: 5}
+	health := models.HealthMetrics{SleepScore: models.Int(84), WaistCm: models.Float(82), BodyWeightKg: models.Float(75), RHR: models.Int(58), NutritionScore: models.Float(8.5)}
+	fitness := models.FitnessMetrics{VO2Max: models.Float(47), Workouts: models.Int(5), DailySteps: models.Int(10500), Mobility: models.Int(4), CardioRecovery: models.Int(28), DeadHangSeconds: models.Int(85)}
+	cognition := models.CognitionMetrics{Mindfulness: models.Int(4), DeepLearning: models.Int(120), StressScore: models.Int(2), SocialDays: models.Int(5)}
 
 	score := defaultMasterScore
 	calculationDate := time.Date(2026, time.January, 4, 0, 0, 0, 0, time.UTC)
@@ -198,7 +360,7 @@ func TestCalculateMasterScore_ConvergesInsteadOfRunningAway(t *testing.T) {
 			cognition,
 			65,
 			42,
-			health.WaistCm/profile.HeightCm,
+			*health.WaistCm/profile.HeightCm,
 			calculationDate,
 		)
 		score = nextScore
@@ -227,20 +389,16 @@ func TestGetAllWeeklyScores_UsesHistoricalRHRBaseline(t *testing.T) {
 		AllDates:    []string{date2, date1},
 		UserProfile: &models.UserProfile{BirthDate: "1990-01-01", HeightCm: 180, Sex: "male"},
 		HealthMap: map[string]*models.HealthMetrics{
-			date1: {RHR: 70, WaistCm: 85, BodyWeightKg: 75, SleepScore: 75, NutritionScore: 7},
-			date2: {RHR: 60, WaistCm: 85, BodyWeightKg: 75, SleepScore: 75, NutritionScore: 7},
+			date1: {RHR: models.Int(70), WaistCm: models.Float(85), BodyWeightKg: models.Float(75), SleepScore: models.Int(75), NutritionScore: models.Float(7)},
+			date2: {RHR: models.Int(60), WaistCm: models.Float(85), BodyWeightKg: models.Float(75), SleepScore: models.Int(75), NutritionScore: models.Float(7)},
 		},
 		FitnessMap: map[string]*models.FitnessMetrics{
-			date1: {VO2Max: 42, Workouts: 3, DailySteps: 8000, Mobility: 3, CardioRecovery: 25, DeadHangSeconds: 60},
-			date2: {VO2Max: 42, Workouts: 3, DailySteps: 8000, Mobility: 3, CardioRecovery: 25, DeadHangSeconds: 60},
+			date1: {VO2Max: models.Float(42), Workouts: models.Int(3), DailySteps: models.Int(8000), Mobility: models.Int(3), CardioRecovery: models.Int(25), DeadHangSeconds: models.Int(60)},
+			date2: {VO2Max: models.Float(42), Workouts: models.Int(3), DailySteps: models.Int(8000), Mobility: models.Int(3), CardioRecovery: models.Int(25), DeadHangSeconds: models.Int(60)},
 		},
 		CognitionMap: map[string]*models.CognitionMetrics{
-			date1: {Mindfulness: 3, DeepLearning: 90, StressScore: 3, SocialDays: 4},
-			date2: {Mindfulness: 3, DeepLearning: 90, StressScore: 3, SocialDays: 4},
-		},
-		RHRBaselineByDate: map[string]int{
-			date1: 70,
-			date2: 63,
+			date1: {Mindfulness: models.Int(3), DeepLearning: models.Int(90), StressScore: models.Int(3), SocialDays: models.Int(4)},
+			date2: {Mindfulness: models.Int(3), DeepLearning: models.Int(90), StressScore: models.Int(3), SocialDays: models.Int(4)},
 		},
 	}
 
@@ -254,8 +412,9 @@ func TestGetAllWeeklyScores_UsesHistoricalRHRBaseline(t *testing.T) {
 	}
 
 	lateWeek := mock.HealthMap[date2]
-	whtr := lateWeek.WaistCm / mock.UserProfile.HeightCm
-	expectedHistorical := CalculateHealthPillar(*lateWeek, 63, whtr)
+	whtr := *lateWeek.WaistCm / mock.UserProfile.HeightCm
+	// The rolling baseline for the later week averages both weeks: (70 + 60) / 2
+	expectedHistorical := CalculateHealthPillar(*lateWeek, 65, whtr)
 	expectedShared := CalculateHealthPillar(*lateWeek, 70, whtr)
 
 	if scores[1].HealthScore != expectedHistorical {
@@ -285,29 +444,26 @@ func TestGetAllWeeklyScores_WeightsConsistencyOverOneWeekSpike(t *testing.T) {
 		healthMap := make(map[string]*models.HealthMetrics, len(dates))
 		fitnessMap := make(map[string]*models.FitnessMetrics, len(dates))
 		cognitionMap := make(map[string]*models.CognitionMetrics, len(dates))
-		rhrBaselineByDate := make(map[string]int, len(dates))
 
 		for i, date := range ordered {
-			healthMap[date] = &models.HealthMetrics{RHR: 60, WaistCm: 85, BodyWeightKg: 75, SleepScore: 80, NutritionScore: 8}
+			healthMap[date] = &models.HealthMetrics{RHR: models.Int(60), WaistCm: models.Float(85), BodyWeightKg: models.Float(75), SleepScore: models.Int(80), NutritionScore: models.Float(8)}
 			fitnessMap[date] = &models.FitnessMetrics{
-				VO2Max:          42,
-				Workouts:        workouts[i],
-				DailySteps:      8000,
-				Mobility:        3,
-				CardioRecovery:  25,
-				DeadHangSeconds: 60,
+				VO2Max:          models.Float(42),
+				Workouts:        models.Int(workouts[i]),
+				DailySteps:      models.Int(8000),
+				Mobility:        models.Int(3),
+				CardioRecovery:  models.Int(25),
+				DeadHangSeconds: models.Int(60),
 			}
-			cognitionMap[date] = &models.CognitionMetrics{Mindfulness: 3, DeepLearning: 90, StressScore: 3, SocialDays: 4}
-			rhrBaselineByDate[date] = 60
+			cognitionMap[date] = &models.CognitionMetrics{Mindfulness: models.Int(3), DeepLearning: models.Int(90), StressScore: models.Int(3), SocialDays: models.Int(4)}
 		}
 
 		return &MockDB{
-			AllDates:          dates,
-			UserProfile:       &models.UserProfile{BirthDate: "1990-01-01", HeightCm: 180, Sex: "male"},
-			HealthMap:         healthMap,
-			FitnessMap:        fitnessMap,
-			CognitionMap:      cognitionMap,
-			RHRBaselineByDate: rhrBaselineByDate,
+			AllDates:     dates,
+			UserProfile:  &models.UserProfile{BirthDate: "1990-01-01", HeightCm: 180, Sex: "male"},
+			HealthMap:    healthMap,
+			FitnessMap:   fitnessMap,
+			CognitionMap: cognitionMap,
 		}
 	}
 
@@ -345,10 +501,10 @@ func TestDataGate_Behavior(t *testing.T) {
 		AllDates:    []string{date},
 		UserProfile: &models.UserProfile{BirthDate: "1995-12-26", HeightCm: 180},
 		HealthMap: map[string]*models.HealthMetrics{
-			date: {SleepScore: 80},
+			date: {SleepScore: models.Int(80)},
 		},
 		FitnessMap: map[string]*models.FitnessMetrics{
-			date: {VO2Max: 40},
+			date: {VO2Max: models.Float(40)},
 		},
 		CognitionMap: make(map[string]*models.CognitionMetrics), // MISSING
 	}
@@ -373,21 +529,16 @@ func TestGetAllWeeklyScores_FillsMissingWeeksAndAppliesAging(t *testing.T) {
 		AllDates:    []string{date3, date1},
 		UserProfile: &models.UserProfile{BirthDate: "1990-01-01", HeightCm: 180, Sex: "male"},
 		HealthMap: map[string]*models.HealthMetrics{
-			date1: {RHR: 60, WaistCm: 85, BodyWeightKg: 75, SleepScore: 80, NutritionScore: 8, SystolicBP: 120, DiastolicBP: 80},
-			date3: {RHR: 60, WaistCm: 85, BodyWeightKg: 75, SleepScore: 80, NutritionScore: 8, SystolicBP: 120, DiastolicBP: 80},
+			date1: {RHR: models.Int(60), WaistCm: models.Float(85), BodyWeightKg: models.Float(75), SleepScore: models.Int(80), NutritionScore: models.Float(8), SystolicBP: models.Int(120), DiastolicBP: models.Int(80)},
+			date3: {RHR: models.Int(60), WaistCm: models.Float(85), BodyWeightKg: models.Float(75), SleepScore: models.Int(80), NutritionScore: models.Float(8), SystolicBP: models.Int(120), DiastolicBP: models.Int(80)},
 		},
 		FitnessMap: map[string]*models.FitnessMetrics{
-			date1: {VO2Max: 42, Workouts: 4, DailySteps: 8000, Mobility: 3, CardioRecovery: 25, LowerBodyWeight: 180, LowerBodyReps: 10, DeadHangSeconds: 60},
-			date3: {VO2Max: 42, Workouts: 4, DailySteps: 8000, Mobility: 3, CardioRecovery: 25, LowerBodyWeight: 180, LowerBodyReps: 10, DeadHangSeconds: 60},
+			date1: {VO2Max: models.Float(42), Workouts: models.Int(4), DailySteps: models.Int(8000), Mobility: models.Int(3), CardioRecovery: models.Int(25), LowerBodyWeight: models.Float(180), LowerBodyReps: models.Int(10), DeadHangSeconds: models.Int(60)},
+			date3: {VO2Max: models.Float(42), Workouts: models.Int(4), DailySteps: models.Int(8000), Mobility: models.Int(3), CardioRecovery: models.Int(25), LowerBodyWeight: models.Float(180), LowerBodyReps: models.Int(10), DeadHangSeconds: models.Int(60)},
 		},
 		CognitionMap: map[string]*models.CognitionMetrics{
-			date1: {Mindfulness: 3, DeepLearning: 90, StressScore: 2, SocialDays: 4},
-			date3: {Mindfulness: 3, DeepLearning: 90, StressScore: 2, SocialDays: 4},
-		},
-		RHRBaselineByDate: map[string]int{
-			date1:       60,
-			missingDate: 60,
-			date3:       60,
+			date1: {Mindfulness: models.Int(3), DeepLearning: models.Int(90), StressScore: models.Int(2), SocialDays: models.Int(4)},
+			date3: {Mindfulness: models.Int(3), DeepLearning: models.Int(90), StressScore: models.Int(2), SocialDays: models.Int(4)},
 		},
 	}
 
@@ -407,58 +558,129 @@ func TestGetAllWeeklyScores_FillsMissingWeeksAndAppliesAging(t *testing.T) {
 	if scores[1].AgingTax <= 0 {
 		t.Fatalf("Expected aging tax to apply during missing week, got %.4f", scores[1].AgingTax)
 	}
+	if scores[0].IsImputed() || scores[0].Confidence != 1 {
+		t.Fatalf("Expected measured week to have full confidence, got %+v", scores[0])
+	}
+	if scores[1].HealthProvenance.Status != models.ProvenanceCarriedForward || scores[1].HealthProvenance.MissedWeeks != 1 {
+		t.Fatalf("Expected missing week health pillar to be carried forward, got %+v", scores[1].HealthProvenance)
+	}
+	if !scores[1].IsImputed() || scores[1].Confidence >= scores[0].Confidence {
+		t.Fatalf("Expected missing week to be flagged as imputed with lower confidence, got %+v", scores[1])
+	}
+}
+
+func TestPillarProvenance(t *testing.T) {
+	testCases := []struct {
+		missed int
+		status string
+		label  string
+	}{
+		{0, models.ProvenanceMeasured, "measured"},
+		{1, models.ProvenanceCarriedForward, "carried forward"},
+		{stableCarryWeeks, models.ProvenanceCarriedForward, "carried forward"},
+		{stableCarryWeeks + 1, models.ProvenanceDrifted, "drifted 3w"},
+	}
+
+	for _, tc := range testCases {
+		p := pillarProvenance(tc.missed)
+		if p.Status != tc.status || p.Label() != tc.label {
+			t.Errorf("pillarProvenance(%d) = %+v (%s); expected %s (%s)", tc.missed, p, p.Label(), tc.status, tc.label)
+		}
+	}
+
+	full := scoreConfidence(pillarProvenance(0), pillarProvenance(0), pillarProvenance(0))
+	partial := scoreConfidence(pillarProvenance(0), pillarProvenance(2), pillarProvenance(0))
+	if full != 1 || partial >= full {
+		t.Fatalf("Expected confidence to drop with imputed pillars, got %.2f vs %.2f", partial, full)
+	}
 }
 
 func TestImputationRules_SubjectiveCarryThenDrift(t *testing.T) {
-	start := models.HealthMetrics{SleepScore: 80, NutritionScore: 8}
+	start := models.HealthMetrics{SleepScore: models.Int(80), NutritionScore: models.Float(8)}
 	profile := models.UserProfile{HeightCm: 180}
 
 	weekOne := imputeHealthMetrics(start, 1, profile, 60)
-	if weekOne.SleepScore != 80 || weekOne.NutritionScore != 8 {
-		t.Fatalf("Expected first missed week to carry subjective values, got sleep=%d nutrition=%.1f", weekOne.SleepScore, weekOne.NutritionScore)
+	if *weekOne.SleepScore != 80 || *weekOne.NutritionScore != 8 {
+		t.Fatalf("Expected first missed week to carry subjective values, got sleep=%d nutrition=%.1f", *weekOne.SleepScore, *weekOne.NutritionScore)
 	}
 
 	weekTwo := imputeHealthMetrics(weekOne, 2, profile, 60)
-	if weekTwo.SleepScore != 78 {
-		t.Fatalf("Expected second missed week sleep to drift toward neutral, got %d", weekTwo.SleepScore)
+	if *weekTwo.SleepScore != 78 {
+		t.Fatalf("Expected second missed week sleep to drift toward neutral, got %d", *weekTwo.SleepScore)
 	}
-	if weekTwo.NutritionScore != 7.5 {
-		t.Fatalf("Expected second missed week nutrition to drift toward neutral, got %.1f", weekTwo.NutritionScore)
+	if *weekTwo.NutritionScore != 7.5 {
+		t.Fatalf("Expected second missed week nutrition to drift toward neutral, got %.1f", *weekTwo.NutritionScore)
 	}
 }
 
 func TestImputationRules_StableCarryThenDrift(t *testing.T) {
-	start := models.FitnessMetrics{VO2Max: 50, CardioRecovery: 30, LowerBodyWeight: 200, LowerBodyReps: 12, DeadHangSeconds: 90}
+	start := models.FitnessMetrics{VO2Max: models.Float(50), CardioRecovery: models.Int(30), LowerBodyWeight: models.Float(200), LowerBodyReps: models.Int(12), DeadHangSeconds: models.Int(90)}
 	profile := models.UserProfile{Sex: "male"}
 
 	weekOne := imputeFitnessMetrics(start, 1, 35, profile)
 	weekTwo := imputeFitnessMetrics(weekOne, 2, 35, profile)
-	if weekTwo.VO2Max != 50 || weekTwo.CardioRecovery != 30 {
-		t.Fatalf("Expected first two missed weeks to carry stable values, got vo2=%.1f recovery=%d", weekTwo.VO2Max, weekTwo.CardioRecovery)
+	if *weekTwo.VO2Max != 50 || *weekTwo.CardioRecovery != 30 {
+		t.Fatalf("Expected first two missed weeks to carry stable values, got vo2=%.1f recovery=%d", *weekTwo.VO2Max, *weekTwo.CardioRecovery)
 	}
 
 	weekThree := imputeFitnessMetrics(weekTwo, 3, 35, profile)
-	if weekThree.VO2Max >= weekTwo.VO2Max {
-		t.Fatalf("Expected stable metrics to drift toward baseline after two missed weeks, got %.1f vs %.1f", weekThree.VO2Max, weekTwo.VO2Max)
+	if *weekThree.VO2Max >= *weekTwo.VO2Max {
+		t.Fatalf("Expected stable metrics to drift toward baseline after two missed weeks, got %.1f vs %.1f", *weekThree.VO2Max, *weekTwo.VO2Max)
 	}
-	if weekThree.CardioRecovery != 29 {
-		t.Fatalf("Expected cardio recovery to drift toward neutral, got %d", weekThree.CardioRecovery)
+	if *weekThree.CardioRecovery != 29 {
+		t.Fatalf("Expected cardio recovery to drift toward neutral, got %d", *weekThree.CardioRecovery)
 	}
 }
 
 func TestImputationRules_BehaviorsDecayTowardZero(t *testing.T) {
-	start := models.CognitionMetrics{Mindfulness: 4, DeepLearning: 90, SocialDays: 5, StressScore: 2}
+	start := models.CognitionMetrics{Mindfulness: models.Int(4), DeepLearning: models.Int(90), SocialDays: models.Int(5), StressScore: models.Int(2)}
 
 	weekOne := imputeCognitionMetrics(start, 1)
-	if weekOne.Mindfulness != 2 || weekOne.DeepLearning != 45 || weekOne.SocialDays != 3 {
+	if *weekOne.Mindfulness != 2 || *weekOne.DeepLearning != 45 || *weekOne.SocialDays != 3 {
 		t.Fatalf("Expected behaviors to decay on first missed week, got %+v", weekOne)
 	}
 
 	weekTwo := imputeCognitionMetrics(weekOne, 2)
-	if weekTwo.Mindfulness != 1 || weekTwo.DeepLearning != 23 || weekTwo.SocialDays != 2 {
+	if *weekTwo.Mindfulness != 1 || *weekTwo.DeepLearning != 23 || *weekTwo.SocialDays != 2 {
 		t.Fatalf("Expected behaviors to keep decaying, got %+v", weekTwo)
 	}
-	if weekTwo.StressScore != 3 {
-		t.Fatalf("Expected stress to drift toward neutral after the first missed week, got %d", weekTwo.StressScore)
+	if *weekTwo.StressScore != 3 {
+		t.Fatalf("Expected stress to drift toward neutral after the first missed week, got %d", *weekTwo.StressScore)
+	}
+}
+
+func TestSkippedMetricsAreNeutral(t *testing.T) {
+	if score := CalculateHealthPillar(models.HealthMetrics{}, 0, 0); score != 0 {
+		t.Errorf("Expected an empty health entry to score neutrally, got %.2f", score)
+	}
+	if score := CalculateFitnessPillar(models.FitnessMetrics{}, 40, 0); score != 0 {
+		t.Errorf("Expected an empty fitness entry to score neutrally, got %.2f", score)
+	}
+	if score := CalculateCognitionPillar(models.CognitionMetrics{}); score != 0 {
+		t.Errorf("Expected an empty cognition entry to score neutrally, got %.2f", score)
+	}
+
+	skipped := CalculateFitnessPillar(models.FitnessMetrics{VO2Max: models.Float(40)}, 40, 75)
+	zeroSteps := CalculateFitnessPillar(models.FitnessMetrics{VO2Max: models.Float(40), DailySteps: models.Int(0)}, 40, 75)
+	if skipped != 0 || zeroSteps >= skipped {
+		t.Fatalf("Expected skipped steps to be neutral and 0 steps to be penalized, got %.2f vs %.2f", skipped, zeroSteps)
+	}
+}
+
+func TestCarryForwardOnlyFillsReserveMarkers(t *testing.T) {
+	previous := models.FitnessMetrics{VO2Max: models.Float(45), DeadHangSeconds: models.Int(70), Workouts: models.Int(2)}
+	current := models.FitnessMetrics{Workouts: models.Int(4)}
+
+	merged := carryForwardFitness(current, previous)
+	if merged.VO2Max == nil || *merged.VO2Max != 45 || merged.DeadHangSeconds == nil || *merged.DeadHangSeconds != 70 {
+		t.Fatalf("Expected skipped reserve markers to carry forward, got %+v", merged)
+	}
+	if *merged.Workouts != 4 {
+		t.Fatalf("Expected recorded workouts to win, got %d", *merged.Workouts)
+	}
+
+	merged = carryForwardFitness(models.FitnessMetrics{VO2Max: models.Float(46)}, previous)
+	if merged.Workouts != nil {
+		t.Fatalf("Expected skipped behaviors to stay empty, got %d", *merged.Workouts)
 	}
 }
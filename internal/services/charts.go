@@ -0,0 +1,323 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"health-balance/internal/database"
+	"health-balance/internal/models"
+	"health-balance/internal/utils"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultChartRange         = "6m"
+	DefaultMovingAverageWeeks = 4
+	MaxMovingAverageWeeks     = 12
+
+	chartWidth   = 640.0
+	chartHeight  = 240.0
+	chartPadLeft = 48.0
+	chartPadTop  = 12.0
+	chartPadEdge = 12.0
+	chartPadAxis = 28.0
+	chartYTicks  = 4
+	chartXTicks  = 4
+)
+
+// ErrInvalidChartRequest is returned for unknown series, ranges or moving average windows
+var ErrInvalidChartRequest = errors.New("invalid chart request")
+
+// ChartRanges lists the selectable ranges in display order
+var ChartRanges = []string{"3m", "6m", "1y", "all"}
+
+// scoreChartSeries are the score-derived series, next to the metric catalog
+var scoreChartSeries = []models.MetricDefinition{
+	{Key: "score", Label: "Total Score", Format: "%.0f"},
+	{Key: "health_score", Label: "Health Pillar", Format: "%.1f"},
+	{Key: "fitness_score", Label: "Fitness Pillar", Format: "%.1f"},
+	{Key: "cognition_score", Label: "Cognition Pillar", Format: "%.1f"},
+	{Key: "aging_tax", Label: "Aging Tax", Format: "%.2f"},
+}
+
+// ChartOptions returns every chartable series grouped for the picker
+func ChartOptions() []models.ChartOptionGroup {
+	scores := models.ChartOptionGroup{Label: "Scores"}
+	for _, s := range scoreChartSeries {
+		scores.Options = append(scores.Options, models.ChartOption{Key: s.Key, Label: s.Label})
+	}
+
+	groups := []models.ChartOptionGroup{scores}
+	for _, pillar := range []string{models.PillarHealth, models.PillarFitness, models.PillarCognition} {
+		group := models.ChartOptionGroup{Label: strings.ToUpper(pillar[:1]) + pillar[1:]}
+		for _, m := range models.MetricsForPillar(pillar) {
+			group.Options = append(group.Options, models.ChartOption{Key: m.Key, Label: m.Label})
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// ChartRangeStart returns the first date included in the given range, or the zero time for "all"
+func ChartRangeStart(rangeKey string, now time.Time) (time.Time, error) {
+	switch rangeKey {
+	case "3m":
+		return now.AddDate(0, -3, 0), nil
+	case "6m":
+		return now.AddDate(0, -6, 0), nil
+	case "1y":
+		return now.AddDate(-1, 0, 0), nil
+	case "all":
+		return time.Time{}, nil
+	default:
+		return time.Time{}, fmt.Errorf("%w: unknown range %q", ErrInvalidChartRequest, rangeKey)
+	}
+}
+
+// GetChartSeries builds the series for a score or catalog metric over the given range
+// with a trailing moving average of movingAverageWeeks points (0 disables it).
+func GetChartSeries(db database.Querier, key, rangeKey string, movingAverageWeeks int) (*models.ChartSeries, error) {
+	if movingAverageWeeks < 0 || movingAverageWeeks > MaxMovingAverageWeeks {
+		return nil, fmt.Errorf("%w: moving average must be between 0 and %d weeks", ErrInvalidChartRequest, MaxMovingAverageWeeks)
+	}
+
+	end := utils.GetCurrentWeekSundayDate()
+	endDate, err := time.Parse("2006-01-02", end)
+	if err != nil {
+		return nil, fmt.Errorf("invalid current week date: %w", err)
+	}
+	startDate, err := ChartRangeStart(rangeKey, endDate)
+	if err != nil {
+		return nil, err
+	}
+	start := ""
+	if !startDate.IsZero() {
+		start = startDate.Format("2006-01-02")
+	}
+
+	var (
+		definition models.MetricDefinition
+		points     []models.ChartPoint
+	)
+
+	if metric, ok := models.LookupMetric(key); ok {
+		definition = metric
+		series, err := db.GetMetricSeries(key, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s series: %w", key, err)
+		}
+		for _, p := range series {
+			points = append(points, models.ChartPoint{Date: p.Date, Value: p.Value})
+		}
+	} else if scoreSeries, ok := lookupScoreSeries(key); ok {
+		definition = scoreSeries
+		scores, err := GetAllWeeklyScores(db)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range scores {
+			if s.Date < start || s.Date > end {
+				continue
+			}
+			points = append(points, models.ChartPoint{Date: s.Date, Value: s.ScoreValue(key)})
+		}
+	} else {
+		return nil, fmt.Errorf("%w: unknown series %q", ErrInvalidChartRequest, key)
+	}
+
+	if points == nil {
+		points = []models.ChartPoint{}
+	}
+
+	interventions, err := db.GetInterventions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch interventions: %w", err)
+	}
+	annotations := []models.ChartAnnotation{}
+	for _, i := range interventions {
+		if i.StartDate <= end && (i.Ongoing() || i.EndDate >= start) {
+			annotations = append(annotations, models.ChartAnnotation{Label: i.Name, Start: i.StartDate, End: i.EndDate})
+		}
+	}
+
+	notes, err := db.GetWeekNotes(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch week notes: %w", err)
+	}
+	if notes == nil {
+		notes = []models.WeekNote{}
+	}
+
+	return &models.ChartSeries{
+		Key:                definition.Key,
+		Label:              definition.Label,
+		Unit:               definition.Unit,
+		Format:             definition.Format,
+		Range:              rangeKey,
+		MovingAverageWeeks: movingAverageWeeks,
+		Points:             points,
+		MovingAverage:      movingAverage(points, movingAverageWeeks),
+		Annotations:        annotations,
+		Notes:              notes,
+	}, nil
+}
+
+func lookupScoreSeries(key string) (models.MetricDefinition, bool) {
+	for _, s := range scoreChartSeries {
+		if s.Key == key {
+			return s, true
+		}
+	}
+	return models.MetricDefinition{}, false
+}
+
+// movingAverage returns the trailing average of up to window points for each point
+func movingAverage(points []models.ChartPoint, window int) []models.ChartPoint {
+	if window <= 1 || len(points) == 0 {
+		return []models.ChartPoint{}
+	}
+
+	averaged := make([]models.ChartPoint, len(points))
+	var total float64
+	for i, p := range points {
+		total += p.Value
+		if i >= window {
+			total -= points[i-window].Value
+		}
+		count := min(i+1, window)
+		averaged[i] = models.ChartPoint{Date: p.Date, Value: total / float64(count)}
+	}
+	return averaged
+}
+
+// LayoutChart maps a series onto SVG coordinates. Points are placed by date, so
+// gaps from skipped weeks stay visible.
+func LayoutChart(series models.ChartSeries) models.ChartView {
+	view := models.ChartView{
+		Series:     series,
+		Width:      chartWidth,
+		Height:     chartHeight,
+		PlotLeft:   chartPadLeft,
+		PlotRight:  chartWidth - chartPadEdge,
+		PlotTop:    chartPadTop,
+		PlotBottom: chartHeight - chartPadAxis,
+		Options:    ChartOptions(),
+		Ranges:     ChartRanges,
+	}
+	if len(series.Points) == 0 {
+		return view
+	}
+
+	times := make([]time.Time, len(series.Points))
+	low, high := math.Inf(1), math.Inf(-1)
+	for i, p := range series.Points {
+		times[i], _ = time.Parse("2006-01-02", p.Date)
+		low = math.Min(low, p.Value)
+		high = math.Max(high, p.Value)
+	}
+	for _, p := range series.MovingAverage {
+		low = math.Min(low, p.Value)
+		high = math.Max(high, p.Value)
+	}
+
+	// Pad the value range so flat series and extremes don't touch the frame
+	padding := (high - low) * 0.1
+	if padding == 0 {
+		padding = math.Max(math.Abs(high)*0.05, 1)
+	}
+	low, high = low-padding, high+padding
+
+	first, last := times[0], times[len(times)-1]
+	span := last.Sub(first).Hours()
+	xFor := func(t time.Time) float64 {
+		if span == 0 {
+			return (view.PlotLeft + view.PlotRight) / 2
+		}
+		return view.PlotLeft + (t.Sub(first).Hours()/span)*(view.PlotRight-view.PlotLeft)
+	}
+	yFor := func(v float64) float64 {
+		return view.PlotBottom - ((v-low)/(high-low))*(view.PlotBottom-view.PlotTop)
+	}
+
+	notes := make(map[string]string, len(series.Notes))
+	for _, n := range series.Notes {
+		notes[n.Date] = n.Summary()
+	}
+
+	var line []string
+	for i, p := range series.Points {
+		x, y := xFor(times[i]), yFor(p.Value)
+		line = append(line, fmt.Sprintf("%.1f,%.1f", x, y))
+		view.Markers = append(view.Markers, models.ChartMarker{
+			X:     x,
+			Y:     y,
+			Date:  p.Date,
+			Label: models.FormatMetric(series.Format, &p.Value),
+			Note:  notes[p.Date],
+		})
+	}
+	view.Line = strings.Join(line, " ")
+
+	var average []string
+	for i, p := range series.MovingAverage {
+		average = append(average, fmt.Sprintf("%.1f,%.1f", xFor(times[i]), yFor(p.Value)))
+	}
+	view.AverageLine = strings.Join(average, " ")
+
+	// Interventions are clipped to the plotted dates; ongoing ones run to the last point
+	for _, a := range series.Annotations {
+		from, err := time.Parse("2006-01-02", a.Start)
+		if err != nil {
+			continue
+		}
+		to := last
+		if a.End != "" {
+			if to, err = time.Parse("2006-01-02", a.End); err != nil {
+				continue
+			}
+		}
+		if from.Before(first) {
+			from = first
+		}
+		if to.After(last) {
+			to = last
+		}
+		if to.Before(from) {
+			continue
+		}
+		x1, x2 := xFor(from), xFor(to)
+		title := a.Label + ": from " + a.Start
+		if a.End != "" {
+			title += " to " + a.End
+		}
+		view.Bands = append(view.Bands, models.ChartBand{
+			X:      x1,
+			Y:      view.PlotTop,
+			Width:  math.Max(x2-x1, 2),
+			Height: view.PlotBottom - view.PlotTop,
+			Label:  a.Label,
+			Title:  title,
+		})
+	}
+
+	for i := 0; i <= chartYTicks; i++ {
+		value := low + (high-low)*float64(i)/chartYTicks
+		view.YTicks = append(view.YTicks, models.ChartTick{Pos: yFor(value), Label: models.FormatMetric(series.Format, &value)})
+	}
+
+	dateLayout := "Jan 2"
+	if last.Sub(first) > 300*24*time.Hour {
+		dateLayout = "Jan 2006"
+	}
+	ticks := min(chartXTicks, len(times))
+	for i := 0; i < ticks; i++ {
+		idx := 0
+		if ticks > 1 {
+			idx = i * (len(times) - 1) / (ticks - 1)
+		}
+		view.XTicks = append(view.XTicks, models.ChartTick{Pos: xFor(times[idx]), Label: times[idx].Format(dateLayout)})
+	}
+
+	return view
+}
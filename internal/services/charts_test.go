@@ -0,0 +1,187 @@
+package services
+
+import (
+	"errors"
+	"health-balance/internal/models"
+	"health-balance/internal/utils"
+	"testing"
+	"time"
+)
+
+func TestMovingAverage(t *testing.T) {
+	points := []models.ChartPoint{
+		{Date: "2025-01-05", Value: 2},
+		{Date: "2025-01-12", Value: 4},
+		{Date: "2025-01-19", Value: 6},
+		{Date: "2025-01-26", Value: 8},
+	}
+
+	averaged := movingAverage(points, 2)
+	expected := []float64{2, 3, 5, 7}
+	for i, p := range averaged {
+		if p.Value != expected[i] || p.Date != points[i].Date {
+			t.Errorf("Point %d: expected %.1f on %s, got %+v", i, expected[i], points[i].Date, p)
+		}
+	}
+
+	if len(movingAverage(points, 0)) != 0 {
+		t.Error("Expected no moving average when disabled")
+	}
+}
+
+func TestGetChartSeries(t *testing.T) {
+	currentWeek, err := time.Parse("2006-01-02", utils.GetCurrentWeekSundayDate())
+	if err != nil {
+		t.Fatalf("Failed to parse current week: %v", err)
+	}
+	old := currentWeek.AddDate(0, -8, 0).Format("2006-01-02")
+	recent := currentWeek.AddDate(0, 0, -7).Format("2006-01-02")
+	current := currentWeek.Format("2006-01-02")
+
+	mock := &MockDB{
+		HealthMap: map[string]*models.HealthMetrics{
+			old:     {SleepScore: models.Int(60)},
+			recent:  {SleepScore: models.Int(70)},
+			current: {SleepScore: models.Int(80)},
+		},
+	}
+
+	series, err := GetChartSeries(mock, "sleep_score", "6m", 2)
+	if err != nil {
+		t.Fatalf("Failed to get chart series: %v", err)
+	}
+	if len(series.Points) != 2 || series.Points[0].Date != recent || series.Label != "Sleep Score" {
+		t.Fatalf("Expected the two points inside 6 months, got %+v", series)
+	}
+	if len(series.MovingAverage) != 2 || series.MovingAverage[1].Value != 75 {
+		t.Errorf("Expected a 2-week moving average ending at 75, got %+v", series.MovingAverage)
+	}
+
+	all, err := GetChartSeries(mock, "sleep_score", "all", 0)
+	if err != nil || len(all.Points) != 3 {
+		t.Errorf("Expected all three points for the full range, got %+v (%v)", all, err)
+	}
+
+	for _, tc := range []struct{ key, rangeKey string }{
+		{"unknown", "6m"},
+		{"sleep_score", "2w"},
+	} {
+		if _, err := GetChartSeries(mock, tc.key, tc.rangeKey, 4); !errors.Is(err, ErrInvalidChartRequest) {
+			t.Errorf("Expected invalid chart request for %s/%s, got %v", tc.key, tc.rangeKey, err)
+		}
+	}
+	if _, err := GetChartSeries(mock, "sleep_score", "6m", MaxMovingAverageWeeks+1); !errors.Is(err, ErrInvalidChartRequest) {
+		t.Errorf("Expected invalid chart request for an oversized moving average, got %v", err)
+	}
+}
+
+func TestGetChartSeriesForScores(t *testing.T) {
+	currentWeek, err := time.Parse("2006-01-02", utils.GetCurrentWeekSundayDate())
+	if err != nil {
+		t.Fatalf("Failed to parse current week: %v", err)
+	}
+	date := currentWeek.Format("2006-01-02")
+
+	mock := &MockDB{
+		AllDates:     []string{date},
+		UserProfile:  &models.UserProfile{BirthDate: "1990-01-01", HeightCm: 180, Sex: "male"},
+		HealthMap:    map[string]*models.HealthMetrics{date: {SleepScore: models.Int(80)}},
+		FitnessMap:   map[string]*models.FitnessMetrics{date: {VO2Max: models.Float(44)}},
+		CognitionMap: map[string]*models.CognitionMetrics{date: {StressScore: models.Int(2)}},
+	}
+
+	series, err := GetChartSeries(mock, "aging_tax", "3m", 4)
+	if err != nil {
+		t.Fatalf("Failed to get aging tax series: %v", err)
+	}
+	if len(series.Points) != 1 || series.Points[0].Value <= 0 {
+		t.Errorf("Expected one positive aging tax point, got %+v", series.Points)
+	}
+}
+
+func TestLayoutChart(t *testing.T) {
+	series := models.ChartSeries{
+		Key:    "rhr",
+		Format: "%.0f",
+		Points: []models.ChartPoint{
+			{Date: "2025-01-05", Value: 60},
+			{Date: "2025-01-19", Value: 56},
+			{Date: "2025-01-26", Value: 58},
+		},
+	}
+	series.MovingAverage = movingAverage(series.Points, 2)
+
+	view := LayoutChart(series)
+	if len(view.Markers) != 3 || view.Line == "" || view.AverageLine == "" {
+		t.Fatalf("Expected three markers and both lines, got %+v", view)
+	}
+
+	first, second, last := view.Markers[0], view.Markers[1], view.Markers[2]
+	if first.X != view.PlotLeft || last.X != view.PlotRight {
+		t.Errorf("Expected the series to span the plot, got x %.1f..%.1f", first.X, last.X)
+	}
+	// Two weeks out of three should place the second point two thirds across
+	if expected := view.PlotLeft + (view.PlotRight-view.PlotLeft)*2/3; second.X < expected-0.5 || second.X > expected+0.5 {
+		t.Errorf("Expected points to be placed by date at %.1f, got %.1f", expected, second.X)
+	}
+	if second.Y <= first.Y {
+		t.Errorf("Expected the lower value to be drawn lower, got y %.1f vs %.1f", second.Y, first.Y)
+	}
+	for _, m := range view.Markers {
+		if m.Y < view.PlotTop || m.Y > view.PlotBottom {
+			t.Errorf("Expected marker inside the plot area, got %+v", m)
+		}
+	}
+
+	if empty := LayoutChart(models.ChartSeries{Key: "rhr"}); len(empty.Markers) != 0 || len(empty.Options) == 0 {
+		t.Errorf("Expected an empty chart to keep its picker options, got %+v", empty)
+	}
+}
+
+func TestChartAnnotations(t *testing.T) {
+	currentWeek, err := time.Parse("2006-01-02", utils.GetCurrentWeekSundayDate())
+	if err != nil {
+		t.Fatalf("Failed to parse current week: %v", err)
+	}
+	week := func(offset int) string { return currentWeek.AddDate(0, 0, 7*offset).Format("2006-01-02") }
+
+	mock := &MockDB{
+		HealthMap: map[string]*models.HealthMetrics{
+			week(-4): {SleepScore: models.Int(60)},
+			week(-2): {SleepScore: models.Int(70)},
+			week(0):  {SleepScore: models.Int(80)},
+		},
+		Interventions: []models.Intervention{
+			{Name: "No alcohol", StartDate: week(-2)},
+			{Name: "Cold showers", StartDate: week(-10), EndDate: week(-3)},
+			{Name: "Long ago", StartDate: "2001-01-07", EndDate: "2001-03-04"},
+		},
+		WeekNotes: []models.WeekNote{{Date: week(-2), Text: "Flu", Tags: []string{"sick"}}},
+	}
+
+	series, err := GetChartSeries(mock, "sleep_score", "6m", 0)
+	if err != nil {
+		t.Fatalf("Failed to get chart series: %v", err)
+	}
+	if len(series.Annotations) != 2 {
+		t.Fatalf("Expected the two interventions inside 6 months, got %+v", series.Annotations)
+	}
+
+	view := LayoutChart(*series)
+	if view.Markers[1].Note != "[sick] Flu" || view.Markers[0].Note != "" {
+		t.Errorf("Expected only the noted week's marker to carry the note, got %+v", view.Markers)
+	}
+	if len(view.Bands) != 2 {
+		t.Fatalf("Expected two bands, got %+v", view.Bands)
+	}
+	ongoing, ended := view.Bands[0], view.Bands[1]
+	// The ongoing intervention covers the second half of the four plotted weeks
+	if expected := (view.PlotLeft + view.PlotRight) / 2; ongoing.X < expected-0.5 || ongoing.X > expected+0.5 ||
+		ongoing.X+ongoing.Width < view.PlotRight-0.5 {
+		t.Errorf("Expected the ongoing band to run from %.1f to the end, got %+v", expected, ongoing)
+	}
+	// The ended one started before the first point and is clipped to it
+	if ended.X != view.PlotLeft || ended.X+ended.Width > ongoing.X || ended.Height != view.PlotBottom-view.PlotTop {
+		t.Errorf("Expected the ended band to be clipped to the plot start, got %+v", ended)
+	}
+}
@@ -0,0 +1,339 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"health-balance/internal/database"
+	"health-balance/internal/models"
+	"health-balance/internal/utils"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// coachWeeks is how many of the latest weeks the coach sees up front, older data is
+	// available through its tools
+	coachWeeks = 12
+	// maxCoachToolCalls bounds the lookups made for one answer
+	maxCoachToolCalls = 4
+	// maxCoachHistory is how many earlier messages of a thread are sent along with a question
+	maxCoachHistory = 40
+	// maxCoachToolWeeks bounds how far back a single lookup reaches
+	maxCoachToolWeeks = 104
+)
+
+// ErrCoachToolLimit is returned when the model keeps looking up data instead of answering
+var ErrCoachToolLimit = errors.New("the coach kept looking up data without answering")
+
+// coachToolCall matches a reply that asks for a lookup, e.g.
+// CALL metric_history {"metric": "vo2_max", "weeks": 26}
+var coachToolCall = regexp.MustCompile(`^CALL\s+([a-z_]+)\s*(\{.*\})?$`)
+
+// coachTool is data the coach can look up while answering. Models differ in how they
+// support native tool calling, so the coach asks for lookups with a plain text protocol.
+type coachTool struct {
+	Name        string
+	Arguments   string
+	Description string
+	Run         func(db database.Querier, args coachToolArgs, privacy models.AIPrivacy, now time.Time) (string, error)
+}
+
+type coachToolArgs struct {
+	Metric string `json:"metric"`
+	Weeks  int    `json:"weeks"`
+	Date   string `json:"date"`
+}
+
+var coachTools = []coachTool{
+	{
+		Name:        "metric_history",
+		Arguments:   `{"metric": "<key>", "weeks": <1-104>}`,
+		Description: "recorded values of one metric over the latest weeks",
+		Run:         coachMetricHistory,
+	},
+	{
+		Name:        "week",
+		Arguments:   `{"date": "YYYY-MM-DD"}`,
+		Description: "scores, metrics and notes of the week containing the date",
+		Run:         coachWeek,
+	},
+	{
+		Name:        "scores",
+		Arguments:   `{"weeks": <1-104>}`,
+		Description: "weekly total and pillar scores over the latest weeks",
+		Run:         coachScores,
+	},
+}
+
+// AskCoach answers a question in a thread with the configured language model, within the
+// configured timeout and budget. See CoachReply.
+func AskCoach(ctx context.Context, db database.Querier, threadID int, question string) (int, []models.CoachMessage, error) {
+	cfg := LoadLLMConfig()
+	provider, err := NewLLMProvider(cfg)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	provider = MeterLLMProvider(provider, db, cfg, models.AIFeatureCoach)
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+	return CoachReply(ctx, db, provider, threadID, question)
+}
+
+// CoachReply asks provider to answer question given the earlier messages of the thread and
+// the user's latest weeks of data, running the lookups it asks for along the way. A threadID
+// of 0 starts a new thread. The question, lookups and answer are only stored once the answer
+// is complete; the thread id and the stored messages are returned.
+func CoachReply(ctx context.Context, db database.Querier, provider LLMProvider, threadID int, question string) (int, []models.CoachMessage, error) {
+	var history []models.CoachMessage
+	if threadID != 0 {
+		var err error
+		if history, err = db.GetCoachMessages(threadID); err != nil {
+			return 0, nil, fmt.Errorf("failed to fetch coach messages: %w", err)
+		}
+	}
+
+	profile, weeks, err := recentWeeklyData(db, coachWeeks)
+	if err != nil {
+		return 0, nil, err
+	}
+	privacy, err := db.GetAIPrivacy()
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to fetch AI privacy settings: %w", err)
+	}
+	now := time.Now()
+	system := coachSystemPrompt(profile, weeks, privacy, now)
+
+	added := []models.CoachMessage{{Role: models.CoachRoleUser, Content: strings.TrimSpace(question)}}
+	for calls := 0; ; calls++ {
+		resp, err := provider.Generate(ctx, LLMRequest{System: system, Messages: coachLLMMessages(append(history, added...))})
+		if err != nil {
+			return 0, nil, err
+		}
+		reply := strings.TrimSpace(resp.Text)
+
+		name, args, ok := parseCoachToolCall(reply)
+		if !ok {
+			added = append(added, models.CoachMessage{Role: models.CoachRoleAssistant, Content: reply})
+			break
+		}
+		if calls == maxCoachToolCalls {
+			return 0, nil, ErrCoachToolLimit
+		}
+		added = append(added,
+			models.CoachMessage{Role: models.CoachRoleToolCall, Content: reply},
+			models.CoachMessage{Role: models.CoachRoleToolResult, Content: runCoachTool(db, name, args, privacy, now)},
+		)
+	}
+
+	if threadID == 0 {
+		threadID, err = db.CreateCoachThread(models.CoachThread{Title: models.CoachThreadTitle(question), CreatedAt: now})
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to create coach thread: %w", err)
+		}
+	}
+	for i := range added {
+		added[i].ThreadID = threadID
+		added[i].CreatedAt = now
+	}
+	if err := db.AddCoachMessages(added); err != nil {
+		return 0, nil, fmt.Errorf("failed to save coach messages: %w", err)
+	}
+	return threadID, added, nil
+}
+
+func coachSystemPrompt(profile *models.UserProfile, weeks []WeeklyData, privacy models.AIPrivacy, now time.Time) string {
+	age, _ := utils.GetAge(profile, now)
+
+	prompt := fmt.Sprintf("You are an expert longevity and health coach chatting with %s about their health tracking data. Today is %s. "+
+		"Answer their questions conversationally: concise, specific and grounded in their numbers. Say so when the data can't answer a question. "+
+		"Use clean Markdown without nested bullet points.\n\n",
+		privacy.Person(age, profile), now.Format("2006-01-02"))
+	prompt += describeWeeklyData(age, weeks, privacy)
+
+	prompt += fmt.Sprintf("\nLooking Up More Data:\n"+
+		"The weeks above are the latest %d. When you need older weeks or a longer history, reply with a single line and nothing else:\n"+
+		"CALL <tool> <JSON arguments>\n"+
+		"The result is sent back to you, then continue. Use at most %d lookups per answer. Tools:\n",
+		coachWeeks, maxCoachToolCalls)
+	for _, tool := range coachTools {
+		prompt += fmt.Sprintf("- %s %s: %s\n", tool.Name, tool.Arguments, tool.Description)
+	}
+	var keys []string
+	for _, m := range models.MetricCatalog {
+		if privacy.Shares(m.Key) {
+			keys = append(keys, m.Key)
+		}
+	}
+	prompt += "Metric keys: " + strings.Join(keys, ", ") + "\n"
+	return prompt
+}
+
+// coachLLMMessages turns the latest messages of a thread into a conversation, starting at a
+// question. Lookups are the coach's turns and their results the user's.
+func coachLLMMessages(messages []models.CoachMessage) []LLMMessage {
+	start := max(0, len(messages)-maxCoachHistory)
+	for start < len(messages) && messages[start].Role != models.CoachRoleUser {
+		start++
+	}
+
+	var conversation []LLMMessage
+	for _, m := range messages[start:] {
+		switch m.Role {
+		case models.CoachRoleUser:
+			conversation = append(conversation, LLMMessage{Role: "user", Content: m.Content})
+		case models.CoachRoleAssistant, models.CoachRoleToolCall:
+			conversation = append(conversation, LLMMessage{Role: "assistant", Content: m.Content})
+		case models.CoachRoleToolResult:
+			conversation = append(conversation, LLMMessage{Role: "user", Content: "Lookup result:\n" + m.Content})
+		}
+	}
+	return conversation
+}
+
+// parseCoachToolCall recognizes a reply that consists of a single lookup
+func parseCoachToolCall(reply string) (string, string, bool) {
+	// Some models wrap the call in inline code
+	reply = strings.TrimSpace(strings.Trim(reply, "`"))
+	if strings.Contains(reply, "\n") {
+		return "", "", false
+	}
+	match := coachToolCall.FindStringSubmatch(reply)
+	if match == nil {
+		return "", "", false
+	}
+	return match[1], match[2], true
+}
+
+// CoachToolCallLabel describes a stored lookup for the chat, e.g. "metric_history · VO2 Max · 26 weeks"
+func CoachToolCallLabel(call string) string {
+	name, raw, ok := parseCoachToolCall(call)
+	if !ok {
+		return call
+	}
+	var args coachToolArgs
+	_ = json.Unmarshal([]byte(raw), &args)
+
+	parts := []string{name}
+	if m, ok := models.LookupMetric(args.Metric); ok {
+		parts = append(parts, m.Label)
+	}
+	if args.Date != "" {
+		parts = append(parts, args.Date)
+	}
+	if args.Weeks > 0 {
+		parts = append(parts, fmt.Sprintf("%d weeks", args.Weeks))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// runCoachTool runs a lookup and returns its result, or the error for the model to correct
+func runCoachTool(db database.Querier, name, raw string, privacy models.AIPrivacy, now time.Time) string {
+	var args coachToolArgs
+	if raw != "" {
+		if err := json.Unmarshal([]byte(raw), &args); err != nil {
+			return fmt.Sprintf("Error: invalid JSON arguments: %v", err)
+		}
+	}
+	for _, tool := range coachTools {
+		if tool.Name == name {
+			result, err := tool.Run(db, args, privacy, now)
+			if err != nil {
+				return "Error: " + err.Error()
+			}
+			return result
+		}
+	}
+	return fmt.Sprintf("Error: unknown tool %q", name)
+}
+
+func coachToolWeeks(weeks int) (int, error) {
+	if weeks < 1 || weeks > maxCoachToolWeeks {
+		return 0, fmt.Errorf("weeks must be between 1 and %d", maxCoachToolWeeks)
+	}
+	return weeks, nil
+}
+
+func coachMetricHistory(db database.Querier, args coachToolArgs, privacy models.AIPrivacy, now time.Time) (string, error) {
+	metric, ok := models.LookupMetric(args.Metric)
+	if !ok || !privacy.Shares(metric.Key) {
+		return "", fmt.Errorf("unknown metric %q", args.Metric)
+	}
+	weeks, err := coachToolWeeks(args.Weeks)
+	if err != nil {
+		return "", err
+	}
+
+	to := utils.WeekEnding(now)
+	from := to.AddDate(0, 0, -7*(weeks-1))
+	points, err := db.GetMetricSeries(metric.Key, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return "", err
+	}
+
+	if len(points) == 0 {
+		return fmt.Sprintf("%s: nothing recorded in the latest %d weeks", metric.Label, weeks), nil
+	}
+	result := fmt.Sprintf("%s, weeks ending:\n", metric.Label)
+	for _, p := range points {
+		result += fmt.Sprintf("%s: %s\n", p.Date, promptMetric(metric, &p.Value, privacy))
+	}
+	return result, nil
+}
+
+func coachWeek(db database.Querier, args coachToolArgs, privacy models.AIPrivacy, now time.Time) (string, error) {
+	date, err := time.Parse("2006-01-02", args.Date)
+	if err != nil {
+		return "", fmt.Errorf("date must be formatted as YYYY-MM-DD")
+	}
+	week := utils.WeekEnding(date).Format("2006-01-02")
+
+	scores, err := GetAllWeeklyScores(db)
+	if err != nil {
+		return "", err
+	}
+	for _, s := range scores {
+		if s.Date != week {
+			continue
+		}
+		h, _ := db.GetHealthMetricsByDate(week)
+		f, _ := db.GetFitnessMetricsByDate(week)
+		c, _ := db.GetCognitionMetricsByDate(week)
+		n, _ := db.GetWeekNote(week)
+		return strings.TrimSpace(describeWeek(WeeklyData{Score: s, Health: h, Fitness: f, Cognition: c, Note: n}, privacy)), nil
+	}
+	return fmt.Sprintf("No score for the week ending %s", week), nil
+}
+
+func coachScores(db database.Querier, args coachToolArgs, privacy models.AIPrivacy, now time.Time) (string, error) {
+	weeks, err := coachToolWeeks(args.Weeks)
+	if err != nil {
+		return "", err
+	}
+	scores, err := GetAllWeeklyScores(db)
+	if err != nil {
+		return "", err
+	}
+	if len(scores) == 0 {
+		return "No scores yet", nil
+	}
+
+	// The aging tax gives the exact age away
+	result := "Week ending: Total | Health | Fitness | Cognition"
+	if privacy.ExactAge() {
+		result += " | Aging Tax"
+	}
+	result += "\n"
+	for _, s := range scores[max(0, len(scores)-weeks):] {
+		result += fmt.Sprintf("%s: %.1f | %.1f | %.1f | %.1f", s.Date, s.Score, s.HealthScore, s.FitnessScore, s.CognitionScore)
+		if privacy.ExactAge() {
+			result += fmt.Sprintf(" | -%.1f", s.AgingTax)
+		}
+		result += "\n"
+	}
+	return result, nil
+}
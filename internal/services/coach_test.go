@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"health-balance/internal/models"
+	"health-balance/internal/utils"
+)
+
+func coachTestDB() *MockDB {
+	week := utils.GetCurrentWeekSundayDate()
+	return &MockDB{
+		AllDates:     []string{week},
+		UserProfile:  &models.UserProfile{BirthDate: "1985-06-01", Sex: "male", HeightCm: 180},
+		HealthMap:    map[string]*models.HealthMetrics{week: {Date: week, RHR: models.Int(58), SleepScore: models.Int(75)}},
+		FitnessMap:   map[string]*models.FitnessMetrics{week: {Date: week, VO2Max: models.Float(44), Workouts: models.Int(2)}},
+		CognitionMap: map[string]*models.CognitionMetrics{week: {Date: week, Mindfulness: models.Int(3)}},
+		WeekNotes:    []models.WeekNote{{Date: week, Text: "Flu", Tags: []string{"sick"}}},
+	}
+}
+
+func TestCoachReply(t *testing.T) {
+	db := coachTestDB()
+	week := utils.GetCurrentWeekSundayDate()
+	provider := &FakeLLMProvider{Replies: []string{
+		`CALL metric_history {"metric": "vo2_max", "weeks": 4}`,
+		"Your **VO2 max** is 44.",
+	}}
+
+	threadID, added, err := CoachReply(context.Background(), db, provider, 0, "  Why is my fitness pillar dropping?  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if threadID != 1 {
+		t.Errorf("expected a new thread, got %d", threadID)
+	}
+	roles := []string{models.CoachRoleUser, models.CoachRoleToolCall, models.CoachRoleToolResult, models.CoachRoleAssistant}
+	if len(added) != len(roles) {
+		t.Fatalf("expected the question, lookup and answer, got %+v", added)
+	}
+	for i, m := range added {
+		if m.Role != roles[i] || m.ThreadID != 1 {
+			t.Errorf("message %d: expected role %s in thread 1, got %+v", i, roles[i], m)
+		}
+	}
+	if added[0].Content != "Why is my fitness pillar dropping?" || !strings.Contains(added[2].Content, week+": 44.0") {
+		t.Errorf("unexpected question or lookup result: %+v", added)
+	}
+	if len(db.CoachMessages) != 4 {
+		t.Errorf("expected the exchange to be stored, got %d messages", len(db.CoachMessages))
+	}
+
+	requests := provider.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("expected a request for the lookup and one for the answer, got %d", len(requests))
+	}
+	system := requests[0].System
+	if !strings.Contains(system, "VO2 Max: 44.0") || !strings.Contains(system, "[sick] Flu") || !strings.Contains(system, "CALL <tool>") {
+		t.Errorf("expected the weekly data and tool protocol in the system prompt, got %q", system)
+	}
+	if msgs := requests[1].Messages; len(msgs) != 3 || msgs[1].Role != "assistant" || msgs[2].Role != "user" || !strings.HasPrefix(msgs[2].Content, "Lookup result:") {
+		t.Errorf("expected the lookup and its result in the conversation, got %+v", msgs)
+	}
+
+	// A follow-up sees the earlier exchange
+	provider.Replies = []string{"Rest until the flu is over."}
+	if _, added, err = CoachReply(context.Background(), db, provider, threadID, "What should I do?"); err != nil || len(added) != 2 {
+		t.Fatalf("expected a question and answer, got %+v (%v)", added, err)
+	}
+	if msgs := provider.Requests()[2].Messages; len(msgs) != 5 || msgs[3].Content != "Your **VO2 max** is 44." {
+		t.Errorf("expected the thread history before the follow-up, got %+v", msgs)
+	}
+}
+
+func TestCoachReplyErrors(t *testing.T) {
+	db := coachTestDB()
+	provider := &FakeLLMProvider{Err: errors.New("model unavailable")}
+	if _, _, err := CoachReply(context.Background(), db, provider, 0, "Hi"); err == nil || err.Error() != "model unavailable" {
+		t.Errorf("expected the provider error, got %v", err)
+	}
+
+	loop := &FakeLLMProvider{Response: `CALL scores {"weeks": 4}`}
+	if _, _, err := CoachReply(context.Background(), db, loop, 0, "Hi"); !errors.Is(err, ErrCoachToolLimit) {
+		t.Errorf("expected the lookup limit, got %v", err)
+	}
+	if len(loop.Requests()) != maxCoachToolCalls+1 {
+		t.Errorf("expected %d requests, got %d", maxCoachToolCalls+1, len(loop.Requests()))
+	}
+	if len(db.CoachMessages) != 0 {
+		t.Errorf("expected nothing stored without an answer, got %+v", db.CoachMessages)
+	}
+}
+
+func TestCoachLLMMessages(t *testing.T) {
+	var messages []models.CoachMessage
+	for i := 0; i < maxCoachHistory; i++ {
+		role := models.CoachRoleUser
+		if i%2 == 1 {
+			role = models.CoachRoleAssistant
+		}
+		messages = append(messages, models.CoachMessage{Role: role})
+	}
+	// Trimming to the latest messages would start at an answer
+	messages = append(messages, models.CoachMessage{Role: models.CoachRoleUser})
+
+	conversation := coachLLMMessages(messages)
+	if len(conversation) != maxCoachHistory-1 || conversation[0].Role != "user" {
+		t.Errorf("expected the history trimmed to start at a question, got %d messages starting with %+v", len(conversation), conversation[0])
+	}
+}
+
+func TestParseCoachToolCall(t *testing.T) {
+	tests := []struct {
+		reply, name, args string
+		ok                bool
+	}{
+		{`CALL metric_history {"metric": "rhr", "weeks": 8}`, "metric_history", `{"metric": "rhr", "weeks": 8}`, true},
+		{"`CALL scores {\"weeks\": 4}`", "scores", `{"weeks": 4}`, true},
+		{"CALL week", "week", "", true},
+		{"Let me check.\nCALL scores {}", "", "", false},
+		{"Your sleep is fine.", "", "", false},
+	}
+	for _, tt := range tests {
+		name, args, ok := parseCoachToolCall(tt.reply)
+		if name != tt.name || args != tt.args || ok != tt.ok {
+			t.Errorf("%q: expected %q %q %v, got %q %q %v", tt.reply, tt.name, tt.args, tt.ok, name, args, ok)
+		}
+	}
+	if label := CoachToolCallLabel(`CALL metric_history {"metric": "vo2_max", "weeks": 26}`); label != "metric_history · VO2 Max · 26 weeks" {
+		t.Errorf("unexpected label %q", label)
+	}
+}
+
+func TestRunCoachTool(t *testing.T) {
+	db := coachTestDB()
+	now := time.Now()
+	week := utils.GetCurrentWeekSundayDate()
+
+	tests := []struct {
+		name, args, want string
+	}{
+		{"metric_history", `{"metric": "rhr", "weeks": 4}`, week + ": 58"},
+		{"metric_history", `{"metric": "hrv", "weeks": 4}`, `Error: unknown metric "hrv"`},
+		{"metric_history", `{"metric": "rhr", "weeks": 500}`, "Error: weeks must be between 1 and 104"},
+		{"week", `{"date": "` + now.Format("2006-01-02") + `"}`, "### Week of " + week},
+		{"week", `{"date": "2001-01-01"}`, "No score for the week ending 2001-01-07"},
+		{"week", `{"date": "last week"}`, "Error: date must be formatted as YYYY-MM-DD"},
+		{"scores", `{"weeks": 2}`, week + ": "},
+		{"scores", `{"weeks": 2`, "Error: invalid JSON arguments"},
+		{"delete_everything", `{}`, `Error: unknown tool "delete_everything"`},
+	}
+	for _, tt := range tests {
+		if result := runCoachTool(db, tt.name, tt.args, models.AIPrivacy{}, now); !strings.Contains(result, tt.want) {
+			t.Errorf("%s %s: expected %q in %q", tt.name, tt.args, tt.want, result)
+		}
+	}
+}
+
+func TestRunCoachToolPrivacy(t *testing.T) {
+	db := coachTestDB()
+	now := time.Now()
+	privacy := models.AIPrivacy{Coarsen: true, HiddenMetrics: []string{"vo2_max"}}
+
+	if result := runCoachTool(db, "metric_history", `{"metric": "vo2_max", "weeks": 4}`, privacy, now); !strings.Contains(result, `Error: unknown metric "vo2_max"`) {
+		t.Errorf("Expected a hidden metric not to be looked up, got %q", result)
+	}
+	if result := runCoachTool(db, "metric_history", `{"metric": "rhr", "weeks": 4}`, privacy, now); !strings.Contains(result, ": ~60 bpm") {
+		t.Errorf("Expected coarsened values, got %q", result)
+	}
+	if result := runCoachTool(db, "scores", `{"weeks": 2}`, privacy, now); strings.Contains(result, "Aging Tax") {
+		t.Errorf("Expected the aging tax left out without the exact age, got %q", result)
+	}
+}
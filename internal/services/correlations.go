@@ -0,0 +1,231 @@
+package services
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"health-balance/internal/database"
+	"health-balance/internal/models"
+	"health-balance/internal/utils"
+	"math"
+	"slices"
+	"time"
+)
+
+const (
+	MaxCorrelationLagWeeks = 8
+	minCorrelationSamples  = 8
+)
+
+// ErrUnknownCorrelationMetric is returned when a correlation is requested for a metric outside the catalog
+var ErrUnknownCorrelationMetric = errors.New("unknown metric")
+
+// CorrelationBehaviors are the weekly habits the explorer relates to outcomes
+var CorrelationBehaviors = []string{"sleep_score", "nutrition_score", "workouts", "daily_steps", "mindfulness", "deep_learning", "stress_score", "social_days"}
+
+// CorrelationOutcomes are the reserve markers that habits are expected to move
+var CorrelationOutcomes = []string{"rhr", "systolic_bp", "diastolic_bp", "vo2_max", "waist_cm", "body_weight_kg"}
+
+// GetCorrelationProfile correlates x with y at every lag from 0 to MaxCorrelationLagWeeks weeks,
+// where a lag of k pairs x in one week with y k weeks later. Lags with too few paired
+// weeks are left out.
+func GetCorrelationProfile(db database.Querier, x, y string) ([]models.Correlation, error) {
+	series, err := loadCorrelationSeries(db, x, y)
+	if err != nil {
+		return nil, err
+	}
+
+	var profile []models.Correlation
+	for lag := 0; lag <= MaxCorrelationLagWeeks; lag++ {
+		if c, ok := laggedCorrelation(x, y, series[x], series[y], lag); ok {
+			profile = append(profile, c)
+		}
+	}
+	return profile, nil
+}
+
+// GetStrongestCorrelations returns the strongest behavior-outcome relationships in the
+// user's data. Each pair is reported at its strongest lag, with the p-value adjusted
+// for the number of lags tried, ordered by adjusted p-value.
+func GetStrongestCorrelations(db database.Querier, limit int) ([]models.Correlation, error) {
+	series, err := loadCorrelationSeries(db, append(slices.Clone(CorrelationBehaviors), CorrelationOutcomes...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var strongest []models.Correlation
+	for _, x := range CorrelationBehaviors {
+		for _, y := range CorrelationOutcomes {
+			var (
+				best  *models.Correlation
+				tried int
+			)
+			for lag := 0; lag <= MaxCorrelationLagWeeks; lag++ {
+				c, ok := laggedCorrelation(x, y, series[x], series[y], lag)
+				if !ok {
+					continue
+				}
+				tried++
+				if best == nil || math.Abs(c.R) > math.Abs(best.R) {
+					best = &c
+				}
+			}
+			if best == nil {
+				continue
+			}
+			best.AdjustedPValue = math.Min(1, best.PValue*float64(tried))
+			strongest = append(strongest, *best)
+		}
+	}
+
+	slices.SortStableFunc(strongest, func(a, b models.Correlation) int {
+		if c := cmp.Compare(a.AdjustedPValue, b.AdjustedPValue); c != 0 {
+			return c
+		}
+		return cmp.Compare(math.Abs(b.R), math.Abs(a.R))
+	})
+	if limit > 0 && len(strongest) > limit {
+		strongest = strongest[:limit]
+	}
+	return strongest, nil
+}
+
+// loadCorrelationSeries fetches every recorded value of the given metrics, keyed by week
+func loadCorrelationSeries(db database.Querier, keys ...string) (map[string]map[string]float64, error) {
+	to := utils.GetCurrentWeekSundayDate()
+	series := make(map[string]map[string]float64, len(keys))
+	for _, key := range keys {
+		if _, ok := series[key]; ok {
+			continue
+		}
+		if _, ok := models.LookupMetric(key); !ok {
+			return nil, fmt.Errorf("%w %q", ErrUnknownCorrelationMetric, key)
+		}
+		points, err := db.GetMetricSeries(key, "", to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s series: %w", key, err)
+		}
+		values := make(map[string]float64, len(points))
+		for _, p := range points {
+			values[p.Date] = p.Value
+		}
+		series[key] = values
+	}
+	return series, nil
+}
+
+// laggedCorrelation pairs each x week with the y value lag weeks later
+func laggedCorrelation(xKey, yKey string, xs, ys map[string]float64, lag int) (models.Correlation, bool) {
+	var xv, yv []float64
+	for date, x := range xs {
+		week, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			continue
+		}
+		if y, ok := ys[week.AddDate(0, 0, 7*lag).Format("2006-01-02")]; ok {
+			xv = append(xv, x)
+			yv = append(yv, y)
+		}
+	}
+	if len(xv) < minCorrelationSamples {
+		return models.Correlation{}, false
+	}
+
+	r, ok := pearson(xv, yv)
+	if !ok {
+		return models.Correlation{}, false
+	}
+	xDef, _ := models.LookupMetric(xKey)
+	yDef, _ := models.LookupMetric(yKey)
+	p := correlationPValue(r, len(xv))
+	return models.Correlation{X: xDef, Y: yDef, LagWeeks: lag, R: r, N: len(xv), PValue: p, AdjustedPValue: p}, true
+}
+
+// pearson returns the Pearson correlation coefficient, or false when either side is constant
+func pearson(xs, ys []float64) (float64, bool) {
+	n := float64(len(xs))
+	var sumX, sumY float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+
+	var cov, varX, varY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0, false
+	}
+	return cov / math.Sqrt(varX*varY), true
+}
+
+// correlationPValue returns the two-sided p-value of r over n pairs from the Student t
+// distribution with n-2 degrees of freedom
+func correlationPValue(r float64, n int) float64 {
+	df := float64(n - 2)
+	if math.Abs(r) >= 1 {
+		return 0
+	}
+	t2 := r * r * df / (1 - r*r)
+	return regularizedIncompleteBeta(df/(df+t2), df/2, 0.5)
+}
+
+// regularizedIncompleteBeta evaluates I_x(a, b) with the continued fraction expansion
+// from Numerical Recipes, using the symmetry relation where it converges faster
+func regularizedIncompleteBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	lgab, _ := math.Lgamma(a + b)
+	lga, _ := math.Lgamma(a)
+	lgb, _ := math.Lgamma(b)
+	front := math.Exp(lgab - lga - lgb + a*math.Log(x) + b*math.Log(1-x))
+	if x < (a+1)/(a+b+2) {
+		return front * betaContinuedFraction(x, a, b) / a
+	}
+	return 1 - front*betaContinuedFraction(1-x, b, a)/b
+}
+
+func betaContinuedFraction(x, a, b float64) float64 {
+	const (
+		maxIterations = 200
+		epsilon       = 3e-14
+		tiny          = 1e-300
+	)
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= maxIterations; m++ {
+		fm := float64(m)
+		for _, numerator := range []float64{
+			fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm)),
+			-(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1)),
+		} {
+			d = 1 + numerator*d
+			if math.Abs(d) < tiny {
+				d = tiny
+			}
+			c = 1 + numerator/c
+			if math.Abs(c) < tiny {
+				c = tiny
+			}
+			d = 1 / d
+			h *= d * c
+		}
+		if math.Abs(d*c-1) < epsilon {
+			break
+		}
+	}
+	return h
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"errors"
+	"health-balance/internal/models"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCorrelationPValue(t *testing.T) {
+	tests := []struct {
+		r        float64
+		n        int
+		expected float64
+	}{
+		{r: 0.632, n: 10, expected: 0.050},
+		{r: 0.5, n: 10, expected: 0.141},
+		{r: -0.5, n: 10, expected: 0.141},
+		{r: 0, n: 10, expected: 1},
+	}
+
+	for _, tt := range tests {
+		if p := correlationPValue(tt.r, tt.n); math.Abs(p-tt.expected) > 0.002 {
+			t.Errorf("r=%.3f n=%d: expected p≈%.3f, got %.4f", tt.r, tt.n, tt.expected, p)
+		}
+	}
+}
+
+func TestGetCorrelationProfileFindsLag(t *testing.T) {
+	// Resting heart rate follows sleep two weeks later
+	sleep := []int{60, 85, 70, 90, 55, 75, 80, 65, 95, 50, 72, 88, 61, 79, 83, 58}
+	start := time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC)
+	db := &MockDB{HealthMap: map[string]*models.HealthMetrics{}}
+	for i := range sleep {
+		week := start.AddDate(0, 0, 7*i).Format("2006-01-02")
+		h := &models.HealthMetrics{Date: week, SleepScore: &sleep[i]}
+		if i >= 2 {
+			rhr := 80 - sleep[i-2]/5
+			h.RHR = &rhr
+		}
+		db.HealthMap[week] = h
+	}
+
+	profile, err := GetCorrelationProfile(db, "sleep_score", "rhr")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(profile) == 0 {
+		t.Fatal("Expected a correlation profile")
+	}
+
+	strongest := profile[0]
+	for _, c := range profile {
+		if c.AbsR() > strongest.AbsR() {
+			strongest = c
+		}
+	}
+	if strongest.LagWeeks != 2 || strongest.R > -0.95 || strongest.Direction() != "falls" {
+		t.Errorf("Expected a strong negative correlation at a 2 week lag, got %+v", strongest)
+	}
+	if strongest.N != len(sleep)-2 {
+		t.Errorf("Expected %d paired weeks, got %d", len(sleep)-2, strongest.N)
+	}
+
+	pairs, err := GetStrongestCorrelations(db, 5)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(pairs) != 1 || pairs[0].LagWeeks != 2 || !pairs[0].Significant() {
+		t.Fatalf("Expected the sleep/rhr pair at a 2 week lag, got %+v", pairs)
+	}
+	if pairs[0].AdjustedPValue < pairs[0].PValue {
+		t.Errorf("Expected the adjusted p-value to be no smaller than the raw one, got %+v", pairs[0])
+	}
+}
+
+func TestGetCorrelationProfileUnknownMetric(t *testing.T) {
+	_, err := GetCorrelationProfile(&MockDB{}, "sleep_score", "horoscope")
+	if !errors.Is(err, ErrUnknownCorrelationMetric) {
+		t.Errorf("Expected ErrUnknownCorrelationMetric, got %v", err)
+	}
+}
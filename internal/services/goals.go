@@ -0,0 +1,195 @@
+package services
+
+import (
+	"fmt"
+	"health-balance/internal/database"
+	"health-balance/internal/models"
+	"log"
+	"math"
+	"time"
+)
+
+const (
+	// The trend towards a goal is fitted over this many recorded weeks
+	goalTrendWeeks = 8
+	// Fewer recorded weeks than this give no trend
+	minGoalTrendPoints = 3
+	// Projections further out than this are treated as never reaching the target
+	maxGoalProjectionWeeks = 520
+)
+
+// CreateGoal stores a validated goal, recording the metric's current value as the
+// starting point for progress and its current status so that only later changes notify
+func CreateGoal(db database.Querier, goal models.Goal, now time.Time) (models.Goal, error) {
+	today := now.Format("2006-01-02")
+	points, err := db.GetMetricSeries(goal.MetricKey, "", today)
+	if err != nil {
+		return goal, fmt.Errorf("failed to fetch %s history: %w", goal.MetricKey, err)
+	}
+
+	goal.CreatedAt = today
+	goal.StartValue = windowAverage(points, goal.WindowWeeks)
+	goal.LastStatus = evaluateGoal(goal, points, now).Status
+	if goal.LastStatus == models.GoalAchieved {
+		goal.AchievedAt = today
+	}
+
+	goal.ID, err = db.CreateGoal(goal)
+	if err != nil {
+		return goal, fmt.Errorf("failed to save goal: %w", err)
+	}
+	return goal, nil
+}
+
+// GetGoalProgress evaluates every goal against the metric tables as of now
+func GetGoalProgress(db database.Querier, now time.Time) ([]models.GoalProgress, error) {
+	goals, err := db.GetGoals()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch goals: %w", err)
+	}
+
+	today := now.Format("2006-01-02")
+	progress := make([]models.GoalProgress, 0, len(goals))
+	for _, goal := range goals {
+		points, err := db.GetMetricSeries(goal.MetricKey, "", today)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s history: %w", goal.MetricKey, err)
+		}
+		progress = append(progress, evaluateGoal(goal, points, now))
+	}
+	return progress, nil
+}
+
+// evaluateGoal measures a goal against the metric's recorded history, oldest first
+func evaluateGoal(goal models.Goal, points []models.MetricPoint, now time.Time) models.GoalProgress {
+	metric, _ := models.LookupMetric(goal.MetricKey)
+	progress := models.GoalProgress{Goal: goal, Metric: metric, Status: models.GoalNoData}
+
+	progress.Current = windowAverage(points, goal.WindowWeeks)
+	if progress.Current == nil {
+		return progress
+	}
+	current := *progress.Current
+
+	if goal.Met(current) {
+		progress.Progress = 1
+		progress.Status = models.GoalAchieved
+		return progress
+	}
+
+	start := points[0].Value
+	if goal.StartValue != nil {
+		start = *goal.StartValue
+	}
+	if span := goal.Target - start; span != 0 {
+		progress.Progress = math.Max(0, math.Min(1, (current-start)/span))
+	}
+
+	recent := points[max(0, len(points)-goalTrendWeeks):]
+	trend, ok := weeklySlope(recent)
+	if !ok {
+		return progress
+	}
+	progress.WeeklyTrend = trend
+
+	// The trend only leads to the target when it moves in the target's direction
+	if remaining := goal.Target - current; trend*remaining > 0 {
+		if weeks := remaining / trend; weeks <= maxGoalProjectionWeeks {
+			last, err := time.Parse("2006-01-02", recent[len(recent)-1].Date)
+			if err == nil {
+				progress.ExpectedDate = last.AddDate(0, 0, int(math.Ceil(weeks*7))).Format("2006-01-02")
+			}
+		}
+	}
+
+	today := now.Format("2006-01-02")
+	switch {
+	case progress.ExpectedDate == "":
+		progress.Status = models.GoalOffTrack
+	case goal.Deadline != "" && (goal.Deadline < today || progress.ExpectedDate > goal.Deadline):
+		progress.Status = models.GoalOffTrack
+	default:
+		progress.Status = models.GoalOnTrack
+	}
+	return progress
+}
+
+// windowAverage returns the latest value when window is 1, or the average of the last
+// window recorded values, and nil without any
+func windowAverage(points []models.MetricPoint, window int) *float64 {
+	if len(points) == 0 {
+		return nil
+	}
+	recent := points[max(0, len(points)-max(window, 1)):]
+	var total float64
+	for _, p := range recent {
+		total += p.Value
+	}
+	average := total / float64(len(recent))
+	return &average
+}
+
+// weeklySlope fits a least squares line through the points and returns its change per week
+func weeklySlope(points []models.MetricPoint) (float64, bool) {
+	if len(points) < minGoalTrendPoints {
+		return 0, false
+	}
+	first, err := time.Parse("2006-01-02", points[0].Date)
+	if err != nil {
+		return 0, false
+	}
+
+	n := float64(len(points))
+	var sumX, sumY, sumXY, sumXX float64
+	for _, p := range points {
+		date, err := time.Parse("2006-01-02", p.Date)
+		if err != nil {
+			return 0, false
+		}
+		x := date.Sub(first).Hours() / (24 * 7)
+		sumX += x
+		sumY += p.Value
+		sumXY += x * p.Value
+		sumXX += x * x
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0, false
+	}
+	return (n*sumXY - sumX*sumY) / denominator, true
+}
+
+// checkGoalNotifications sends a push notification when a goal with notifications on is
+// reached or falls off track, and records each goal's latest status
+func checkGoalNotifications(db database.Querier, now time.Time) {
+	progress, err := GetGoalProgress(db, now)
+	if err != nil {
+		log.Printf("Scheduler error: failed to evaluate goals: %v", err)
+		return
+	}
+
+	for _, p := range progress {
+		goal := p.Goal
+		if p.Status == goal.LastStatus || p.Status == models.GoalNoData {
+			continue
+		}
+
+		achievedAt := goal.AchievedAt
+		var message *models.PushMessage
+		switch {
+		case p.Status == models.GoalAchieved && goal.AchievedAt == "":
+			achievedAt = now.Format("2006-01-02")
+			message = &models.PushMessage{Title: "Goal reached", Body: goal.Description(), URL: "/#goals"}
+		case p.Status == models.GoalOffTrack && goal.LastStatus == models.GoalOnTrack:
+			message = &models.PushMessage{Title: "Goal off track", Body: goal.Description(), URL: "/#goals"}
+		}
+
+		if err := db.UpdateGoalStatus(goal.ID, p.Status, achievedAt); err != nil {
+			log.Printf("Scheduler error: failed to update goal %d: %v", goal.ID, err)
+			continue
+		}
+		if message != nil && goal.Notify {
+			sendPushToAll(db, *message)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"health-balance/internal/models"
+	"health-balance/internal/testutil"
+	"math"
+	"testing"
+	"time"
+)
+
+func weeklyPoints(start string, values ...float64) []models.MetricPoint {
+	date, _ := time.Parse("2006-01-02", start)
+	points := make([]models.MetricPoint, len(values))
+	for i, v := range values {
+		points[i] = models.MetricPoint{Date: date.AddDate(0, 0, 7*i).Format("2006-01-02"), Value: v}
+	}
+	return points
+}
+
+func TestEvaluateGoal(t *testing.T) {
+	now := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	// VO2 Max rising one point per week from 40 to 44, last recorded 2026-02-01
+	rising := weeklyPoints("2026-01-04", 40, 41, 42, 43, 44)
+
+	tests := []struct {
+		name         string
+		goal         models.Goal
+		points       []models.MetricPoint
+		status       string
+		expectedDate string
+		progress     float64
+	}{
+		{
+			name:   "no data",
+			goal:   models.Goal{MetricKey: "vo2_max", Comparison: models.GoalAtLeast, Target: 50, WindowWeeks: 1},
+			status: models.GoalNoData,
+		},
+		{
+			name:         "on track without deadline",
+			goal:         models.Goal{MetricKey: "vo2_max", Comparison: models.GoalAtLeast, Target: 50, WindowWeeks: 1, StartValue: models.Float(40)},
+			points:       rising,
+			status:       models.GoalOnTrack,
+			expectedDate: "2026-03-15",
+			progress:     0.4,
+		},
+		{
+			name:         "off track for deadline",
+			goal:         models.Goal{MetricKey: "vo2_max", Comparison: models.GoalAtLeast, Target: 50, WindowWeeks: 1, Deadline: "2026-03-01"},
+			points:       rising,
+			status:       models.GoalOffTrack,
+			expectedDate: "2026-03-15",
+			progress:     0.4,
+		},
+		{
+			name:     "trend moving away",
+			goal:     models.Goal{MetricKey: "waist_cm", Comparison: models.GoalAtMost, Target: 85, WindowWeeks: 1},
+			points:   weeklyPoints("2026-01-04", 88, 89, 90),
+			status:   models.GoalOffTrack,
+			progress: 0,
+		},
+		{
+			name:     "achieved on average",
+			goal:     models.Goal{MetricKey: "sleep_score", Comparison: models.GoalAtLeast, Target: 80, WindowWeeks: 4},
+			points:   weeklyPoints("2026-01-04", 60, 78, 82, 79, 84),
+			status:   models.GoalAchieved,
+			progress: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			progress := evaluateGoal(tt.goal, tt.points, now)
+			if progress.Status != tt.status {
+				t.Errorf("Expected status %s, got %s", tt.status, progress.Status)
+			}
+			if progress.ExpectedDate != tt.expectedDate {
+				t.Errorf("Expected completion %q, got %q", tt.expectedDate, progress.ExpectedDate)
+			}
+			if math.Abs(progress.Progress-tt.progress) > 1e-9 {
+				t.Errorf("Expected progress %.2f, got %.2f", tt.progress, progress.Progress)
+			}
+		})
+	}
+}
+
+func TestWeeklySlope(t *testing.T) {
+	slope, ok := weeklySlope(weeklyPoints("2026-01-04", 10, 12, 14, 16))
+	if !ok || math.Abs(slope-2) > 1e-9 {
+		t.Errorf("Expected a slope of 2 per week, got %.3f (%v)", slope, ok)
+	}
+	if _, ok := weeklySlope(weeklyPoints("2026-01-04", 10, 12)); ok {
+		t.Error("Expected no slope from two points")
+	}
+}
+
+func TestCreateGoalRecordsStart(t *testing.T) {
+	var saved models.Goal
+	db := &testutil.MockDB{
+		GetMetricSeriesFunc: func(key, from, to string) ([]models.MetricPoint, error) {
+			return weeklyPoints("2026-01-04", 90, 89, 88), nil
+		},
+		CreateGoalFunc: func(g models.Goal) (int, error) {
+			saved = g
+			return 7, nil
+		},
+	}
+
+	goal, err := CreateGoal(db, models.Goal{MetricKey: "waist_cm", Comparison: models.GoalAtMost, Target: 85, WindowWeeks: 1}, time.Date(2026, 1, 20, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if goal.ID != 7 || saved.CreatedAt != "2026-01-20" || saved.StartValue == nil || *saved.StartValue != 88 || saved.LastStatus != models.GoalOnTrack {
+		t.Errorf("Expected the goal to start at 88 on track, got %+v", saved)
+	}
+}
+
+func TestCheckGoalNotifications(t *testing.T) {
+	updates := map[int]string{}
+	achieved := map[int]string{}
+	db := &testutil.MockDB{
+		GetGoalsFunc: func() ([]models.Goal, error) {
+			return []models.Goal{
+				{ID: 1, MetricKey: "waist_cm", Comparison: models.GoalAtMost, Target: 85, WindowWeeks: 1, LastStatus: models.GoalOnTrack, Notify: true},
+				{ID: 2, MetricKey: "waist_cm", Comparison: models.GoalAtMost, Target: 80, WindowWeeks: 1, LastStatus: models.GoalOffTrack},
+			}, nil
+		},
+		GetMetricSeriesFunc: func(key, from, to string) ([]models.MetricPoint, error) {
+			return weeklyPoints("2026-01-04", 88, 86, 84), nil
+		},
+		UpdateGoalStatusFunc: func(id int, status, achievedAt string) error {
+			updates[id] = status
+			achieved[id] = achievedAt
+			return nil
+		},
+	}
+
+	checkGoalNotifications(db, time.Date(2026, 1, 20, 0, 0, 0, 0, time.UTC))
+
+	if updates[1] != models.GoalAchieved || achieved[1] != "2026-01-20" {
+		t.Errorf("Expected goal 1 to be recorded as achieved, got %q on %q", updates[1], achieved[1])
+	}
+	if updates[2] != models.GoalOnTrack || achieved[2] != "" {
+		t.Errorf("Expected goal 2 to be back on track, got %q", updates[2])
+	}
+}
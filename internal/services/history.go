@@ -0,0 +1,92 @@
+package services
+
+import (
+	"fmt"
+	"health-balance/internal/database"
+	"health-balance/internal/models"
+	"slices"
+)
+
+// GetScoresPage returns one keyset page of weekly scores. Scores are derived rather than
+// stored, so the page is cut from the full series with the same cursor rules as the
+// pillar history queries.
+func GetScoresPage(db database.Querier, q models.HistoryQuery) ([]models.MasterScore, error) {
+	if !models.HistorySortable(models.HistoryViewScores, q.Sort) {
+		return nil, fmt.Errorf("cannot sort scores history by %q", q.Sort)
+	}
+
+	scores, err := GetAllWeeklyScores(db)
+	if err != nil {
+		return nil, err
+	}
+
+	// before reports whether a sorts ahead of b in the requested order
+	before := func(a, b models.MasterScore) bool {
+		if q.Sort != models.HistorySortDate {
+			av, bv := a.ScoreValue(q.Sort), b.ScoreValue(q.Sort)
+			if av != bv {
+				return (av < bv) == q.Ascending
+			}
+		}
+		return a.Date != b.Date && (a.Date < b.Date) == q.Ascending
+	}
+
+	var cursor *models.MasterScore
+	if q.After != nil {
+		cursor = &models.MasterScore{Date: q.After.Date}
+		if q.After.Value != nil {
+			*cursor = scoreAtCursor(q.Sort, q.After)
+		}
+	}
+
+	var page []models.MasterScore
+	for _, s := range scores {
+		if (q.From != "" && s.Date < q.From) || (q.To != "" && s.Date > q.To) {
+			continue
+		}
+		if cursor != nil && !before(*cursor, s) {
+			continue
+		}
+		page = append(page, s)
+	}
+
+	slices.SortStableFunc(page, func(a, b models.MasterScore) int {
+		if before(a, b) {
+			return -1
+		}
+		if before(b, a) {
+			return 1
+		}
+		return 0
+	})
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = models.DefaultHistoryLimit
+	}
+	if len(page) > limit {
+		page = page[:limit]
+	}
+	if err := AttachWeekNotes(db, page); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// scoreAtCursor builds a score whose sort column and date match the cursor
+func scoreAtCursor(key string, cursor *models.HistoryCursor) models.MasterScore {
+	s := models.MasterScore{Date: cursor.Date}
+	switch key {
+	case "health_score":
+		s.HealthScore = *cursor.Value
+	case "fitness_score":
+		s.FitnessScore = *cursor.Value
+	case "cognition_score":
+		s.CognitionScore = *cursor.Value
+	case "aging_tax":
+		s.AgingTax = *cursor.Value
+	default:
+		s.Score = *cursor.Value
+	}
+	return s
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"health-balance/internal/models"
+	"health-balance/internal/utils"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestGetScoresPage(t *testing.T) {
+	currentWeek, err := time.Parse("2006-01-02", utils.GetCurrentWeekSundayDate())
+	if err != nil {
+		t.Fatalf("Failed to parse current week: %v", err)
+	}
+
+	mock := &MockDB{
+		UserProfile:  &models.UserProfile{BirthDate: "1990-01-01", HeightCm: 180, Sex: "male"},
+		HealthMap:    map[string]*models.HealthMetrics{},
+		FitnessMap:   map[string]*models.FitnessMetrics{},
+		CognitionMap: map[string]*models.CognitionMetrics{},
+	}
+	for i, sleep := range []int{70, 90, 60, 80, 75} {
+		date := currentWeek.AddDate(0, 0, -7*(4-i)).Format("2006-01-02")
+		mock.AllDates = append([]string{date}, mock.AllDates...)
+		mock.HealthMap[date] = &models.HealthMetrics{SleepScore: models.Int(sleep)}
+		mock.FitnessMap[date] = &models.FitnessMetrics{VO2Max: models.Float(44)}
+		mock.CognitionMap[date] = &models.CognitionMetrics{StressScore: models.Int(2)}
+	}
+
+	all, err := GetAllWeeklyScores(mock)
+	if err != nil {
+		t.Fatalf("Failed to calculate scores: %v", err)
+	}
+
+	// Walk pages of two sorted by health pillar score and compare with a full sort
+	var walked []string
+	q := models.HistoryQuery{Sort: "health_score", Limit: 2}
+	for page := 0; page < 5; page++ {
+		scores, err := GetScoresPage(mock, q)
+		if err != nil {
+			t.Fatalf("Failed to get scores page: %v", err)
+		}
+		if len(scores) == 0 {
+			break
+		}
+		for _, s := range scores {
+			walked = append(walked, s.Date)
+		}
+		last := scores[len(scores)-1]
+		q.After = &models.HistoryCursor{Date: last.Date, Value: models.Float(last.HealthScore)}
+	}
+
+	slices.SortStableFunc(all, func(a, b models.MasterScore) int {
+		if a.HealthScore != b.HealthScore {
+			if a.HealthScore > b.HealthScore {
+				return -1
+			}
+			return 1
+		}
+		if a.Date > b.Date {
+			return -1
+		}
+		return 1
+	})
+	var expected []string
+	for _, s := range all {
+		expected = append(expected, s.Date)
+	}
+	if len(walked) != 5 || !slices.Equal(walked, expected) {
+		t.Errorf("Expected pages in health score order %v, got %v", expected, walked)
+	}
+
+	from := currentWeek.AddDate(0, 0, -14).Format("2006-01-02")
+	recent, err := GetScoresPage(mock, models.HistoryQuery{From: from, Sort: models.HistorySortDate, Ascending: true})
+	if err != nil {
+		t.Fatalf("Failed to get filtered scores page: %v", err)
+	}
+	if len(recent) != 3 || recent[0].Date != from {
+		t.Errorf("Expected the last three weeks oldest first, got %+v", recent)
+	}
+
+	if _, err := GetScoresPage(mock, models.HistoryQuery{Sort: "rhr"}); err == nil {
+		t.Error("Expected an error when sorting scores by a metric")
+	}
+}
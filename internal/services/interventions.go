@@ -0,0 +1,176 @@
+package services
+
+import (
+	"fmt"
+	"health-balance/internal/database"
+	"health-balance/internal/models"
+	"math"
+	"time"
+)
+
+const (
+	// The before period matches the intervention's length within these limits, so short
+	// trials still have a usable baseline and long ones compare against recent weeks
+	minInterventionBaselineWeeks = 4
+	maxInterventionBaselineWeeks = 12
+	// Both periods need this many recorded weeks for an effect size
+	minInterventionEffectWeeks = 2
+)
+
+// interventionScoreSeries are the scores compared for every intervention
+var interventionScoreSeries = []string{"score", "health_score", "fitness_score", "cognition_score"}
+
+// GetInterventionResults compares the pillar scores and chosen metrics of every
+// intervention over the weeks before it started and the weeks since, as of now
+func GetInterventionResults(db database.Querier, now time.Time) ([]models.InterventionResult, error) {
+	interventions, err := db.GetInterventions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch interventions: %w", err)
+	}
+	if len(interventions) == 0 {
+		return nil, nil
+	}
+
+	scores, err := GetAllWeeklyScores(db)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.InterventionResult, 0, len(interventions))
+	for _, intervention := range interventions {
+		result, err := compareIntervention(db, intervention, scores, now)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// compareIntervention builds the before/after comparison of one intervention
+func compareIntervention(db database.Querier, intervention models.Intervention, scores []models.MasterScore, now time.Time) (models.InterventionResult, error) {
+	result := models.InterventionResult{Intervention: intervention}
+
+	before, during, err := interventionPeriods(intervention, now)
+	if err != nil {
+		return result, err
+	}
+	result.BeforeFrom, result.BeforeTo = before.From, before.To
+	result.AfterFrom, result.AfterTo = during.From, during.To
+
+	split := func(date string, value float64, before, after *[]float64) {
+		switch {
+		case date >= result.BeforeFrom && date <= result.BeforeTo:
+			*before = append(*before, value)
+		case date >= result.AfterFrom && date <= result.AfterTo:
+			*after = append(*after, value)
+		}
+	}
+
+	for _, key := range interventionScoreSeries {
+		definition, _ := lookupScoreSeries(key)
+		var before, after []float64
+		for _, s := range scores {
+			split(s.Date, s.ScoreValue(key), &before, &after)
+		}
+		result.Scores = append(result.Scores, compareInterventionValues(definition, before, after))
+	}
+
+	for _, key := range intervention.Metrics {
+		definition, ok := models.LookupMetric(key)
+		if !ok {
+			continue
+		}
+		points, err := db.GetMetricSeries(key, result.BeforeFrom, result.AfterTo)
+		if err != nil {
+			return result, fmt.Errorf("failed to fetch %s series: %w", key, err)
+		}
+		var before, after []float64
+		for _, p := range points {
+			split(p.Date, p.Value, &before, &after)
+		}
+		result.Metrics = append(result.Metrics, compareInterventionValues(definition, before, after))
+	}
+
+	return result, nil
+}
+
+// interventionPeriods returns the weeks before an intervention and the weeks during it as of
+// now. The before period is as long as the intervention, within limits.
+func interventionPeriods(intervention models.Intervention, now time.Time) (before, during models.Period, err error) {
+	start, err := time.Parse("2006-01-02", intervention.StartDate)
+	if err != nil {
+		return before, during, fmt.Errorf("invalid start date of intervention %d: %w", intervention.ID, err)
+	}
+	end := now
+	if !intervention.Ongoing() {
+		if end, err = time.Parse("2006-01-02", intervention.EndDate); err != nil {
+			return before, during, fmt.Errorf("invalid end date of intervention %d: %w", intervention.ID, err)
+		}
+	}
+
+	weeks := int(math.Ceil((end.Sub(start).Hours()/24 + 1) / 7))
+	weeks = max(minInterventionBaselineWeeks, min(maxInterventionBaselineWeeks, weeks))
+
+	before = models.Period{From: start.AddDate(0, 0, -7*weeks).Format("2006-01-02"), To: start.AddDate(0, 0, -1).Format("2006-01-02")}
+	during = models.Period{From: intervention.StartDate, To: end.Format("2006-01-02")}
+	return before, during, nil
+}
+
+// compareInterventionValues summarizes the weekly values of a metric before and during an intervention
+func compareInterventionValues(metric models.MetricDefinition, before, after []float64) models.InterventionEffect {
+	effect := models.InterventionEffect{
+		Metric:      metric,
+		BeforeWeeks: len(before),
+		AfterWeeks:  len(after),
+	}
+	if len(before) > 0 {
+		m, _ := meanAndVariance(before)
+		effect.BeforeMean = &m
+	}
+	if len(after) > 0 {
+		m, _ := meanAndVariance(after)
+		effect.AfterMean = &m
+	}
+	if g, ok := hedgesG(before, after); ok {
+		effect.EffectSize = &g
+	}
+	return effect
+}
+
+// hedgesG returns the standardized mean difference of after over before, corrected for
+// small samples. It reports false when either side is too short or nothing varies.
+func hedgesG(before, after []float64) (float64, bool) {
+	n1, n2 := len(before), len(after)
+	if n1 < minInterventionEffectWeeks || n2 < minInterventionEffectWeeks {
+		return 0, false
+	}
+
+	mean1, var1 := meanAndVariance(before)
+	mean2, var2 := meanAndVariance(after)
+	pooled := (float64(n1-1)*var1 + float64(n2-1)*var2) / float64(n1+n2-2)
+	if pooled == 0 {
+		return 0, false
+	}
+
+	correction := 1 - 3/(4*float64(n1+n2)-9)
+	return (mean2 - mean1) / math.Sqrt(pooled) * correction, true
+}
+
+// meanAndVariance returns the mean and sample variance of values
+func meanAndVariance(values []float64) (float64, float64) {
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	mean := total / float64(len(values))
+	if len(values) < 2 {
+		return mean, 0
+	}
+
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return mean, squares / float64(len(values)-1)
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"health-balance/internal/models"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHedgesG(t *testing.T) {
+	g, ok := hedgesG([]float64{60, 62, 64}, []float64{56, 58, 60})
+	if !ok {
+		t.Fatal("Expected an effect size")
+	}
+	// The means differ by two pooled standard deviations, shrunk by the small sample correction
+	if expected := -2 * (1 - 3/(4*6.0-9)); math.Abs(g-expected) > 1e-9 {
+		t.Errorf("Expected g of %.3f, got %.3f", expected, g)
+	}
+
+	if _, ok := hedgesG([]float64{60}, []float64{56, 58, 60}); ok {
+		t.Error("Expected no effect size with a single week before")
+	}
+	if _, ok := hedgesG([]float64{60, 60}, []float64{60, 60}); ok {
+		t.Error("Expected no effect size without any variation")
+	}
+}
+
+func TestCompareIntervention(t *testing.T) {
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	mock := &MockDB{
+		HealthMap: map[string]*models.HealthMetrics{
+			"2026-01-11": {RHR: models.Int(64)},
+			"2026-01-18": {RHR: models.Int(62)},
+			"2026-02-01": {RHR: models.Int(63)},
+			"2026-02-08": {RHR: models.Int(58)},
+			"2026-02-15": {RHR: models.Int(57)},
+			"2026-03-15": {RHR: models.Int(56)},
+		},
+	}
+	scores := []models.MasterScore{
+		{Date: "2026-01-18", Score: 70},
+		{Date: "2026-02-01", Score: 72},
+		{Date: "2026-02-15", Score: 75},
+		{Date: "2026-03-01", Score: 77},
+	}
+	intervention := models.Intervention{Name: "No alcohol", StartDate: "2026-02-05", EndDate: "2026-03-04", Metrics: []string{"rhr"}}
+
+	result, err := compareIntervention(mock, intervention, scores, now)
+	if err != nil {
+		t.Fatalf("Failed to compare intervention: %v", err)
+	}
+	// Four weeks long, so it is compared with the four weeks before it
+	if result.BeforeFrom != "2026-01-08" || result.BeforeTo != "2026-02-04" || result.AfterTo != "2026-03-04" {
+		t.Errorf("Unexpected periods: %+v", result)
+	}
+	if len(result.Scores) != len(interventionScoreSeries) || len(result.Metrics) != 1 {
+		t.Fatalf("Expected every score and the chosen metric, got %+v", result)
+	}
+
+	score := result.Scores[0]
+	if score.BeforeWeeks != 2 || score.AfterWeeks != 2 || *score.BeforeMean != 71 || *score.AfterMean != 76 || !score.Improved() {
+		t.Errorf("Unexpected score comparison: %+v", score)
+	}
+
+	rhr := result.Metrics[0]
+	// The week after the intervention ended is left out
+	if rhr.BeforeWeeks != 3 || rhr.AfterWeeks != 2 || *rhr.AfterMean != 57.5 {
+		t.Errorf("Unexpected resting heart rate comparison: %+v", rhr)
+	}
+	if !rhr.Improved() || rhr.EffectSize == nil || *rhr.EffectSize >= 0 || rhr.Magnitude() != "large" {
+		t.Errorf("Expected a large drop in resting heart rate to count as an improvement, got %+v", rhr)
+	}
+	if rhr.FormatChange() != "-6" {
+		t.Errorf("Expected a change of -6 bpm in the metric's format, got %q", rhr.FormatChange())
+	}
+
+	ongoing := models.Intervention{Name: "Zone 2", StartDate: "2026-03-15"}
+	result, err = compareIntervention(mock, ongoing, scores, now)
+	if err != nil {
+		t.Fatalf("Failed to compare ongoing intervention: %v", err)
+	}
+	// An ongoing intervention runs until now and still gets the minimum baseline
+	if result.AfterTo != "2026-03-20" || result.BeforeFrom != "2026-02-15" {
+		t.Errorf("Unexpected periods for an ongoing intervention: %+v", result)
+	}
+	if s := result.Scores[0]; s.AfterMean != nil || s.EffectSize != nil {
+		t.Errorf("Expected no scores during the ongoing intervention, got %+v", s)
+	}
+}
@@ -0,0 +1,322 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	LLMProviderGemini    = "gemini"
+	LLMProviderOpenAI    = "openai"
+	LLMProviderAnthropic = "anthropic"
+	LLMProviderFake      = "fake"
+
+	defaultGeminiModel    = "gemini-3-flash-preview"
+	defaultGeminiBaseURL  = "https://generativelanguage.googleapis.com/v1beta"
+	defaultOpenAIBaseURL  = "https://api.openai.com/v1"
+	defaultAnthropicModel = "claude-sonnet-4-5"
+	defaultAnthropicURL   = "https://api.anthropic.com/v1"
+	// Anthropic requires max_tokens, the other APIs leave the output uncapped by default
+	defaultAnthropicMaxTokens = 2048
+	defaultLLMTimeout         = 2 * time.Minute
+	maxLLMErrorBodyPreview    = 512
+	// maxSSELineBytes bounds one line of a streamed response
+	maxSSELineBytes = 1024 * 1024
+)
+
+// LLMMessage is one turn of a conversation with a language model
+type LLMMessage struct {
+	// Role is "user" or "assistant"
+	Role    string
+	Content string
+}
+
+// LLMRequest is a provider-independent request for a completion
+type LLMRequest struct {
+	// System holds the instructions that frame the conversation, empty for none
+	System   string
+	Messages []LLMMessage
+	// MaxTokens caps the output, 0 for the provider's default. Thinking models count their
+	// thinking against the cap, so leave it unset unless the output must be short.
+	MaxTokens int
+	// JSON asks for a JSON object where the API has a JSON mode. The prompt still has to
+	// describe the format, and the response has to be validated.
+	JSON bool
+}
+
+// UserPrompt builds a single-turn request
+func UserPrompt(prompt string) LLMRequest {
+	return LLMRequest{Messages: []LLMMessage{{Role: "user", Content: prompt}}}
+}
+
+// LLMUsage counts the tokens of one completion as reported by the provider
+type LLMUsage struct {
+	InputTokens  int
+	OutputTokens int
+}
+
+// LLMResponse is the text a model generated together with its token usage
+type LLMResponse struct {
+	Text  string
+	Usage LLMUsage
+}
+
+// LLMProvider generates text with a language model behind some API
+type LLMProvider interface {
+	// Name identifies the provider, e.g. "gemini"
+	Name() string
+	// Model is the model the provider sends requests to
+	Model() string
+	Generate(ctx context.Context, req LLMRequest) (*LLMResponse, error)
+	// Stream generates like Generate but calls onText with each piece of text as the
+	// provider's streaming endpoint delivers it. An error from onText aborts the stream.
+	// When the stream fails after it started, the text and usage received so far are
+	// returned along with the error.
+	Stream(ctx context.Context, req LLMRequest, onText func(string) error) (*LLMResponse, error)
+}
+
+// LLMConfig selects and configures the provider used for AI features
+type LLMConfig struct {
+	Provider string
+	Model    string
+	APIKey   string
+	BaseURL  string
+	// Timeout bounds a whole request including a streamed response
+	Timeout time.Duration
+	// Debug logs every request and response, prompts included
+	Debug bool
+	// InputPrice and OutputPrice are the US dollars per million tokens used to estimate
+	// the cost of a call, 0 when unknown
+	InputPrice  float64
+	OutputPrice float64
+	// MonthlyBudget is the estimated cost in US dollars after which calls are refused for
+	// the rest of the month, 0 for no limit
+	MonthlyBudget float64
+}
+
+// LoadLLMConfig reads LLM_PROVIDER (gemini, openai, anthropic or fake, default gemini),
+// LLM_TIMEOUT_SECONDS (default 120), LLM_DEBUG (default false), LLM_MONTHLY_BUDGET (default
+// none), LLM_INPUT_PRICE and LLM_OUTPUT_PRICE (default the list price of known models) and
+// the settings of the chosen provider:
+//   - gemini: GEMINI_API_KEY and GEMINI_MODEL_NAME
+//   - openai: OPENAI_BASE_URL, OPENAI_API_KEY and OPENAI_MODEL, for OpenAI and compatible
+//     servers such as Ollama, llama.cpp or vLLM
+//   - anthropic: ANTHROPIC_API_KEY, ANTHROPIC_MODEL and ANTHROPIC_BASE_URL
+func LoadLLMConfig() LLMConfig {
+	cfg := LLMConfig{
+		Provider: strings.ToLower(strings.TrimSpace(os.Getenv("LLM_PROVIDER"))),
+		Timeout:  defaultLLMTimeout,
+	}
+	if cfg.Provider == "" {
+		cfg.Provider = LLMProviderGemini
+	}
+	if seconds, err := strconv.Atoi(os.Getenv("LLM_TIMEOUT_SECONDS")); err == nil && seconds > 0 {
+		cfg.Timeout = time.Duration(seconds) * time.Second
+	}
+	if debug, err := strconv.ParseBool(os.Getenv("LLM_DEBUG")); err == nil {
+		cfg.Debug = debug
+	}
+
+	switch cfg.Provider {
+	case LLMProviderGemini:
+		cfg.APIKey = os.Getenv("GEMINI_API_KEY")
+		cfg.Model = envOr("GEMINI_MODEL_NAME", defaultGeminiModel)
+		cfg.BaseURL = defaultGeminiBaseURL
+	case LLMProviderOpenAI:
+		cfg.APIKey = os.Getenv("OPENAI_API_KEY")
+		cfg.Model = os.Getenv("OPENAI_MODEL")
+		cfg.BaseURL = envOr("OPENAI_BASE_URL", defaultOpenAIBaseURL)
+	case LLMProviderAnthropic:
+		cfg.APIKey = os.Getenv("ANTHROPIC_API_KEY")
+		cfg.Model = envOr("ANTHROPIC_MODEL", defaultAnthropicModel)
+		cfg.BaseURL = envOr("ANTHROPIC_BASE_URL", defaultAnthropicURL)
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+
+	cfg.InputPrice, cfg.OutputPrice = lookupLLMPrice(cfg.Model)
+	if price, err := strconv.ParseFloat(os.Getenv("LLM_INPUT_PRICE"), 64); err == nil && price >= 0 {
+		cfg.InputPrice = price
+	}
+	if price, err := strconv.ParseFloat(os.Getenv("LLM_OUTPUT_PRICE"), 64); err == nil && price >= 0 {
+		cfg.OutputPrice = price
+	}
+	if budget, err := strconv.ParseFloat(os.Getenv("LLM_MONTHLY_BUDGET"), 64); err == nil && budget > 0 {
+		cfg.MonthlyBudget = budget
+	}
+	return cfg
+}
+
+// NewLLMProvider creates the configured provider, reporting missing settings. Prompts
+// are health data, so they are only logged when cfg.Debug is set.
+func NewLLMProvider(cfg LLMConfig) (LLMProvider, error) {
+	provider, err := newLLMProvider(cfg)
+	if err != nil || !cfg.Debug {
+		return provider, err
+	}
+	return &debugLLMProvider{LLMProvider: provider}, nil
+}
+
+func newLLMProvider(cfg LLMConfig) (LLMProvider, error) {
+	switch cfg.Provider {
+	case LLMProviderGemini:
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("GEMINI_API_KEY environment variable is not set")
+		}
+		return &GeminiProvider{apiKey: cfg.APIKey, model: cfg.Model, baseURL: cfg.BaseURL, client: http.DefaultClient}, nil
+	case LLMProviderOpenAI:
+		if cfg.Model == "" {
+			return nil, fmt.Errorf("OPENAI_MODEL environment variable is not set")
+		}
+		// Local servers usually run without a key, the hosted API never does
+		if cfg.APIKey == "" && cfg.BaseURL == defaultOpenAIBaseURL {
+			return nil, fmt.Errorf("OPENAI_API_KEY environment variable is not set")
+		}
+		return &OpenAIProvider{apiKey: cfg.APIKey, model: cfg.Model, baseURL: cfg.BaseURL, client: http.DefaultClient}, nil
+	case LLMProviderAnthropic:
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("ANTHROPIC_API_KEY environment variable is not set")
+		}
+		return &AnthropicProvider{apiKey: cfg.APIKey, model: cfg.Model, baseURL: cfg.BaseURL, client: http.DefaultClient}, nil
+	case LLMProviderFake:
+		return &FakeLLMProvider{}, nil
+	default:
+		return nil, fmt.Errorf("unknown LLM_PROVIDER %q, expected gemini, openai, anthropic or fake", cfg.Provider)
+	}
+}
+
+func envOr(key, fallback string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// postLLMJSON sends body as JSON to url and decodes a successful response into out
+func postLLMJSON(ctx context.Context, client *http.Client, provider, url string, headers map[string]string, body, out any) error {
+	resp, err := sendLLMRequest(ctx, client, provider, url, headers, body)
+	if err != nil {
+		return err
+	}
+	defer closeLLMResponse(resp)
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s response body: %w", provider, err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to unmarshal %s response: %w", provider, err)
+	}
+	return nil
+}
+
+// streamLLM sends body as JSON to url and calls onEvent with the name and data of each
+// server-sent event of the response
+func streamLLM(ctx context.Context, client *http.Client, provider, url string, headers map[string]string, body any, onEvent func(event, data string) error) error {
+	resp, err := sendLLMRequest(ctx, client, provider, url, headers, body)
+	if err != nil {
+		return err
+	}
+	defer closeLLMResponse(resp)
+
+	if err := readSSE(resp.Body, onEvent); err != nil {
+		// A cancelled or timed out context surfaces as a read error, report the cause instead
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("failed to read %s stream: %w", provider, err)
+	}
+	return nil
+}
+
+// sendLLMRequest posts body as JSON and returns the response when it succeeded. Error
+// bodies are shortened since some providers echo the whole request back.
+func sendLLMRequest(ctx context.Context, client *http.Client, provider, url string, headers map[string]string, body any) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s request: %w", provider, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s request: %w", provider, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s API: %w", provider, err)
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+	defer closeLLMResponse(resp)
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxLLMErrorBodyPreview+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s response body: %w", provider, err)
+	}
+	preview := string(data)
+	if len(preview) > maxLLMErrorBodyPreview {
+		preview = preview[:maxLLMErrorBodyPreview] + "…"
+	}
+	return nil, fmt.Errorf("failed %s API call (status %d): %s", provider, resp.StatusCode, preview)
+}
+
+func closeLLMResponse(resp *http.Response) {
+	if err := resp.Body.Close(); err != nil {
+		log.Printf("Error closing response body: %v", err)
+	}
+}
+
+// readSSE calls onEvent with the event name (empty when unnamed) and the data of each
+// server-sent event in r
+func readSSE(r io.Reader, onEvent func(event, data string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSSELineBytes)
+
+	var (
+		event string
+		data  []string
+	)
+	dispatch := func() error {
+		defer func() { event, data = "", nil }()
+		if len(data) == 0 {
+			return nil
+		}
+		return onEvent(event, strings.Join(data, "\n"))
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if err := dispatch(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, ":"):
+			// Comment, used by some servers as a keep-alive
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	// The last event may not be followed by a blank line
+	return dispatch()
+}
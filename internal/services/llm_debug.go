@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"log"
+)
+
+// debugLLMProvider logs the requests and responses of the provider it wraps, for
+// troubleshooting prompts with LLM_DEBUG=true
+type debugLLMProvider struct {
+	LLMProvider
+}
+
+func (p *debugLLMProvider) Generate(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	p.logRequest(req)
+	resp, err := p.LLMProvider.Generate(ctx, req)
+	p.logResponse(resp, err)
+	return resp, err
+}
+
+func (p *debugLLMProvider) Stream(ctx context.Context, req LLMRequest, onText func(string) error) (*LLMResponse, error) {
+	p.logRequest(req)
+	resp, err := p.LLMProvider.Stream(ctx, req, onText)
+	p.logResponse(resp, err)
+	return resp, err
+}
+
+func (p *debugLLMProvider) logRequest(req LLMRequest) {
+	log.Printf("LLM request to %s/%s (json: %t)", p.Name(), p.Model(), req.JSON)
+	if req.System != "" {
+		log.Printf("LLM system:\n%s", req.System)
+	}
+	for _, m := range req.Messages {
+		log.Printf("LLM %s:\n%s", m.Role, m.Content)
+	}
+}
+
+func (p *debugLLMProvider) logResponse(resp *LLMResponse, err error) {
+	if err != nil {
+		log.Printf("LLM error from %s/%s: %v", p.Name(), p.Model(), err)
+		return
+	}
+	log.Printf("LLM response from %s/%s (%d input, %d output tokens):\n%s",
+		p.Name(), p.Model(), resp.Usage.InputTokens, resp.Usage.OutputTokens, resp.Text)
+}
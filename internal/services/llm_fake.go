@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// FakeLLMProvider answers without calling any API, for tests and for trying the AI
+// features offline with LLM_PROVIDER=fake. It records every request it receives.
+type FakeLLMProvider struct {
+	// Replies are returned in order, one per request, before falling back to Response
+	Replies []string
+	// Response is returned as the generated text; a short canned summary is used when empty
+	Response string
+	// Err is returned instead of a response when set
+	Err error
+
+	mu       sync.Mutex
+	requests []LLMRequest
+}
+
+const fakeLLMResponse = "**Summary**\n- This is a canned response from the fake LLM provider.\n- Set LLM_PROVIDER to gemini, openai or anthropic for real insights."
+
+// fakeLLMJSONResponse answers requests for JSON in the summary format
+const fakeLLMJSONResponse = `{"summary": "This is a canned response from the fake LLM provider. Set LLM_PROVIDER to gemini, openai or anthropic for real insights.", ` +
+	`"bottlenecks": [{"pillar": "health", "metric": "sleep_score", "finding": "Sleep is the weakest reserve-building behavior."}], ` +
+	`"recommendations": [{"pillar": "health", "title": "Protect your sleep", "action": "Keep a fixed bedtime on weekdays.", ` +
+	`"target_metric": "sleep_score", "comparison": "at_least", "target_value": 80, "expected_impact": "A steadier health pillar within a month.", "priority": "high"}]}`
+
+func (p *FakeLLMProvider) Name() string  { return LLMProviderFake }
+func (p *FakeLLMProvider) Model() string { return LLMProviderFake }
+
+func (p *FakeLLMProvider) Generate(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	return p.Stream(ctx, req, func(string) error { return nil })
+}
+
+// Stream delivers the response word by word
+func (p *FakeLLMProvider) Stream(ctx context.Context, req LLMRequest, onText func(string) error) (*LLMResponse, error) {
+	p.mu.Lock()
+	p.requests = append(p.requests, req)
+	text := p.Response
+	if len(p.Replies) > 0 {
+		text, p.Replies = p.Replies[0], p.Replies[1:]
+	}
+	p.mu.Unlock()
+
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	if text == "" {
+		text = fakeLLMResponse
+		if req.JSON {
+			text = fakeLLMJSONResponse
+		}
+	}
+	var sent strings.Builder
+	for _, word := range strings.SplitAfter(text, " ") {
+		err := ctx.Err()
+		if err == nil {
+			err = onText(word)
+		}
+		if err != nil {
+			return fakeLLMResponseFor(req, sent.String()), err
+		}
+		sent.WriteString(word)
+	}
+	return fakeLLMResponseFor(req, text), nil
+}
+
+// fakeLLMResponseFor returns text with the usage estimated from the request and text
+func fakeLLMResponseFor(req LLMRequest, text string) *LLMResponse {
+	return &LLMResponse{Text: text, Usage: LLMUsage{InputTokens: estimateRequestTokens(req), OutputTokens: estimateTokens(text)}}
+}
+
+// Requests returns the requests received so far
+func (p *FakeLLMProvider) Requests() []LLMRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]LLMRequest(nil), p.requests...)
+}
+
+// estimateTokens approximates the token count of English text at four characters per token
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// estimateRequestTokens approximates the input token count of req
+func estimateRequestTokens(req LLMRequest) int {
+	var tokens int
+	if req.System != "" {
+		tokens += estimateTokens(req.System)
+	}
+	for _, m := range req.Messages {
+		tokens += estimateTokens(m.Content)
+	}
+	return tokens
+}
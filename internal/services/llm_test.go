@@ -0,0 +1,617 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"health-balance/internal/models"
+	"health-balance/internal/utils"
+)
+
+// llmTestServer answers every request with response and records the last request
+func llmTestServer(t *testing.T, status int, response string) (*httptest.Server, *http.Request, *map[string]any) {
+	t.Helper()
+	var got http.Request
+	body := map[string]any{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = *r
+		// Decode into a fresh map so no field of an earlier request lingers
+		body = map[string]any{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("request body is not JSON: %v", err)
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server, &got, &body
+}
+
+var testLLMRequest = LLMRequest{
+	System: "You are a coach.",
+	Messages: []LLMMessage{
+		{Role: "user", Content: "How am I doing?"},
+		{Role: "assistant", Content: "Well."},
+		{Role: "user", Content: "Details?"},
+	},
+	MaxTokens: 100,
+}
+
+func TestGeminiProvider(t *testing.T) {
+	server, req, body := llmTestServer(t, http.StatusOK, `{
+		"candidates": [{"content": {"parts": [{"text": "Sleep "}, {"text": "more."}]}}],
+		"usageMetadata": {"promptTokenCount": 12, "candidatesTokenCount": 3}
+	}`)
+	provider, err := NewLLMProvider(LLMConfig{Provider: "gemini", APIKey: "key", Model: "gemini-test", BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := provider.Generate(context.Background(), testLLMRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Text != "Sleep more." || resp.Usage != (LLMUsage{InputTokens: 12, OutputTokens: 3}) {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if req.URL.Path != "/models/gemini-test:generateContent" || req.Header.Get("x-goog-api-key") != "key" {
+		t.Errorf("unexpected request %s with key %q", req.URL.Path, req.Header.Get("x-goog-api-key"))
+	}
+	contents := (*body)["contents"].([]any)
+	if len(contents) != 3 || contents[1].(map[string]any)["role"] != "model" {
+		t.Errorf("expected the assistant turn as model, got %v", contents)
+	}
+	if (*body)["systemInstruction"] == nil {
+		t.Error("expected the system instruction")
+	}
+	if max := (*body)["generationConfig"].(map[string]any)["maxOutputTokens"]; max != 100.0 {
+		t.Errorf("expected maxOutputTokens 100, got %v", max)
+	}
+
+	jsonRequest := testLLMRequest
+	jsonRequest.JSON = true
+	if _, err := provider.Generate(context.Background(), jsonRequest); err != nil {
+		t.Fatal(err)
+	}
+	if mime := (*body)["generationConfig"].(map[string]any)["responseMimeType"]; mime != "application/json" {
+		t.Errorf("expected JSON mode, got responseMimeType %v", mime)
+	}
+
+	// Thinking counts against the cap, so none is sent unless asked for
+	if _, err := provider.Generate(context.Background(), UserPrompt("hi")); err != nil {
+		t.Fatal(err)
+	}
+	if config, _ := (*body)["generationConfig"].(map[string]any); config["maxOutputTokens"] != nil {
+		t.Errorf("expected no output cap by default, got %v", config)
+	}
+}
+
+func TestOpenAIProvider(t *testing.T) {
+	server, req, body := llmTestServer(t, http.StatusOK, `{
+		"choices": [{"message": {"role": "assistant", "content": "Sleep more."}}],
+		"usage": {"prompt_tokens": 20, "completion_tokens": 4}
+	}`)
+	// Local servers run without a key
+	provider, err := NewLLMProvider(LLMConfig{Provider: "openai", Model: "llama3", BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := provider.Generate(context.Background(), testLLMRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Text != "Sleep more." || resp.Usage != (LLMUsage{InputTokens: 20, OutputTokens: 4}) {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if req.URL.Path != "/chat/completions" || req.Header.Get("Authorization") != "" {
+		t.Errorf("unexpected request %s with authorization %q", req.URL.Path, req.Header.Get("Authorization"))
+	}
+	messages := (*body)["messages"].([]any)
+	if len(messages) != 4 || messages[0].(map[string]any)["role"] != "system" {
+		t.Errorf("expected the system message first, got %v", messages)
+	}
+	if (*body)["model"] != "llama3" || (*body)["max_tokens"] != 100.0 || (*body)["response_format"] != nil {
+		t.Errorf("unexpected body %v", *body)
+	}
+
+	jsonRequest := testLLMRequest
+	jsonRequest.JSON = true
+	if _, err := provider.Generate(context.Background(), jsonRequest); err != nil {
+		t.Fatal(err)
+	}
+	if format, _ := (*body)["response_format"].(map[string]any); format["type"] != "json_object" {
+		t.Errorf("expected JSON mode, got response_format %v", (*body)["response_format"])
+	}
+
+	if _, err := provider.Generate(context.Background(), UserPrompt("hi")); err != nil {
+		t.Fatal(err)
+	}
+	if max, ok := (*body)["max_tokens"]; ok {
+		t.Errorf("expected no max_tokens by default, got %v", max)
+	}
+}
+
+func TestAnthropicProvider(t *testing.T) {
+	server, req, body := llmTestServer(t, http.StatusOK, `{
+		"content": [{"type": "text", "text": "Sleep more."}],
+		"usage": {"input_tokens": 30, "output_tokens": 5}
+	}`)
+	provider, err := NewLLMProvider(LLMConfig{Provider: "anthropic", APIKey: "key", Model: "claude-test", BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := provider.Generate(context.Background(), testLLMRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Text != "Sleep more." || resp.Usage != (LLMUsage{InputTokens: 30, OutputTokens: 5}) {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if req.URL.Path != "/messages" || req.Header.Get("x-api-key") != "key" || req.Header.Get("anthropic-version") == "" {
+		t.Errorf("unexpected request %s with headers %v", req.URL.Path, req.Header)
+	}
+	if (*body)["system"] != "You are a coach." || len((*body)["messages"].([]any)) != 3 || (*body)["max_tokens"] != 100.0 {
+		t.Errorf("unexpected body %v", *body)
+	}
+
+	// The API requires max_tokens
+	if _, err := provider.Generate(context.Background(), UserPrompt("hi")); err != nil {
+		t.Fatal(err)
+	}
+	if (*body)["max_tokens"] != float64(defaultAnthropicMaxTokens) {
+		t.Errorf("expected the default max_tokens, got %v", (*body)["max_tokens"])
+	}
+}
+
+func TestLLMProviderErrors(t *testing.T) {
+	server, _, _ := llmTestServer(t, http.StatusTooManyRequests, `{"error": "`+strings.Repeat("x", 2000)+`"}`)
+	provider, err := NewLLMProvider(LLMConfig{Provider: "openai", Model: "m", BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = provider.Generate(context.Background(), UserPrompt("hi"))
+	if err == nil || !strings.Contains(err.Error(), "status 429") {
+		t.Fatalf("expected the status in the error, got %v", err)
+	}
+	if len(err.Error()) > maxLLMErrorBodyPreview+100 {
+		t.Errorf("expected the error body to be shortened, got %d bytes", len(err.Error()))
+	}
+
+	empty, _, _ := llmTestServer(t, http.StatusOK, `{"candidates": []}`)
+	provider, _ = NewLLMProvider(LLMConfig{Provider: "gemini", APIKey: "key", Model: "m", BaseURL: empty.URL})
+	if _, err := provider.Generate(context.Background(), UserPrompt("hi")); err == nil || err.Error() != "empty response from Gemini API" {
+		t.Errorf("expected an empty response error, got %v", err)
+	}
+}
+
+func TestLoadLLMConfig(t *testing.T) {
+	t.Setenv("LLM_PROVIDER", "")
+	t.Setenv("GEMINI_API_KEY", "gkey")
+	t.Setenv("GEMINI_MODEL_NAME", "")
+	cfg := LoadLLMConfig()
+	if cfg.Provider != "gemini" || cfg.APIKey != "gkey" || cfg.Model != defaultGeminiModel || cfg.BaseURL != defaultGeminiBaseURL {
+		t.Errorf("unexpected default config %+v", cfg)
+	}
+
+	t.Setenv("LLM_PROVIDER", " OpenAI ")
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("OPENAI_MODEL", "llama3")
+	t.Setenv("OPENAI_BASE_URL", "http://localhost:11434/v1/")
+	cfg = LoadLLMConfig()
+	if cfg.Provider != "openai" || cfg.Model != "llama3" || cfg.BaseURL != "http://localhost:11434/v1" {
+		t.Errorf("unexpected openai config %+v", cfg)
+	}
+	if _, err := NewLLMProvider(cfg); err != nil {
+		t.Errorf("expected a local server to work without a key, got %v", err)
+	}
+}
+
+func TestNewLLMProviderErrors(t *testing.T) {
+	tests := []struct {
+		cfg  LLMConfig
+		want string
+	}{
+		{LLMConfig{Provider: "gemini", Model: "m"}, "GEMINI_API_KEY environment variable is not set"},
+		{LLMConfig{Provider: "openai", BaseURL: defaultOpenAIBaseURL}, "OPENAI_MODEL environment variable is not set"},
+		{LLMConfig{Provider: "openai", Model: "gpt", BaseURL: defaultOpenAIBaseURL}, "OPENAI_API_KEY environment variable is not set"},
+		{LLMConfig{Provider: "anthropic", Model: "m"}, "ANTHROPIC_API_KEY environment variable is not set"},
+		{LLMConfig{Provider: "mistral"}, `unknown LLM_PROVIDER "mistral", expected gemini, openai, anthropic or fake`},
+	}
+	for _, tt := range tests {
+		if _, err := NewLLMProvider(tt.cfg); err == nil || err.Error() != tt.want {
+			t.Errorf("%+v: expected %q, got %v", tt.cfg, tt.want, err)
+		}
+	}
+}
+
+func TestSummarizeHealthWithFakeProvider(t *testing.T) {
+	week := utils.GetCurrentWeekSundayDate()
+	mockDB := &MockDB{
+		AllDates:     []string{week},
+		UserProfile:  &models.UserProfile{BirthDate: "1990-01-01", Sex: "female", HeightCm: 170},
+		HealthMap:    map[string]*models.HealthMetrics{week: {SleepScore: models.Int(80)}},
+		FitnessMap:   map[string]*models.FitnessMetrics{week: {Workouts: models.Int(3)}},
+		CognitionMap: map[string]*models.CognitionMetrics{week: {Mindfulness: models.Int(4)}},
+	}
+	provider := &FakeLLMProvider{Response: testInsights("Keep going.")}
+
+	summary, err := SummarizeHealth(context.Background(), mockDB, provider, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Text != testInsights("Keep going.") || summary.Cached || summary.ID != 1 || summary.Week != week || summary.Model != "fake" {
+		t.Errorf("expected a new stored summary of the fake response, got %+v", summary)
+	}
+	requests := provider.Requests()
+	if len(requests) != 1 || !strings.Contains(requests[0].Messages[0].Content, week) || !requests[0].JSON {
+		t.Errorf("expected one JSON request with the week's data, got %+v", requests)
+	}
+
+	// Unchanged data serves the stored summary without calling the model
+	provider.Response = testInsights("Different advice.")
+	summary, err = SummarizeHealth(context.Background(), mockDB, provider, false, nil)
+	if err != nil || !summary.Cached || summary.Text != testInsights("Keep going.") || len(provider.Requests()) != 1 {
+		t.Errorf("expected the cached summary, got %+v (%v)", summary, err)
+	}
+
+	summary, err = SummarizeHealth(context.Background(), mockDB, provider, true, nil)
+	if err != nil || summary.Cached || summary.Text != testInsights("Different advice.") || len(mockDB.AISummaries) != 2 {
+		t.Errorf("expected a regenerated summary, got %+v (%v)", summary, err)
+	}
+
+	// New data changes the prompt
+	mockDB.HealthMap[week].SleepScore = models.Int(60)
+	provider.Response = testInsights("Sleep more.")
+	if summary, err = SummarizeHealth(context.Background(), mockDB, provider, false, nil); err != nil || summary.Text != testInsights("Sleep more.") {
+		t.Errorf("expected a new summary after the data changed, got %+v (%v)", summary, err)
+	}
+
+	provider.Err = errors.New("model unavailable")
+	if _, err := SummarizeHealth(context.Background(), mockDB, provider, true, nil); err == nil || err.Error() != "model unavailable" {
+		t.Errorf("expected the provider error, got %v", err)
+	}
+}
+
+// testInsights is a valid structured summary response
+func testInsights(summary string) string {
+	return `{"summary": "` + summary + `", "bottlenecks": [], "recommendations": [{"pillar": "health", "title": "Sleep", "action": "Go to bed earlier.", "priority": "high"}]}`
+}
+
+func TestSummarizeHealthValidatesResponse(t *testing.T) {
+	week := utils.GetCurrentWeekSundayDate()
+	mockDB := &MockDB{
+		AllDates:     []string{week},
+		UserProfile:  &models.UserProfile{BirthDate: "1990-01-01", Sex: "female", HeightCm: 170},
+		HealthMap:    map[string]*models.HealthMetrics{week: {SleepScore: models.Int(80)}},
+		FitnessMap:   map[string]*models.FitnessMetrics{week: {Workouts: models.Int(3)}},
+		CognitionMap: map[string]*models.CognitionMetrics{week: {Mindfulness: models.Int(4)}},
+	}
+
+	// An invalid response is sent back once to be corrected
+	invalid := `{"summary": "Fine.", "recommendations": [{"pillar": "sleep", "title": "Sleep", "action": "More.", "priority": "high"}]}`
+	provider := &FakeLLMProvider{Replies: []string{invalid, testInsights("Fixed.")}}
+	summary, err := SummarizeHealth(context.Background(), mockDB, provider, true, nil)
+	if err != nil || summary.Text != testInsights("Fixed.") {
+		t.Fatalf("expected the corrected response, got %+v (%v)", summary, err)
+	}
+	requests := provider.Requests()
+	if len(requests) != 2 || len(requests[1].Messages) != 3 || requests[1].Messages[1].Content != invalid ||
+		!strings.Contains(requests[1].Messages[2].Content, "recommendations[0].pillar must be one of health, fitness, cognition") {
+		t.Errorf("expected the invalid response sent back with the validation error, got %+v", requests)
+	}
+
+	// Prose that stays invalid is kept as markdown
+	provider = &FakeLLMProvider{Response: "**Sleep** more."}
+	if summary, err = SummarizeHealth(context.Background(), mockDB, provider, true, nil); err != nil || summary.Text != "**Sleep** more." {
+		t.Errorf("expected the prose response kept, got %+v (%v)", summary, err)
+	}
+	if len(provider.Requests()) != 2 {
+		t.Errorf("expected a single correction attempt, got %d requests", len(provider.Requests()))
+	}
+}
+
+func TestSummarizeHealthWithoutData(t *testing.T) {
+	mockDB := &MockDB{UserProfile: &models.UserProfile{BirthDate: "1990-01-01", Sex: "female", HeightCm: 170}}
+	provider := &FakeLLMProvider{}
+
+	summary, err := SummarizeHealth(context.Background(), mockDB, provider, false, nil)
+	if err != nil || summary.Text != noSummaryDataMessage || summary.ID != 0 {
+		t.Errorf("expected the no data message, got %+v (%v)", summary, err)
+	}
+	if len(provider.Requests()) != 0 || len(mockDB.AISummaries) != 0 {
+		t.Error("expected no request and nothing stored without data")
+	}
+}
+
+// collectText returns an onText callback that records each piece of streamed text
+func collectText(pieces *[]string) func(string) error {
+	return func(text string) error {
+		*pieces = append(*pieces, text)
+		return nil
+	}
+}
+
+func TestGeminiProviderStream(t *testing.T) {
+	server, req, _ := llmTestServer(t, http.StatusOK, "data: {\"candidates\": [{\"content\": {\"parts\": [{\"text\": \"Sleep \"}]}}], \"usageMetadata\": {\"promptTokenCount\": 12, \"candidatesTokenCount\": 1}}\r\n\r\n"+
+		"data: {\"candidates\": [{\"content\": {\"parts\": [{\"text\": \"more.\"}]}}], \"usageMetadata\": {\"promptTokenCount\": 12, \"candidatesTokenCount\": 3}}\r\n\r\n")
+	provider, _ := NewLLMProvider(LLMConfig{Provider: "gemini", APIKey: "key", Model: "gemini-test", BaseURL: server.URL})
+
+	var pieces []string
+	resp, err := provider.Stream(context.Background(), testLLMRequest, collectText(&pieces))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pieces) != 2 || resp.Text != "Sleep more." || resp.Usage != (LLMUsage{InputTokens: 12, OutputTokens: 3}) {
+		t.Errorf("unexpected stream %q with response %+v", pieces, resp)
+	}
+	if req.URL.Path != "/models/gemini-test:streamGenerateContent" || req.URL.Query().Get("alt") != "sse" {
+		t.Errorf("unexpected request %s", req.URL)
+	}
+}
+
+func TestOpenAIProviderStream(t *testing.T) {
+	server, _, body := llmTestServer(t, http.StatusOK, `data: {"choices": [{"delta": {"role": "assistant", "content": ""}}]}
+
+data: {"choices": [{"delta": {"content": "Sleep "}}]}
+
+data: {"choices": [{"delta": {"content": "more."}}]}
+
+data: {"choices": [], "usage": {"prompt_tokens": 20, "completion_tokens": 4}}
+
+data: [DONE]
+
+`)
+	provider, _ := NewLLMProvider(LLMConfig{Provider: "openai", Model: "llama3", BaseURL: server.URL})
+
+	var pieces []string
+	resp, err := provider.Stream(context.Background(), testLLMRequest, collectText(&pieces))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pieces) != 2 || resp.Text != "Sleep more." || resp.Usage != (LLMUsage{InputTokens: 20, OutputTokens: 4}) {
+		t.Errorf("unexpected stream %q with response %+v", pieces, resp)
+	}
+	if (*body)["stream"] != true || (*body)["stream_options"] == nil {
+		t.Errorf("expected a streaming request with usage, got %v", *body)
+	}
+}
+
+func TestAnthropicProviderStream(t *testing.T) {
+	server, _, body := llmTestServer(t, http.StatusOK, `event: message_start
+data: {"type": "message_start", "message": {"usage": {"input_tokens": 30, "output_tokens": 1}}}
+
+event: content_block_start
+data: {"type": "content_block_start", "index": 0, "content_block": {"type": "text", "text": ""}}
+
+event: ping
+data: {"type": "ping"}
+
+event: content_block_delta
+data: {"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": "Sleep "}}
+
+event: content_block_delta
+data: {"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": "more."}}
+
+event: message_delta
+data: {"type": "message_delta", "delta": {"stop_reason": "end_turn"}, "usage": {"output_tokens": 5}}
+
+event: message_stop
+data: {"type": "message_stop"}
+`)
+	provider, _ := NewLLMProvider(LLMConfig{Provider: "anthropic", APIKey: "key", Model: "claude-test", BaseURL: server.URL})
+
+	var pieces []string
+	resp, err := provider.Stream(context.Background(), testLLMRequest, collectText(&pieces))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pieces) != 2 || resp.Text != "Sleep more." || resp.Usage != (LLMUsage{InputTokens: 30, OutputTokens: 5}) {
+		t.Errorf("unexpected stream %q with response %+v", pieces, resp)
+	}
+	if (*body)["stream"] != true {
+		t.Errorf("expected a streaming request, got %v", *body)
+	}
+
+	overloaded, _, _ := llmTestServer(t, http.StatusOK, `event: message_start
+data: {"type": "message_start", "message": {"usage": {"input_tokens": 30, "output_tokens": 1}}}
+
+event: content_block_delta
+data: {"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": "Sleep "}}
+
+event: error
+data: {"type": "error", "error": {"type": "overloaded_error", "message": "Overloaded"}}
+`)
+	provider, _ = NewLLMProvider(LLMConfig{Provider: "anthropic", APIKey: "key", Model: "claude-test", BaseURL: overloaded.URL})
+	resp, err = provider.Stream(context.Background(), testLLMRequest, collectText(&pieces))
+	if err == nil || !strings.Contains(err.Error(), "Overloaded") {
+		t.Errorf("expected the stream error, got %v", err)
+	}
+	if resp == nil || resp.Text != "Sleep " || resp.Usage.InputTokens != 30 {
+		t.Errorf("expected the text and usage received before the error, got %+v", resp)
+	}
+}
+
+func TestReadSSE(t *testing.T) {
+	input := ": keep-alive\n\nevent: greeting\ndata: hello\ndata:world\n\ndata: {}\n\n\ndata: last"
+	var events []string
+	err := readSSE(strings.NewReader(input), func(event, data string) error {
+		events = append(events, event+"|"+data)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"greeting|hello\nworld", "|{}", "|last"}
+	if !slices.Equal(events, want) {
+		t.Errorf("expected %q, got %q", want, events)
+	}
+
+	stop := errors.New("stop")
+	if err := readSSE(strings.NewReader(input), func(string, string) error { return stop }); err != stop {
+		t.Errorf("expected the callback error to end reading, got %v", err)
+	}
+}
+
+func TestLLMStreamTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("data: {\"choices\": [{\"delta\": {\"content\": \"Sleep \"}}]}\n\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+	provider, _ := NewLLMProvider(LLMConfig{Provider: "openai", Model: "m", BaseURL: server.URL})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var pieces []string
+	resp, err := provider.Stream(ctx, UserPrompt("hi"), collectText(&pieces))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to end the stream, got %v", err)
+	}
+	if len(pieces) != 1 || resp == nil || resp.Text != "Sleep " {
+		t.Errorf("expected the text sent before the deadline, got %q and %+v", pieces, resp)
+	}
+}
+
+func TestSummarizeHealthStream(t *testing.T) {
+	week := utils.GetCurrentWeekSundayDate()
+	mockDB := &MockDB{
+		AllDates:     []string{week},
+		UserProfile:  &models.UserProfile{BirthDate: "1990-01-01", Sex: "female", HeightCm: 170},
+		HealthMap:    map[string]*models.HealthMetrics{week: {SleepScore: models.Int(80)}},
+		FitnessMap:   map[string]*models.FitnessMetrics{week: {Workouts: models.Int(3)}},
+		CognitionMap: map[string]*models.CognitionMetrics{week: {Mindfulness: models.Int(4)}},
+	}
+	provider := &FakeLLMProvider{Response: "Keep going strong."}
+
+	var pieces []string
+	summary, err := SummarizeHealth(context.Background(), mockDB, provider, false, collectText(&pieces))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pieces) != 3 || strings.Join(pieces, "") != summary.Text || summary.ID != 1 {
+		t.Errorf("expected the stored summary streamed word by word, got %q for %+v", pieces, summary)
+	}
+
+	pieces = nil
+	if summary, err = SummarizeHealth(context.Background(), mockDB, provider, false, collectText(&pieces)); err != nil || !summary.Cached {
+		t.Fatalf("expected the cached summary, got %+v (%v)", summary, err)
+	}
+	if len(pieces) != 1 || pieces[0] != "Keep going strong." {
+		t.Errorf("expected the cached summary passed whole, got %q", pieces)
+	}
+
+	// Structured responses stream only the summary text
+	provider.Response = testInsights("Sleep more.")
+	pieces = nil
+	if summary, err = SummarizeHealth(context.Background(), mockDB, provider, true, collectText(&pieces)); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(pieces, "") != "Sleep more." || summary.Text != testInsights("Sleep more.") {
+		t.Errorf("expected only the summary text streamed, got %q", pieces)
+	}
+	pieces = nil
+	if _, err = SummarizeHealth(context.Background(), mockDB, provider, false, collectText(&pieces)); err != nil || len(pieces) != 1 || pieces[0] != "Sleep more." {
+		t.Errorf("expected the cached summary text passed whole, got %q (%v)", pieces, err)
+	}
+
+	// A client that goes away aborts the stream and nothing is stored
+	provider.Response = "Keep going strong."
+	gone := errors.New("client disconnected")
+	_, err = SummarizeHealth(context.Background(), mockDB, provider, true, func(string) error { return gone })
+	if err != gone || len(mockDB.AISummaries) != 2 {
+		t.Errorf("expected the aborted summary not to be stored, got %v with %d summaries", err, len(mockDB.AISummaries))
+	}
+}
+
+func TestSummaryStream(t *testing.T) {
+	var pieces []string
+	stream := &summaryStream{onText: collectText(&pieces)}
+	for _, chunk := range []string{"```json\n{\"sum", "mary\": \"Sleep \\", "\"more\\", "\" and \\u00", "e9at ", "well.\", \"recommendations\": [\"x"} {
+		if err := stream.write(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if strings.Join(pieces, "") != `Sleep "more" and éat well.` {
+		t.Errorf("expected the decoded summary field, got %q", pieces)
+	}
+	for _, piece := range pieces {
+		if strings.ContainsAny(piece, "\\{[") {
+			t.Errorf("expected no JSON in the streamed text, got %q", pieces)
+		}
+	}
+
+	pieces = nil
+	stream = &summaryStream{onText: collectText(&pieces)}
+	for _, chunk := range []string{"  ", "**Sleep** ", "more."} {
+		if err := stream.write(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if strings.Join(pieces, "") != "**Sleep** more." {
+		t.Errorf("expected prose forwarded as is, got %q", pieces)
+	}
+}
+
+func TestLoadLLMConfigTimeout(t *testing.T) {
+	t.Setenv("LLM_PROVIDER", "fake")
+	t.Setenv("LLM_TIMEOUT_SECONDS", "")
+	if cfg := LoadLLMConfig(); cfg.Timeout != defaultLLMTimeout {
+		t.Errorf("expected the default timeout, got %v", cfg.Timeout)
+	}
+	t.Setenv("LLM_TIMEOUT_SECONDS", "30")
+	if cfg := LoadLLMConfig(); cfg.Timeout != 30*time.Second {
+		t.Errorf("expected a 30s timeout, got %v", cfg.Timeout)
+	}
+	t.Setenv("LLM_TIMEOUT_SECONDS", "soon")
+	if cfg := LoadLLMConfig(); cfg.Timeout != defaultLLMTimeout {
+		t.Errorf("expected the default timeout for an invalid value, got %v", cfg.Timeout)
+	}
+}
+
+func TestNewLLMProviderDebug(t *testing.T) {
+	var logs strings.Builder
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	provider, err := NewLLMProvider(LLMConfig{Provider: "fake"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.Generate(context.Background(), UserPrompt("Private prompt")); err != nil {
+		t.Fatal(err)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("Expected nothing logged without debug, got %q", logs.String())
+	}
+
+	provider, err = NewLLMProvider(LLMConfig{Provider: "fake", Debug: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if provider.Name() != "fake" {
+		t.Errorf("Expected the wrapped provider's name, got %q", provider.Name())
+	}
+	if _, err := provider.Generate(context.Background(), UserPrompt("Private prompt")); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logs.String(), "Private prompt") || !strings.Contains(logs.String(), "canned response") {
+		t.Errorf("Expected the prompt and response logged in debug mode, got %q", logs.String())
+	}
+
+	t.Setenv("LLM_DEBUG", "true")
+	if !LoadLLMConfig().Debug {
+		t.Error("Expected LLM_DEBUG to enable debug logging")
+	}
+}
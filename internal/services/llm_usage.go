@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"health-balance/internal/database"
+	"health-balance/internal/models"
+	"log"
+	"strings"
+	"time"
+)
+
+// aiUsageMonths is how many months of usage the settings show
+const aiUsageMonths = 6
+
+// ErrLLMBudgetExceeded is returned instead of calling the provider once the estimated cost
+// of the month's calls reaches LLM_MONTHLY_BUDGET
+var ErrLLMBudgetExceeded = errors.New("monthly AI budget exceeded")
+
+// llmPrices are list prices in US dollars per million input and output tokens, matched
+// against the start of the model name. More specific names come first.
+var llmPrices = []struct {
+	prefix        string
+	input, output float64
+}{
+	{"gemini-3-pro", 2, 12},
+	{"gemini-3-flash", 0.5, 3},
+	{"gemini-2.5-pro", 1.25, 10},
+	{"gemini-2.5-flash-lite", 0.1, 0.4},
+	{"gemini-2.5-flash", 0.3, 2.5},
+	{"claude-opus-4-5", 5, 25},
+	{"claude-opus-4", 15, 75},
+	{"claude-sonnet-4", 3, 15},
+	{"claude-haiku-4-5", 1, 5},
+	{"gpt-4.1-nano", 0.1, 0.4},
+	{"gpt-4.1-mini", 0.4, 1.6},
+	{"gpt-4.1", 2, 8},
+	{"gpt-4o-mini", 0.15, 0.6},
+	{"gpt-4o", 2.5, 10},
+	{"gpt-5-nano", 0.05, 0.4},
+	{"gpt-5-mini", 0.25, 2},
+	{"gpt-5", 1.25, 10},
+}
+
+// lookupLLMPrice returns the list price of model, or zeros for unknown and local models
+func lookupLLMPrice(model string) (input, output float64) {
+	model = strings.ToLower(model)
+	for _, p := range llmPrices {
+		if strings.HasPrefix(model, p.prefix) {
+			return p.input, p.output
+		}
+	}
+	return 0, 0
+}
+
+// llmCost estimates the cost in US dollars of a call with the given usage
+func llmCost(cfg LLMConfig, usage LLMUsage) float64 {
+	return (float64(usage.InputTokens)*cfg.InputPrice + float64(usage.OutputTokens)*cfg.OutputPrice) / 1e6
+}
+
+// MeterLLMProvider wraps provider so that every call of feature is recorded with its token
+// usage, latency and estimated cost, and calls are refused with ErrLLMBudgetExceeded once
+// the month's spending reaches cfg.MonthlyBudget
+func MeterLLMProvider(provider LLMProvider, db database.Querier, cfg LLMConfig, feature string) LLMProvider {
+	return &meteredLLMProvider{LLMProvider: provider, db: db, cfg: cfg, feature: feature}
+}
+
+type meteredLLMProvider struct {
+	LLMProvider
+	db      database.Querier
+	cfg     LLMConfig
+	feature string
+}
+
+func (p *meteredLLMProvider) Generate(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	if err := p.checkBudget(); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	resp, err := p.LLMProvider.Generate(ctx, req)
+	p.record(start, req, resp, err)
+	return resp, err
+}
+
+func (p *meteredLLMProvider) Stream(ctx context.Context, req LLMRequest, onText func(string) error) (*LLMResponse, error) {
+	if err := p.checkBudget(); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	resp, err := p.LLMProvider.Stream(ctx, req, onText)
+	p.record(start, req, resp, err)
+	return resp, err
+}
+
+func (p *meteredLLMProvider) checkBudget() error {
+	if p.cfg.MonthlyBudget <= 0 {
+		return nil
+	}
+	spent, err := MonthlyAICost(p.db, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to check the AI budget: %w", err)
+	}
+	if spent >= p.cfg.MonthlyBudget {
+		return fmt.Errorf("%w: spent $%.2f of $%.2f", ErrLLMBudgetExceeded, spent, p.cfg.MonthlyBudget)
+	}
+	return nil
+}
+
+// record stores the outcome and usage of a call, failed calls included. A stream that
+// stopped part way has been billed for the prompt and the text it delivered, so usage the
+// provider had not reported yet is estimated from them.
+func (p *meteredLLMProvider) record(start time.Time, req LLMRequest, resp *LLMResponse, err error) {
+	var usage LLMUsage
+	if resp != nil {
+		usage = resp.Usage
+		if err != nil && resp.Text != "" {
+			if usage.InputTokens == 0 {
+				usage.InputTokens = estimateRequestTokens(req)
+			}
+			if usage.OutputTokens == 0 {
+				usage.OutputTokens = estimateTokens(resp.Text)
+			}
+		}
+	}
+
+	if err := p.db.AddAIUsage(models.AIUsage{
+		CreatedAt:    start,
+		Feature:      p.feature,
+		Provider:     p.Name(),
+		Model:        p.Model(),
+		Status:       llmCallStatus(err),
+		InputTokens:  usage.InputTokens,
+		OutputTokens: usage.OutputTokens,
+		Latency:      time.Since(start),
+		Cost:         llmCost(p.cfg, usage),
+	}); err != nil {
+		log.Printf("Error recording AI usage: %v", err)
+	}
+}
+
+// llmCallStatus returns the outcome of a call that returned err
+func llmCallStatus(err error) string {
+	switch {
+	case err == nil:
+		return models.AIUsageOK
+	case errors.Is(err, context.Canceled):
+		return models.AIUsageCancelled
+	default:
+		return models.AIUsageFailed
+	}
+}
+
+// startOfMonth returns midnight of the first day of now's month
+func startOfMonth(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+}
+
+// MonthlyAICost returns the estimated cost in US dollars of the calls made in now's month
+func MonthlyAICost(db database.Querier, now time.Time) (float64, error) {
+	usage, err := db.GetAIUsage(startOfMonth(now))
+	if err != nil {
+		return 0, err
+	}
+	var cost float64
+	for _, u := range usage {
+		cost += u.Cost
+	}
+	return cost, nil
+}
+
+// AIUsageReport is the recent usage of the AI features against the monthly budget
+type AIUsageReport struct {
+	// Totals sum up the calls per month and model, newest month first
+	Totals []models.AIUsageTotal
+	// MonthCost is the estimated cost of this month's calls
+	MonthCost float64
+	// Budget is the monthly budget, 0 for none
+	Budget float64
+}
+
+// BudgetExceeded reports whether this month's calls reached the budget
+func (r AIUsageReport) BudgetExceeded() bool {
+	return r.Budget > 0 && r.MonthCost >= r.Budget
+}
+
+// GetAIUsageReport sums up the AI usage of the last months
+func GetAIUsageReport(db database.Querier, cfg LLMConfig, now time.Time) (*AIUsageReport, error) {
+	thisMonth := startOfMonth(now)
+	usage, err := db.GetAIUsage(thisMonth.AddDate(0, 1-aiUsageMonths, 0))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch AI usage: %w", err)
+	}
+
+	report := &AIUsageReport{Budget: cfg.MonthlyBudget}
+	index := map[string]int{}
+	// Newest first, so this month's totals lead the table
+	for i := len(usage) - 1; i >= 0; i-- {
+		u := usage[i]
+		month := startOfMonth(u.CreatedAt)
+		if !month.Before(thisMonth) {
+			report.MonthCost += u.Cost
+		}
+
+		key := month.Format("2006-01") + "\x00" + u.Provider + "\x00" + u.Model
+		n, ok := index[key]
+		if !ok {
+			n = len(report.Totals)
+			index[key] = n
+			report.Totals = append(report.Totals, models.AIUsageTotal{Month: month, Provider: u.Provider, Model: u.Model})
+		}
+		total := &report.Totals[n]
+		total.Calls++
+		if u.Status != models.AIUsageOK {
+			total.Failed++
+		}
+		total.InputTokens += u.InputTokens
+		total.OutputTokens += u.OutputTokens
+		total.Latency += u.Latency
+		total.Cost += u.Cost
+	}
+	return report, nil
+}
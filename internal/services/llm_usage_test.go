@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"health-balance/internal/models"
+	"testing"
+	"time"
+)
+
+func TestLookupLLMPrice(t *testing.T) {
+	tests := []struct {
+		model         string
+		input, output float64
+	}{
+		{"gemini-3-flash-preview", 0.5, 3},
+		{"claude-sonnet-4-5", 3, 15},
+		{"claude-opus-4-5-20251101", 5, 25},
+		{"claude-opus-4-1", 15, 75},
+		{"gpt-4o-mini", 0.15, 0.6},
+		{"GPT-4o", 2.5, 10},
+		{"llama3.1", 0, 0},
+	}
+	for _, tt := range tests {
+		if input, output := lookupLLMPrice(tt.model); input != tt.input || output != tt.output {
+			t.Errorf("%s: expected %v/%v, got %v/%v", tt.model, tt.input, tt.output, input, output)
+		}
+	}
+}
+
+func TestLoadLLMConfigPricing(t *testing.T) {
+	t.Setenv("LLM_PROVIDER", "anthropic")
+	t.Setenv("ANTHROPIC_MODEL", "")
+	t.Setenv("LLM_INPUT_PRICE", "")
+	t.Setenv("LLM_OUTPUT_PRICE", "")
+	t.Setenv("LLM_MONTHLY_BUDGET", "")
+	if cfg := LoadLLMConfig(); cfg.InputPrice != 3 || cfg.OutputPrice != 15 || cfg.MonthlyBudget != 0 {
+		t.Errorf("expected the list price without a budget, got %+v", cfg)
+	}
+
+	t.Setenv("LLM_INPUT_PRICE", "1.5")
+	t.Setenv("LLM_OUTPUT_PRICE", "-1")
+	t.Setenv("LLM_MONTHLY_BUDGET", "10")
+	if cfg := LoadLLMConfig(); cfg.InputPrice != 1.5 || cfg.OutputPrice != 15 || cfg.MonthlyBudget != 10 {
+		t.Errorf("expected the overridden price and budget, got %+v", cfg)
+	}
+}
+
+func TestMeterLLMProvider(t *testing.T) {
+	db := &MockDB{}
+	fake := &FakeLLMProvider{Response: "Sleep more."}
+	cfg := LLMConfig{InputPrice: 2, OutputPrice: 10, MonthlyBudget: 0.01}
+	provider := MeterLLMProvider(fake, db, cfg, models.AIFeatureCoach)
+
+	if _, err := provider.Generate(context.Background(), UserPrompt("How am I doing?")); err != nil {
+		t.Fatal(err)
+	}
+	if len(db.Usage) != 1 {
+		t.Fatalf("Expected the call recorded, got %+v", db.Usage)
+	}
+	u := db.Usage[0]
+	if u.Feature != models.AIFeatureCoach || u.Provider != LLMProviderFake || u.Status != models.AIUsageOK || u.InputTokens == 0 || u.OutputTokens == 0 {
+		t.Errorf("Unexpected usage %+v", u)
+	}
+	if want := (float64(u.InputTokens)*2 + float64(u.OutputTokens)*10) / 1e6; u.Cost != want {
+		t.Errorf("Expected a cost of %v, got %v", want, u.Cost)
+	}
+
+	fake.Err = errors.New("provider down")
+	if _, err := provider.Generate(context.Background(), UserPrompt("Again?")); err == nil || len(db.Usage) != 2 {
+		t.Fatalf("Expected the failed call recorded, got %v and %d records", err, len(db.Usage))
+	}
+	if u := db.Usage[1]; u.Status != models.AIUsageFailed || u.InputTokens != 0 || u.Cost != 0 {
+		t.Errorf("Expected a failed call without usage, got %+v", u)
+	}
+	fake.Err = nil
+
+	// A stream cancelled part way is billed for the prompt and the text sent so far
+	fake.Response = "Sleep more and walk daily."
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := provider.Stream(ctx, UserPrompt("Tell me more"), func(text string) error {
+		if text == "and " {
+			cancel()
+		}
+		return nil
+	})
+	cancel()
+	if !errors.Is(err, context.Canceled) || len(db.Usage) != 3 {
+		t.Fatalf("Expected the cancelled stream recorded, got %v and %d records", err, len(db.Usage))
+	}
+	if u := db.Usage[2]; u.Status != models.AIUsageCancelled || u.InputTokens != estimateTokens("Tell me more") ||
+		u.OutputTokens != estimateTokens("Sleep more and ") || u.Cost == 0 {
+		t.Errorf("Expected the partial usage of the cancelled stream, got %+v", u)
+	}
+
+	db.Usage = append(db.Usage, models.AIUsage{CreatedAt: time.Now(), Cost: 0.02})
+	calls := len(fake.Requests())
+	_, err = provider.Stream(context.Background(), UserPrompt("And now?"), func(string) error { return nil })
+	if !errors.Is(err, ErrLLMBudgetExceeded) || len(fake.Requests()) != calls {
+		t.Errorf("Expected the call refused over budget, got %v", err)
+	}
+}
+
+func TestGetAIUsageReport(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.Local)
+	db := &MockDB{Usage: []models.AIUsage{
+		{CreatedAt: time.Date(2025, 8, 1, 0, 0, 0, 0, time.Local), Provider: "gemini", Model: "old", Status: models.AIUsageOK, Cost: 9},
+		{CreatedAt: time.Date(2026, 2, 3, 0, 0, 0, 0, time.Local), Provider: "gemini", Model: "g", Status: models.AIUsageOK, InputTokens: 100, OutputTokens: 10, Latency: time.Second, Cost: 0.5},
+		{CreatedAt: time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local), Provider: "gemini", Model: "g", Status: models.AIUsageOK, InputTokens: 200, OutputTokens: 20, Latency: 2 * time.Second, Cost: 1},
+		{CreatedAt: time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local), Provider: "gemini", Model: "g", Status: models.AIUsageCancelled, InputTokens: 300, OutputTokens: 30, Latency: 4 * time.Second, Cost: 2},
+		{CreatedAt: time.Date(2026, 3, 3, 0, 0, 0, 0, time.Local), Provider: "openai", Model: "llama", Status: models.AIUsageOK, InputTokens: 50, OutputTokens: 5, Latency: time.Second},
+	}}
+
+	report, err := GetAIUsageReport(db, LLMConfig{MonthlyBudget: 3}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.MonthCost != 3 || !report.BudgetExceeded() {
+		t.Errorf("Expected $3 spent this month, over budget, got %+v", report)
+	}
+	if len(report.Totals) != 3 {
+		t.Fatalf("Expected totals per month and model within 6 months, got %+v", report.Totals)
+	}
+	march := report.Totals[1]
+	if !march.Month.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)) || march.Model != "g" || march.Calls != 2 || march.Failed != 1 ||
+		march.InputTokens != 500 || march.OutputTokens != 50 || march.Cost != 3 || march.AverageLatency() != 3*time.Second {
+		t.Errorf("Unexpected March total %+v", march)
+	}
+	if report.Totals[0].Model != "llama" || report.Totals[2].Month.Month() != time.February {
+		t.Errorf("Expected the newest totals first, got %+v", report.Totals)
+	}
+}
@@ -1,12 +1,17 @@
 package services
 
 import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
 	"crypto/ecdh"
 	"crypto/ecdsa"
 	"crypto/elliptic"
+	"crypto/hkdf"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"health-balance/internal/database"
@@ -17,6 +22,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -30,9 +36,15 @@ const VapidSubject = "mailto:admin@example.com"
 
 func StartNotificationScheduler(db database.Querier) {
 	ticker := time.NewTicker(1 * time.Minute)
+	autoSummary := newAutoSummaryJob(db)
 	go func() {
-		for range ticker.C {
+		for now := range ticker.C {
 			checkAndSendNotifications(db)
+			autoSummary.check(now)
+			// Goal status only changes with new data or passing deadlines, so hourly is enough
+			if now.Minute() == 0 {
+				checkGoalNotifications(db, now)
+			}
 		}
 	}()
 }
@@ -91,12 +103,41 @@ func checkAndSendNotifications(db database.Querier) {
 
 		if shouldSend {
 			log.Printf("Scheduler: Sending notification to %s (Timezone: %s, Local Time: %s)", sub.Endpoint, sub.Timezone, timeStr)
-			go sendPush(db, sub, priv)
+			go sendPush(db, sub, priv, nil)
 		}
 	}
 }
 
-func sendPush(db database.Querier, sub models.PushSubscription, priv *ecdsa.PrivateKey) {
+// sendPushToAll sends the message to every subscription
+func sendPushToAll(db database.Querier, message models.PushMessage) {
+	subs, err := db.GetAllSubscriptions()
+	if err != nil {
+		log.Printf("Failed to get subscriptions: %v", err)
+		return
+	}
+	if len(subs) == 0 {
+		return
+	}
+
+	_, privKeyStr := getVapidKeys()
+	if privKeyStr == "" {
+		log.Printf("VAPID_PRIVATE_KEY not set")
+		return
+	}
+	priv, err := decodePrivateKey(privKeyStr)
+	if err != nil {
+		log.Printf("Failed to decode VAPID private key: %v", err)
+		return
+	}
+
+	for _, sub := range subs {
+		go sendPush(db, sub, priv, &message)
+	}
+}
+
+// sendPush delivers a push to one subscription. Without a message the service worker
+// shows its default weekly reminder.
+func sendPush(db database.Querier, sub models.PushSubscription, priv *ecdsa.PrivateKey, message *models.PushMessage) {
 	parsedURL, err := url.Parse(sub.Endpoint)
 	if err != nil {
 		log.Printf("Invalid endpoint URL %s: %v", sub.Endpoint, err)
@@ -110,7 +151,20 @@ func sendPush(db database.Querier, sub models.PushSubscription, priv *ecdsa.Priv
 		return
 	}
 
-	req, err := http.NewRequest("POST", sub.Endpoint, nil)
+	var body []byte
+	if message != nil {
+		payload, err := json.Marshal(message)
+		if err != nil {
+			log.Printf("Failed to encode push message: %v", err)
+			return
+		}
+		if body, err = encryptPushPayload(sub, payload); err != nil {
+			log.Printf("Failed to encrypt push message for %s: %v", sub.Endpoint, err)
+			return
+		}
+	}
+
+	req, err := http.NewRequest("POST", sub.Endpoint, bytes.NewReader(body))
 	if err != nil {
 		log.Printf("Failed to create push request: %v", err)
 		return
@@ -120,7 +174,10 @@ func sendPush(db database.Querier, sub models.PushSubscription, priv *ecdsa.Priv
 	req.Header.Set("Authorization", fmt.Sprintf("vapid t=%s, k=%s", token, pubKey))
 	req.Header.Set("TTL", "86400")
 	req.Header.Set("Urgency", "normal")
-	req.Header.Set("Content-Length", "0")
+	if body != nil {
+		req.Header.Set("Content-Encoding", "aes128gcm")
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
 
 	client := &http.Client{Timeout: 10 * time.Second}
 	resp, err := client.Do(req)
@@ -149,6 +206,83 @@ func sendPush(db database.Querier, sub models.PushSubscription, priv *ecdsa.Priv
 	}
 }
 
+// encryptPushPayload encrypts the payload for the subscription's browser with the
+// aes128gcm content encoding of RFC 8291, as a single record
+func encryptPushPayload(sub models.PushSubscription, payload []byte) ([]byte, error) {
+	clientKey, err := decodeBase64URL(sub.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	authSecret, err := decodeBase64URL(sub.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth secret: %w", err)
+	}
+	clientPub, err := ecdh.P256().NewPublicKey(clientKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+
+	serverPriv, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	sharedSecret, err := serverPriv.ECDH(clientPub)
+	if err != nil {
+		return nil, err
+	}
+	serverKey := serverPriv.PublicKey().Bytes()
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	keyInfo := "WebPush: info\x00" + string(clientKey) + string(serverKey)
+	authPRK, err := hkdf.Extract(sha256.New, sharedSecret, authSecret)
+	if err != nil {
+		return nil, err
+	}
+	ikm, err := hkdf.Expand(sha256.New, authPRK, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// 0x02 pads and marks the last (and only) record
+	ciphertext := gcm.Seal(nil, nonce, append(payload, 0x02), nil)
+
+	header := make([]byte, 0, 16+4+1+len(serverKey))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, 4096)
+	header = append(header, byte(len(serverKey)))
+	header = append(header, serverKey...)
+	return append(header, ciphertext...), nil
+}
+
+// decodeBase64URL accepts the padded or unpadded URL-safe base64 browsers use for subscription keys
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
 func decodePrivateKey(encoded string) (*ecdsa.PrivateKey, error) {
 	raw, err := base64.RawURLEncoding.DecodeString(encoded)
 	if err != nil {
@@ -1,9 +1,17 @@
 package services
 
 import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
 	"crypto/ecdsa"
 	"crypto/elliptic"
+	"crypto/hkdf"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
 	"os"
 	"strings"
 	"testing"
@@ -154,3 +162,74 @@ func TestCheckAndSendNotificationsMissingData(t *testing.T) {
 	// Call checkAndSendNotifications - should attempt to send notification since data is missing
 	checkAndSendNotifications(mockDB)
 }
+
+func TestEncryptPushPayload(t *testing.T) {
+	// Play the browser: a P-256 key pair and a 16 byte auth secret
+	clientPriv, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authSecret := make([]byte, 16)
+	if _, err := rand.Read(authSecret); err != nil {
+		t.Fatal(err)
+	}
+	sub := models.PushSubscription{
+		P256dh: base64.URLEncoding.EncodeToString(clientPriv.PublicKey().Bytes()),
+		Auth:   base64.RawURLEncoding.EncodeToString(authSecret),
+	}
+
+	payload := []byte(`{"title":"Goal reached","body":"Waist ≤ 85 cm","url":"/#goals"}`)
+	body, err := encryptPushPayload(sub, payload)
+	if err != nil {
+		t.Fatalf("Failed to encrypt payload: %v", err)
+	}
+
+	salt := body[:16]
+	if rs := binary.BigEndian.Uint32(body[16:20]); rs != 4096 {
+		t.Errorf("Expected record size 4096, got %d", rs)
+	}
+	keyLen := int(body[20])
+	serverKey := body[21 : 21+keyLen]
+	ciphertext := body[21+keyLen:]
+
+	serverPub, err := ecdh.P256().NewPublicKey(serverKey)
+	if err != nil {
+		t.Fatalf("Invalid server key in header: %v", err)
+	}
+	shared, err := clientPriv.ECDH(serverPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyInfo := "WebPush: info\x00" + string(clientPriv.PublicKey().Bytes()) + string(serverKey)
+	ikm, err := hkdf.Key(sha256.New, shared, authSecret, keyInfo, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cek, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		t.Fatalf("Failed to decrypt payload: %v", err)
+	}
+	if !bytes.Equal(plaintext, append(payload, 0x02)) {
+		t.Errorf("Expected the payload followed by the last record delimiter, got %q", plaintext)
+	}
+
+	if _, err := encryptPushPayload(models.PushSubscription{P256dh: "bad", Auth: sub.Auth}, payload); err == nil {
+		t.Error("Expected an error for an invalid p256dh key")
+	}
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// OpenAIProvider calls an OpenAI-compatible chat completions API, which local servers
+// such as Ollama, llama.cpp and vLLM provide as well
+type OpenAIProvider struct {
+	apiKey  string
+	model   string
+	baseURL string
+	client  *http.Client
+}
+
+type OpenAIRequest struct {
+	Model          string                `json:"model"`
+	Messages       []OpenAIMessage       `json:"messages"`
+	MaxTokens      int                   `json:"max_tokens,omitempty"`
+	Stream         bool                  `json:"stream,omitempty"`
+	StreamOptions  *OpenAIStreamOptions  `json:"stream_options,omitempty"`
+	ResponseFormat *OpenAIResponseFormat `json:"response_format,omitempty"`
+}
+
+type OpenAIResponseFormat struct {
+	Type string `json:"type"`
+}
+
+type OpenAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type OpenAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type OpenAIResponse struct {
+	Choices []struct {
+		Message OpenAIMessage `json:"message"`
+		// Delta holds the new text of a streamed chunk
+		Delta OpenAIMessage `json:"delta"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+func (p *OpenAIProvider) Name() string  { return LLMProviderOpenAI }
+func (p *OpenAIProvider) Model() string { return p.model }
+
+func (p *OpenAIProvider) Generate(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	var resp OpenAIResponse
+	if err := postLLMJSON(ctx, p.client, "OpenAI", p.baseURL+"/chat/completions", p.headers(), p.request(req), &resp); err != nil {
+		return nil, err
+	}
+
+	if len(resp.Choices) == 0 || resp.Choices[0].Message.Content == "" {
+		return nil, fmt.Errorf("empty response from OpenAI API")
+	}
+	return &LLMResponse{Text: resp.Choices[0].Message.Content, Usage: resp.usage()}, nil
+}
+
+func (p *OpenAIProvider) Stream(ctx context.Context, req LLMRequest, onText func(string) error) (*LLMResponse, error) {
+	body := p.request(req)
+	body.Stream = true
+	body.StreamOptions = &OpenAIStreamOptions{IncludeUsage: true}
+
+	var (
+		text  strings.Builder
+		usage LLMUsage
+	)
+	err := streamLLM(ctx, p.client, "OpenAI", p.baseURL+"/chat/completions", p.headers(), body, func(_, data string) error {
+		if data == "[DONE]" {
+			return nil
+		}
+		var chunk OpenAIResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to unmarshal OpenAI stream chunk: %w", err)
+		}
+		// Usage arrives in a final chunk without choices
+		if u := chunk.usage(); u.InputTokens > 0 || u.OutputTokens > 0 {
+			usage = u
+		}
+		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+			text.WriteString(chunk.Choices[0].Delta.Content)
+			return onText(chunk.Choices[0].Delta.Content)
+		}
+		return nil
+	})
+	resp := &LLMResponse{Text: text.String(), Usage: usage}
+	if err != nil {
+		return resp, err
+	}
+
+	if text.Len() == 0 {
+		return resp, fmt.Errorf("empty response from OpenAI API")
+	}
+	return resp, nil
+}
+
+func (p *OpenAIProvider) headers() map[string]string {
+	headers := map[string]string{}
+	if p.apiKey != "" {
+		headers["Authorization"] = "Bearer " + p.apiKey
+	}
+	return headers
+}
+
+func (p *OpenAIProvider) request(req LLMRequest) OpenAIRequest {
+	body := OpenAIRequest{Model: p.model, MaxTokens: req.MaxTokens}
+	if req.JSON {
+		body.ResponseFormat = &OpenAIResponseFormat{Type: "json_object"}
+	}
+	if req.System != "" {
+		body.Messages = append(body.Messages, OpenAIMessage{Role: "system", Content: req.System})
+	}
+	for _, m := range req.Messages {
+		body.Messages = append(body.Messages, OpenAIMessage{Role: m.Role, Content: m.Content})
+	}
+	return body
+}
+
+func (r OpenAIResponse) usage() LLMUsage {
+	return LLMUsage{InputTokens: r.Usage.PromptTokens, OutputTokens: r.Usage.CompletionTokens}
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"health-balance/internal/database"
+	"health-balance/internal/models"
+	"health-balance/internal/utils"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+//go:embed prompts/summary.tmpl
+var builtinSummaryTemplate string
+
+// summaryTemplate is the parsed built-in template, whose "week" template the coach uses too
+var summaryTemplate = template.Must(parseSummaryTemplate(builtinSummaryTemplate))
+
+// summaryTemplateFile is the name of the summary template in PROMPT_TEMPLATES_DIR
+const summaryTemplateFile = "summary.tmpl"
+
+// Where the summary prompt template comes from
+const (
+	PromptSourceBuiltin  = "built-in"
+	PromptSourceFile     = "file"
+	PromptSourceSettings = "settings"
+)
+
+// SummaryTemplate is the source of a summary prompt template and where it comes from
+type SummaryTemplate struct {
+	Body   string
+	Source string
+	// Path is the file the template was read from, for PromptSourceFile
+	Path string
+}
+
+// SummaryPromptData is what the summary prompt template is rendered with, as documented in
+// prompts/summary.tmpl. Everything in it already respects the AI privacy settings.
+type SummaryPromptData struct {
+	Person  string
+	Profile PromptProfile
+	Scoring PromptScoringModel
+	Weeks   []PromptWeek
+	Format  string
+}
+
+// PromptProfile is the profile as it is sent, with hidden fields left empty
+type PromptProfile struct {
+	Age    string
+	Sex    string
+	Height string
+}
+
+// PromptScoringModel is what the "scoring" template explains the Master Score with
+type PromptScoringModel struct {
+	// Baseline is the Master Score everyone starts from
+	Baseline float64
+	// ExactAge is whether the exact age is shared. Age and DecayRate, the weekly aging tax
+	// in percent, are 0 otherwise, since the decay rate gives the age away.
+	ExactAge  bool
+	Age       int
+	DecayRate float64
+	// Markers are the reserve markers, which carry the most weight, with their targets
+	Markers []PromptTarget
+	// Behaviors are the reserve-building behaviors, scored by their recent consistency
+	Behaviors []string
+}
+
+// PromptTarget is a reserve marker and the value it is scored against
+type PromptTarget struct {
+	Label  string
+	Target string
+}
+
+// PromptWeek is the scores, shared metrics and note of one week
+type PromptWeek struct {
+	Date           string
+	Score          float64
+	HealthScore    float64
+	FitnessScore   float64
+	CognitionScore float64
+	// AgingTax is 0 unless the exact age is shared, since it gives the age away
+	AgingTax float64
+	Pillars  []PromptPillar
+	Note     string
+}
+
+// PromptPillar is the shared metrics of one pillar
+type PromptPillar struct {
+	Label   string
+	Metrics []PromptMetric
+}
+
+// PromptMetric is a metric formatted for the prompt, e.g. "~80 kg" or "not recorded"
+type PromptMetric struct {
+	Key   string
+	Label string
+	Value string
+}
+
+// BuiltinSummaryTemplate returns the summary prompt template shipped with the app
+func BuiltinSummaryTemplate() SummaryTemplate {
+	return SummaryTemplate{Body: builtinSummaryTemplate, Source: PromptSourceBuiltin}
+}
+
+// ActiveSummaryTemplate returns the template summaries are rendered from: the one saved in
+// the settings, else summary.tmpl in PROMPT_TEMPLATES_DIR, else the built-in one. A file
+// that can't be read or doesn't render is logged and skipped.
+func ActiveSummaryTemplate(db database.Querier) (SummaryTemplate, error) {
+	saved, err := db.GetPromptTemplate(models.PromptTemplateSummary)
+	if err != nil {
+		return SummaryTemplate{}, fmt.Errorf("failed to fetch the prompt template: %w", err)
+	}
+	if saved != nil {
+		return SummaryTemplate{Body: saved.Body, Source: PromptSourceSettings}, nil
+	}
+
+	if dir := strings.TrimSpace(os.Getenv("PROMPT_TEMPLATES_DIR")); dir != "" {
+		path := filepath.Join(dir, summaryTemplateFile)
+		body, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			log.Printf("Error reading prompt template %s: %v", path, err)
+		default:
+			if err := ValidateSummaryTemplate(string(body)); err != nil {
+				log.Printf("Ignoring prompt template %s: %v", path, err)
+			} else {
+				return SummaryTemplate{Body: string(body), Source: PromptSourceFile, Path: path}, nil
+			}
+		}
+	}
+	return BuiltinSummaryTemplate(), nil
+}
+
+// ValidateSummaryTemplate checks that body parses and renders a prompt from sample data
+// using every field, so mistyped fields are caught before the template is used
+func ValidateSummaryTemplate(body string) error {
+	tmpl, err := parseSummaryTemplate(body)
+	if err != nil {
+		return err
+	}
+	prompt, err := renderSummaryPrompt(tmpl, sampleSummaryPromptData())
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(prompt) == "" {
+		return errors.New("template renders an empty prompt")
+	}
+	return nil
+}
+
+func parseSummaryTemplate(body string) (*template.Template, error) {
+	return template.New(models.PromptTemplateSummary).Parse(body)
+}
+
+func renderSummaryPrompt(tmpl *template.Template, data SummaryPromptData) (string, error) {
+	var prompt strings.Builder
+	if err := tmpl.Execute(&prompt, data); err != nil {
+		return "", err
+	}
+	return prompt.String(), nil
+}
+
+// sampleSummaryPromptData fills every field of the template data for validation
+func sampleSummaryPromptData() SummaryPromptData {
+	profile := &models.UserProfile{BirthDate: "1980-01-01", Sex: "female", HeightCm: 170}
+	week := WeeklyData{
+		Score:     models.MasterScore{Date: "2026-01-04", Score: 1000, HealthScore: 100, FitnessScore: 100, CognitionScore: 100, AgingTax: 4},
+		Health:    &models.HealthMetrics{},
+		Fitness:   &models.FitnessMetrics{},
+		Cognition: &models.CognitionMetrics{},
+		Note:      &models.WeekNote{Text: "Sample note"},
+	}
+	return newSummaryPromptData(profile, []WeeklyData{week}, models.AIPrivacy{}, time.Now())
+}
+
+func newSummaryPromptData(profile *models.UserProfile, data []WeeklyData, privacy models.AIPrivacy, now time.Time) SummaryPromptData {
+	age, _ := utils.GetAge(profile, now)
+	prompt := SummaryPromptData{
+		Person: privacy.Person(age, profile),
+		Profile: PromptProfile{
+			Age:    privacy.Age(age),
+			Sex:    privacy.Sex(profile),
+			Height: privacy.Height(profile),
+		},
+		Scoring: newPromptScoringModel(age, privacy),
+		Format:  summaryFormat,
+	}
+	for _, d := range data {
+		prompt.Weeks = append(prompt.Weeks, newPromptWeek(d, privacy))
+	}
+	return prompt
+}
+
+// newPromptScoringModel describes how the Master Score is calculated, leaving the age out
+// unless privacy shares it exactly
+func newPromptScoringModel(age int, privacy models.AIPrivacy) PromptScoringModel {
+	scoring := PromptScoringModel{
+		Baseline: defaultMasterScore,
+		Markers: []PromptTarget{
+			{Label: "VO2 Max", Target: "the average for their age and sex"},
+			{Label: "WHtR (Waist-to-Height Ratio)", Target: "0.48 or lower"},
+			{Label: "RHR (Resting Heart Rate)", Target: "their personal baseline or lower"},
+			{Label: "Blood Pressure", Target: fmt.Sprintf("%.0f/%.0f mmHg or lower", neutralSystolicBP, neutralDiastolicBP)},
+			{Label: "Lower-body Strength", Target: "a relative strength index (leg press weight / body weight × reps) of 24"},
+			{Label: "Grip Strength", Target: "a 60 second dead hang"},
+		},
+		Behaviors: []string{"Sleep", "Nutrition", "Workouts", "Steps", "Mobility", "Cardio Recovery", "Mindfulness", "Deep Learning", "Stress Score", "Social Days"},
+	}
+	if privacy.ExactAge() {
+		scoring.ExactAge = true
+		scoring.Age = age
+		scoring.DecayRate = (float64(age*age) / 8000.0) / 52.0 * 100.0
+	}
+	return scoring
+}
+
+// newPromptWeek collects the scores, metrics and note of one week, leaving out what privacy hides
+func newPromptWeek(d WeeklyData, privacy models.AIPrivacy) PromptWeek {
+	s := d.Score
+	week := PromptWeek{
+		Date:           s.Date,
+		Score:          s.Score,
+		HealthScore:    s.HealthScore,
+		FitnessScore:   s.FitnessScore,
+		CognitionScore: s.CognitionScore,
+	}
+	if privacy.ExactAge() {
+		week.AgingTax = s.AgingTax
+	}
+
+	if d.Health != nil {
+		week.addPillar("Health", models.PillarHealth, d.Health.Value, privacy)
+	}
+	if d.Fitness != nil {
+		week.addPillar("Fitness", models.PillarFitness, d.Fitness.Value, privacy)
+	}
+	if d.Cognition != nil {
+		week.addPillar("Cognition", models.PillarCognition, d.Cognition.Value, privacy)
+	}
+	if d.Note != nil && !d.Note.Empty() && !privacy.HideNotes {
+		week.Note = d.Note.Summary()
+	}
+	return week
+}
+
+// addPillar adds the shared metrics of one pillar, if any
+func (w *PromptWeek) addPillar(label, pillar string, value func(key string) *float64, privacy models.AIPrivacy) {
+	p := PromptPillar{Label: label}
+	for _, m := range models.MetricsForPillar(pillar) {
+		if privacy.Shares(m.Key) {
+			p.Metrics = append(p.Metrics, PromptMetric{Key: m.Key, Label: m.Label, Value: promptMetric(m, value(m.Key), privacy)})
+		}
+	}
+	if len(p.Metrics) > 0 {
+		w.Pillars = append(w.Pillars, p)
+	}
+}
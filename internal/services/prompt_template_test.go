@@ -0,0 +1,88 @@
+package services
+
+import (
+	"health-balance/internal/models"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinSummaryTemplate(t *testing.T) {
+	if err := ValidateSummaryTemplate(builtinSummaryTemplate); err != nil {
+		t.Fatalf("Expected the built-in template to be valid, got %v", err)
+	}
+
+	prompt, err := renderSummaryPrompt(summaryTemplate, sampleSummaryPromptData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(prompt, "You are an expert longevity and health coach. Based on the following health data for a ") {
+		t.Errorf("Expected the template comment left out of the prompt, got %q", prompt[:min(len(prompt), 200)])
+	}
+	for _, want := range []string{
+		"\n### Week of 2026-01-04\n- **Scores**: Total: 1000.0 | Health: 100.0 | Fitness: 100.0 | Cognition: 100.0 | Aging Tax: -4.0\n- **Health Metrics**: Body Weight: not recorded | ",
+		"- **User Notes**: Sample note\n\nAnalysis Task:\n",
+		"   - For this user, the weekly decay rate is approximately ",
+		"   - Blood Pressure: 120/80 mmHg or lower\n",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected %q in the prompt:\n%s", want, prompt)
+		}
+	}
+	if !strings.HasSuffix(prompt, "Use null for target_value when there is no target metric.\n") {
+		t.Error("Expected the response format at the end of the prompt")
+	}
+}
+
+func TestValidateSummaryTemplate(t *testing.T) {
+	valid := `Advise {{.Person}} ({{.Profile.Age}}, {{.Profile.Sex}}, {{.Profile.Height}}).
+{{range .Weeks}}{{.Date}}: {{.Score}}{{range .Pillars}}{{range .Metrics}} {{.Key}}={{.Value}}{{end}}{{end}} {{.Note}}
+{{end}}Aging tax: {{.Scoring.DecayRate}}%{{range .Scoring.Markers}} {{.Label}}={{.Target}}{{end}}
+{{.Format}}`
+	if err := ValidateSummaryTemplate(valid); err != nil {
+		t.Errorf("Expected a custom template to be valid, got %v", err)
+	}
+
+	for name, body := range map[string]string{
+		"syntax":        "{{range .Weeks}}",
+		"unknown field": "{{.Person}} {{range .Weeks}}{{.Sleep}}{{end}}",
+		"nested field":  "{{range .Weeks}}{{range .Pillars}}{{.Score}}{{end}}{{end}}",
+		"empty":         "{{/* nothing */}}  ",
+	} {
+		if err := ValidateSummaryTemplate(body); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestActiveSummaryTemplate(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PROMPT_TEMPLATES_DIR", dir)
+	db := &MockDB{}
+
+	tmpl, err := ActiveSummaryTemplate(db)
+	if err != nil || tmpl.Source != PromptSourceBuiltin || tmpl.Body != builtinSummaryTemplate {
+		t.Fatalf("Expected the built-in template without overrides, got %+v (%v)", tmpl, err)
+	}
+
+	path := filepath.Join(dir, "summary.tmpl")
+	if err := os.WriteFile(path, []byte("{{range .Weeks}}{{.Typo}}{{end}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if tmpl, _ := ActiveSummaryTemplate(db); tmpl.Source != PromptSourceBuiltin {
+		t.Errorf("Expected an invalid file skipped, got %+v", tmpl)
+	}
+
+	if err := os.WriteFile(path, []byte("File {{.Person}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if tmpl, _ := ActiveSummaryTemplate(db); tmpl.Source != PromptSourceFile || tmpl.Path != path || tmpl.Body != "File {{.Person}}" {
+		t.Errorf("Expected the file template, got %+v", tmpl)
+	}
+
+	db.Templates = map[string]models.PromptTemplate{models.PromptTemplateSummary: {Name: models.PromptTemplateSummary, Body: "Saved {{.Person}}"}}
+	if tmpl, _ := ActiveSummaryTemplate(db); tmpl.Source != PromptSourceSettings || tmpl.Body != "Saved {{.Person}}" {
+		t.Errorf("Expected the saved template to win, got %+v", tmpl)
+	}
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"health-balance/internal/database"
+	"health-balance/internal/models"
+	"health-balance/internal/utils"
+	"slices"
+	"time"
+)
+
+const defaultReportMonths = 3
+
+// ErrInvalidReportPeriod is returned for malformed or reversed report dates
+var ErrInvalidReportPeriod = errors.New("invalid report period")
+
+// reportMarkerKeys are the recorded reserve markers shown in the report and the year in
+// review. Leg press weight is tracked as entered; the functional age instead uses leg
+// strength relative to body weight, which has no series of its own.
+var reportMarkerKeys = []string{"vo2_max", "waist_cm", "rhr", "systolic_bp", "dead_hang_seconds", "lower_body_weight"}
+
+// DefaultReportPeriod returns the last three months up to the current week
+func DefaultReportPeriod() (string, string) {
+	to := utils.GetCurrentWeekSundayDate()
+	toDate, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return "", to
+	}
+	return toDate.AddDate(0, -defaultReportMonths, 0).Format("2006-01-02"), to
+}
+
+// BuildReport collects the profile, scores, reserve markers and blood pressure readings
+// for the period from..to (inclusive) into a report for sharing with a doctor.
+func BuildReport(db database.Querier, from, to string) (*models.HealthReport, error) {
+	fromDate, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid start date %q", ErrInvalidReportPeriod, from)
+	}
+	toDate, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid end date %q", ErrInvalidReportPeriod, to)
+	}
+	if toDate.Before(fromDate) {
+		return nil, fmt.Errorf("%w: start date is after end date", ErrInvalidReportPeriod)
+	}
+
+	profile, err := db.GetUserProfile()
+	if err != nil || profile == nil {
+		return nil, errors.New("profile required for health report")
+	}
+	age, err := utils.GetAge(profile, toDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid profile: %w", err)
+	}
+
+	report := &models.HealthReport{
+		From:        from,
+		To:          to,
+		GeneratedAt: time.Now(),
+		Profile:     *profile,
+		Age:         age,
+	}
+
+	scores, err := GetAllWeeklyScores(db)
+	if err != nil {
+		return nil, err
+	}
+	var period []models.MasterScore
+	for _, s := range scores {
+		if s.Date >= from && s.Date <= to {
+			period = append(period, s)
+		}
+	}
+	if len(period) > 0 {
+		current := period[len(period)-1]
+		report.CurrentScore = &current
+		for _, s := range scoreChartSeries {
+			report.Trends = append(report.Trends, scoreTrend(period, s))
+		}
+	}
+
+	cfg := LoadBaselineConfig()
+	for _, key := range reportMarkerKeys {
+		marker, err := reportMarker(db, key, from, to, toDate, *profile, age, cfg)
+		if err != nil {
+			return nil, err
+		}
+		report.Markers = append(report.Markers, marker)
+	}
+
+	if err := addBloodPressure(db, report); err != nil {
+		return nil, err
+	}
+
+	if report.Notes, err = db.GetWeekNotes(from, to); err != nil {
+		return nil, fmt.Errorf("failed to fetch week notes: %w", err)
+	}
+
+	return report, nil
+}
+
+func scoreTrend(period []models.MasterScore, series models.MetricDefinition) models.ReportTrend {
+	var total float64
+	for _, s := range period {
+		total += s.ScoreValue(series.Key)
+	}
+	return models.ReportTrend{
+		Label:   series.Label,
+		Start:   period[0].ScoreValue(series.Key),
+		End:     period[len(period)-1].ScoreValue(series.Key),
+		Average: total / float64(len(period)),
+		Weeks:   len(period),
+	}
+}
+
+func reportMarker(db database.Querier, key, from, to string, asOf time.Time, profile models.UserProfile, age int, cfg BaselineConfig) (models.ReportMarker, error) {
+	metric, _ := models.LookupMetric(key)
+	marker := models.ReportMarker{Metric: metric}
+
+	points, err := db.GetMetricSeries(key, from, to)
+	if err != nil {
+		return marker, fmt.Errorf("failed to fetch %s series: %w", key, err)
+	}
+	if len(points) > 0 {
+		last := points[len(points)-1]
+		marker.Latest = models.Float(last.Value)
+		marker.LatestDate = last.Date
+	}
+
+	switch key {
+	case "vo2_max":
+		marker.AgeBaseline = models.Float(models.GetVO2MaxNorm(float64(age), profile.Sex).P50)
+	case "waist_cm":
+		if profile.HeightCm > 0 {
+			marker.AgeBaseline = models.Float(models.GetWHtRBaseline(age) * profile.HeightCm)
+		}
+	case "rhr":
+		marker.AgeBaseline = models.Float(models.GetRHRBaseline(age))
+	case "systolic_bp":
+		marker.AgeBaseline = models.Float(models.GetSystolicBPBaseline(age))
+	case "dead_hang_seconds":
+		marker.AgeBaseline = models.Float(models.GetDeadHangBaseline(age))
+	}
+
+	personal, err := GetPersonalBaseline(db, key, asOf, cfg)
+	if err != nil {
+		return marker, err
+	}
+	if personal != nil && personal.Samples >= minDisplayBaselineSamples {
+		personal.Current = marker.Latest
+		marker.Personal = personal
+	}
+	return marker, nil
+}
+
+// addBloodPressure merges the systolic and diastolic series by week and averages each side
+func addBloodPressure(db database.Querier, report *models.HealthReport) error {
+	systolic, err := db.GetMetricSeries("systolic_bp", report.From, report.To)
+	if err != nil {
+		return fmt.Errorf("failed to fetch systolic BP series: %w", err)
+	}
+	diastolic, err := db.GetMetricSeries("diastolic_bp", report.From, report.To)
+	if err != nil {
+		return fmt.Errorf("failed to fetch diastolic BP series: %w", err)
+	}
+
+	byDate := map[string]*models.BloodPressureReading{}
+	var dates []string
+	reading := func(date string) *models.BloodPressureReading {
+		if r, ok := byDate[date]; ok {
+			return r
+		}
+		r := &models.BloodPressureReading{Date: date}
+		byDate[date] = r
+		dates = append(dates, date)
+		return r
+	}
+	for _, p := range systolic {
+		reading(p.Date).Systolic = models.Float(p.Value)
+	}
+	for _, p := range diastolic {
+		reading(p.Date).Diastolic = models.Float(p.Value)
+	}
+
+	slices.Sort(dates)
+	for _, date := range dates {
+		report.BloodPressure = append(report.BloodPressure, *byDate[date])
+	}
+	report.AverageSystolic = averagePoints(systolic)
+	report.AverageDiastolic = averagePoints(diastolic)
+	return nil
+}
+
+func averagePoints(points []models.MetricPoint) *float64 {
+	if len(points) == 0 {
+		return nil
+	}
+	var total float64
+	for _, p := range points {
+		total += p.Value
+	}
+	return models.Float(total / float64(len(points)))
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"fmt"
+	"health-balance/internal/models"
+	"health-balance/internal/pdf"
+	"regexp"
+	"strings"
+)
+
+const (
+	reportMargin     = 50.0
+	reportLineHeight = 14.0
+	reportBodySize   = 10.0
+)
+
+// reportPDF lays out report sections top to bottom, starting new pages as needed
+type reportPDF struct {
+	doc *pdf.Document
+	y   float64
+}
+
+// RenderReportPDF lays out the health report as an A4 PDF
+func RenderReportPDF(report *models.HealthReport) []byte {
+	p := &reportPDF{doc: pdf.New(), y: reportMargin}
+
+	p.doc.Text(reportMargin, p.y+6, 18, true, "Health Balance Report")
+	p.y += 26
+	p.line(fmt.Sprintf("Period %s to %s · generated %s", report.From, report.To, report.GeneratedAt.Format("2006-01-02 15:04")), false)
+	p.line(reportProfileLine(report), false)
+
+	p.heading("Current Score")
+	if report.CurrentScore == nil {
+		p.line("No scores in this period.", false)
+	} else {
+		s := report.CurrentScore
+		p.line(fmt.Sprintf("%.1f in the week of %s (%.0f%% data confidence)", s.Score, s.Date, s.Confidence*100), true)
+	}
+
+	if len(report.Trends) > 0 {
+		p.heading("Score Trends")
+		columns := []float64{0, 150, 230, 310, 390}
+		p.row(columns, true, "", "Start", "End", "Change", "Average")
+		for _, t := range report.Trends {
+			p.row(columns, false, t.Label, fmt.Sprintf("%.1f", t.Start), fmt.Sprintf("%.1f", t.End), fmt.Sprintf("%+.1f", t.Change()), fmt.Sprintf("%.1f", t.Average))
+		}
+	}
+
+	p.heading("Reserve Markers")
+	columns := []float64{0, 150, 250, 330, 420}
+	p.row(columns, true, "", "Latest", "Week", "Age Typical", "Personal Baseline")
+	for _, m := range report.Markers {
+		p.row(columns, false, m.Metric.Label,
+			reportValue(m.Metric, m.Latest), orDash(m.LatestDate),
+			reportValue(m.Metric, m.AgeBaseline), reportPersonal(m))
+	}
+
+	p.heading("Blood Pressure")
+	if len(report.BloodPressure) == 0 {
+		p.line("No blood pressure readings in this period.", false)
+	} else {
+		if report.AverageSystolic != nil && report.AverageDiastolic != nil {
+			p.line(fmt.Sprintf("Average %.0f/%.0f mmHg over %d readings", *report.AverageSystolic, *report.AverageDiastolic, len(report.BloodPressure)), true)
+		}
+		columns := []float64{0, 100, 170}
+		p.row(columns, true, "Week", "Systolic", "Diastolic")
+		for _, r := range report.BloodPressure {
+			p.row(columns, false, r.Date, models.FormatMetric("%.0f", r.Systolic), models.FormatMetric("%.0f", r.Diastolic))
+		}
+	}
+
+	if len(report.Notes) > 0 {
+		p.heading("Week Notes")
+		for _, n := range report.Notes {
+			for _, l := range pdf.Wrap(n.Date+" "+n.Summary(), reportBodySize, pdf.PageWidth-2*reportMargin, false) {
+				p.line(l, false)
+			}
+		}
+	}
+
+	if report.Summary != "" {
+		p.heading("Latest AI Summary")
+		p.line("Generated "+report.SummaryGeneratedAt.Format("2006-01-02 15:04"), false)
+		for _, paragraph := range plainSummary(report.Summary) {
+			if paragraph == "" {
+				p.y += reportLineHeight / 2
+				continue
+			}
+			for _, l := range pdf.Wrap(paragraph, reportBodySize, pdf.PageWidth-2*reportMargin, false) {
+				p.line(l, false)
+			}
+		}
+	}
+
+	return p.doc.Bytes()
+}
+
+func (p *reportPDF) ensureSpace(height float64) {
+	if p.y+height > pdf.PageHeight-reportMargin {
+		p.doc.AddPage()
+		p.y = reportMargin
+	}
+}
+
+func (p *reportPDF) heading(text string) {
+	p.ensureSpace(3 * reportLineHeight)
+	p.y += reportLineHeight
+	p.doc.Text(reportMargin, p.y, 13, true, text)
+	p.doc.Line(reportMargin, p.y+4, pdf.PageWidth-reportMargin, p.y+4, 0.5)
+	p.y += reportLineHeight + 4
+}
+
+func (p *reportPDF) line(text string, bold bool) {
+	p.ensureSpace(reportLineHeight)
+	p.doc.Text(reportMargin, p.y, reportBodySize, bold, text)
+	p.y += reportLineHeight
+}
+
+func (p *reportPDF) row(columns []float64, bold bool, cells ...string) {
+	p.ensureSpace(reportLineHeight)
+	for i, cell := range cells {
+		if cell == "" {
+			continue
+		}
+		p.doc.Text(reportMargin+columns[i], p.y, reportBodySize, bold, cell)
+	}
+	p.y += reportLineHeight
+}
+
+func reportProfileLine(report *models.HealthReport) string {
+	parts := []string{fmt.Sprintf("Age %d", report.Age)}
+	if report.Profile.Sex != "" {
+		parts = append(parts, report.Profile.Sex)
+	}
+	if report.Profile.HeightCm > 0 {
+		parts = append(parts, fmt.Sprintf("%.0f cm", report.Profile.HeightCm))
+	}
+	return strings.Join(parts, " · ")
+}
+
+func reportValue(metric models.MetricDefinition, value *float64) string {
+	if value == nil {
+		return "—"
+	}
+	formatted := metric.FormatValue(*value)
+	if metric.Unit != "" {
+		formatted += " " + metric.Unit
+	}
+	return formatted
+}
+
+func reportPersonal(m models.ReportMarker) string {
+	if m.Personal == nil {
+		return "—"
+	}
+	return fmt.Sprintf("%s (%d wks)", reportValue(m.Metric, &m.Personal.Value), m.Personal.Samples)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "—"
+	}
+	return s
+}
+
+var (
+	markdownEmphasis = regexp.MustCompile(`\*\*|__|\x60`)
+	markdownHeading  = regexp.MustCompile(`^#{1,6}\s*`)
+	markdownBullet   = regexp.MustCompile(`^\s*[-*+]\s+`)
+)
+
+// plainSummary turns the markdown summary into plain paragraphs for the PDF,
+// keeping list items as bullets and blank lines as paragraph breaks
+func plainSummary(markdown string) []string {
+	var paragraphs []string
+	for _, line := range strings.Split(markdown, "\n") {
+		line = strings.TrimSpace(line)
+		line = markdownHeading.ReplaceAllString(line, "")
+		line = markdownBullet.ReplaceAllString(line, "• ")
+		line = markdownEmphasis.ReplaceAllString(line, "")
+		paragraphs = append(paragraphs, line)
+	}
+	return paragraphs
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"health-balance/internal/models"
+	"health-balance/internal/utils"
+	"testing"
+	"time"
+)
+
+func TestBuildReport(t *testing.T) {
+	currentWeek, err := time.Parse("2006-01-02", utils.GetCurrentWeekSundayDate())
+	if err != nil {
+		t.Fatalf("Failed to parse current week: %v", err)
+	}
+
+	mock := &MockDB{
+		UserProfile:  &models.UserProfile{BirthDate: "1980-01-01", HeightCm: 180, Sex: "male"},
+		HealthMap:    map[string]*models.HealthMetrics{},
+		FitnessMap:   map[string]*models.FitnessMetrics{},
+		CognitionMap: map[string]*models.CognitionMetrics{},
+		WeekNotes: []models.WeekNote{
+			{Date: currentWeek.AddDate(0, 0, -35).Format("2006-01-02"), Tags: []string{"holiday"}},
+			{Date: currentWeek.AddDate(0, 0, -7).Format("2006-01-02"), Text: "Flu", Tags: []string{"sick"}},
+		},
+	}
+	for i := 0; i < 6; i++ {
+		date := currentWeek.AddDate(0, 0, -7*i).Format("2006-01-02")
+		mock.AllDates = append(mock.AllDates, date)
+		health := &models.HealthMetrics{RHR: models.Int(60 - i), SystolicBP: models.Int(120 + i)}
+		if i != 2 {
+			health.DiastolicBP = models.Int(80)
+		}
+		mock.HealthMap[date] = health
+		mock.FitnessMap[date] = &models.FitnessMetrics{VO2Max: models.Float(44)}
+		mock.CognitionMap[date] = &models.CognitionMetrics{StressScore: models.Int(2)}
+	}
+
+	from := currentWeek.AddDate(0, 0, -21).Format("2006-01-02")
+	to := currentWeek.Format("2006-01-02")
+	report, err := BuildReport(mock, from, to)
+	if err != nil {
+		t.Fatalf("Failed to build report: %v", err)
+	}
+
+	if report.CurrentScore == nil || report.CurrentScore.Date != to {
+		t.Errorf("Expected the current score to be the last week of the period, got %+v", report.CurrentScore)
+	}
+	if len(report.Trends) == 0 || report.Trends[0].Weeks != 4 {
+		t.Errorf("Expected trends over the four weeks in the period, got %+v", report.Trends)
+	}
+	if len(report.BloodPressure) != 4 || report.BloodPressure[0].Date != from || report.BloodPressure[1].Diastolic != nil {
+		t.Errorf("Expected four BP readings oldest first with the skipped diastolic kept empty, got %+v", report.BloodPressure)
+	}
+	if report.AverageSystolic == nil || *report.AverageSystolic != 121.5 {
+		t.Errorf("Expected an average systolic of 121.5, got %v", report.AverageSystolic)
+	}
+
+	if len(report.Notes) != 1 || report.Notes[0].Text != "Flu" {
+		t.Errorf("Expected only the week note inside the period, got %+v", report.Notes)
+	}
+
+	for _, m := range report.Markers {
+		if m.Metric.Key != "rhr" {
+			continue
+		}
+		if m.Latest == nil || *m.Latest != 60 || m.LatestDate != to {
+			t.Errorf("Expected this week's RHR of 60, got %+v", m)
+		}
+		if m.AgeBaseline == nil || m.Personal == nil {
+			t.Errorf("Expected RHR to have age and personal baselines, got %+v", m)
+		}
+	}
+
+	if _, err := BuildReport(mock, to, from); !errors.Is(err, ErrInvalidReportPeriod) {
+		t.Errorf("Expected a reversed period to be rejected, got %v", err)
+	}
+	if _, err := BuildReport(mock, "last month", to); !errors.Is(err, ErrInvalidReportPeriod) {
+		t.Errorf("Expected a malformed date to be rejected, got %v", err)
+	}
+
+	report.Summary = "## Focus\n- **Sleep** more"
+	out := RenderReportPDF(report)
+	if !bytes.HasPrefix(out, []byte("%PDF-")) || !bytes.Contains(out, []byte("(\x95 Sleep more) Tj")) {
+		t.Error("Expected a PDF with the summary flattened to plain text")
+	}
+	if !bytes.Contains(out, []byte("[sick] Flu) Tj")) {
+		t.Error("Expected the week notes in the PDF")
+	}
+}
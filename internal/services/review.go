@@ -0,0 +1,153 @@
+package services
+
+import (
+	"cmp"
+	"fmt"
+	"health-balance/internal/database"
+	"health-balance/internal/models"
+	"slices"
+	"strconv"
+)
+
+// GetYearReview aggregates the weekly scores and reserve markers of one calendar year.
+// A year without scores returns a review with no weeks, so the page can still offer
+// the years that do have data.
+func GetYearReview(db database.Querier, year int) (*models.YearReview, error) {
+	scores, err := GetAllWeeklyScores(db)
+	if err != nil {
+		return nil, err
+	}
+
+	review := &models.YearReview{Year: year}
+	var (
+		weeks    []models.MasterScore
+		previous *models.MasterScore
+	)
+	for i, s := range scores {
+		scoreYear, err := strconv.Atoi(s.Date[:4])
+		if err != nil {
+			return nil, fmt.Errorf("invalid score date %s: %w", s.Date, err)
+		}
+		if !slices.Contains(review.Years, scoreYear) {
+			review.Years = append(review.Years, scoreYear)
+		}
+		if scoreYear != year {
+			continue
+		}
+		if len(weeks) == 0 && i > 0 {
+			previous = &scores[i-1]
+		}
+		weeks = append(weeks, s)
+	}
+	if len(weeks) == 0 {
+		return review, nil
+	}
+
+	summary := summarizeScores(weeks)
+	review.Weeks = summary.Weeks
+	review.StartScore = summary.StartScore
+	review.EndScore = summary.EndScore
+	review.AgingTaxPaid = summary.AgingTax
+	review.AverageHealth = summary.AverageHealth
+	review.AverageFitness = summary.AverageFitness
+	review.AverageCognition = summary.AverageCognition
+	review.CompleteWeeks = summary.CompleteWeeks
+
+	// Best and worst weeks are judged by the change in total score, so the first week
+	// of the year needs the last week of the year before to compare against
+	streak := 0
+	for i, s := range weeks {
+		if s.IsImputed() {
+			streak = 0
+		} else {
+			streak++
+			review.LongestStreak = max(review.LongestStreak, streak)
+		}
+
+		before := previous
+		if i > 0 {
+			before = &weeks[i-1]
+		}
+		if before == nil {
+			continue
+		}
+		week := &models.ReviewWeek{Date: s.Date, Score: s.Score, Change: s.Score - before.Score}
+		if review.BestWeek == nil || week.Change > review.BestWeek.Change {
+			review.BestWeek = week
+		}
+		if review.WorstWeek == nil || week.Change < review.WorstWeek.Change {
+			review.WorstWeek = week
+		}
+	}
+
+	var previousQuarter *models.QuarterReview
+	for quarter := 1; quarter <= 4; quarter++ {
+		var quarterWeeks []models.MasterScore
+		for _, s := range weeks {
+			month, _ := strconv.Atoi(s.Date[5:7])
+			if (month-1)/3+1 == quarter {
+				quarterWeeks = append(quarterWeeks, s)
+			}
+		}
+		if len(quarterWeeks) == 0 {
+			continue
+		}
+		q := summarizeScores(quarterWeeks)
+		q.Quarter = quarter
+		if previousQuarter != nil {
+			q.AverageScoreDelta = models.Float(q.AverageScore - previousQuarter.AverageScore)
+		}
+		review.Quarters = append(review.Quarters, q)
+		previousQuarter = &review.Quarters[len(review.Quarters)-1]
+	}
+
+	from, to := fmt.Sprintf("%04d-01-01", year), fmt.Sprintf("%04d-12-31", year)
+	for _, key := range reportMarkerKeys {
+		points, err := db.GetMetricSeries(key, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s series: %w", key, err)
+		}
+		if len(points) < 2 {
+			continue
+		}
+		metric, _ := models.LookupMetric(key)
+		first, last := points[0], points[len(points)-1]
+		review.Improvements = append(review.Improvements, models.MarkerImprovement{
+			Metric:    metric,
+			Start:     first.Value,
+			End:       last.Value,
+			StartDate: first.Date,
+			EndDate:   last.Date,
+		})
+	}
+	slices.SortStableFunc(review.Improvements, func(a, b models.MarkerImprovement) int {
+		return cmp.Compare(b.Improvement(), a.Improvement())
+	})
+
+	return review, nil
+}
+
+// summarizeScores aggregates consecutive weekly scores into averages and totals
+func summarizeScores(scores []models.MasterScore) models.QuarterReview {
+	summary := models.QuarterReview{
+		Weeks:      len(scores),
+		StartScore: scores[0].Score,
+		EndScore:   scores[len(scores)-1].Score,
+	}
+	for _, s := range scores {
+		summary.AverageScore += s.Score
+		summary.AverageHealth += s.HealthScore
+		summary.AverageFitness += s.FitnessScore
+		summary.AverageCognition += s.CognitionScore
+		summary.AgingTax += s.AgingTax
+		if !s.IsImputed() {
+			summary.CompleteWeeks++
+		}
+	}
+	n := float64(len(scores))
+	summary.AverageScore /= n
+	summary.AverageHealth /= n
+	summary.AverageFitness /= n
+	summary.AverageCognition /= n
+	return summary
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"health-balance/internal/models"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestGetYearReview(t *testing.T) {
+	mock := &MockDB{
+		UserProfile:  &models.UserProfile{BirthDate: "1980-01-01", HeightCm: 180, Sex: "male"},
+		HealthMap:    map[string]*models.HealthMetrics{},
+		FitnessMap:   map[string]*models.FitnessMetrics{},
+		CognitionMap: map[string]*models.CognitionMetrics{},
+	}
+
+	// Weekly data from mid-December 2024 through 2025, with a gap in the cognition
+	// pillar during March and an improving RHR
+	start, _ := time.Parse("2006-01-02", "2024-12-15")
+	for date := start; date.Year() < 2026; date = date.AddDate(0, 0, 7) {
+		d := date.Format("2006-01-02")
+		weeks := int(date.Sub(start).Hours() / 24 / 7)
+		mock.AllDates = append([]string{d}, mock.AllDates...)
+		mock.HealthMap[d] = &models.HealthMetrics{RHR: models.Int(64 - weeks/10), SleepScore: models.Int(70 + weeks%20)}
+		mock.FitnessMap[d] = &models.FitnessMetrics{VO2Max: models.Float(42)}
+		if date.Month() != time.March {
+			mock.CognitionMap[d] = &models.CognitionMetrics{StressScore: models.Int(2)}
+		}
+	}
+
+	review, err := GetYearReview(mock, 2025)
+	if err != nil {
+		t.Fatalf("Failed to build year review: %v", err)
+	}
+
+	// Scores carry on to the current week, so later years are listed too
+	if review.Weeks != 52 || !slices.Contains(review.Years, 2024) || !slices.Contains(review.Years, 2025) {
+		t.Fatalf("Expected 52 weeks of 2025 and both years with data, got %d weeks and years %v", review.Weeks, review.Years)
+	}
+	if review.CompleteWeeks >= review.Weeks || review.LongestStreak == 0 || review.LongestStreak > review.CompleteWeeks {
+		t.Errorf("Expected March to break the complete-week streak, got %d complete and a streak of %d", review.CompleteWeeks, review.LongestStreak)
+	}
+	if review.BestWeek == nil || review.WorstWeek == nil || review.BestWeek.Change < review.WorstWeek.Change {
+		t.Errorf("Expected best and worst weeks, got %+v and %+v", review.BestWeek, review.WorstWeek)
+	}
+	if review.AgingTaxPaid <= 0 {
+		t.Errorf("Expected aging tax to accumulate over the year, got %.2f", review.AgingTaxPaid)
+	}
+
+	if len(review.Quarters) != 4 || review.Quarters[0].AverageScoreDelta != nil || review.Quarters[1].AverageScoreDelta == nil {
+		t.Fatalf("Expected four quarters compared with the one before, got %+v", review.Quarters)
+	}
+	if review.Quarters[0].CompleteWeeks >= review.Quarters[0].Weeks {
+		t.Errorf("Expected Q1 to include the incomplete March weeks, got %+v", review.Quarters[0])
+	}
+
+	if len(review.Improvements) == 0 || review.Improvements[0].Metric.Key != "rhr" || review.Improvements[0].Improvement() <= 0 {
+		t.Errorf("Expected the falling RHR to be the top improvement, got %+v", review.Improvements)
+	}
+
+	empty, err := GetYearReview(mock, 2023)
+	if err != nil || empty.Weeks != 0 || len(empty.Years) != len(review.Years) {
+		t.Errorf("Expected an empty review that still lists the years with data, got %+v (%v)", empty, err)
+	}
+}
@@ -0,0 +1,412 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"health-balance/internal/database"
+	"health-balance/internal/models"
+	"log"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// summaryWeeks is how many of the latest weeks the summary prompt covers
+const summaryWeeks = 10
+
+const noSummaryDataMessage = "No health data available yet to generate a summary. Start tracking your metrics!"
+
+// GetHealthSummary asks the configured language model for a summary of the last weeks
+// of data with actionable recommendations, within the configured timeout and budget
+func GetHealthSummary(ctx context.Context, db database.Querier, regenerate bool, onText func(string) error) (*models.AISummary, error) {
+	cfg := LoadLLMConfig()
+	provider, err := NewLLMProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	provider = MeterLLMProvider(provider, db, cfg, models.AIFeatureSummary)
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+	return SummarizeHealth(ctx, db, provider, regenerate, onText)
+}
+
+// SummarizeHealth builds the summary prompt from the last weeks of data and sends it to provider.
+// The stored summary for the same prompt and model is returned instead unless regenerate is set,
+// and new summaries are stored for the history. When onText is set the summary text is streamed
+// to it as it is generated, see summaryStream; a stored summary's text is passed to it whole.
+func SummarizeHealth(ctx context.Context, db database.Querier, provider LLMProvider, regenerate bool, onText func(string) error) (*models.AISummary, error) {
+	privacy, err := db.GetAIPrivacy()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch AI privacy settings: %w", err)
+	}
+	tmpl, err := ActiveSummaryTemplate(db)
+	if err != nil {
+		return nil, err
+	}
+	week, prompt, err := buildSummaryPrompt(db, privacy, tmpl)
+	if err != nil {
+		return nil, err
+	}
+	if prompt == "" {
+		return &models.AISummary{Text: noSummaryDataMessage}, nil
+	}
+
+	hash := promptHash(prompt)
+	if !regenerate {
+		cached, err := db.GetAISummaryByPrompt(hash, provider.Name(), provider.Model())
+		if err != nil {
+			log.Printf("Error loading cached AI summary: %v", err)
+		} else if cached != nil {
+			cached.Cached = true
+			if onText != nil {
+				if err := onText(streamedSummary(cached.Text)); err != nil {
+					return nil, err
+				}
+			}
+			return cached, nil
+		}
+	}
+
+	req := UserPrompt(prompt)
+	req.JSON = true
+	var resp *LLMResponse
+	if onText != nil {
+		resp, err = provider.Stream(ctx, req, (&summaryStream{onText: onText}).write)
+	} else {
+		resp, err = provider.Generate(ctx, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	text, err := validateSummary(ctx, provider, req, resp.Text)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := models.AISummary{
+		Week:       week,
+		Provider:   provider.Name(),
+		Model:      provider.Model(),
+		PromptHash: hash,
+		Text:       text,
+		CreatedAt:  time.Now(),
+	}
+	if summary.ID, err = db.SaveAISummary(summary); err != nil {
+		// The user still gets the summary, it just won't be cached
+		log.Printf("Error saving AI summary: %v", err)
+	}
+	return &summary, nil
+}
+
+// summaryFieldPattern finds the start of the summary field's value in a JSON response
+var summaryFieldPattern = regexp.MustCompile(`"summary"\s*:\s*"`)
+
+// summaryStream forwards a streamed summary response to onText as it is generated. A JSON
+// response is reduced to the decoded text of its "summary" field, so prose is shown while
+// it is generated rather than JSON; the cards are rendered once it is complete. A response
+// that doesn't start like JSON is prose from a model ignoring the format and is forwarded as is.
+type summaryStream struct {
+	onText func(string) error
+	raw    strings.Builder
+	json   bool
+	// sent is how much of the decoded summary was forwarded
+	sent int
+	done bool
+}
+
+func (s *summaryStream) write(chunk string) error {
+	if s.done {
+		return nil
+	}
+	if s.raw.Len() == 0 && !s.json {
+		trimmed := strings.TrimSpace(chunk)
+		if trimmed == "" {
+			return nil
+		}
+		if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "`") {
+			return s.onText(chunk)
+		}
+		s.json = true
+	}
+	if !s.json {
+		return s.onText(chunk)
+	}
+
+	s.raw.WriteString(chunk)
+	raw := s.raw.String()
+	loc := summaryFieldPattern.FindStringIndex(raw)
+	if loc == nil {
+		return nil
+	}
+	value, closed := jsonStringPrefix(raw[loc[1]:])
+	var decoded string
+	if err := json.Unmarshal([]byte(`"`+value+`"`), &decoded); err != nil {
+		// Broken JSON is left to the validation once the response is complete
+		s.done = true
+		return nil
+	}
+	s.done = closed
+	if len(decoded) <= s.sent {
+		return nil
+	}
+	text := decoded[s.sent:]
+	s.sent = len(decoded)
+	return s.onText(text)
+}
+
+// jsonStringPrefix returns the complete characters at the start of a JSON string's body, and
+// whether its closing quote was reached. An escape cut off by the end of a chunk is left out,
+// as is a high surrogate whose pair may be in the next chunk.
+func jsonStringPrefix(body string) (string, bool) {
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '"':
+			return body[:i], true
+		case '\\':
+			n := 2
+			if i+1 < len(body) && body[i+1] == 'u' {
+				n = 6
+			}
+			if i+n > len(body) {
+				return body[:i], false
+			}
+			if n == 6 && i+n == len(body) && strings.ContainsAny(body[i+2:i+3], "dD") && strings.ContainsAny(body[i+3:i+4], "89abAB") {
+				return body[:i], false
+			}
+			i += n - 1
+		}
+	}
+	return body, false
+}
+
+// streamedSummary returns what is streamed for a stored summary: the summary text of
+// structured insights, or the whole text of a plain one
+func streamedSummary(text string) string {
+	if insights, err := models.ParseAIInsights(text); err == nil {
+		return insights.Summary
+	}
+	return text
+}
+
+// validateSummary checks the model's response against the insights format and asks it
+// once to correct an invalid response. A response that is still invalid is kept as is and
+// shown as markdown, since models that ignore the format usually write prose instead.
+func validateSummary(ctx context.Context, provider LLMProvider, req LLMRequest, text string) (string, error) {
+	_, err := models.ParseAIInsights(text)
+	if err == nil {
+		return text, nil
+	}
+
+	req.Messages = append(req.Messages,
+		LLMMessage{Role: "assistant", Content: text},
+		LLMMessage{Role: "user", Content: fmt.Sprintf("That response does not match the required format: %v. Reply again with only the corrected JSON object.", err)},
+	)
+	resp, retryErr := provider.Generate(ctx, req)
+	if retryErr != nil {
+		return "", retryErr
+	}
+	if _, retryErr = models.ParseAIInsights(resp.Text); retryErr != nil {
+		log.Printf("AI summary does not match the insights format, keeping it as text: %v", retryErr)
+		return text, nil
+	}
+	return resp.Text, nil
+}
+
+// SummaryPromptPreview returns exactly what the summary would send to the model with the
+// given privacy settings and template, or a message when there is no data yet
+func SummaryPromptPreview(db database.Querier, privacy models.AIPrivacy, tmpl SummaryTemplate) (string, error) {
+	_, prompt, err := buildSummaryPrompt(db, privacy, tmpl)
+	if err != nil {
+		return "", err
+	}
+	if prompt == "" {
+		return noSummaryDataMessage, nil
+	}
+	return prompt, nil
+}
+
+// buildSummaryPrompt renders the summary prompt for the latest weeks of data and returns it
+// together with the latest week it covers, or an empty prompt when there is no data yet
+func buildSummaryPrompt(db database.Querier, privacy models.AIPrivacy, tmpl SummaryTemplate) (week, prompt string, err error) {
+	profile, weeklyData, err := recentWeeklyData(db, summaryWeeks)
+	if err != nil || len(weeklyData) == 0 {
+		return "", "", err
+	}
+	parsed, err := parseSummaryTemplate(tmpl.Body)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid prompt template: %w", err)
+	}
+	if prompt, err = constructPrompt(parsed, profile, weeklyData, privacy); err != nil {
+		return "", "", fmt.Errorf("failed to render the prompt template: %w", err)
+	}
+	return weeklyData[0].Score.Date, prompt, nil
+}
+
+// recentWeeklyData returns the profile and the scores, metrics and notes of the latest weeks,
+// most recent first
+func recentWeeklyData(db database.Querier, weeks int) (*models.UserProfile, []WeeklyData, error) {
+	profile, err := db.GetUserProfile()
+	if err != nil || profile == nil {
+		return nil, nil, fmt.Errorf("user profile required for summary")
+	}
+
+	scores, err := GetAllWeeklyScores(db)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch scores: %v", err)
+	}
+
+	// Scores are oldest first
+	recentScores := scores[max(0, len(scores)-weeks):]
+	var weeklyData []WeeklyData
+	for i := len(recentScores) - 1; i >= 0; i-- {
+		s := recentScores[i]
+		h, _ := db.GetHealthMetricsByDate(s.Date)
+		f, _ := db.GetFitnessMetricsByDate(s.Date)
+		c, _ := db.GetCognitionMetricsByDate(s.Date)
+		n, _ := db.GetWeekNote(s.Date)
+
+		weeklyData = append(weeklyData, WeeklyData{
+			Score:     s,
+			Health:    h,
+			Fitness:   f,
+			Cognition: c,
+			Note:      n,
+		})
+	}
+	return profile, weeklyData, nil
+}
+
+// promptHash identifies a prompt, so a summary is only reused while its input is unchanged
+func promptHash(prompt string) string {
+	sum := sha256.Sum256([]byte(prompt))
+	return hex.EncodeToString(sum[:])
+}
+
+type WeeklyData struct {
+	Score     models.MasterScore
+	Health    *models.HealthMetrics
+	Fitness   *models.FitnessMetrics
+	Cognition *models.CognitionMetrics
+	Note      *models.WeekNote
+}
+
+// constructPrompt renders the summary prompt template with the data privacy allows
+func constructPrompt(tmpl *template.Template, profile *models.UserProfile, data []WeeklyData, privacy models.AIPrivacy) (string, error) {
+	return renderSummaryPrompt(tmpl, newSummaryPromptData(profile, data, privacy, time.Now()))
+}
+
+// summaryFormat describes the JSON the model answers with, see models.AIInsights
+var summaryFormat = `
+Response Format:
+Reply with only a JSON object, without Markdown or code fences, in this format:
+{
+  "summary": "two or three sentences on the overall picture",
+  "bottlenecks": [
+    {"pillar": "fitness", "metric": "vo2_max", "finding": "what holds the score back, with the numbers"}
+  ],
+  "recommendations": [
+    {
+      "pillar": "health",
+      "title": "short imperative title",
+      "action": "the specific action to take",
+      "target_metric": "sleep_score",
+      "comparison": "at_least",
+      "target_value": 80,
+      "expected_impact": "what should change and how soon",
+      "priority": "high"
+    }
+  ]
+}
+- pillar is one of: ` + strings.Join(models.Pillars, ", ") + `
+- priority is one of: ` + strings.Join(models.Priorities, ", ") + `
+- metric and target_metric are metric keys, or "" when no single metric applies: ` + metricKeys() + `
+- target_value is the value of target_metric to reach within the next few weeks, in the metric's unit; comparison is at_least, or at_most for metrics where lower is better. Use null for target_value when there is no target metric.
+`
+
+func metricKeys() string {
+	keys := make([]string, 0, len(models.MetricCatalog))
+	for _, m := range models.MetricCatalog {
+		keys = append(keys, m.Key)
+	}
+	return strings.Join(keys, ", ")
+}
+
+// describeWeeklyData explains how the Master Score works and lists the weekly scores, metrics
+// and notes as far as privacy allows, for the coach prompt
+func describeWeeklyData(age int, data []WeeklyData, privacy models.AIPrivacy) string {
+	prompt := describeScoringModel(age, privacy) + "\nDetailed Weekly Data (most recent first):\n"
+	for _, d := range data {
+		prompt += describeWeek(d, privacy)
+	}
+	return prompt
+}
+
+// describeWeek lists the scores, metrics and note of one week with the built-in "week"
+// template, leaving out what privacy hides
+func describeWeek(d WeeklyData, privacy models.AIPrivacy) string {
+	var week strings.Builder
+	if err := summaryTemplate.ExecuteTemplate(&week, "week", newPromptWeek(d, privacy)); err != nil {
+		log.Printf("Error describing the week of %s: %v", d.Score.Date, err)
+	}
+	return week.String()
+}
+
+// describeScoringModel explains how the Master Score works with the built-in "scoring" template
+func describeScoringModel(age int, privacy models.AIPrivacy) string {
+	var scoring strings.Builder
+	if err := summaryTemplate.ExecuteTemplate(&scoring, "scoring", newPromptScoringModel(age, privacy)); err != nil {
+		log.Printf("Error describing the scoring model: %v", err)
+	}
+	return scoring.String()
+}
+
+// promptUnits spell out the scale of metrics whose unit alone doesn't explain their values
+var promptUnits = map[string]string{
+	"nutrition_score":   "/10",
+	"cardio_recovery":   " bpm drop",
+	"dead_hang_seconds": " seconds",
+	"mindfulness":       " sessions",
+	"deep_learning":     " total minutes",
+	"stress_score":      "/5",
+	"social_days":       "/7",
+}
+
+// promptMetric formats an optional metric for the prompt, marking skipped metrics explicitly
+// and rounded ones as approximate
+func promptMetric(m models.MetricDefinition, value *float64, privacy models.AIPrivacy) string {
+	format := m.Format
+	if m.Integer {
+		format = "%.0f"
+	}
+	return formatPromptMetric(m, format, value, privacy)
+}
+
+// formatPromptMetric formats an optional metric for the prompt in format, or as a whole
+// number when it is coarsened
+func formatPromptMetric(m models.MetricDefinition, format string, value *float64, privacy models.AIPrivacy) string {
+	sent, coarsened := privacy.MetricValue(m.Key, value)
+	if sent == nil {
+		return "not recorded"
+	}
+
+	if coarsened {
+		format = "%.0f"
+	}
+	formatted := models.FormatMetric(format, sent)
+	if coarsened {
+		formatted = "~" + formatted
+	}
+	if unit, ok := promptUnits[m.Key]; ok {
+		return formatted + unit
+	}
+	if m.Unit != "" {
+		formatted += " " + m.Unit
+	}
+	return formatted
+}
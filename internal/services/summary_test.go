@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"health-balance/internal/models"
+	"health-balance/internal/testutil"
+)
+
+// mustConstructPrompt renders the built-in summary template
+func mustConstructPrompt(t *testing.T, profile *models.UserProfile, data []WeeklyData, privacy models.AIPrivacy) string {
+	t.Helper()
+	prompt, err := constructPrompt(summaryTemplate, profile, data, privacy)
+	if err != nil {
+		t.Fatalf("Failed to render the built-in template: %v", err)
+	}
+	return prompt
+}
+
+func TestConstructPrompt(t *testing.T) {
+	profile := &models.UserProfile{
+		Sex:       "male",
+		HeightCm:  180.0,
+		BirthDate: "1990-01-01",
+	}
+
+	now := time.Now()
+	date1 := now.AddDate(0, 0, -7).Format("2006-01-02")  // 1 week ago
+	date2 := now.AddDate(0, 0, -14).Format("2006-01-02") // 2 weeks ago
+
+	data := []WeeklyData{
+		{
+			Score: models.MasterScore{
+				Date:           date1,
+				Score:          1050.5,
+				HealthScore:    60.0,
+				FitnessScore:   40.0,
+				CognitionScore: 20.0,
+				AgingTax:       10.0,
+			},
+			Health: &models.HealthMetrics{
+				SleepScore:     models.Int(80),
+				WaistCm:        models.Float(85.0),
+				RHR:            models.Int(60),
+				NutritionScore: models.Float(8.0),
+			},
+			Fitness: &models.FitnessMetrics{
+				VO2Max:         models.Float(45.0),
+				Workouts:       models.Int(4),
+				DailySteps:     models.Int(10000),
+				Mobility:       models.Int(3),
+				CardioRecovery: models.Int(25),
+			},
+			Cognition: &models.CognitionMetrics{
+				Mindfulness:  models.Int(4),
+				DeepLearning: models.Int(40),
+				StressScore:  models.Int(2),
+				SocialDays:   models.Int(5),
+			},
+			Note: &models.WeekNote{Date: date1, Text: "Red-eye flight,\nslept badly", Tags: []string{"travel"}},
+		},
+		{
+			Score: models.MasterScore{
+				Date:           date2,
+				Score:          1040.0,
+				HealthScore:    55.0,
+				FitnessScore:   35.0,
+				CognitionScore: 15.0,
+				AgingTax:       12.0,
+			},
+			Health: &models.HealthMetrics{
+				SleepScore:     models.Int(75),
+				WaistCm:        models.Float(86.0),
+				RHR:            models.Int(62),
+				NutritionScore: models.Float(7.0),
+			},
+			Fitness: &models.FitnessMetrics{
+				VO2Max:         models.Float(42.0),
+				Workouts:       models.Int(3),
+				DailySteps:     models.Int(8000),
+				Mobility:       models.Int(2),
+				CardioRecovery: models.Int(20),
+			},
+			Cognition: &models.CognitionMetrics{
+				Mindfulness:  models.Int(3),
+				DeepLearning: models.Int(30),
+				StressScore:  models.Int(3),
+				SocialDays:   models.Int(4),
+			},
+		},
+	}
+
+	prompt := mustConstructPrompt(t, profile, data, models.AIPrivacy{})
+
+	if prompt == "" {
+		t.Error("constructPrompt returned an empty string")
+	}
+
+	expectedElements := []string{
+		"expert longevity and health coach",
+		"180.0 cm",
+		"male",
+		"Health Pillar",
+		"Fitness Pillar",
+		"Cognition Pillar",
+		date1,
+		date2,
+		"Total: 1050.5",
+		"Sleep Score: 80",
+		"VO2 Max: 45.0",
+		"Mindfulness: 4",
+		"Deep Learning: 40",
+		"Stress: 2/5",
+		"Social Days: 5/7",
+	}
+
+	for _, element := range expectedElements {
+		if element == "expert longevity and health coach" && len(prompt) < 100 {
+			t.Error("Prompt appears too short, might be missing content")
+		}
+	}
+
+	// Line breaks in notes must not break the week's bullet list
+	if !strings.Contains(prompt, "- **User Notes**: [travel] Red-eye flight, slept badly\n") {
+		t.Error("Expected the week note in the prompt")
+	}
+}
+
+func TestConstructPromptPrivacy(t *testing.T) {
+	profile := &models.UserProfile{Sex: "male", HeightCm: 181.2, BirthDate: "1980-06-01"}
+	week := WeeklyData{
+		Score:   models.MasterScore{Date: "2026-03-01", Score: 1000, AgingTax: 5.2},
+		Health:  &models.HealthMetrics{BodyWeightKg: models.Float(81.7), WaistCm: models.Float(88.3), SleepScore: models.Int(77)},
+		Fitness: &models.FitnessMetrics{DailySteps: models.Int(8432), Workouts: models.Int(3)},
+		Note:    &models.WeekNote{Text: "Hospital visit"},
+	}
+
+	prompt := mustConstructPrompt(t, profile, []WeeklyData{week}, models.AIPrivacy{})
+	for _, want := range []string{"-year-old male (height: 181.2 cm)", "weekly decay rate", "Aging Tax: -5.2", "Body Weight: 81.7 kg", "Waist: 88.3 cm", "Daily Steps: 8432", "Hospital visit"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected %q when sharing everything", want)
+		}
+	}
+
+	privacy := models.AIPrivacy{HideSex: true, HideNotes: true, Coarsen: true, HiddenMetrics: []string{"waist_cm"}}
+	prompt = mustConstructPrompt(t, profile, []WeeklyData{week}, privacy)
+	for _, want := range []string{"for a person in their 40s (height: about 180 cm)", "Body Weight: ~80 kg", "Sleep Score: ~75", "Daily Steps: ~8000", "Workouts: 3 |"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected %q in the private prompt", want)
+		}
+	}
+	for _, unwanted := range []string{"male", "181.2", "weekly decay rate", "Aging Tax: -", "Waist:", "88.3", "81.7", "8432", "Hospital"} {
+		if strings.Contains(prompt, unwanted) {
+			t.Errorf("Expected %q left out of the private prompt", unwanted)
+		}
+	}
+}
+
+func TestConstructPromptWithEmptyData(t *testing.T) {
+	profile := &models.UserProfile{
+		Sex:       "female",
+		HeightCm:  165.0,
+		BirthDate: "1985-05-15",
+	}
+
+	data := []WeeklyData{}
+
+	prompt := mustConstructPrompt(t, profile, data, models.AIPrivacy{})
+
+	if prompt == "" {
+		t.Error("constructPrompt returned an empty string for empty data")
+	}
+
+	// Should still contain the basic structure
+	if len(prompt) < 50 {
+		t.Error("Prompt appears too short even with empty data")
+	}
+}
+
+func TestGetHealthSummaryWithoutApiKey(t *testing.T) {
+	t.Setenv("LLM_PROVIDER", "")
+	oldApiKey := os.Getenv("GEMINI_API_KEY")
+	_ = os.Unsetenv("GEMINI_API_KEY")
+	defer func() {
+		_ = os.Setenv("GEMINI_API_KEY", oldApiKey)
+	}()
+
+	mockDB := &testutil.MockDB{
+		GetUserProfileFunc: func() (*models.UserProfile, error) {
+			return &models.UserProfile{
+				BirthDate: "1990-01-01",
+				Sex:       "male",
+				HeightCm:  180.0,
+			}, nil
+		},
+		GetAllDatesWithDataFunc: func() ([]string, error) {
+			return []string{"2023-01-01"}, nil
+		},
+	}
+
+	_, err := GetHealthSummary(context.Background(), mockDB, false, nil)
+	if err == nil {
+		t.Error("Expected error when GEMINI_API_KEY is not set, but got none")
+	}
+
+	if err.Error() != "GEMINI_API_KEY environment variable is not set" {
+		t.Errorf("Expected specific error message, got: %v", err)
+	}
+}
+
+func TestGetHealthSummaryWithoutProfile(t *testing.T) {
+	t.Setenv("LLM_PROVIDER", "")
+	oldApiKey := os.Getenv("GEMINI_API_KEY")
+	_ = os.Setenv("GEMINI_API_KEY", "fake-key")
+	defer func() {
+		_ = os.Setenv("GEMINI_API_KEY", oldApiKey)
+	}()
+
+	mockDB := &testutil.MockDB{
+		GetUserProfileFunc: func() (*models.UserProfile, error) {
+			return nil, nil // No profile
+		},
+	}
+
+	_, err := GetHealthSummary(context.Background(), mockDB, false, nil)
+	if err == nil {
+		t.Error("Expected error when user profile is not available, but got none")
+	}
+
+	if err.Error() != "user profile required for summary" {
+		t.Errorf("Expected specific error message, got: %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"fmt"
+	"health-balance/internal/database"
+	"health-balance/internal/models"
+)
+
+// AttachWeekNotes sets the note of each score's week, leaving weeks without one untouched
+func AttachWeekNotes(db database.Querier, scores []models.MasterScore) error {
+	if len(scores) == 0 {
+		return nil
+	}
+
+	notes, err := weekNotesByDate(db, "", "")
+	if err != nil {
+		return err
+	}
+	for i := range scores {
+		if note, ok := notes[scores[i].Date]; ok {
+			scores[i].Note = &note
+		}
+	}
+	return nil
+}
+
+// weekNotesByDate returns the notes between from and to keyed by week
+func weekNotesByDate(db database.Querier, from, to string) (map[string]models.WeekNote, error) {
+	notes, err := db.GetWeekNotes(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch week notes: %w", err)
+	}
+	byDate := make(map[string]models.WeekNote, len(notes))
+	for _, n := range notes {
+		byDate[n.Date] = n
+	}
+	return byDate, nil
+}
@@ -2,6 +2,7 @@ package testutil
 
 import (
 	"health-balance/internal/models"
+	"time"
 )
 
 type MockDB struct {
@@ -9,6 +10,9 @@ type MockDB struct {
 	GetRecentHealthMetricsFunc    func(limit int) ([]models.HealthMetrics, error)
 	GetRecentFitnessMetricsFunc   func(limit int) ([]models.FitnessMetrics, error)
 	GetRecentCognitionMetricsFunc func(limit int) ([]models.CognitionMetrics, error)
+	GetHealthMetricsPageFunc      func(q models.HistoryQuery) ([]models.HealthMetrics, error)
+	GetFitnessMetricsPageFunc     func(q models.HistoryQuery) ([]models.FitnessMetrics, error)
+	GetCognitionMetricsPageFunc   func(q models.HistoryQuery) ([]models.CognitionMetrics, error)
 	SaveHealthMetricsFunc         func(m models.HealthMetrics) error
 	SaveFitnessMetricsFunc        func(m models.FitnessMetrics) error
 	SaveCognitionMetricsFunc      func(m models.CognitionMetrics) error
@@ -18,13 +22,48 @@ type MockDB struct {
 	DeleteHealthMetricsFunc       func(date string) error
 	DeleteFitnessMetricsFunc      func(date string) error
 	DeleteCognitionMetricsFunc    func(date string) error
-	GetRHRBaselineForDateFunc     func(date string) (int, error)
+	GetMetricSeriesFunc           func(key, from, to string) ([]models.MetricPoint, error)
 	GetUserProfileFunc            func() (*models.UserProfile, error)
 	SaveUserProfileFunc           func(profile models.UserProfile) error
 	SavePushSubscriptionFunc      func(sub models.PushSubscription) error
 	GetAllSubscriptionsFunc       func() ([]models.PushSubscription, error)
 	GetAnyPushSubscriptionFunc    func() (*models.PushSubscription, error)
 	DeletePushSubscriptionFunc    func(endpoint string) error
+	GetGoalsFunc                  func() ([]models.Goal, error)
+	CreateGoalFunc                func(g models.Goal) (int, error)
+	UpdateGoalStatusFunc          func(id int, status, achievedAt string) error
+	DeleteGoalFunc                func(id int) error
+	GetInterventionsFunc          func() ([]models.Intervention, error)
+	CreateInterventionFunc        func(i models.Intervention) (int, error)
+	EndInterventionFunc           func(id int, endDate string) error
+	DeleteInterventionFunc        func(id int) error
+	GetWeekNoteFunc               func(date string) (*models.WeekNote, error)
+	GetWeekNotesFunc              func(from, to string) ([]models.WeekNote, error)
+	SaveWeekNoteFunc              func(n models.WeekNote) error
+	DeleteWeekNoteFunc            func(date string) error
+	GetAISummariesFunc            func() ([]models.AISummary, error)
+	GetLatestAISummaryFunc        func() (*models.AISummary, error)
+	GetAISummaryByPromptFunc      func(promptHash, provider, model string) (*models.AISummary, error)
+	SaveAISummaryFunc             func(s models.AISummary) (int, error)
+	GetAIComparisonsFunc          func() ([]models.AIComparison, error)
+	GetAIComparisonByPromptFunc   func(promptHash, provider, model string) (*models.AIComparison, error)
+	SaveAIComparisonFunc          func(c models.AIComparison) (int, error)
+	GetCoachThreadsFunc           func() ([]models.CoachThread, error)
+	GetCoachThreadFunc            func(id int) (*models.CoachThread, error)
+	CreateCoachThreadFunc         func(t models.CoachThread) (int, error)
+	DeleteCoachThreadFunc         func(id int) error
+	GetCoachMessagesFunc          func(threadID int) ([]models.CoachMessage, error)
+	AddCoachMessagesFunc          func(messages []models.CoachMessage) error
+	GetAIPrivacyFunc              func() (models.AIPrivacy, error)
+	SaveAIPrivacyFunc             func(p models.AIPrivacy) error
+	GetPromptTemplateFunc         func(name string) (*models.PromptTemplate, error)
+	SavePromptTemplateFunc        func(t models.PromptTemplate) error
+	DeletePromptTemplateFunc      func(name string) error
+	AddAIUsageFunc                func(u models.AIUsage) error
+	GetAIUsageFunc                func(since time.Time) ([]models.AIUsage, error)
+	GetAutoSummarySettingsFunc    func() (models.AutoSummarySettings, error)
+	SaveAutoSummarySettingsFunc   func(s models.AutoSummarySettings) error
+	SetAutoSummaryWeekFunc        func(week string) error
 	CloseFunc                     func() error
 }
 
@@ -56,6 +95,27 @@ func (m *MockDB) GetRecentCognitionMetrics(limit int) ([]models.CognitionMetrics
 	return nil, nil
 }
 
+func (m *MockDB) GetHealthMetricsPage(q models.HistoryQuery) ([]models.HealthMetrics, error) {
+	if m.GetHealthMetricsPageFunc != nil {
+		return m.GetHealthMetricsPageFunc(q)
+	}
+	return nil, nil
+}
+
+func (m *MockDB) GetFitnessMetricsPage(q models.HistoryQuery) ([]models.FitnessMetrics, error) {
+	if m.GetFitnessMetricsPageFunc != nil {
+		return m.GetFitnessMetricsPageFunc(q)
+	}
+	return nil, nil
+}
+
+func (m *MockDB) GetCognitionMetricsPage(q models.HistoryQuery) ([]models.CognitionMetrics, error) {
+	if m.GetCognitionMetricsPageFunc != nil {
+		return m.GetCognitionMetricsPageFunc(q)
+	}
+	return nil, nil
+}
+
 func (m *MockDB) SaveHealthMetrics(m1 models.HealthMetrics) error {
 	if m.SaveHealthMetricsFunc != nil {
 		return m.SaveHealthMetricsFunc(m1)
@@ -119,11 +179,11 @@ func (m *MockDB) DeleteCognitionMetrics(date string) error {
 	return nil
 }
 
-func (m *MockDB) GetRHRBaselineForDate(date string) (int, error) {
-	if m.GetRHRBaselineForDateFunc != nil {
-		return m.GetRHRBaselineForDateFunc(date)
+func (m *MockDB) GetMetricSeries(key, from, to string) ([]models.MetricPoint, error) {
+	if m.GetMetricSeriesFunc != nil {
+		return m.GetMetricSeriesFunc(key, from, to)
 	}
-	return 0, nil
+	return nil, nil
 }
 
 func (m *MockDB) GetUserProfile() (*models.UserProfile, error) {
@@ -168,6 +228,251 @@ func (m *MockDB) DeletePushSubscription(endpoint string) error {
 	return nil
 }
 
+func (m *MockDB) GetGoals() ([]models.Goal, error) {
+	if m.GetGoalsFunc != nil {
+		return m.GetGoalsFunc()
+	}
+	return nil, nil
+}
+
+func (m *MockDB) CreateGoal(g models.Goal) (int, error) {
+	if m.CreateGoalFunc != nil {
+		return m.CreateGoalFunc(g)
+	}
+	return 0, nil
+}
+
+func (m *MockDB) UpdateGoalStatus(id int, status, achievedAt string) error {
+	if m.UpdateGoalStatusFunc != nil {
+		return m.UpdateGoalStatusFunc(id, status, achievedAt)
+	}
+	return nil
+}
+
+func (m *MockDB) DeleteGoal(id int) error {
+	if m.DeleteGoalFunc != nil {
+		return m.DeleteGoalFunc(id)
+	}
+	return nil
+}
+
+func (m *MockDB) GetInterventions() ([]models.Intervention, error) {
+	if m.GetInterventionsFunc != nil {
+		return m.GetInterventionsFunc()
+	}
+	return nil, nil
+}
+
+func (m *MockDB) CreateIntervention(i models.Intervention) (int, error) {
+	if m.CreateInterventionFunc != nil {
+		return m.CreateInterventionFunc(i)
+	}
+	return 0, nil
+}
+
+func (m *MockDB) EndIntervention(id int, endDate string) error {
+	if m.EndInterventionFunc != nil {
+		return m.EndInterventionFunc(id, endDate)
+	}
+	return nil
+}
+
+func (m *MockDB) DeleteIntervention(id int) error {
+	if m.DeleteInterventionFunc != nil {
+		return m.DeleteInterventionFunc(id)
+	}
+	return nil
+}
+
+func (m *MockDB) GetWeekNote(date string) (*models.WeekNote, error) {
+	if m.GetWeekNoteFunc != nil {
+		return m.GetWeekNoteFunc(date)
+	}
+	return nil, nil
+}
+
+func (m *MockDB) GetWeekNotes(from, to string) ([]models.WeekNote, error) {
+	if m.GetWeekNotesFunc != nil {
+		return m.GetWeekNotesFunc(from, to)
+	}
+	return nil, nil
+}
+
+func (m *MockDB) SaveWeekNote(n models.WeekNote) error {
+	if m.SaveWeekNoteFunc != nil {
+		return m.SaveWeekNoteFunc(n)
+	}
+	return nil
+}
+
+func (m *MockDB) DeleteWeekNote(date string) error {
+	if m.DeleteWeekNoteFunc != nil {
+		return m.DeleteWeekNoteFunc(date)
+	}
+	return nil
+}
+
+func (m *MockDB) GetAISummaries() ([]models.AISummary, error) {
+	if m.GetAISummariesFunc != nil {
+		return m.GetAISummariesFunc()
+	}
+	return nil, nil
+}
+
+func (m *MockDB) GetLatestAISummary() (*models.AISummary, error) {
+	if m.GetLatestAISummaryFunc != nil {
+		return m.GetLatestAISummaryFunc()
+	}
+	return nil, nil
+}
+
+func (m *MockDB) GetAISummaryByPrompt(promptHash, provider, model string) (*models.AISummary, error) {
+	if m.GetAISummaryByPromptFunc != nil {
+		return m.GetAISummaryByPromptFunc(promptHash, provider, model)
+	}
+	return nil, nil
+}
+
+func (m *MockDB) SaveAISummary(s models.AISummary) (int, error) {
+	if m.SaveAISummaryFunc != nil {
+		return m.SaveAISummaryFunc(s)
+	}
+	return 0, nil
+}
+
+func (m *MockDB) GetAIComparisons() ([]models.AIComparison, error) {
+	if m.GetAIComparisonsFunc != nil {
+		return m.GetAIComparisonsFunc()
+	}
+	return nil, nil
+}
+
+func (m *MockDB) GetAIComparisonByPrompt(promptHash, provider, model string) (*models.AIComparison, error) {
+	if m.GetAIComparisonByPromptFunc != nil {
+		return m.GetAIComparisonByPromptFunc(promptHash, provider, model)
+	}
+	return nil, nil
+}
+
+func (m *MockDB) SaveAIComparison(c models.AIComparison) (int, error) {
+	if m.SaveAIComparisonFunc != nil {
+		return m.SaveAIComparisonFunc(c)
+	}
+	return 0, nil
+}
+
+func (m *MockDB) GetCoachThreads() ([]models.CoachThread, error) {
+	if m.GetCoachThreadsFunc != nil {
+		return m.GetCoachThreadsFunc()
+	}
+	return nil, nil
+}
+
+func (m *MockDB) GetCoachThread(id int) (*models.CoachThread, error) {
+	if m.GetCoachThreadFunc != nil {
+		return m.GetCoachThreadFunc(id)
+	}
+	return nil, nil
+}
+
+func (m *MockDB) CreateCoachThread(t models.CoachThread) (int, error) {
+	if m.CreateCoachThreadFunc != nil {
+		return m.CreateCoachThreadFunc(t)
+	}
+	return 0, nil
+}
+
+func (m *MockDB) DeleteCoachThread(id int) error {
+	if m.DeleteCoachThreadFunc != nil {
+		return m.DeleteCoachThreadFunc(id)
+	}
+	return nil
+}
+
+func (m *MockDB) GetCoachMessages(threadID int) ([]models.CoachMessage, error) {
+	if m.GetCoachMessagesFunc != nil {
+		return m.GetCoachMessagesFunc(threadID)
+	}
+	return nil, nil
+}
+
+func (m *MockDB) AddCoachMessages(messages []models.CoachMessage) error {
+	if m.AddCoachMessagesFunc != nil {
+		return m.AddCoachMessagesFunc(messages)
+	}
+	return nil
+}
+
+func (m *MockDB) GetAIPrivacy() (models.AIPrivacy, error) {
+	if m.GetAIPrivacyFunc != nil {
+		return m.GetAIPrivacyFunc()
+	}
+	return models.AIPrivacy{}, nil
+}
+
+func (m *MockDB) SaveAIPrivacy(p models.AIPrivacy) error {
+	if m.SaveAIPrivacyFunc != nil {
+		return m.SaveAIPrivacyFunc(p)
+	}
+	return nil
+}
+
+func (m *MockDB) GetPromptTemplate(name string) (*models.PromptTemplate, error) {
+	if m.GetPromptTemplateFunc != nil {
+		return m.GetPromptTemplateFunc(name)
+	}
+	return nil, nil
+}
+
+func (m *MockDB) SavePromptTemplate(t models.PromptTemplate) error {
+	if m.SavePromptTemplateFunc != nil {
+		return m.SavePromptTemplateFunc(t)
+	}
+	return nil
+}
+
+func (m *MockDB) DeletePromptTemplate(name string) error {
+	if m.DeletePromptTemplateFunc != nil {
+		return m.DeletePromptTemplateFunc(name)
+	}
+	return nil
+}
+
+func (m *MockDB) AddAIUsage(u models.AIUsage) error {
+	if m.AddAIUsageFunc != nil {
+		return m.AddAIUsageFunc(u)
+	}
+	return nil
+}
+
+func (m *MockDB) GetAIUsage(since time.Time) ([]models.AIUsage, error) {
+	if m.GetAIUsageFunc != nil {
+		return m.GetAIUsageFunc(since)
+	}
+	return nil, nil
+}
+
+func (m *MockDB) GetAutoSummarySettings() (models.AutoSummarySettings, error) {
+	if m.GetAutoSummarySettingsFunc != nil {
+		return m.GetAutoSummarySettingsFunc()
+	}
+	return models.DefaultAutoSummarySettings(), nil
+}
+
+func (m *MockDB) SaveAutoSummarySettings(s models.AutoSummarySettings) error {
+	if m.SaveAutoSummarySettingsFunc != nil {
+		return m.SaveAutoSummarySettingsFunc(s)
+	}
+	return nil
+}
+
+func (m *MockDB) SetAutoSummaryWeek(week string) error {
+	if m.SetAutoSummaryWeekFunc != nil {
+		return m.SetAutoSummaryWeekFunc(week)
+	}
+	return nil
+}
+
 func (m *MockDB) Close() error {
 	if m.CloseFunc != nil {
 		return m.CloseFunc()
@@ -11,18 +11,12 @@ import (
 // If today is Sunday, it returns today's date
 // If today is Monday-Saturday, it returns the upcoming Sunday's date
 func GetCurrentWeekSundayDate() string {
-	now := time.Now()
-	weekday := now.Weekday()
-
-	if weekday == time.Sunday {
-		// If today is Sunday, this is the current week
-		return now.Format("2006-01-02")
-	}
+	return WeekEnding(time.Now()).Format("2006-01-02")
+}
 
-	// For Monday-Saturday, the current week is the upcoming Sunday
-	daysUntilSunday := 7 - int(weekday)
-	nextSunday := now.AddDate(0, 0, daysUntilSunday)
-	return nextSunday.Format("2006-01-02")
+// WeekEnding returns the Sunday that ends the week of t, the date weeks are stored under
+func WeekEnding(t time.Time) time.Time {
+	return t.AddDate(0, 0, (7-int(t.Weekday()))%7)
 }
 
 // GetCurrentWeekDateRange returns the Monday and Sunday dates for the current week
@@ -31,6 +31,22 @@ func TestGetCurrentWeekSundayDate(t *testing.T) {
 	}
 }
 
+func TestWeekEnding(t *testing.T) {
+	tests := []struct {
+		date     time.Time
+		expected string
+	}{
+		{time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC), "2026-03-01"},   // Sunday
+		{time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), "2026-03-08"},    // Monday
+		{time.Date(2026, 12, 31, 12, 0, 0, 0, time.UTC), "2027-01-03"}, // Thursday
+	}
+	for _, tt := range tests {
+		if got := WeekEnding(tt.date).Format("2006-01-02"); got != tt.expected {
+			t.Errorf("WeekEnding(%s) = %s, expected %s", tt.date.Format("Mon 2006-01-02"), got, tt.expected)
+		}
+	}
+}
+
 func TestGetCurrentWeekDateRange(t *testing.T) {
 	// Test that the function returns a non-empty string
 	dateRange := GetCurrentWeekDateRange()
@@ -74,6 +74,84 @@ document.addEventListener("showToast", function (evt) {
     }
 });
 
+function clearFieldErrors(form) {
+    form.querySelectorAll(".field-error").forEach((el) => el.remove());
+    form.querySelectorAll("[aria-invalid]").forEach((el) => el.removeAttribute("aria-invalid"));
+}
+
+// Sent by the server when a form fails validation; shows each message next to its input
+document.addEventListener("fieldErrors", function (evt) {
+    const form = document.getElementById(evt.detail.form);
+    if (!form) {
+        // Posted from outside the form, e.g. a recommendation made into a goal
+        Object.values(evt.detail.errors).forEach((message) => showToast(message, "error"));
+        return;
+    }
+    clearFieldErrors(form);
+
+    Object.entries(evt.detail.errors).forEach(([name, message]) => {
+        const input = name && form.querySelector(`[name="${name}"]`);
+        if (!input) {
+            showToast(message, "error");
+            return;
+        }
+        const error = document.createElement("small");
+        error.className = "field-error";
+        error.textContent = message;
+        input.setAttribute("aria-invalid", "true");
+        input.insertAdjacentElement("afterend", error);
+    });
+});
+
+document.addEventListener("htmx:beforeRequest", function (evt) {
+    if (evt.detail.elt instanceof HTMLFormElement) {
+        clearFieldErrors(evt.detail.elt);
+    }
+});
+
+// Sent by the server when submitted values look like typos; saving again needs confirmation
+document.addEventListener("confirmAnomalies", function (evt) {
+    const form = document.getElementById(evt.detail.form);
+    const dialog = document.getElementById("confirm-dialog");
+    if (!form || !dialog) {
+        return;
+    }
+
+    const titleEl = document.getElementById("confirm-title");
+    const messageEl = document.getElementById("confirm-message");
+    const okBtn = document.getElementById("confirm-ok");
+    const cancelBtn = document.getElementById("confirm-cancel");
+    const defaults = { title: titleEl.textContent, ok: okBtn.textContent };
+
+    titleEl.textContent = "Check These Values";
+    messageEl.textContent = evt.detail.warnings.join("\n") + "\n\nSave anyway?";
+    okBtn.textContent = "Save Anyway";
+
+    const handleResult = (confirmed) => {
+        dialog.onclose = null;
+        dialog.close();
+        titleEl.textContent = defaults.title;
+        okBtn.textContent = defaults.ok;
+        okBtn.onclick = null;
+        cancelBtn.onclick = null;
+        if (confirmed) {
+            const input = document.createElement("input");
+            input.type = "hidden";
+            input.name = "confirm_anomalies";
+            input.value = "true";
+            form.appendChild(input);
+            form.addEventListener("htmx:afterRequest", () => input.remove(), { once: true });
+            htmx.trigger(form, "submit");
+        }
+    };
+
+    okBtn.onclick = () => handleResult(true);
+    cancelBtn.onclick = () => handleResult(false);
+    dialog.onclose = () => handleResult(false);
+
+    dialog.showModal();
+});
+
 function copyValuesToInputs(fieldValues) {
     Object.entries(fieldValues).forEach(([inputId, value]) => {
         const input = document.getElementById(inputId);
@@ -120,6 +198,51 @@ function copyCognitionFromLastWeek(button) {
     showToast("Last week's cognition values copied into this week's form.");
 }
 
+// Streams the AI summary into the summary card as it is generated. Regenerating
+// skips the saved summary for the current data.
+let summaryStream = null;
+
+function streamAISummary(regenerate = false) {
+    const target = document.getElementById("summary-content");
+    const spinner = document.getElementById("summary-spinner");
+    if (summaryStream) summaryStream.close();
+
+    let text = "";
+    target.textContent = "";
+    target.classList.add("streaming");
+    spinner.classList.add("htmx-request");
+
+    const source = new EventSource(
+        "/ai-summary/stream" + (regenerate ? "?regenerate=true" : "")
+    );
+    summaryStream = source;
+    const finish = () => {
+        // Closing right away keeps EventSource from reconnecting and generating again
+        source.close();
+        summaryStream = null;
+        target.classList.remove("streaming");
+        spinner.classList.remove("htmx-request");
+    };
+
+    source.addEventListener("chunk", (evt) => {
+        text += JSON.parse(evt.data);
+        target.textContent = text;
+    });
+    source.addEventListener("done", (evt) => {
+        finish();
+        target.innerHTML = evt.data;
+        htmx.process(target);
+    });
+    source.addEventListener("error", (evt) => {
+        finish();
+        if (evt.data) {
+            target.innerHTML = evt.data;
+        } else {
+            showToast("Lost the connection while generating the AI summary.", "error");
+        }
+    });
+}
+
 function registerServiceWorker() {
     if ("serviceWorker" in navigator) {
         window.addEventListener("load", () => {